@@ -21,129 +21,92 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/cruntime"
-	"k8s.io/minikube/pkg/minikube/exit"
-	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/util/retry"
 )
 
+// pollInterval is how often the idle time is checked
+const pollInterval = 5 * time.Second
+
 var (
-	unpauseRequests = make(chan struct{})
-	done            = make(chan struct{})
-	mu              sync.Mutex
-	runtimePaused   bool
-	version         = "0.0.1"
-
-	runtime  = flag.String("container-runtime", "docker", "Container runtime to use for (un)pausing")
-	interval = flag.Duration("interval", time.Minute*1, "Interval of inactivity for pause to occur")
+	version = "0.0.2"
+
+	runtime       = flag.String("container-runtime", "docker", "Container runtime to use for (un)pausing")
+	interval      = flag.Duration("interval", time.Minute*1, "Interval of inactivity (no API server requests) for pause to occur")
+	gracePeriod   = flag.Duration("grace-period", 0, "Minimum time the cluster keeps running after it was unpaused")
+	namespaces    = flag.String("namespaces", "kube-system", "Comma separated list of namespaces whose containers are paused")
+	listenAddress = flag.String("listen-address", "0.0.0.0:8080", "Address the auto-pause server listens on")
 )
 
 func main() {
 	flag.Parse()
 
-	tickerChannel := time.NewTicker(*interval)
+	p := newPauser(runtimeOps{
+		pause:   runPause,
+		unpause: runUnpause,
+		paused:  alreadyPaused,
+	}, *interval, *gracePeriod)
 
 	// Check current state
-	alreadyPaused()
-
-	// channel for incoming messages
-	go func() {
-		for {
-			select {
-			case <-tickerChannel.C:
-				tickerChannel.Stop()
-				runPause()
-			case <-unpauseRequests:
-				tickerChannel.Stop()
-				log.Println("Got request")
-				runUnpause()
-				tickerChannel.Reset(*interval)
-
-				done <- struct{}{}
-			}
-		}
-	}()
+	p.check()
+	go p.run(pollInterval, nil)
 
-	http.HandleFunc("/", handler) // each request calls handler
-	fmt.Printf("Starting auto-pause server %s at port 8080 \n", version)
-	log.Fatal(http.ListenAndServe("0.0.0.0:8080", nil))
+	fmt.Printf("Starting auto-pause server %s at %s\n", version, *listenAddress)
+	log.Fatal(http.ListenAndServe(*listenAddress, p.handler()))
 }
 
-// handler echoes the Path component of the requested URL.
-func handler(w http.ResponseWriter, _ *http.Request) {
-	unpauseRequests <- struct{}{}
-	<-done
-	fmt.Fprintf(w, "allow")
-}
-
-func runPause() {
-	mu.Lock()
-	defer mu.Unlock()
-	if runtimePaused {
-		log.Println("Already paused, skipping")
-		return
-	}
-	log.Println("Pausing...")
-
-	r := command.NewExecRunner(true)
-
-	cr, err := cruntime.New(cruntime.Config{Type: *runtime, Runner: r})
-	if err != nil {
-		exit.Error(reason.InternalNewRuntime, "Failed runtime", err)
-	}
-
-	uids, err := cluster.Pause(cr, r, []string{"kube-system"})
-	if err != nil {
-		exit.Error(reason.GuestPause, "Pause", err)
+// namespaceList returns the namespaces to pause
+func namespaceList() []string {
+	var nss []string
+	for _, ns := range strings.Split(*namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			nss = append(nss, ns)
+		}
 	}
-
-	runtimePaused = true
-
-	log.Printf("Paused %d containers", len(uids))
+	return nss
 }
 
-func runUnpause() {
-	mu.Lock()
-	defer mu.Unlock()
-	if !runtimePaused {
-		log.Println("Already unpaused, skipping")
-		return
-	}
-	log.Println("Unpausing...")
-
+func newRuntime() (cruntime.Manager, command.Runner, error) {
 	r := command.NewExecRunner(true)
-
 	cr, err := cruntime.New(cruntime.Config{Type: *runtime, Runner: r})
 	if err != nil {
-		exit.Error(reason.InternalNewRuntime, "Failed runtime", err)
+		return nil, nil, fmt.Errorf("runtime: %w", err)
 	}
+	return cr, r, nil
+}
 
-	uids, err := cluster.Unpause(cr, r, nil)
+func runPause() (int, error) {
+	cr, r, err := newRuntime()
 	if err != nil {
-		exit.Error(reason.GuestUnpause, "Unpause", err)
+		return 0, err
 	}
-	runtimePaused = false
-
-	log.Printf("Unpaused %d containers", len(uids))
+	uids, err := cluster.Pause(cr, r, namespaceList())
+	return len(uids), err
 }
 
-func alreadyPaused() {
-	mu.Lock()
-	defer mu.Unlock()
-
-	r := command.NewExecRunner(true)
-	cr, err := cruntime.New(cruntime.Config{Type: *runtime, Runner: r})
+func runUnpause() (int, error) {
+	cr, r, err := newRuntime()
 	if err != nil {
-		exit.Error(reason.InternalNewRuntime, "Failed runtime", err)
+		return 0, err
 	}
+	var uids []string
+	// the request is held by the proxy meanwhile, so retry a few times before giving up
+	err = retry.Expo(func() error {
+		uids, err = cluster.Unpause(cr, r, nil)
+		return err
+	}, 250*time.Millisecond, 3*time.Second)
+	return len(uids), err
+}
 
-	runtimePaused, err = cluster.CheckIfPaused(cr, []string{"kube-system"})
+func alreadyPaused() (bool, error) {
+	cr, _, err := newRuntime()
 	if err != nil {
-		exit.Error(reason.GuestCheckPaused, "Fail check if container paused", err)
+		return false, err
 	}
-	log.Printf("containers paused status: %t", runtimePaused)
+	return cluster.CheckIfPaused(cr, namespaceList())
 }
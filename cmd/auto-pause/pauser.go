@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// runtimeOps (un)pauses the containers of the cluster, returning the number of affected containers
+type runtimeOps struct {
+	pause   func() (int, error)
+	unpause func() (int, error)
+	// paused returns whether the containers are currently paused
+	paused func() (bool, error)
+}
+
+// pauser pauses the cluster once no API request was seen for the idle interval
+type pauser struct {
+	ops runtimeOps
+	now func() time.Time
+
+	// interval is the period of inactivity after which the cluster is paused
+	interval time.Duration
+	// gracePeriod is the minimum time the cluster keeps running after it was unpaused
+	gracePeriod time.Duration
+
+	mu sync.Mutex
+	// known is false until the paused state was read from the runtime
+	known        bool
+	paused       bool
+	lastActivity time.Time
+	resumedAt    time.Time
+	pausedAt     time.Time
+	pauseCount   int
+	unpauseCount int
+	errorCount   int
+	pausedTotal  time.Duration
+	lastError    string
+}
+
+// status is the response of the /status endpoint
+type status struct {
+	Paused        bool      `json:"paused"`
+	LastActivity  time.Time `json:"lastActivity"`
+	Interval      string    `json:"interval"`
+	GracePeriod   string    `json:"gracePeriod"`
+	PauseCount    int       `json:"pauseCount"`
+	UnpauseCount  int       `json:"unpauseCount"`
+	PausedSeconds float64   `json:"pausedSeconds"`
+	LastError     string    `json:"lastError,omitempty"`
+}
+
+func newPauser(ops runtimeOps, interval time.Duration, gracePeriod time.Duration) *pauser {
+	p := &pauser{
+		ops:         ops,
+		now:         time.Now,
+		interval:    interval,
+		gracePeriod: gracePeriod,
+	}
+	p.lastActivity = p.now()
+	p.resumedAt = p.lastActivity
+	return p
+}
+
+// check reads the paused state from the runtime if it is not known yet
+func (p *pauser) check() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkLocked()
+}
+
+func (p *pauser) checkLocked() bool {
+	if p.known {
+		return true
+	}
+	paused, err := p.ops.paused()
+	if err != nil {
+		p.failed("check if containers are paused", err)
+		return false
+	}
+	p.known = true
+	p.paused = paused
+	if paused {
+		p.pausedAt = p.now()
+	}
+	log.Printf("containers paused status: %t", paused)
+	return true
+}
+
+// tick pauses the cluster if it was idle for long enough. Runtime errors are
+// recorded and the operation is retried on the next tick.
+func (p *pauser) tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.checkLocked() || p.paused {
+		return
+	}
+	now := p.now()
+	if now.Sub(p.lastActivity) < p.interval || now.Sub(p.resumedAt) < p.gracePeriod {
+		return
+	}
+
+	log.Println("Pausing...")
+	n, err := p.ops.pause()
+	if err != nil {
+		p.failed("pause", err)
+		return
+	}
+	p.paused = true
+	p.pausedAt = now
+	p.pauseCount++
+	p.lastError = ""
+	log.Printf("Paused %d containers", n)
+}
+
+// activity records an API request and unpauses the cluster if needed
+func (p *pauser) activity() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastActivity = p.now()
+	if !p.checkLocked() {
+		// the state is unknown, make sure the request is not blocked by paused containers
+		p.paused = true
+	}
+	if !p.paused {
+		return nil
+	}
+
+	log.Println("Unpausing...")
+	n, err := p.ops.unpause()
+	if err != nil {
+		p.failed("unpause", err)
+		return err
+	}
+	now := p.now()
+	if p.known {
+		p.pausedTotal += now.Sub(p.pausedAt)
+	}
+	p.known = true
+	p.paused = false
+	p.resumedAt = now
+	p.unpauseCount++
+	p.lastError = ""
+	log.Printf("Unpaused %d containers", n)
+	return nil
+}
+
+func (p *pauser) failed(op string, err error) {
+	p.errorCount++
+	p.lastError = fmt.Sprintf("%s: %v", op, err)
+	log.Printf("Failed to %s, will retry: %v", op, err)
+}
+
+// status returns a snapshot of the current state
+func (p *pauser) status() status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pausedTotal := p.pausedTotal
+	if p.known && p.paused {
+		pausedTotal += p.now().Sub(p.pausedAt)
+	}
+	return status{
+		Paused:        p.paused,
+		LastActivity:  p.lastActivity,
+		Interval:      p.interval.String(),
+		GracePeriod:   p.gracePeriod.String(),
+		PauseCount:    p.pauseCount,
+		UnpauseCount:  p.unpauseCount,
+		PausedSeconds: pausedTotal.Seconds(),
+		LastError:     p.lastError,
+	}
+}
+
+// run pauses the cluster when idle until stop is closed
+func (p *pauser) run(poll time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(poll)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			p.tick()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// handleActivity is called by the API server proxy for every new connection,
+// the request is only let through once the cluster is running.
+func (p *pauser) handleActivity(w http.ResponseWriter, _ *http.Request) {
+	if err := p.activity(); err != nil {
+		http.Error(w, "deny", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(w, "allow")
+}
+
+func (p *pauser) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.status()); err != nil {
+		log.Printf("Failed to write status: %v", err)
+	}
+}
+
+func (p *pauser) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintf(w, "ok")
+}
+
+// handleMetrics writes the metrics in the Prometheus text format
+func (p *pauser) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	s := p.status()
+	p.mu.Lock()
+	errorCount := p.errorCount
+	p.mu.Unlock()
+
+	paused := 0
+	if s.Paused {
+		paused = 1
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"auto_pause_paused", "gauge", "Whether the cluster is paused.", float64(paused)},
+		{"auto_pause_pauses_total", "counter", "Number of times the cluster was paused.", float64(s.PauseCount)},
+		{"auto_pause_unpauses_total", "counter", "Number of times the cluster was unpaused.", float64(s.UnpauseCount)},
+		{"auto_pause_paused_seconds_total", "counter", "Time the cluster spent paused.", s.PausedSeconds},
+		{"auto_pause_last_activity_timestamp_seconds", "gauge", "Unix time of the last API server request.", float64(s.LastActivity.UnixNano()) / 1e9},
+		{"auto_pause_runtime_errors_total", "counter", "Number of failed container runtime operations.", float64(errorCount)},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+}
+
+// handler returns the HTTP handler of the auto-pause server
+func (p *pauser) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", p.handleStatus)
+	mux.HandleFunc("/healthz", p.handleHealthz)
+	mux.HandleFunc("/metrics", p.handleMetrics)
+	// the proxy requests /<client address>
+	mux.HandleFunc("/", p.handleActivity)
+	return mux
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRuntime records the (un)pause calls and fails them while err is set
+type fakeRuntime struct {
+	paused bool
+	err    error
+}
+
+func (f *fakeRuntime) ops() runtimeOps {
+	return runtimeOps{
+		pause: func() (int, error) {
+			if f.err != nil {
+				return 0, f.err
+			}
+			f.paused = true
+			return 3, nil
+		},
+		unpause: func() (int, error) {
+			if f.err != nil {
+				return 0, f.err
+			}
+			f.paused = false
+			return 3, nil
+		},
+		paused: func() (bool, error) {
+			return f.paused, f.err
+		},
+	}
+}
+
+func newTestPauser(f *fakeRuntime, interval time.Duration, grace time.Duration) (*pauser, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newPauser(f.ops(), interval, grace)
+	p.now = func() time.Time { return now }
+	p.lastActivity = now
+	p.resumedAt = now
+	return p, &now
+}
+
+func TestPauseWhenIdle(t *testing.T) {
+	f := &fakeRuntime{}
+	p, now := newTestPauser(f, time.Minute, 0)
+	p.check()
+
+	*now = now.Add(30 * time.Second)
+	p.tick()
+	if f.paused {
+		t.Fatalf("paused before the interval elapsed")
+	}
+
+	// a request restarts the idle interval
+	if err := p.activity(); err != nil {
+		t.Fatalf("activity: %v", err)
+	}
+	*now = now.Add(45 * time.Second)
+	p.tick()
+	if f.paused {
+		t.Fatalf("paused %s after the last request", 45*time.Second)
+	}
+
+	*now = now.Add(15 * time.Second)
+	p.tick()
+	if !f.paused {
+		t.Fatalf("not paused after an idle interval")
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if err := p.activity(); err != nil {
+		t.Fatalf("activity: %v", err)
+	}
+	if f.paused {
+		t.Fatalf("request did not unpause the cluster")
+	}
+	s := p.status()
+	if s.PauseCount != 1 || s.UnpauseCount != 1 || s.PausedSeconds != 600 || s.Paused {
+		t.Errorf("unexpected status: %+v", s)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	f := &fakeRuntime{paused: true}
+	p, now := newTestPauser(f, time.Minute, 5*time.Minute)
+	p.check()
+
+	if err := p.activity(); err != nil {
+		t.Fatalf("activity: %v", err)
+	}
+	*now = now.Add(2 * time.Minute)
+	p.tick()
+	if f.paused {
+		t.Fatalf("paused within the grace period")
+	}
+	*now = now.Add(3 * time.Minute)
+	p.tick()
+	if !f.paused {
+		t.Fatalf("not paused after the grace period")
+	}
+}
+
+func TestRuntimeErrorsAreRetried(t *testing.T) {
+	f := &fakeRuntime{err: errors.New("runtime is down")}
+	p, now := newTestPauser(f, time.Minute, 0)
+
+	p.check()
+	*now = now.Add(2 * time.Minute)
+	p.tick()
+	if s := p.status(); !strings.Contains(s.LastError, "runtime is down") {
+		t.Errorf("LastError = %q; want the runtime error", s.LastError)
+	}
+
+	f.err = nil
+	p.tick()
+	if !f.paused {
+		t.Fatalf("pause was not retried once the runtime recovered")
+	}
+	if s := p.status(); s.LastError != "" || s.PauseCount != 1 {
+		t.Errorf("unexpected status after recovery: %+v", s)
+	}
+
+	f.err = errors.New("runtime is down")
+	if err := p.activity(); err == nil {
+		t.Fatalf("activity: expected the unpause error")
+	}
+	f.err = nil
+	if err := p.activity(); err != nil || f.paused {
+		t.Fatalf("unpause was not retried on the next request: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	f := &fakeRuntime{paused: true}
+	p, _ := newTestPauser(f, time.Minute, 0)
+	p.check()
+	srv := httptest.NewServer(p.handler())
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	// status endpoints are not API activity
+	for _, path := range []string{"/healthz", "/metrics", "/status"} {
+		if code, _ := get(path); code != http.StatusOK {
+			t.Errorf("GET %s = %d", path, code)
+		}
+	}
+	if !f.paused {
+		t.Fatalf("status endpoints unpaused the cluster")
+	}
+
+	if code, body := get("/192.168.49.1:51234"); code != http.StatusOK || body != "allow" {
+		t.Errorf("proxy request = %d %q; want 200 allow", code, body)
+	}
+	if f.paused {
+		t.Errorf("proxy request did not unpause the cluster")
+	}
+
+	_, body := get("/status")
+	var s status
+	if err := json.Unmarshal([]byte(body), &s); err != nil {
+		t.Fatalf("invalid status %q: %v", body, err)
+	}
+	if s.Paused || s.UnpauseCount != 1 || s.Interval != "1m0s" {
+		t.Errorf("unexpected status: %+v", s)
+	}
+
+	_, body = get("/metrics")
+	for _, want := range []string{"# TYPE auto_pause_paused gauge", "auto_pause_paused 0", "auto_pause_unpauses_total 1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+
+	f.err = errors.New("runtime is down")
+	f.paused = true
+	p.paused = true
+	if code, body := get("/10.0.0.1:1234"); code != http.StatusServiceUnavailable || body == "allow" {
+		t.Errorf("proxy request with a failing runtime = %d %q; want it to be denied", code, body)
+	}
+}
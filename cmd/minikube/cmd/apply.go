@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+
+	configCmd "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/cni"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/delete"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/spec"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var applySpecPath string
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:     "apply",
+	Short:   "Creates or updates a cluster from a cluster spec file",
+	Long:    "Creates the cluster described by a cluster spec file, or reconciles a running cluster with it by adding or removing nodes and enabling or disabling addons.",
+	Example: "minikube apply -f cluster.yaml",
+	Run:     runApply,
+}
+
+func runApply(cmd *cobra.Command, _ []string) {
+	if applySpecPath == "" {
+		exit.Message(reason.Usage, "Usage: minikube apply -f FILE")
+	}
+	c := loadClusterSpec(applySpecPath)
+	if c.Metadata.Name == "" || cmd.Flags().Changed(config.ProfileName) {
+		c.Metadata.Name = ClusterFlagValue()
+	}
+	viper.Set(config.ProfileName, c.Metadata.Name)
+	cname := c.Metadata.Name
+
+	if !config.ProfileExists(cname) {
+		out.Step(style.Launch, "Creating cluster {{.profile}} from {{.file}}", out.V{"profile": cname, "file": applySpecPath})
+		applySpecFlags(startCmd, c)
+		runStart(startCmd, nil)
+		return
+	}
+
+	co := mustload.Healthy(cname)
+	p, err := c.Plan(co.Config)
+	if err != nil {
+		exit.Message(reason.Usage, "Unable to reconcile cluster {{.profile}}: {{.error}}", out.V{"profile": cname, "error": err})
+	}
+	for _, ch := range p.Unreconciled {
+		out.WarningT("{{.field}} is {{.current}} in the cluster but {{.desired}} in the spec, the cluster has to be recreated to change it", out.V{"field": ch.Field, "current": ch.Current, "desired": ch.Desired})
+	}
+	if p.Empty() {
+		out.Step(style.Ready, "Cluster {{.profile}} already matches {{.file}}", out.V{"profile": cname, "file": applySpecPath})
+		return
+	}
+
+	reconcileNodes(co.Config, p)
+	if p.CNI != "" {
+		// the nodes of the cluster may have changed
+		setCNI(mustload.Healthy(cname), p.CNI)
+	}
+	reconcileAddons(cname, c, p)
+	out.Step(style.Ready, "Cluster {{.profile}} now matches {{.file}}", out.V{"profile": cname, "file": applySpecPath})
+}
+
+// loadClusterSpec loads and validates a cluster spec file
+func loadClusterSpec(path string) *spec.Cluster {
+	c, err := spec.Load(path)
+	if err != nil {
+		exit.Message(reason.Usage, "Unable to load cluster spec: {{.error}}", out.V{"error": err})
+	}
+	if err := configCmd.ValidateSpec(c); err != nil {
+		exit.Message(reason.Usage, "Invalid cluster spec {{.file}}: {{.error}}", out.V{"file": path, "error": err})
+	}
+	return c
+}
+
+// applySpecFlags sets the start flags from a cluster spec, flags given on the command line take precedence
+func applySpecFlags(cmd *cobra.Command, c *spec.Cluster) {
+	if c.Metadata.Name != "" && !cmd.Flags().Changed(config.ProfileName) {
+		viper.Set(config.ProfileName, c.Metadata.Name)
+	}
+
+	fs, err := c.Flags()
+	if err != nil {
+		exit.Message(reason.Usage, "Invalid cluster spec: {{.error}}", out.V{"error": err})
+	}
+	explicit := map[string]bool{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		explicit[f.Name] = true
+	})
+	for _, f := range fs {
+		if explicit[f.Name] {
+			klog.Infof("--%s was given on the command line, ignoring the value from the cluster spec", f.Name)
+			continue
+		}
+		if err := cmd.Flags().Set(f.Name, f.Value); err != nil {
+			exit.Message(reason.Usage, "Invalid value {{.value}} for --{{.flag}} in the cluster spec: {{.error}}", out.V{"value": f.Value, "flag": f.Name, "error": err})
+		}
+	}
+	setSpecAddonImages(c)
+}
+
+// reconcileNodes deletes and adds the nodes of a running cluster according to the plan
+func reconcileNodes(cc *config.ClusterConfig, p *spec.Plan) {
+	for _, name := range p.DeleteNodes {
+		out.Step(style.DeletingHost, "Deleting node {{.name}} from cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
+		n, err := node.Delete(*cc, name)
+		if err != nil {
+			exit.Error(reason.GuestNodeDelete, "deleting node", err)
+		}
+		if driver.IsKIC(cc.Driver) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			delete.PossibleLeftOvers(ctx, config.MachineName(*cc, *n), cc.Driver)
+			cancel()
+		}
+		cc, err = config.Load(cc.Name)
+		if err != nil {
+			exit.Error(reason.HostConfigLoad, "Error getting cluster config", err)
+		}
+	}
+
+	for _, n := range p.AddNodes {
+		if n.ControlPlane && !config.IsHA(*cc) {
+			out.Step(style.Improvement, "Converting {{.cluster}} into a highly available cluster ...", out.V{"cluster": cc.Name})
+			if err := node.EnableHA(cc); err != nil {
+				exit.Error(reason.GuestNodeAdd, "Failed to convert the cluster into a highly available one", err)
+			}
+		}
+		lastID, err := node.ID(cc.Nodes[len(cc.Nodes)-1].Name)
+		if err != nil {
+			lastID = len(cc.Nodes)
+			klog.Warningf("determining last node index (will assume %d): %v", lastID, err)
+		}
+		n.Name = node.Name(lastID + 1)
+
+		if len(cc.Nodes) == 1 && (!cc.MultiNodeRequested || cni.IsDisabled(*cc)) {
+			warnAboutMultiNodeCNI()
+		}
+		out.Step(style.Happy, "Adding node {{.name}} to cluster {{.cluster}}", out.V{"name": n.Name, "cluster": cc.Name})
+		if err := node.Add(cc, n, false); err != nil {
+			exit.Error(reason.GuestNodeAdd, "failed to add node", err)
+		}
+		if err := config.SaveProfile(cc.Name, cc); err != nil {
+			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		}
+	}
+}
+
+// reconcileAddons enables and disables the addons of a running cluster according to the plan
+func reconcileAddons(cname string, c *spec.Cluster, p *spec.Plan) {
+	setSpecAddonImages(c)
+
+	for _, a := range p.EnableAddons {
+		err := addons.SetAndSave(cname, a, "true")
+		if err != nil && !errors.Is(err, addons.ErrSkipThisAddon) {
+			exit.Error(reason.InternalAddonEnable, "enable failed", err)
+		}
+		if err == nil {
+			out.Step(style.AddonEnable, "The '{{.addonName}}' addon is enabled", out.V{"addonName": a})
+		}
+	}
+	for _, a := range p.DisableAddons {
+		if err := addons.SetAndSave(cname, a, "false"); err != nil {
+			exit.Error(reason.InternalAddonDisable, "disable failed", err)
+		}
+		out.Step(style.AddonDisable, `"The '{{.minikube_addon}}' addon is disabled`, out.V{"minikube_addon": a})
+	}
+}
+
+// setSpecAddonImages makes the custom addon images of the spec used when addons are enabled
+func setSpecAddonImages(c *spec.Cluster) {
+	images, registries := c.AddonImages()
+	if images != "" {
+		viper.Set(config.AddonImages, images)
+	}
+	if registries != "" {
+		viper.Set(config.AddonRegistries, registries)
+	}
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applySpecPath, "file", "f", "", "Path to the cluster spec file to apply (see 'minikube profile export').")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/spec"
+)
+
+// TestClusterSpecFlags makes sure every field of the cluster spec maps to an existing start flag
+func TestClusterSpecFlags(t *testing.T) {
+	enabled := true
+	c := &spec.Cluster{Spec: spec.ClusterSpec{
+		Driver:    "docker",
+		CPUs:      "2",
+		Memory:    "2g",
+		DiskSize:  "20g",
+		BaseImage: "example.com/kicbase:v1",
+		ISOURL:    "https://example.com/minikube.iso",
+		Network:   "dev",
+		Kubernetes: spec.Kubernetes{
+			Version:          "v1.30.0",
+			ContainerRuntime: "containerd",
+			CNI:              "calico",
+			FeatureGates:     "Foo=true",
+			ExtraConfig:      []string{"kubelet.max-pods=150"},
+			ServiceCIDR:      "10.96.0.0/12",
+			DNSDomain:        "cluster.local",
+			ImageRepository:  "example.com",
+			APIServerNames:   []string{"dev.local"},
+		},
+		Nodes:  []spec.Node{{Roles: []string{spec.RoleControlPlane, spec.RoleWorker}}, {Roles: []string{spec.RoleControlPlane, spec.RoleWorker}}, {Roles: []string{spec.RoleControlPlane, spec.RoleWorker}}},
+		Addons: []spec.Addon{{Name: "dashboard", Enabled: &enabled}},
+		Mount:  &spec.Mount{HostPath: "/src", GuestPath: "/mnt/src"},
+	}}
+	fs, err := c.Flags()
+	if err != nil {
+		t.Fatalf("Flags: %v", err)
+	}
+	for _, f := range fs {
+		if startCmd.Flags().Lookup(f.Name) == nil {
+			t.Errorf("cluster spec sets unknown start flag --%s", f.Name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/audit"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+var (
+	auditCommand string
+	auditSince   string
+	auditUntil   string
+	auditFailed  bool
+	auditOutput  string
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Lists the commands recorded in the audit log",
+	Long:  "Lists the minikube commands recorded in the audit log and its rotated archives, with their exit code and duration.",
+	Example: `minikube audit --profile minikube --since 24h
+minikube audit --command start --failed -o json`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		f := audit.Filter{
+			Command: auditCommand,
+			Failed:  auditFailed,
+		}
+		// --profile and --user are global flags, only filter by them when given explicitly
+		if cmd.Flags().Changed(config.ProfileName) {
+			f.Profile = viper.GetString(config.ProfileName)
+		}
+		if cmd.Flags().Changed(config.UserFlag) {
+			f.User = viper.GetString(config.UserFlag)
+		}
+		var err error
+		now := time.Now()
+		if f.Since, err = parseAuditTime(auditSince, now); err != nil {
+			exit.Message(reason.Usage, "Invalid --since: {{.error}}", out.V{"error": err})
+		}
+		if f.Until, err = parseAuditTime(auditUntil, now); err != nil {
+			exit.Message(reason.Usage, "Invalid --until: {{.error}}", out.V{"error": err})
+		}
+
+		r, err := audit.Query(f)
+		if err != nil {
+			exit.Error(reason.InternalAuditLog, "Failed to read the audit log", err)
+		}
+		switch auditOutput {
+		case "table":
+			out.String("%s", r.ASCIITable())
+		case "json":
+			s, err := r.JSON()
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "audit json failure", err)
+			}
+			out.Ln("%s", s)
+		case "yaml":
+			s, err := r.YAML()
+			if err != nil {
+				exit.Error(reason.InternalYamlMarshal, "audit yaml failure", err)
+			}
+			out.String("%s", s)
+		case "csv":
+			s, err := r.CSV()
+			if err != nil {
+				exit.Error(reason.InternalAuditLog, "audit csv failure", err)
+			}
+			out.String("%s", s)
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'table', 'json', 'yaml' or 'csv'")
+		}
+	},
+}
+
+// parseAuditTime parses a time given either as RFC3339 or as a duration before now
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration (e.g. 24h) nor a RFC3339 time (e.g. 2024-01-02T15:04:05Z)", s)
+	}
+	return t, nil
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditCommand, "command", "", "Only list the invocations of this command, e.g. start")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only list commands started after this time, either RFC3339 or a duration before now, e.g. 24h")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "Only list commands started before this time, either RFC3339 or a duration before now, e.g. 1h")
+	auditCmd.Flags().BoolVar(&auditFailed, "failed", false, "Only list commands which exited with a non-zero exit code")
+	auditCmd.Flags().StringVarP(&auditOutput, "output", "o", "table", "The output format. One of 'table', 'json', 'yaml', 'csv'")
+}
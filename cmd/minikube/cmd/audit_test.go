@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+		err   bool
+	}{
+		{"", time.Time{}, false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2024-02-01T08:30:00Z", time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+		{"2024-02-01", time.Time{}, true},
+	}
+	for _, tc := range tests {
+		got, err := parseAuditTime(tc.value, now)
+		if (err != nil) != tc.err {
+			t.Errorf("parseAuditTime(%q) error = %v; want error %t", tc.value, err, tc.err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseAuditTime(%q) = %v; want %v", tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cmdConfig "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/bundle"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	bundleOpts   bundle.Options
+	bundleOutput string
+)
+
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Create and load bundles for offline clusters",
+	Long: `Create a bundle of everything that minikube start downloads, and load it on a host without network access.
+Start the clusters with the options of the bundle once it is loaded.`,
+}
+
+// bundleCreateCmd represents the bundle create command
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a bundle of the artifacts downloaded by minikube start",
+	Long: `Download the preload or the Kubernetes images, the Kubernetes binaries, the ISO or the kic base image, the images of
+the CNI and the images of the addons, and write them into a bundle with their checksums.`,
+	Example: "minikube bundle create --kubernetes-version v1.30.0 --container-runtime containerd --addons ingress,metrics-server",
+	Run: func(_ *cobra.Command, _ []string) {
+		opts := bundleOpts
+		if !strings.HasPrefix(opts.KubernetesVersion, "v") {
+			opts.KubernetesVersion = "v" + opts.KubernetesVersion
+		}
+		if opts.ContainerRuntime == "" {
+			opts.ContainerRuntime = constants.Docker
+		}
+		output := bundleOutput
+		if output == "" {
+			output = fmt.Sprintf("minikube-bundle-%s-%s-%s-%s.tar", opts.KubernetesVersion, opts.ContainerRuntime, opts.Driver, detect.EffectiveArch())
+		}
+
+		m, err := bundle.Create(opts, output)
+		if err != nil {
+			exit.Error(reason.HostBundle, "Failed to create the bundle", err)
+		}
+		out.Step(style.Check, "Created {{.path}} with {{.count}} artifacts", out.V{"path": output, "count": len(m.Files)})
+	},
+}
+
+// bundleLoadCmd represents the bundle load command
+var bundleLoadCmd = &cobra.Command{
+	Use:     "load BUNDLE",
+	Short:   "Load a bundle into the minikube caches",
+	Long:    "Verify the checksums of the artifacts of the bundle and copy them into the minikube caches, for minikube start to run without network access.",
+	Example: "minikube bundle load minikube-bundle-v1.30.0-containerd-docker-amd64.tar",
+	Args:    cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		m, err := bundle.Load(args[0])
+		if err != nil {
+			exit.Error(reason.HostBundle, "Failed to load the bundle", err)
+		}
+		// the images which are not in the preload are loaded into the clusters by minikube start, as with "minikube cache add"
+		if len(m.Images) > 0 {
+			if err := cmdConfig.AddToConfigMap(cacheImageConfigKey, m.Images); err != nil {
+				exit.Error(reason.InternalAddConfig, "Failed to update config", err)
+			}
+		}
+		out.Step(style.Check, "Loaded {{.count}} artifacts from {{.path}}", out.V{"count": len(m.Files), "path": args[0]})
+		startArgs := []string{"--kubernetes-version=" + m.KubernetesVersion, "--container-runtime=" + m.ContainerRuntime, "--driver=" + m.Driver}
+		if len(m.Addons) > 0 {
+			startArgs = append(startArgs, "--addons="+strings.Join(m.Addons, ","))
+		}
+		out.Styled(style.Tip, "Start a cluster without network access with: minikube start {{.args}}", out.V{"args": strings.Join(startArgs, " ")})
+	},
+}
+
+func init() {
+	bundleCreateCmd.Flags().StringVar(&bundleOpts.KubernetesVersion, "kubernetes-version", constants.DefaultKubernetesVersion, "The Kubernetes version of the clusters")
+	bundleCreateCmd.Flags().StringVar(&bundleOpts.ContainerRuntime, "container-runtime", constants.Docker, "The container runtime of the clusters (docker, containerd, cri-o)")
+	bundleCreateCmd.Flags().StringVar(&bundleOpts.Driver, "driver", driver.Docker, "The driver of the clusters, which decides between the ISO and the kic base image")
+	bundleCreateCmd.Flags().StringVar(&bundleOpts.CNI, "cni", "", "The CNI of the clusters, as passed to minikube start")
+	bundleCreateCmd.Flags().StringSliceVar(&bundleOpts.Addons, "addons", nil, "The addons whose images are bundled")
+	bundleCreateCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "The path of the bundle (defaults to minikube-bundle-<version>-<runtime>-<driver>-<arch>.tar)")
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleLoadCmd)
+}
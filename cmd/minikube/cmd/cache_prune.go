@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	cmdConfig "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	pruneDryRun          bool
+	pruneOlderThan       time.Duration
+	pruneKeepK8sVersions []string
+)
+
+// pruneCacheCmd represents the cache prune command
+var pruneCacheCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused files from the local cache.",
+	Long: `Remove the images, preloads, ISOs, kic base images and Kubernetes binaries from the local cache which are not used by any profile.
+Files needed by the Kubernetes version of an existing profile, or a version given with --keep-k8s-version, are kept.`,
+	Example: `minikube cache prune --dry-run
+minikube cache prune --older-than 720h --keep-k8s-version v1.28.3`,
+	Run: func(_ *cobra.Command, _ []string) {
+		o := cachePruneOptions()
+		items, err := machine.ListCache(validProfiles(), o)
+		if err != nil {
+			exit.Error(reason.InternalCacheList, "Failed to list the cache", err)
+		}
+		prune := machine.PruneCandidates(items, o, time.Now())
+		if len(prune) == 0 {
+			out.Step(style.Empty, "Nothing to prune from the cache")
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Kind", "Name", "Size", "Modified"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		var size int64
+		for _, i := range prune {
+			table.Append([]string{i.Kind, i.Name, units.HumanSize(float64(i.Size)), i.Modified.Format("2006-01-02 15:04:05")})
+			size += i.Size
+		}
+		table.Render()
+
+		if pruneDryRun {
+			out.Step(style.Tip, "Pruning would reclaim {{.size}} from the cache", out.V{"size": units.HumanSize(float64(size))})
+			return
+		}
+		reclaimed, err := machine.RemoveCacheItems(prune)
+		if err != nil {
+			exit.Error(reason.HostDelCache, "Failed to prune the cache", err)
+		}
+		out.Step(style.Deleted, "Reclaimed {{.size}} from the cache", out.V{"size": units.HumanSize(float64(reclaimed))})
+	},
+}
+
+// cachePruneOptions returns the prune options of the flags, keeping the images added with "cache add"
+func cachePruneOptions() machine.PruneOptions {
+	o := pruneOptions()
+	imgs, err := cmdConfig.ListConfigMap(cacheImageConfigKey)
+	if err != nil {
+		exit.Error(reason.InternalListConfig, "Failed to get image map", err)
+	}
+	o.KeepImages = imgs
+	return o
+}
+
+// pruneOptions returns the prune options of the flags shared by the prune commands
+func pruneOptions() machine.PruneOptions {
+	return machine.PruneOptions{
+		OlderThan:       pruneOlderThan,
+		KeepK8sVersions: pruneKeepK8sVersions,
+	}
+}
+
+// validProfiles returns the profiles whose cache files must be kept
+func validProfiles() []*config.Profile {
+	profiles, _, err := config.ListProfiles()
+	if err != nil {
+		klog.Warningf("error listing profiles: %v", err)
+	}
+	return profiles
+}
+
+// addPruneFlags adds the flags shared by the prune commands
+func addPruneFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Only report what would be removed and the space it would reclaim")
+	cmd.Flags().DurationVar(&pruneOlderThan, "older-than", 0, "Only remove what was created or modified longer ago than this duration, e.g. 720h")
+	cmd.Flags().StringSliceVar(&pruneKeepK8sVersions, "keep-k8s-version", nil, "Keep the files and images needed by these Kubernetes versions, besides those of the existing profiles")
+}
+
+func init() {
+	addPruneFlags(pruneCacheCmd)
+	cacheCmd.AddCommand(pruneCacheCmd)
+}
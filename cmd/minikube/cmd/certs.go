@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	certsListOutput string
+	rotateCACert    string
+	rotateCAKey     string
+)
+
+// certsCmd represents the certs command
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "List and rotate the certificates of a cluster",
+	Long: `List and rotate the certificates of a cluster: the minikube CA shared by the profiles, the apiserver, client and
+proxy-client certificates generated by minikube, and the kubeadm, etcd and kubelet certificates of the nodes.`,
+}
+
+// certsListCmd represents the certs list command
+var certsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the certificates of a cluster with their subject, SANs and expiry",
+	Long:  "List the certificates of a cluster with their subject, SANs and expiry. The certificates of the nodes are only listed when they are running.",
+	Run: func(_ *cobra.Command, _ []string) {
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+
+		infos := bootstrapper.HostCerts(*cc)
+		for _, n := range cc.Nodes {
+			machineName := config.MachineName(*cc, n)
+			hs, err := machine.Status(api, machineName)
+			if err != nil || hs != state.Running.String() {
+				out.WarningT("Skipping the certificates of node {{.node}}, which is not running", out.V{"node": machineName})
+				continue
+			}
+			h, err := machine.LoadHost(api, machineName)
+			if err != nil {
+				exit.Error(reason.GuestLoadHost, "Error getting host", err)
+			}
+			r, err := machine.CommandRunner(h)
+			if err != nil {
+				exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+			}
+			infos = append(infos, bootstrapper.NodeCerts(r, n, machineName)...)
+		}
+
+		switch certsListOutput {
+		case "table":
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Node", "Certificate", "Subject", "SANs", "Expires"})
+			table.SetAutoFormatHeaders(false)
+			table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+			table.SetCenterSeparator("|")
+			for _, ci := range infos {
+				table.Append(certRow(ci, time.Now()))
+			}
+			table.Render()
+		case "json":
+			b, err := json.Marshal(infos)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "certs list json failure", err)
+			}
+			out.Ln("%s", string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'table' or 'json'")
+		}
+	},
+}
+
+// certRow returns the table row of a certificate
+func certRow(ci bootstrapper.CertInfo, now time.Time) []string {
+	node := ci.Node
+	if node == "" {
+		node = "-"
+	}
+	if ci.Error != "" {
+		return []string{node, ci.Name, "-", "-", ci.Error}
+	}
+	expires := ci.NotAfter.Format("2006-01-02 15:04:05")
+	if ci.NotAfter.Before(now) {
+		expires += " (expired)"
+	}
+	return []string{node, ci.Name, ci.Subject, strings.Join(ci.SANs, ","), expires}
+}
+
+// certsRotateCmd represents the certs rotate command
+var certsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Regenerate the certificates of a cluster, without recreating it",
+	Long: `Regenerate the certificates of a running cluster and restart the components using them, without recreating it.
+With --ca-cert and --ca-key, the minikube CA is replaced first, for example by a corporate intermediate CA, so that the
+certificates of the cluster chain to it. The CA is shared by all the profiles, whose certificates must be rotated too.`,
+	Example: `minikube certs rotate
+minikube certs rotate --ca-cert corp-intermediate.crt --ca-key corp-intermediate.key`,
+	Run: func(_ *cobra.Command, _ []string) {
+		if (rotateCACert == "") != (rotateCAKey == "") {
+			exit.Message(reason.Usage, "--ca-cert and --ca-key must be specified together")
+		}
+		co := mustload.Running(ClusterFlagValue())
+		defer co.API.Close()
+
+		caChanged := false
+		if rotateCACert != "" {
+			var err error
+			caChanged, err = bootstrapper.InstallCA(rotateCACert, rotateCAKey)
+			if err != nil {
+				exit.Error(reason.HostCerts, "Failed to install the CA", err)
+			}
+			if caChanged {
+				out.Step(style.Check, "Installed {{.cert}} as the minikube CA", out.V{"cert": rotateCACert})
+			}
+		}
+
+		// the primary control-plane node comes first, as it signs the kubeconfig of the other nodes
+		nodes := []config.Node{*co.CP.Node}
+		for _, n := range co.Config.Nodes {
+			if !config.IsPrimaryControlPlane(*co.Config, n) {
+				nodes = append(nodes, n)
+			}
+		}
+		for _, n := range nodes {
+			machineName := config.MachineName(*co.Config, n)
+			out.Step(style.Permissions, "Rotating the certificates of node {{.node}} ...", out.V{"node": machineName})
+			h, err := machine.LoadHost(co.API, machineName)
+			if err != nil {
+				exit.Error(reason.GuestLoadHost, "Error getting host", err)
+			}
+			r, err := machine.CommandRunner(h)
+			if err != nil {
+				exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+			}
+			if err := bootstrapper.RotateCerts(*co.Config, n, co.CP.Runner, r, caChanged); err != nil {
+				exit.Error(reason.GuestCert, "Failed to rotate the certificates", err)
+			}
+		}
+
+		st, err := kverify.WaitForAPIServerStatus(co.CP.Runner, 2*time.Minute, co.CP.Hostname, co.CP.Port)
+		if err != nil || st != state.Running {
+			exit.Error(reason.GuestCert, "The apiserver did not come back with the new certificates", fmt.Errorf("apiserver is %s: %v", st, err))
+		}
+		if caChanged {
+			if err := bootstrapper.RestartCAConsumers(*co.Config, co.CP.Runner); err != nil {
+				out.WarningT("Unable to restart the kube-system workloads to load the new CA: {{.error}}", out.V{"error": err})
+			}
+		}
+		if co.Config.EmbedCerts {
+			updateEmbeddedCerts(*co.Config)
+		}
+		out.Step(style.Check, "Rotated the certificates of {{.profile}}", out.V{"profile": co.Config.Name})
+
+		if caChanged {
+			others := []string{}
+			for _, p := range validProfiles() {
+				if p.Name != co.Config.Name {
+					others = append(others, p.Name)
+				}
+			}
+			if len(others) > 0 {
+				out.WarningT("The CA is shared by all the profiles, rotate the certificates of {{.profiles}} too", out.V{"profiles": strings.Join(others, ", ")})
+			}
+		}
+	},
+}
+
+// updateEmbeddedCerts embeds the rotated client cert and CA in the kubeconfig of the cluster
+func updateEmbeddedCerts(cc config.ClusterConfig) {
+	kcPath := kubeconfig.PathFromEnv()
+	host, port, err := kubeconfig.Endpoint(cc.Name, kcPath)
+	if err != nil {
+		klog.Warningf("unable to get the endpoint of %s: %v", cc.Name, err)
+		out.WarningT("Unable to update the certificates of {{.profile}} in the kubeconfig, run: minikube update-context", out.V{"profile": cc.Name})
+		return
+	}
+	kcs := &kubeconfig.Settings{
+		ClusterName:          cc.Name,
+		Namespace:            cc.KubernetesConfig.Namespace,
+		ClusterServerAddress: "https://" + net.JoinHostPort(host, strconv.Itoa(port)),
+		ClientCertificate:    localpath.ClientCert(cc.Name),
+		ClientKey:            localpath.ClientKey(cc.Name),
+		CertificateAuthority: localpath.CACert(),
+		KeepContext:          true,
+		EmbedCerts:           true,
+	}
+	kcs.SetPath(kcPath)
+	if err := kubeconfig.Update(kcs); err != nil {
+		exit.Error(reason.HostKubeconfigUpdate, "Failed to update the kubeconfig", err)
+	}
+}
+
+func init() {
+	certsListCmd.Flags().StringVarP(&certsListOutput, "output", "o", "table", "The output format. One of 'table', 'json'")
+	certsRotateCmd.Flags().StringVar(&rotateCACert, "ca-cert", "", "The CA certificate replacing the minikube CA, which may be an intermediate CA followed by its chain")
+	certsRotateCmd.Flags().StringVar(&rotateCAKey, "ca-key", "", "The private key of the CA certificate given with --ca-cert")
+	certsCmd.AddCommand(certsListCmd)
+	certsCmd.AddCommand(certsRotateCmd)
+}
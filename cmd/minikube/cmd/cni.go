@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/cni"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/out/register"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// builtinCNIs are the CNIs deployed by minikube, any other value of --cni being the path of a manifest
+var builtinCNIs = []string{"auto", "bridge", "calico", "cilium", "flannel", "kindnet"}
+
+// cniCmd represents the cni command
+var cniCmd = &cobra.Command{
+	Use:   "cni",
+	Short: "Manage the CNI of a cluster",
+	Long:  "Manage the Container Networking Interface plug-in of a cluster.",
+}
+
+// cniSetCmd represents the cni set command
+var cniSetCmd = &cobra.Command{
+	Use:   "set PLUGIN",
+	Short: "Change the CNI of a running cluster",
+	Long: `Change the CNI of a running cluster, without recreating it. Valid plug-ins: auto, bridge, calico, cilium, flannel, kindnet, or path to a CNI manifest.
+
+The resources and the node configs of the current CNI are removed, the new CNI is deployed, the container runtime and the
+kubelet of every node are restarted, and the pods not running on the host network are deleted for their controllers to
+recreate them with an address of the new CNI. The pods without a controller are not recreated.`,
+	Example: "minikube cni set calico",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "Usage: minikube cni set PLUGIN")
+		}
+		co := mustload.Healthy(ClusterFlagValue())
+		defer co.API.Close()
+		setCNI(co, args[0])
+	},
+}
+
+// setCNI migrates the running cluster to the CNI plug-in
+func setCNI(co mustload.ClusterController, plugin string) {
+	cc := co.Config
+	if cc.KubernetesConfig.NetworkPlugin != "cni" {
+		exit.Message(reason.Usage, "The CNI of {{.cluster}} cannot be changed, as it uses the {{.plugin}} network plugin", out.V{"cluster": cc.Name, "plugin": cc.KubernetesConfig.NetworkPlugin})
+	}
+	if plugin == "false" {
+		exit.Message(reason.Usage, "The CNI of a running cluster cannot be disabled")
+	}
+	if !slices.Contains(builtinCNIs, plugin) {
+		plugin = absPath(plugin)
+	}
+
+	current, err := cni.New(cc)
+	if err != nil {
+		exit.Error(reason.GuestCNIChange, "Unable to load the current CNI", err)
+	}
+	target := *cc
+	target.KubernetesConfig.CNI = plugin
+	cnm, err := cni.New(&target)
+	if err != nil {
+		exit.Message(reason.Usage, "Invalid CNI {{.cni}}: {{.error}}", out.V{"cni": plugin, "error": err})
+	}
+	if _, ok := cnm.(cni.Bridge); ok && len(cc.Nodes) > 1 {
+		exit.Message(reason.Usage, "Bridge CNI is incompatible with multi-node clusters, use a different CNI")
+	}
+	if cni.Same(current, cnm) {
+		cc.KubernetesConfig.CNI = plugin
+		if err := config.SaveProfile(cc.Name, cc); err != nil {
+			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		}
+		out.Step(style.Check, "{{.cluster}} already uses {{.cni}}", out.V{"cluster": cc.Name, "cni": cnm})
+		return
+	}
+
+	runners := nodeRunners(co)
+
+	register.Reg.SetStep(register.RemovingCNI)
+	out.Step(style.CNI, "Removing {{.cni}} ...", out.V{"cni": current})
+	if err := cni.Remove(*cc, current, co.CP.Runner); err != nil {
+		exit.Error(reason.GuestCNIChange, "Failed to remove the current CNI", err)
+	}
+	for _, r := range runners {
+		if err := cni.DisableConfigs(r); err != nil {
+			exit.Error(reason.GuestCNIChange, "Failed to disable the configs of the current CNI", err)
+		}
+	}
+
+	register.Reg.SetStep(register.ConfiguringCNI)
+	out.Step(style.CNI, "Configuring {{.name}} (Container Networking Interface) ...", out.V{"name": cnm})
+	if err := cnm.Apply(co.CP.Runner); err != nil {
+		exit.Error(reason.GuestCNIChange, "Failed to apply the new CNI", err)
+	}
+	cc.KubernetesConfig.CNI = plugin
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+
+	register.Reg.SetStep(register.RestartingRuntime)
+	out.Step(style.Restarting, "Restarting the container runtime and the kubelet of the nodes ...")
+	for _, r := range runners {
+		if err := cni.RestartRuntime(*cc, r); err != nil {
+			exit.Error(reason.GuestCNIChange, "Failed to restart the container runtime", err)
+		}
+	}
+
+	register.Reg.SetStep(register.RestartingPods)
+	out.Step(style.Restarting, "Restarting the pods for them to get an address of the new CNI ...")
+	// the apiserver may be briefly unreachable while the kubelet restarts
+	if err := retry.Expo(func() error { return cni.RestartPods(*cc, co.CP.Runner) }, time.Second, time.Minute); err != nil {
+		exit.Error(reason.GuestCNIChange, "Failed to restart the pods", err)
+	}
+
+	register.Reg.SetStep(register.Done)
+	out.Step(style.Ready, "{{.cluster}} now uses {{.cni}}", out.V{"cluster": cc.Name, "cni": cnm})
+}
+
+// nodeRunners returns the runners of the nodes of the cluster, which must all be running
+func nodeRunners(co mustload.ClusterController) []command.Runner {
+	var runners []command.Runner
+	for _, n := range co.Config.Nodes {
+		machineName := config.MachineName(*co.Config, n)
+		if !machine.IsRunning(co.API, machineName) {
+			exit.Message(reason.GuestCNIChange, "Node {{.name}} is not running, start it first with: minikube node start {{.name}}", out.V{"name": machineName})
+		}
+		h, err := machine.LoadHost(co.API, machineName)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		runners = append(runners, r)
+	}
+	return runners
+}
+
+func init() {
+	cniCmd.AddCommand(cniSetCmd)
+}
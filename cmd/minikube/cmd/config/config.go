@@ -172,6 +172,15 @@ var settings = []Setting{
 		name: config.MaxAuditEntries,
 		set:  SetInt,
 	},
+	{
+		name:        config.MaxAuditSize,
+		set:         SetString,
+		validations: []setFn{IsValidFileSize},
+	},
+	{
+		name: config.MaxAuditArchives,
+		set:  SetInt,
+	},
 }
 
 // ConfigCmd represents the config command
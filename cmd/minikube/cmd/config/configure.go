@@ -20,6 +20,8 @@ import (
 	"net"
 	"os"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -265,6 +267,32 @@ var addonsConfigureCmd = &cobra.Command{
 				out.ErrT(style.Fatal, "Interval must be greater than 0s")
 			}
 			cfg.AutoPauseInterval = intervalTime
+
+			graceInput := AskForStaticValueOptional("-- (Optional) Enter the grace period after unpausing, during which the cluster is not paused again (ex. 5m0s): ")
+			if graceInput != "" {
+				grace, err := time.ParseDuration(graceInput)
+				if err != nil || grace < 0 {
+					exit.Message(reason.Usage, "Grace period is an invalid duration: {{.value}}", out.V{"value": graceInput})
+				}
+				cfg.AutoPauseGracePeriod = grace
+			}
+			nsInput := AskForStaticValueOptional("-- (Optional) Enter the namespaces to pause, separated by commas (default kube-system): ")
+			if nsInput != "" {
+				var nss []string
+				for _, ns := range strings.Split(nsInput, ",") {
+					if ns = strings.TrimSpace(ns); ns != "" {
+						nss = append(nss, ns)
+					}
+				}
+				cfg.AutoPauseNamespaces = nss
+			}
+			portInput := AskForStaticValueOptional("-- (Optional) Enter the port of the auto-pause server (default 8080): ")
+			if portInput != "" {
+				if err := IsValidPort("port", portInput); err != nil {
+					exit.Message(reason.Usage, "Invalid auto-pause configuration: {{.error}}", out.V{"error": err})
+				}
+				cfg.AutoPausePort, _ = strconv.Atoi(portInput)
+			}
 			if err := config.SaveProfile(profile, cfg); err != nil {
 				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
 			}
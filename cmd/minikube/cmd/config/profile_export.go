@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/spec"
+)
+
+var profileExportFile string
+
+var profileExportCmd = &cobra.Command{
+	Use:     "export [PROFILE]",
+	Short:   "Export a profile as a cluster spec file",
+	Long:    "Export the configuration of a profile as a cluster spec file, which can be used with 'minikube start --config' or 'minikube apply -f'.",
+	Example: "minikube profile export minikube --file cluster.yaml",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) > 1 {
+			exit.Message(reason.Usage, "usage: minikube profile export [PROFILE]")
+		}
+		name := ClusterFlagValue()
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		api, cc := mustload.Partial(name)
+		api.Close()
+
+		data, err := spec.FromClusterConfig(cc).Marshal()
+		if err != nil {
+			exit.Error(reason.InternalYamlMarshal, "Failed to marshal cluster spec", err)
+		}
+		if profileExportFile == "" {
+			out.String("%s", data)
+			return
+		}
+		if err := os.WriteFile(profileExportFile, data, 0644); err != nil {
+			exit.Error(reason.HostSaveProfile, "Failed to write cluster spec", err)
+		}
+		out.SuccessT("Exported profile {{.profile}} to {{.file}}", out.V{"profile": name, "file": profileExportFile})
+	},
+}
+
+func init() {
+	profileExportCmd.Flags().StringVarP(&profileExportFile, "file", "f", "", "The file to write the cluster spec to. Defaults to stdout.")
+	ProfileCmd.AddCommand(profileExportCmd)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+var profileRenameCmd = &cobra.Command{
+	Use:     "rename OLD NEW",
+	Short:   "Rename a stopped profile",
+	Long:    "Rename a stopped profile, including its nodes, kubeconfig context and machine store. The nodes are recreated under the new name on the next start, keeping their data.",
+	Example: "minikube profile rename minikube dev",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit.Message(reason.Usage, "usage: minikube profile rename OLD NEW")
+		}
+		src, dst := args[0], args[1]
+		validateNewProfileName(dst)
+
+		api, cc := mustload.Stopped(src)
+		api.Close()
+
+		if _, err := machine.RenameProfile(cc, dst); err != nil {
+			exit.Error(reason.GuestProfileRename, "Failed to rename profile", err)
+		}
+
+		if active, err := config.Get(config.ProfileName); err == nil && active == src {
+			if err := Set(config.ProfileName, dst); err != nil {
+				klog.Warningf("failed to set the active profile to %s: %v", dst, err)
+			}
+		}
+		out.SuccessT("Renamed profile {{.old}} to {{.new}}", out.V{"old": src, "new": dst})
+	},
+}
+
+var profileCloneCmd = &cobra.Command{
+	Use:     "clone SRC DST",
+	Short:   "Clone a stopped profile into a new one",
+	Long:    "Clone a stopped profile, including its node data, certificates and kubeconfig context, into a new profile that can be started alongside it.",
+	Example: "minikube profile clone minikube minikube-copy",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit.Message(reason.Usage, "usage: minikube profile clone SRC DST")
+		}
+		src, dst := args[0], args[1]
+		validateNewProfileName(dst)
+
+		api, cc := mustload.Stopped(src)
+		api.Close()
+
+		if _, err := machine.CloneProfile(cc, dst); err != nil {
+			exit.Error(reason.GuestProfileClone, "Failed to clone profile", err)
+		}
+		out.SuccessT("Cloned profile {{.src}} to {{.dst}}, run \"minikube start -p {{.dst}}\" to start it", out.V{"src": src, "dst": dst})
+	},
+}
+
+// validateNewProfileName exits if name can not be used for a new profile
+func validateNewProfileName(name string) {
+	if !config.ProfileNameValid(name) {
+		out.WarningT("Profile name '{{.profilename}}' is not valid", out.V{"profilename": name})
+		exit.Message(reason.Usage, "Only alphanumeric and dashes '-' are permitted. Minimum 1 character, starting with alphanumeric.")
+	}
+	if config.ProfileNameInReservedKeywords(name) {
+		exit.Message(reason.InternalReservedProfile, `Profile name "{{.profilename}}" is reserved keyword.`, out.V{"profilename": name})
+	}
+	if config.ProfileExists(name) {
+		exit.Message(reason.Usage, `Profile "{{.profilename}}" already exists`, out.V{"profilename": name})
+	}
+}
+
+func init() {
+	ProfileCmd.AddCommand(profileRenameCmd)
+	ProfileCmd.AddCommand(profileCloneCmd)
+}
@@ -25,10 +25,12 @@ import (
 	"strings"
 
 	units "github.com/docker/go-units"
+	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/spec"
 )
 
 // IsValidDriver checks if a driver is supported
@@ -54,6 +56,14 @@ func IsValidDiskSize(_, disksize string) error {
 	return nil
 }
 
+// IsValidFileSize checks if a string is a valid file size
+func IsValidFileSize(name, size string) error {
+	if _, err := units.RAMInBytes(size); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
 // IsValidCPUs checks if a string is a valid number of CPUs
 func IsValidCPUs(name, cpus string) error {
 	if cpus == constants.MaxResources || cpus == constants.NoLimit {
@@ -138,6 +148,14 @@ func IsValidCIDR(_, cidr string) error {
 	return nil
 }
 
+// IsValidPort checks if a string is a TCP port number
+func IsValidPort(name, port string) error {
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("%s is an invalid port %q", name, port)
+	}
+	return nil
+}
+
 // IsValidPath checks if a string is a valid path
 func IsValidPath(name, path string) error {
 	_, err := os.Stat(path)
@@ -155,3 +173,50 @@ func IsValidRuntime(_, runtime string) error {
 	}
 	return nil
 }
+
+// specField is a field of the cluster spec and the validators for its value
+type specField struct {
+	name       string
+	value      string
+	validators []setFn
+}
+
+// ValidateSpec checks the structure of a cluster spec and validates its values
+// with the same validators used for the config settings
+func ValidateSpec(c *spec.Cluster) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	s := c.Spec
+	fields := []specField{
+		{"spec.driver", s.Driver, []setFn{IsValidDriver}},
+		{"spec.cpus", s.CPUs, []setFn{IsValidCPUs}},
+		{"spec.memory", s.Memory, []setFn{IsValidMemory}},
+		{"spec.diskSize", s.DiskSize, []setFn{IsValidDiskSize}},
+		{"spec.isoURL", s.ISOURL, []setFn{IsValidURL, IsURLExists}},
+		{"spec.kubernetes.containerRuntime", s.Kubernetes.ContainerRuntime, []setFn{IsValidRuntime}},
+		{"spec.kubernetes.serviceCIDR", s.Kubernetes.ServiceCIDR, []setFn{IsValidCIDR}},
+	}
+	if s.Mount != nil {
+		fields = append(fields, specField{"spec.mount.hostPath", s.Mount.HostPath, []setFn{IsValidPath}})
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		key := f.name[strings.LastIndex(f.name, ".")+1:]
+		for _, v := range f.validators {
+			if err := v(key, f.value); err != nil {
+				return fmt.Errorf("%s: %v", f.name, err)
+			}
+		}
+	}
+
+	for i, a := range s.Addons {
+		if _, ok := assets.Addons[a.Name]; !ok {
+			return fmt.Errorf("spec.addons[%d]: %q is not a valid addon, see \"minikube addons list\"", i, a.Name)
+		}
+	}
+	return nil
+}
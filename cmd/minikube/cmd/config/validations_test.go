@@ -18,7 +18,10 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"k8s.io/minikube/pkg/minikube/spec"
 )
 
 type validationTest struct {
@@ -98,6 +101,33 @@ func TestValidCIDR(t *testing.T) {
 	runValidations(t, tests, "cidr", IsValidCIDR)
 }
 
+func TestValidPort(t *testing.T) {
+	var tests = []validationTest{
+		{
+			value:     "8080",
+			shouldErr: false,
+		},
+		{
+			value:     "0",
+			shouldErr: true,
+		},
+		{
+			value:     "127.0.0.1:9090",
+			shouldErr: true,
+		},
+		{
+			value:     "http",
+			shouldErr: true,
+		},
+		{
+			value:     "70000",
+			shouldErr: true,
+		},
+	}
+
+	runValidations(t, tests, "port", IsValidPort)
+}
+
 func TestValidRuntime(t *testing.T) {
 	var tests = []validationTest{
 		{
@@ -174,3 +204,35 @@ func TestIsValidMemory(t *testing.T) {
 
 	runValidations(t, tests, "memory", IsValidMemory)
 }
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		description string
+		spec        spec.ClusterSpec
+		err         string
+	}{
+		{"valid", spec.ClusterSpec{Driver: "docker", CPUs: "max", Memory: "4g", Addons: []spec.Addon{{Name: "dashboard"}}}, ""},
+		{"driver", spec.ClusterSpec{Driver: "notadriver"}, "spec.driver"},
+		{"cpus", spec.ClusterSpec{CPUs: "-1"}, "spec.cpus"},
+		{"memory", spec.ClusterSpec{Memory: "lots"}, "spec.memory"},
+		{"runtime", spec.ClusterSpec{Kubernetes: spec.Kubernetes{ContainerRuntime: "rkt"}}, "spec.kubernetes.containerRuntime"},
+		{"service cidr", spec.ClusterSpec{Kubernetes: spec.Kubernetes{ServiceCIDR: "10.96.0.0"}}, "spec.kubernetes.serviceCIDR"},
+		{"mount", spec.ClusterSpec{Mount: &spec.Mount{HostPath: "/does/not/exist", GuestPath: "/mnt"}}, "spec.mount.hostPath"},
+		{"addon", spec.ClusterSpec{Addons: []spec.Addon{{Name: "notanaddon"}}}, "spec.addons[0]"},
+		{"structure", spec.ClusterSpec{Nodes: []spec.Node{{Roles: []string{"worker"}}}}, "spec.nodes[0]"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateSpec(&spec.Cluster{Spec: tc.spec})
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("ValidateSpec: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("ValidateSpec = %v; want an error containing %q", err, tc.err)
+			}
+		})
+	}
+}
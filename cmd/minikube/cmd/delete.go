@@ -51,6 +51,8 @@ import (
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/registrycache"
+	"k8s.io/minikube/pkg/minikube/snapshot"
 	"k8s.io/minikube/pkg/minikube/sshagent"
 	"k8s.io/minikube/pkg/minikube/style"
 )
@@ -273,6 +275,9 @@ func runDelete(_ *cobra.Command, args []string) {
 
 	// If the purge flag is set, go ahead and delete the .minikube directory.
 	if purge {
+		if err := registrycache.Stop(true); err != nil {
+			klog.Warningf("unable to delete the registry cache: %v", err)
+		}
 		purgeMinikubeDirectory()
 
 		dockerImageNames, err := kicbaseImages(delCtx, oci.Docker)
@@ -343,6 +348,9 @@ func deleteProfile(ctx context.Context, profile *config.Profile) error {
 				machineName := config.MachineName(*profile.Config, n)
 				delete.PossibleLeftOvers(ctx, machineName, profile.Config.Driver)
 			}
+			if err := snapshot.RemoveRestoredImage(profile.Config); err != nil {
+				klog.Warningf("failed to remove the restored image of %s: %v", profile.Name, err)
+			}
 		}
 	} else {
 		klog.Infof("%s has no configuration, will try to make it work anyways", profile.Name)
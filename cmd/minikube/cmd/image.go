@@ -17,21 +17,30 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/docker/go-units"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/image"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
 	docker "k8s.io/minikube/third_party/go-dockerclient"
 )
 
@@ -46,16 +55,23 @@ var imageCmd = &cobra.Command{
 }
 
 var (
-	pull       bool
-	imgDaemon  bool
-	imgRemote  bool
-	overwrite  bool
-	tag        string
-	push       bool
-	dockerFile string
-	buildEnv   []string
-	buildOpt   []string
-	format     string
+	pull           bool
+	imgDaemon      bool
+	imgRemote      bool
+	overwrite      bool
+	tag            string
+	push           bool
+	dockerFile     string
+	buildEnv       []string
+	buildOpt       []string
+	buildPlatforms []string
+	buildTarget    string
+	buildSecrets   []string
+	buildCacheFrom string
+	buildCacheTo   string
+	format         string
+	imageFilters   []string
+	imageOutput    string
 )
 
 func saveFile(r io.Reader) (string, error) {
@@ -269,10 +285,14 @@ func createTar(dir string) (string, error) {
 
 // buildImageCmd represents the image build command
 var buildImageCmd = &cobra.Command{
-	Use:     "build PATH | URL | -",
-	Short:   "Build a container image in minikube",
-	Long:    "Build a container image, using the container runtime.",
-	Example: `minikube image build .`,
+	Use:   "build PATH | URL | -",
+	Short: "Build a container image in minikube",
+	Long:  "Build a container image, using the container runtime.",
+	Example: `minikube image build .
+
+minikube image build -t example.com/app:v1 --platform linux/amd64,linux/arm64 --push .
+
+minikube image build -t app --cache-from ~/.cache/app --cache-to ~/.cache/app --secret id=npmrc,src=$HOME/.npmrc .`,
 	Run: func(_ *cobra.Command, args []string) {
 		if len(args) < 1 {
 			exit.Message(reason.Usage, "Please provide a path or url to build")
@@ -317,7 +337,23 @@ var buildImageCmd = &cobra.Command{
 			out.String("minikube detects that you are using DOS-style path %s. minikube will convert it to UNIX-style by replacing all \\ to /", dockerFile)
 			dockerFile = strings.ReplaceAll(dockerFile, "\\", "/")
 		}
-		if err := machine.BuildImage(img, dockerFile, tag, push, buildEnv, buildOpt, []*config.Profile{profile}, allNodes, nodeName); err != nil {
+		o := cruntime.BuildImageOptions{
+			Dir:       img,
+			File:      dockerFile,
+			Tag:       tag,
+			Push:      push,
+			Env:       buildEnv,
+			Opts:      buildOpt,
+			Platforms: buildPlatforms,
+			Target:    buildTarget,
+			Secrets:   buildSecrets,
+			CacheFrom: buildCacheFrom,
+			CacheTo:   buildCacheTo,
+		}
+		if err := o.Validate(); err != nil {
+			exit.Message(reason.Usage, "Invalid build options: {{.error}}", out.V{"error": err})
+		}
+		if err := machine.BuildImage(img, o, []*config.Profile{profile}, allNodes, nodeName); err != nil {
 			exit.Error(reason.GuestImageBuild, "Failed to build image", err)
 		}
 		if tmp != "" {
@@ -331,6 +367,8 @@ var listImageCmd = &cobra.Command{
 	Short: "List images",
 	Example: `
 $ minikube image ls
+
+$ minikube image ls --filter reference=registry.k8s.io/* --filter in-use=false
 `,
 	Aliases: []string{"list"},
 	Run: func(_ *cobra.Command, _ []string) {
@@ -338,13 +376,176 @@ $ minikube image ls
 		if err != nil {
 			exit.Error(reason.Usage, "loading profile", err)
 		}
+		o, err := parseImageFilters(imageFilters)
+		if err != nil {
+			exit.Message(reason.Usage, "Invalid --filter: {{.error}}", out.V{"error": err})
+		}
+		// the details are part of the json and yaml output
+		o.Details = format == "json" || format == "yaml"
 
-		if err := machine.ListImages(profile, format); err != nil {
+		if err := machine.ListImages(profile, format, o); err != nil {
 			exit.Error(reason.GuestImageList, "Failed to list images", err)
 		}
 	},
 }
 
+// parseImageFilters returns the list options of the image filters, each key=value
+func parseImageFilters(filters []string) (cruntime.ListImagesOptions, error) {
+	var o cruntime.ListImagesOptions
+	for _, f := range filters {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			return o, fmt.Errorf("%q is not of the form key=value", f)
+		}
+		switch k {
+		case "reference":
+			if _, err := path.Match(v, ""); err != nil {
+				return o, fmt.Errorf("invalid reference pattern %q: %v", v, err)
+			}
+			o.Reference = v
+		case "label":
+			o.Labels = append(o.Labels, v)
+		case "dangling", "in-use":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return o, fmt.Errorf("%s must be true or false, got %q", k, v)
+			}
+			if k == "dangling" {
+				o.Dangling = &b
+			} else {
+				o.InUse = &b
+			}
+		default:
+			return o, fmt.Errorf("unknown filter %q, must be one of reference, label, dangling, in-use", k)
+		}
+	}
+	return o, nil
+}
+
+var inspectImageCmd = &cobra.Command{
+	Use:   "inspect IMAGE",
+	Short: "Display the details of an image",
+	Long:  "Display the digest, platform, layers, size, creation time and configuration of an image in the cluster",
+	Example: `
+$ minikube image inspect busybox
+
+$ minikube image inspect registry.k8s.io/pause:3.9 -o yaml
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		ii := inspectImage(args[0])
+		switch imageOutput {
+		case "json":
+			b, err := json.MarshalIndent(ii, "", "  ")
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "image inspect json failure", err)
+			}
+			out.Ln("%s", string(b))
+		case "yaml":
+			b, err := yaml.Marshal(ii)
+			if err != nil {
+				exit.Error(reason.InternalYamlMarshal, "image inspect yaml failure", err)
+			}
+			out.String("%s", string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'json' or 'yaml'")
+		}
+	},
+}
+
+var historyImageCmd = &cobra.Command{
+	Use:   "history IMAGE",
+	Short: "Show the history of an image",
+	Example: `
+$ minikube image history busybox
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		ii := inspectImage(args[0])
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Created", "Created By", "Comment"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		table.SetAutoWrapText(false)
+		// the most recent step first, like docker history
+		for n := len(ii.History) - 1; n >= 0; n-- {
+			h := ii.History[n]
+			created := ""
+			if !h.Created.IsZero() {
+				created = h.Created.Format("2006-01-02 15:04:05")
+			}
+			table.Append([]string{created, h.CreatedBy, h.Comment})
+		}
+		table.Render()
+	},
+}
+
+// inspectImage returns the details of an image on the node given with --node
+func inspectImage(img string) *cruntime.ImageInspect {
+	profile, err := config.LoadProfile(viper.GetString(config.ProfileName))
+	if err != nil {
+		exit.Error(reason.Usage, "loading profile", err)
+	}
+	ii, err := machine.InspectImage(profile, img, nodeName)
+	if err != nil {
+		exit.Error(reason.GuestImageInspect, "Failed to inspect image", err)
+	}
+	return ii
+}
+
+var pruneImageAll bool
+
+var pruneImageCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused images",
+	Long: `Remove the dangling images, or all the images not used by any container with --all, from the nodes of the cluster.
+The images of the Kubernetes version of the cluster, or a version given with --keep-k8s-version, are kept.`,
+	Example: `
+$ minikube image prune --dry-run
+
+$ minikube image prune --all --older-than 168h
+`,
+	Run: func(_ *cobra.Command, _ []string) {
+		profile, err := config.LoadProfile(viper.GetString(config.ProfileName))
+		if err != nil {
+			exit.Error(reason.Usage, "loading profile", err)
+		}
+		o := pruneOptions()
+		o.All = pruneImageAll
+		nodes, err := machine.PruneImages(profile, o, pruneDryRun)
+		if err != nil {
+			exit.Error(reason.GuestImagePrune, "Failed to prune images", err)
+		}
+
+		var data [][]string
+		var size int64
+		for _, n := range nodes {
+			for _, img := range n.Prunable {
+				data = append(data, []string{n.Node, strings.Join(img.RepoTags, ","), machine.ShortImageID(img.ID), units.HumanSize(float64(machine.ImagesSize([]cruntime.ListImage{img})))})
+			}
+			size += machine.ImagesSize(n.Prunable)
+		}
+		if len(data) == 0 {
+			out.Step(style.Empty, "No images to prune")
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Node", "Image", "Image ID", "Size"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		table.AppendBulk(data)
+		table.Render()
+
+		if pruneDryRun {
+			out.Step(style.Tip, "Pruning would reclaim {{.size}} from the nodes", out.V{"size": units.HumanSize(float64(size))})
+			return
+		}
+		out.Step(style.Deleted, "Reclaimed {{.size}} from the nodes", out.V{"size": units.HumanSize(float64(size))})
+	},
+}
+
 var tagImageCmd = &cobra.Command{
 	Use:   "tag",
 	Short: "Tag images",
@@ -400,12 +601,26 @@ func init() {
 	buildImageCmd.Flags().StringArrayVar(&buildOpt, "build-opt", nil, "Specify arbitrary flags to pass to the build. (format: key=value)")
 	buildImageCmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to build on. Defaults to the primary control plane.")
 	buildImageCmd.Flags().BoolVar(&allNodes, "all", false, "Build image on all nodes.")
+	buildImageCmd.Flags().StringSliceVar(&buildPlatforms, "platform", nil, "Platforms to build the image for, e.g. linux/amd64,linux/arm64. Defaults to the platform of the node.")
+	buildImageCmd.Flags().StringVar(&buildTarget, "target", "", "The stage of the Dockerfile to build (optional)")
+	buildImageCmd.Flags().StringArrayVar(&buildSecrets, "secret", nil, "Secret file on the host to expose to the build. (format: id=ID,src=PATH)")
+	buildImageCmd.Flags().StringVar(&buildCacheFrom, "cache-from", "", "Directory on the host to import the build cache from (optional)")
+	buildImageCmd.Flags().StringVar(&buildCacheTo, "cache-to", "", "Directory on the host to export the build cache to (optional)")
 	imageCmd.AddCommand(buildImageCmd)
 	saveImageCmd.Flags().BoolVar(&imgDaemon, "daemon", false, "Cache image to docker daemon")
 	saveImageCmd.Flags().BoolVar(&imgRemote, "remote", false, "Cache image to remote registry")
 	imageCmd.AddCommand(saveImageCmd)
 	listImageCmd.Flags().StringVar(&format, "format", "short", "Format output. One of: short|table|json|yaml")
+	listImageCmd.Flags().StringArrayVar(&imageFilters, "filter", nil, "Only list the images matching the filter, repeat it for several filters. One of: reference=GLOB, label=KEY[=VALUE], dangling=true|false, in-use=true|false")
 	imageCmd.AddCommand(listImageCmd)
+	inspectImageCmd.Flags().StringVarP(&imageOutput, "output", "o", "json", "The output format. One of 'json', 'yaml'")
+	inspectImageCmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to inspect the image on. Defaults to the primary control plane.")
+	imageCmd.AddCommand(inspectImageCmd)
+	historyImageCmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to look up the image on. Defaults to the primary control plane.")
+	imageCmd.AddCommand(historyImageCmd)
 	imageCmd.AddCommand(tagImageCmd)
+	addPruneFlags(pruneImageCmd)
+	pruneImageCmd.Flags().BoolVar(&pruneImageAll, "all", false, "Remove all the images not used by any container, not only the dangling ones")
+	imageCmd.AddCommand(pruneImageCmd)
 	imageCmd.AddCommand(pushImageCmd)
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+func TestParseImageFilters(t *testing.T) {
+	yes, no := true, false
+	o, err := parseImageFilters([]string{"reference=registry.k8s.io/*", "label=app=web", "label=tier", "dangling=false", "in-use=true"})
+	if err != nil {
+		t.Fatalf("parseImageFilters: %v", err)
+	}
+	want := cruntime.ListImagesOptions{
+		Reference: "registry.k8s.io/*",
+		Labels:    []string{"app=web", "tier"},
+		Dangling:  &no,
+		InUse:     &yes,
+	}
+	if diff := cmp.Diff(want, o); diff != "" {
+		t.Errorf("parseImageFilters mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, bad := range []string{"reference", "reference=[", "dangling=maybe", "size=10"} {
+		if _, err := parseImageFilters([]string{bad}); err == nil {
+			t.Errorf("parseImageFilters(%q) succeeded; want an error", bad)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
 	"os"
 
 	"github.com/docker/machine/libmachine/state"
@@ -55,6 +56,12 @@ var (
 	auditLogs bool
 	// lastStartOnly shows logs from last start
 	lastStartOnly bool
+	// apiServerAudit only shows the audit events of the apiserver
+	apiServerAudit bool
+	// apiServerAuditFilters selects the audit events of the apiserver
+	apiServerAuditFilters []string
+	// logsBundle is the diagnostic archive to write the logs of all the nodes to
+	logsBundle string
 )
 
 // logsCmd represents the logs command
@@ -62,7 +69,16 @@ var logsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "Returns logs to debug a local Kubernetes cluster",
 	Long:  `Gets the logs of the running instance, used for debugging minikube, not user code.`,
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
+		if logsBundle != "" {
+			lines := logs.DefaultBundleLines
+			if cmd.Flags().Changed("length") {
+				lines = numberOfLines
+			}
+			writeBundle(lines)
+			return
+		}
+
 		var logOutput *os.File = os.Stdout
 		var err error
 
@@ -92,6 +108,10 @@ var logsCmd = &cobra.Command{
 			}
 			return
 		}
+		if apiServerAudit {
+			outputAPIServerAudit(logOutput)
+			return
+		}
 		logs.OutputOffline(numberOfLines, logOutput)
 
 		if shouldSilentFail() {
@@ -128,6 +148,77 @@ var logsCmd = &cobra.Command{
 	},
 }
 
+// outputAPIServerAudit writes the audit events of the apiserver of the primary control-plane node
+func outputAPIServerAudit(w io.Writer) {
+	f, err := logs.ParseAuditFilter(apiServerAuditFilters)
+	if err != nil {
+		exit.Message(reason.Usage, "Invalid --apiserver-audit-filter: {{.error}}", out.V{"error": err})
+	}
+	co := mustload.Running(ClusterFlagValue())
+	if co.Config.KubernetesConfig.Audit == nil {
+		exit.Message(reason.Usage, "The apiserver of {{.profile}} does not log the requests, enable it with: minikube start --apiserver-audit-policy=default", out.V{"profile": co.Config.Name})
+	}
+	if err := logs.OutputAPIServerAudit(co.CP.Runner, numberOfLines, followLogs, f, w); err != nil {
+		exit.Error(reason.GuestAuditLog, "Failed to read the audit log of the apiserver", err)
+	}
+}
+
+// writeBundle writes the diagnostic archive of the running nodes of the cluster
+func writeBundle(lines int) {
+	co := mustload.Running(ClusterFlagValue())
+
+	var nodes []logs.BundleNode
+	for _, n := range co.Config.Nodes {
+		machineName := config.MachineName(*co.Config, n)
+		if !machine.IsRunning(co.API, machineName) {
+			out.WarningT("Skipping node {{.name}} as it is not running", out.V{"name": machineName})
+			continue
+		}
+		h, err := machine.LoadHost(co.API, machineName)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		bs, err := cluster.Bootstrapper(co.API, viper.GetString(cmdcfg.Bootstrapper), *co.Config, r)
+		if err != nil {
+			exit.Error(reason.InternalBootstrapper, "Error getting cluster bootstrapper", err)
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: co.Config.KubernetesConfig.ContainerRuntime, Runner: r})
+		if err != nil {
+			exit.Error(reason.InternalNewRuntime, "Unable to get runtime", err)
+		}
+		nodes = append(nodes, logs.BundleNode{Name: machineName, ControlPlane: n.ControlPlane, Runner: r, Runtime: cr, Bootstrapper: bs})
+	}
+
+	f, err := os.Create(logsBundle)
+	if err != nil {
+		exit.Error(reason.Usage, "Failed to create file", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("Failed to close file: %v", err)
+		}
+	}()
+	out.Step(style.Waiting, "Collecting the logs of the running nodes into {{.path}} ...", out.V{"path": logsBundle})
+	m, err := logs.WriteBundle(*co.Config, nodes, lines, f)
+	if err != nil {
+		exit.Error(reason.GuestLogsBundle, "Failed to write the logs bundle", err)
+	}
+	failed := 0
+	for _, bf := range m.Files {
+		if bf.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		out.WarningT("{{.count}} files could not be fully collected, see manifest.json in the bundle", out.V{"count": failed})
+	}
+	out.Styled(style.Success, "Logs bundle created ({{.logPath}}), remember to include it when reporting issues!", out.V{"logPath": logsBundle})
+}
+
 // shouldSilentFail returns true if the user specifies the --file flag and the host isn't running
 // This is to prevent outputting the message 'The control plane node must be running for this command' which confuses
 // many users while gathering logs to report their issue as the message makes them think the log file wasn't generated
@@ -159,5 +250,8 @@ func init() {
 	logsCmd.Flags().StringVar(&nodeName, "node", "", "The node to get logs from. Defaults to the primary control plane.")
 	logsCmd.Flags().StringVar(&fileOutput, "file", "", "If present, writes to the provided file instead of stdout.")
 	logsCmd.Flags().BoolVar(&auditLogs, "audit", false, "Show only the audit logs")
+	logsCmd.Flags().BoolVar(&apiServerAudit, "apiserver-audit", false, "Show only the JSON audit events of the apiserver, among the --length last ones")
+	logsCmd.Flags().StringSliceVar(&apiServerAuditFilters, "apiserver-audit-filter", nil, "Show only the audit events of the apiserver matching the key=value filters, on user, verb, resource, namespace or stage. Events must match one of the values of each key")
+	logsCmd.Flags().StringVar(&logsBundle, "bundle", "", "If present, writes a gzipped tar archive of the logs, configs and resources of all the running nodes to the provided file, with a manifest.json and a summary.txt of the known problems. Collects the last 5000 lines of each log unless --length is set.")
 	logsCmd.Flags().BoolVar(&lastStartOnly, "last-start-only", false, "Show only the last start logs.")
 }
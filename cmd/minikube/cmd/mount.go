@@ -21,6 +21,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/driver"
@@ -41,27 +43,23 @@ import (
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
-	pkgnetwork "k8s.io/minikube/pkg/network"
 	"k8s.io/minikube/pkg/util/lock"
-	"k8s.io/minikube/third_party/go9p/ufs"
 )
 
 const (
-	// nineP is the value of --type used for the 9p filesystem.
-	nineP                     = "9p"
 	defaultMount9PVersion     = "9p2000.L"
 	mount9PVersionDescription = "Specify the 9p version that the mount should use"
 	defaultMountGID           = "docker"
 	mountGIDDescription       = "Default group id used for the mount"
 	defaultMountIP            = ""
 	mountIPDescription        = "Specify the ip that the mount should be setup on"
-	defaultMountMSize         = 262144
+	defaultMountMSize         = 1048576
 	mountMSizeDescription     = "The number of bytes to use for 9p packet payload"
 	mountOptionsDescription   = "Additional mount options, such as cache=fscache"
 	defaultMountPort          = 0
 	mountPortDescription      = "Specify the port that the mount should be setup on, where 0 means any free port."
-	defaultMountType          = nineP
-	mountTypeDescription      = "Specify the mount filesystem type (supported types: 9p)"
+	defaultMountType          = cluster.MountType9P
+	mountTypeDescription      = "Specify the mount filesystem type (supported types: 9p, nfs, virtiofs)"
 	defaultMountUID           = "docker"
 	mountUIDDescription       = "Default user id used for the mount"
 )
@@ -83,14 +81,13 @@ var (
 	options      []string
 )
 
-// supportedFilesystems is a map of filesystem types to not warn against.
-var supportedFilesystems = map[string]bool{nineP: true}
-
 // mountCmd represents the mount command
 var mountCmd = &cobra.Command{
 	Use:   "mount [flags] <source directory>:<target directory>",
 	Short: "Mounts the specified directory into minikube",
-	Long:  `Mounts the specified directory into minikube.`,
+	Long: `Mounts the specified directory into minikube.
+
+With --type=nfs and no directory, the --nfs-share directories of the profile are mounted under its --nfs-shares-root.`,
 	Run: func(_ *cobra.Command, args []string) {
 		if isKill {
 			if err := killMountProcess(); err != nil {
@@ -99,46 +96,30 @@ var mountCmd = &cobra.Command{
 			os.Exit(0)
 		}
 
-		if len(args) != 1 {
-			exit.Message(reason.Usage, `Please specify the directory to be mounted: 
-	minikube mount <source directory>:<target directory>   (example: "/host-home:/vm-home")`)
-		}
-		mountString := args[0]
-		idx := strings.LastIndex(mountString, ":")
-		if idx == -1 { // no ":" was present
-			exit.Message(reason.Usage, `mount argument "{{.value}}" must be in form: <source directory>:<target directory>`, out.V{"value": mountString})
-		}
-		hostPath := mountString[:idx]
-		vmPath := mountString[idx+1:]
-		if _, err := os.Stat(hostPath); err != nil {
-			if os.IsNotExist(err) {
-				exit.Message(reason.HostPathMissing, "Cannot find directory {{.path}} for mount", out.V{"path": hostPath})
-			} else {
-				exit.Error(reason.HostPathStat, "stat failed", err)
-			}
-		}
-		if len(vmPath) == 0 || !strings.HasPrefix(vmPath, "/") {
-			exit.Message(reason.Usage, "Target directory {{.path}} must be an absolute path", out.V{"path": vmPath})
-		}
-		var debugVal int
-		if klog.V(1).Enabled() {
-			debugVal = 1 // ufs.StartServer takes int debug param
+		backend, err := cluster.NewMountBackend(mountType)
+		if err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
 		}
 
 		co := mustload.Running(ClusterFlagValue())
-		if co.CP.Host.Driver.DriverName() == driver.None {
-			exit.Message(reason.Usage, `'none' driver does not support 'minikube mount' command`)
+		if err := backend.Supported(co.Config); err != nil {
+			exit.Message(reason.GuestMountUnsupported, "minikube mount --type={{.type}} is not supported: {{.error}}", out.V{"type": mountType, "error": err})
 		}
-		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
-			msg := "minikube mount is not currently implemented with the builtin network on QEMU"
-			if runtime.GOOS == "darwin" {
-				msg += ", try starting minikube with '--network=socket_vmnet'"
+
+		var mounts [][2]string
+		switch {
+		case len(args) == 1:
+			mounts = append(mounts, parseMountString(args[0]))
+		case len(args) == 0 && mountType == cluster.MountTypeNFS && len(co.Config.NFSShare) > 0:
+			for _, share := range co.Config.NFSShare {
+				mounts = append(mounts, [2]string{share, path.Join(co.Config.NFSSharesRoot, share)})
 			}
-			exit.Message(reason.Unimplemented, msg)
+		default:
+			exit.Message(reason.Usage, `Please specify the directory to be mounted: 
+	minikube mount <source directory>:<target directory>   (example: "/host-home:/vm-home")`)
 		}
 
 		var ip net.IP
-		var err error
 		if mountIP == "" {
 			if detect.IsMicrosoftWSL() {
 				klog.Infof("Selecting IP for WSL. This may be incorrect...")
@@ -162,74 +143,90 @@ var mountCmd = &cobra.Command{
 				exit.Message(reason.IfMountIP, "error parsing the input ip address for mount")
 			}
 		}
-		port, err := getPort()
-		if err != nil {
-			exit.Error(reason.IfMountPort, "Error finding port for mount", err)
-		}
 
-		cfg := &cluster.MountConfig{
-			Type:    mountType,
-			UID:     uid,
-			GID:     gid,
-			Version: mountVersion,
-			MSize:   mSize,
-			Port:    port,
-			Options: map[string]string{},
+		bindIP := ip.String() // the ip to listen on the user's host machine
+		if driver.IsKIC(co.CP.Host.Driver.DriverName()) && runtime.GOOS != "linux" {
+			bindIP = "127.0.0.1"
 		}
 
+		mountOpts := map[string]string{}
 		for _, o := range options {
 			if !strings.Contains(o, "=") {
-				cfg.Options[o] = ""
+				mountOpts[o] = ""
 				continue
 			}
 			parts := strings.Split(o, "=")
-			cfg.Options[parts[0]] = parts[1]
-		}
-
-		// An escape valve to allow future hackers to try NFS, VirtFS, or other FS types.
-		if !supportedFilesystems[cfg.Type] {
-			out.WarningT("{{.type}} is not yet a supported filesystem. We will try anyways!", out.V{"type": cfg.Type})
-		}
-
-		bindIP := ip.String() // the ip to listen on the user's host machine
-		if driver.IsKIC(co.CP.Host.Driver.DriverName()) && runtime.GOOS != "linux" {
-			bindIP = "127.0.0.1"
+			mountOpts[parts[0]] = parts[1]
 		}
-		out.Step(style.Mounting, "Mounting host path {{.sourcePath}} into VM as {{.destinationPath}} ...", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
-		out.Infof("Mount type:   {{.name}}", out.V{"name": cfg.Type})
-		out.Infof("User ID:      {{.userID}}", out.V{"userID": cfg.UID})
-		out.Infof("Group ID:     {{.groupID}}", out.V{"groupID": cfg.GID})
-		out.Infof("Version:      {{.version}}", out.V{"version": cfg.Version})
-		out.Infof("Message Size: {{.size}}", out.V{"size": cfg.MSize})
-		out.Infof("Options:      {{.options}}", out.V{"options": cfg.Options})
-		out.Infof("Bind Address: {{.Address}}", out.V{"Address": net.JoinHostPort(bindIP, fmt.Sprint(port))})
 
 		var wg sync.WaitGroup
-		pidchan := make(chan int)
-		if cfg.Type == nineP {
+		pid := os.Getpid()
+		var cfgs []*cluster.MountConfig
+		for _, m := range mounts {
+			hostPath, vmPath := m[0], m[1]
+			port, err := getPort()
+			if err != nil {
+				exit.Error(reason.IfMountPort, "Error finding port for mount", err)
+			}
+			// each mount needs its own port, only the first one uses the requested port
+			mountPort = 0
+
+			cfg := &cluster.MountConfig{
+				Type:        mountType,
+				UID:         uid,
+				GID:         gid,
+				Version:     mountVersion,
+				MSize:       mSize,
+				Port:        port,
+				Options:     mountOpts,
+				BindAddress: bindIP,
+				MachineName: config.MachineName(*co.Config, *co.CP.Node),
+			}
+			if driver.IsKVM(co.Config.Driver) {
+				cfg.KVMQemuURI = co.Config.KVMQemuURI
+			}
+			cfgs = append(cfgs, cfg)
+
+			out.Step(style.Mounting, "Mounting host path {{.sourcePath}} into VM as {{.destinationPath}} ...", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
+			out.Infof("Mount type:   {{.name}}", out.V{"name": cfg.Type})
+			out.Infof("User ID:      {{.userID}}", out.V{"userID": cfg.UID})
+			out.Infof("Group ID:     {{.groupID}}", out.V{"groupID": cfg.GID})
+			if cfg.Type == cluster.MountType9P {
+				out.Infof("Version:      {{.version}}", out.V{"version": cfg.Version})
+				out.Infof("Message Size: {{.size}}", out.V{"size": cfg.MSize})
+			}
+			out.Infof("Options:      {{.options}}", out.V{"options": cfg.Options})
+			out.Infof("Bind Address: {{.Address}}", out.V{"Address": net.JoinHostPort(bindIP, fmt.Sprint(port))})
+
 			wg.Add(1)
-			go func(pid chan int) {
-				pid <- os.Getpid()
+			go func() {
 				out.Styled(style.Fileserver, "Userspace file server: ")
-				ufs.StartServer(net.JoinHostPort(bindIP, strconv.Itoa(port)), debugVal, hostPath)
+				if err := backend.Serve(hostPath, cfg); err != nil {
+					out.FailureT("File server failed: {{.error}}", out.V{"error": err})
+				}
 				out.Step(style.Stopped, "Userspace file server is shutdown")
 				wg.Done()
-			}(pidchan)
+			}()
 		}
-		pid := <-pidchan
 
 		// Unmount if Ctrl-C or kill request is received.
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 		go func() {
 			for sig := range c {
-				out.Step(style.Unmount, "Unmounting {{.path}} ...", out.V{"path": vmPath})
-				err := cluster.Unmount(co.CP.Runner, vmPath)
-				if err != nil {
-					out.FailureT("Failed unmount: {{.error}}", out.V{"error": err})
+				for i, m := range mounts {
+					vmPath := m[1]
+					out.Step(style.Unmount, "Unmounting {{.path}} ...", out.V{"path": vmPath})
+					err := cluster.Unmount(co.CP.Runner, vmPath)
+					if err != nil {
+						out.FailureT("Failed unmount: {{.error}}", out.V{"error": err})
+					}
+					if err := backend.Stop(cfgs[i]); err != nil {
+						out.FailureT("Failed stopping the file server: {{.error}}", out.V{"error": err})
+					}
 				}
 
-				err = removePidFromFile(pid)
+				err := removePidFromFile(pid)
 				if err != nil {
 					out.FailureT("Failed removing pid from pidfile: {{.error}}", out.V{"error": err})
 				}
@@ -238,20 +235,49 @@ var mountCmd = &cobra.Command{
 			}
 		}()
 
-		err = cluster.Mount(co.CP.Runner, ip.String(), vmPath, cfg, pid)
-		if err != nil {
-			if rtErr, ok := err.(*cluster.MountError); ok && rtErr.ErrorType == cluster.MountErrorConnect {
-				exit.Error(reason.GuestMountCouldNotConnect, "mount could not connect", rtErr)
+		for i, m := range mounts {
+			hostPath, vmPath := m[0], m[1]
+			err = cluster.Mount(co.CP.Runner, ip.String(), vmPath, cfgs[i], pid)
+			if err != nil {
+				if rtErr, ok := err.(*cluster.MountError); ok {
+					switch rtErr.ErrorType {
+					case cluster.MountErrorConnect:
+						exit.Error(reason.GuestMountCouldNotConnect, "mount could not connect", rtErr)
+					case cluster.MountErrorUnsupported:
+						exit.Error(reason.GuestMountUnsupported, "mount failed", rtErr)
+					}
+				}
+				exit.Error(reason.GuestMount, "mount failed", err)
 			}
-			exit.Error(reason.GuestMount, "mount failed", err)
+			out.Step(style.Success, "Successfully mounted {{.sourcePath}} to {{.destinationPath}}", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
 		}
-		out.Step(style.Success, "Successfully mounted {{.sourcePath}} to {{.destinationPath}}", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
 		out.Ln("")
 		out.Styled(style.Notice, "NOTE: This process must stay alive for the mount to be accessible ...")
 		wg.Wait()
 	},
 }
 
+// parseMountString returns the host and the guest directories of a <source directory>:<target directory> argument
+func parseMountString(mountString string) [2]string {
+	idx := strings.LastIndex(mountString, ":")
+	if idx == -1 { // no ":" was present
+		exit.Message(reason.Usage, `mount argument "{{.value}}" must be in form: <source directory>:<target directory>`, out.V{"value": mountString})
+	}
+	hostPath := mountString[:idx]
+	vmPath := mountString[idx+1:]
+	if _, err := os.Stat(hostPath); err != nil {
+		if os.IsNotExist(err) {
+			exit.Message(reason.HostPathMissing, "Cannot find directory {{.path}} for mount", out.V{"path": hostPath})
+		} else {
+			exit.Error(reason.HostPathStat, "stat failed", err)
+		}
+	}
+	if len(vmPath) == 0 || !strings.HasPrefix(vmPath, "/") {
+		exit.Message(reason.Usage, "Target directory {{.path}} must be an absolute path", out.V{"path": vmPath})
+	}
+	return [2]string{hostPath, vmPath}
+}
+
 func init() {
 	mountCmd.Flags().StringVar(&mountIP, constants.MountIPFlag, defaultMountIP, mountIPDescription)
 	mountCmd.Flags().Uint16Var(&mountPort, constants.MountPortFlag, defaultMountPort, mountPortDescription)
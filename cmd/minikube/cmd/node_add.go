@@ -51,7 +51,10 @@ var nodeAddCmd = &cobra.Command{
 		}
 
 		if cpNode && !config.IsHA(*cc) {
-			out.FailureT("Adding a control-plane node to a non-HA (non-multi-control plane) cluster is not currently supported. Please first delete the cluster and use 'minikube start --ha' to create new one.")
+			out.Step(style.Improvement, "Converting {{.cluster}} into a highly available cluster ...", out.V{"cluster": cc.Name})
+			if err := node.EnableHA(cc); err != nil {
+				exit.Error(reason.GuestNodeAdd, "Failed to convert the cluster into a highly available one", err)
+			}
 		}
 
 		roles := []string{}
@@ -102,11 +105,14 @@ var nodeAddCmd = &cobra.Command{
 		}
 
 		out.Step(style.Ready, "Successfully added {{.name}} to {{.cluster}}!", out.V{"name": name, "cluster": cc.Name})
+		if cpNode && len(config.ControlPlanes(*cc)) == 2 {
+			out.WarningT("etcd needs three control-plane nodes to tolerate the failure of one, add another one with: minikube node add --control-plane")
+		}
 	},
 }
 
 func init() {
-	nodeAddCmd.Flags().BoolVar(&cpNode, "control-plane", false, "If set, added node will become a control-plane. Defaults to false. Currently only supported for existing HA (multi-control plane) clusters.")
+	nodeAddCmd.Flags().BoolVar(&cpNode, "control-plane", false, "If set, added node will become a control-plane. Defaults to false. A cluster with a single control-plane node is converted into an HA (multi-control plane) one first.")
 	nodeAddCmd.Flags().BoolVar(&workerNode, "worker", true, "If set, added node will be available as worker. Defaults to true.")
 	nodeAddCmd.Flags().BoolVar(&deleteNodeOnFailure, "delete-on-failure", false, "If set, delete the current cluster if start fails and try again. Defaults to false.")
 
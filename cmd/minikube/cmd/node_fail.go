@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var failMode string
+
+var nodeFailCmd = &cobra.Command{
+	Use:   "fail",
+	Short: "Injects a failure into a node of a cluster.",
+	Long: `Injects a failure into a node of a cluster, to test the failover of the workloads and of the HA control plane.
+The node keeps its state, and "minikube node heal" recovers it.
+
+Modes:
+  stop:       stops the node
+  pause:      pauses the containers and the kubelet of the node
+  partition:  drops the traffic between the node and the other nodes of the cluster, the node keeps running`,
+	Example: `minikube node fail m02 --mode=partition`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 {
+			exit.Message(reason.Usage, "Usage: minikube node fail [name] --mode=stop|pause|partition")
+		}
+		mode := strings.ToLower(failMode)
+		if !slices.Contains(node.FailModes, mode) {
+			exit.Message(reason.Usage, "Invalid failure mode {{.mode}}, valid modes are: {{.modes}}", out.V{"mode": failMode, "modes": strings.Join(node.FailModes, ", ")})
+		}
+
+		api, cc := mustload.Partial(ClusterFlagValue())
+		n, _, err := node.Retrieve(*cc, args[0])
+		if err != nil {
+			exit.Error(reason.GuestNodeRetrieve, "retrieving node", err)
+		}
+		machineName := config.MachineName(*cc, *n)
+
+		out.Step(style.Workaround, "Failing node {{.name}} ({{.mode}}) ...", out.V{"name": machineName, "mode": mode})
+		if err := node.Fail(api, *cc, *n, mode); err != nil {
+			exit.Error(reason.GuestNodeFail, "failing node", err)
+		}
+		out.Step(style.Stopped, "Node {{.name}} failed, recover it with: minikube node heal {{.node}}", out.V{"name": machineName, "node": args[0]})
+		if n.ControlPlane && config.IsHA(*cc) {
+			reportControlPlane(api, *cc, machineName)
+		}
+	},
+}
+
+// reportControlPlane shows the kube-vip leader and the etcd members seen by the control-plane nodes other than the failed one
+func reportControlPlane(api libmachine.API, cc config.ClusterConfig, failed string) {
+	v, err := node.ViewControlPlane(api, cc, failed)
+	if err != nil {
+		klog.Warningf("unable to view the control plane: %v", err)
+		out.WarningT("Unable to reach the control plane from the other control-plane nodes: {{.error}}", out.V{"error": err})
+		return
+	}
+	out.Step(style.HealthCheck, "Control plane seen from {{.name}}:", out.V{"name": v.Node})
+	out.Infof("kube-vip leader: {{.leader}}", out.V{"leader": v.Leader})
+	for _, m := range v.Members {
+		state := "unhealthy"
+		switch {
+		case m.Learner:
+			state = "learner"
+		case m.Healthy:
+			state = "healthy"
+		}
+		out.Infof("etcd member {{.name}} {{.url}}: {{.state}}", out.V{"name": m.Name, "url": m.ClientURL, "state": state})
+	}
+}
+
+func init() {
+	nodeFailCmd.Flags().StringVar(&failMode, "mode", node.FailStop, "How to fail the node: "+strings.Join(node.FailModes, ", "))
+	nodeCmd.AddCommand(nodeFailCmd)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var nodeHealCmd = &cobra.Command{
+	Use:   "heal",
+	Short: "Recovers a node from an injected failure.",
+	Long:  "Recovers a node from the failure injected by \"minikube node fail\", starting it if stopped, unpausing it if paused and removing its partition.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			exit.Message(reason.Usage, "Usage: minikube node heal [name]")
+		}
+
+		api, cc := mustload.Partial(ClusterFlagValue())
+		n, _, err := node.Retrieve(*cc, args[0])
+		if err != nil {
+			exit.Error(reason.GuestNodeRetrieve, "retrieving node", err)
+		}
+		machineName := config.MachineName(*cc, *n)
+
+		if !machine.IsRunning(api, machineName) {
+			// the partition of a node does not survive its restart
+			startNode(cmd, cc, n)
+		} else if err := node.Heal(api, *cc, *n); err != nil {
+			exit.Error(reason.GuestNodeHeal, "healing node", err)
+		}
+		out.Step(style.Happy, "Node {{.name}} healed", out.V{"name": machineName})
+		if n.ControlPlane && config.IsHA(*cc) {
+			reportControlPlane(api, *cc, "")
+		}
+	},
+}
+
+func init() {
+	nodeHealCmd.Flags().Bool(deleteOnFailure, false, "If set, delete the current cluster if starting the stopped node fails and try again. Defaults to false.")
+	nodeCmd.AddCommand(nodeHealCmd)
+}
@@ -55,30 +55,35 @@ var nodeStartCmd = &cobra.Command{
 			os.Exit(0)
 		}
 
-		register.Reg.SetStep(register.InitialSetup)
-		r, p, m, h, err := node.Provision(cc, n, viper.GetBool(deleteOnFailure))
-		if err != nil {
-			exit.Error(reason.GuestNodeProvision, "provisioning host for node", err)
-		}
+		startNode(cmd, cc, n)
+		out.Step(style.Happy, "Successfully started node {{.name}}!", out.V{"name": machineName})
+	},
+}
 
-		s := node.Starter{
-			Runner:         r,
-			PreExists:      p,
-			MachineAPI:     m,
-			Host:           h,
-			Cfg:            cc,
-			Node:           n,
-			ExistingAddons: cc.Addons,
-		}
+// startNode starts the existing stopped node
+func startNode(cmd *cobra.Command, cc *config.ClusterConfig, n *config.Node) {
+	register.Reg.SetStep(register.InitialSetup)
+	r, p, m, h, err := node.Provision(cc, n, viper.GetBool(deleteOnFailure))
+	if err != nil {
+		exit.Error(reason.GuestNodeProvision, "provisioning host for node", err)
+	}
+
+	s := node.Starter{
+		Runner:         r,
+		PreExists:      p,
+		MachineAPI:     m,
+		Host:           h,
+		Cfg:            cc,
+		Node:           n,
+		ExistingAddons: cc.Addons,
+	}
 
-		if _, err = node.Start(s); err != nil {
-			if _, err := maybeDeleteAndRetry(cmd, *cc, *n, nil, err); err != nil {
-				node.ExitIfFatal(err, false)
-				exit.Error(reason.GuestNodeStart, "failed to start node", err)
-			}
+	if _, err = node.Start(s); err != nil {
+		if _, err := maybeDeleteAndRetry(cmd, *cc, *n, nil, err); err != nil {
+			node.ExitIfFatal(err, false)
+			exit.Error(reason.GuestNodeStart, "failed to start node", err)
 		}
-		out.Step(style.Happy, "Successfully started node {{.name}}!", out.V{"name": machineName})
-	},
+	}
 }
 
 func init() {
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/registrycache"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// registryCacheCmd represents the registry-cache command
+var registryCacheCmd = &cobra.Command{
+	Use:   "registry-cache",
+	Short: "Manage the registry cache shared by the clusters",
+	Long: `Manage the pull-through caching registry enabled with "minikube start --registry-cache".
+It is shared by the clusters, and keeps the pulled images when they are deleted.`,
+}
+
+// registryCacheStatusCmd represents the registry-cache status command
+var registryCacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the registry cache is running",
+	Run: func(_ *cobra.Command, _ []string) {
+		st := registrycache.GetStatus()
+		if st.Host == 0 && len(st.Containers) == 0 {
+			out.Step(style.Stopped, "The registry cache is not running")
+			return
+		}
+		if st.Host != 0 {
+			out.Step(style.Running, "The registry cache is running on the host (pid {{.pid}}), caching images in {{.dir}}", out.V{"pid": st.Host, "dir": registrycache.Dir()})
+		}
+		for ociBin, s := range st.Containers {
+			out.Step(style.Running, "The {{.runtime}} registry cache container is {{.state}}", out.V{"runtime": ociBin, "state": s})
+		}
+	},
+}
+
+// registryCacheStopCmd represents the registry-cache stop command
+var registryCacheStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the registry cache, keeping the cached images",
+	Long:  "Stop the registry cache, keeping the cached images for when it is started again by minikube start.",
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := registrycache.Stop(false); err != nil {
+			exit.Error(reason.HostRegistryCache, "Failed to stop the registry cache", err)
+		}
+		out.Step(style.Stopped, "Stopped the registry cache")
+	},
+}
+
+// registryCacheDeleteCmd represents the registry-cache delete command
+var registryCacheDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Stop the registry cache and delete the cached images",
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := registrycache.Stop(true); err != nil {
+			exit.Error(reason.HostRegistryCache, "Failed to delete the registry cache", err)
+		}
+		out.Step(style.Deleted, "Deleted the registry cache")
+	},
+}
+
+var registryCacheAddresses []string
+
+// registryCacheServeCmd serves the registry cache on the host, run by minikube start
+var registryCacheServeCmd = &cobra.Command{
+	Use:    "serve",
+	Short:  "Serve the registry cache on the host",
+	Hidden: true,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := registrycache.Serve(registryCacheAddresses); err != nil {
+			exit.Error(reason.HostRegistryCache, "Failed to serve the registry cache", err)
+		}
+	},
+}
+
+func init() {
+	registryCacheCmd.AddCommand(registryCacheStatusCmd)
+	registryCacheCmd.AddCommand(registryCacheStopCmd)
+	registryCacheCmd.AddCommand(registryCacheDeleteCmd)
+	registryCacheServeCmd.Flags().StringSliceVar(&registryCacheAddresses, "addresses", nil, "The addresses of the host the clusters reach the registry cache on, besides 127.0.0.1")
+	registryCacheCmd.AddCommand(registryCacheServeCmd)
+}
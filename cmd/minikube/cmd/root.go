@@ -79,6 +79,12 @@ var RootCmd = &cobra.Command{
 		if err != nil {
 			klog.Warningf("failed to log command start to audit: %v", err)
 		}
+		// commands failing with exit.Message or exit.Error never reach PersistentPostRun
+		exit.AddHook(func(code int) {
+			if err := audit.LogCommandExit(auditID, code); err != nil {
+				klog.Warningf("failed to log command exit to audit: %v", err)
+			}
+		})
 		// viper maps $MINIKUBE_ROOTLESS to "rootless" property automatically, but it does not do vice versa,
 		// so we map "rootless" property to $MINIKUBE_ROOTLESS expliclity here.
 		// $MINIKUBE_ROOTLESS is referred by KIC runner, which is decoupled from viper.
@@ -233,6 +239,7 @@ func init() {
 			Message: translate.T("Basic Commands:"),
 			Commands: []*cobra.Command{
 				startCmd,
+				applyCmd,
 				statusCmd,
 				stopCmd,
 				deleteCmd,
@@ -248,6 +255,8 @@ func init() {
 				podmanEnvCmd,
 				cacheCmd,
 				imageCmd,
+				registryCacheCmd,
+				bundleCmd,
 			},
 		},
 		{
@@ -256,6 +265,9 @@ func init() {
 				configCmd.AddonsCmd,
 				configCmd.ConfigCmd,
 				configCmd.ProfileCmd,
+				snapshotCmd,
+				certsCmd,
+				systemCmd,
 				updateContextCmd,
 			},
 		},
@@ -264,6 +276,7 @@ func init() {
 			Commands: []*cobra.Command{
 				serviceCmd,
 				tunnelCmd,
+				cniCmd,
 			},
 		},
 		{
@@ -274,6 +287,7 @@ func init() {
 				kubectlCmd,
 				nodeCmd,
 				cpCmd,
+				syncCmd,
 			},
 		},
 		{
@@ -283,6 +297,7 @@ func init() {
 				sshHostCmd,
 				ipCmd,
 				logsCmd,
+				auditCmd,
 				updateCheckCmd,
 				versionCmd,
 				optionsCmd,
@@ -330,6 +345,8 @@ func setupViper() {
 	viper.SetDefault(config.WantNoneDriverWarning, true)
 	viper.SetDefault(config.WantVirtualBoxDriverWarning, true)
 	viper.SetDefault(config.MaxAuditEntries, 1000)
+	viper.SetDefault(config.MaxAuditSize, "1mb")
+	viper.SetDefault(config.MaxAuditArchives, 5)
 	viper.SetDefault(config.SkipAuditFlag, false)
 }
 
@@ -58,6 +58,7 @@ var (
 	serviceURLTemplate *template.Template
 	wait               int
 	interval           int
+	serviceNativeSSH   bool
 )
 
 // serviceCmd represents the service command
@@ -183,6 +184,7 @@ func init() {
 	serviceCmd.Flags().BoolVar(&https, "https", false, "Open the service URL with https instead of http (defaults to \"false\")")
 	serviceCmd.Flags().IntVar(&wait, "wait", service.DefaultWait, "Amount of time to wait for a service in seconds")
 	serviceCmd.Flags().IntVar(&interval, "interval", service.DefaultInterval, "The initial time interval for each check that wait performs in seconds")
+	serviceCmd.Flags().BoolVar(&serviceNativeSSH, "native-ssh", false, "Use native Golang SSH client to forward the service ports of the docker and podman drivers, instead of the command line 'ssh' command.")
 
 	serviceCmd.PersistentFlags().StringVar(&serviceURLFormat, "format", defaultServiceFormatTemplate, "Format to output service URL in. This format will be applied to each url individually and they will be printed one at a time.")
 }
@@ -206,6 +208,7 @@ func startKicServiceTunnel(services service.URLs, configName, driverName string)
 		sshKey := filepath.Join(localpath.MiniPath(), "machines", configName, "id_rsa")
 
 		serviceTunnel := kic.NewServiceTunnel(sshPort, sshKey, clientset.CoreV1(), serviceURLMode)
+		serviceTunnel.NativeSSH = serviceNativeSSH
 		urls, err := serviceTunnel.Start(svc.Name, namespace)
 
 		if err != nil {
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/snapshot"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var snapshotRestoreProfile string
+
+// snapshotCmd represents the set of snapshot subcommands
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save, restore, list or delete snapshots of a stopped cluster",
+	Long:  "Snapshots capture the configuration, certificates and node disks of a stopped cluster, so it can be restored into the same or a new profile.",
+	Run: func(_ *cobra.Command, _ []string) {
+		exit.Message(reason.Usage, "Usage: minikube snapshot [save|restore|list|delete]")
+	},
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:     "save SNAPSHOT",
+	Short:   "Save a snapshot of a stopped cluster",
+	Long:    "Save the configuration, certificates and node disks of a stopped cluster as a snapshot.",
+	Example: "minikube snapshot save seeded -p minikube",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "Usage: minikube snapshot save SNAPSHOT")
+		}
+		cname := ClusterFlagValue()
+		api, cc := mustload.Stopped(cname)
+		defer api.Close()
+
+		out.Step(style.Waiting, "Saving snapshot {{.snapshot}} of profile {{.profile}} ...", out.V{"snapshot": args[0], "profile": cname})
+		if _, err := snapshot.Save(cc, args[0]); err != nil {
+			exit.Error(reason.HostSnapshotSave, "Failed to save snapshot", err)
+		}
+		out.Step(style.Ready, "Saved snapshot {{.snapshot}}", out.V{"snapshot": args[0]})
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:     "restore SNAPSHOT",
+	Short:   "Restore a snapshot into a stopped or new profile",
+	Long:    "Restore a snapshot into the profile it was saved from, or into a new profile with --to. The profile must be stopped, run 'minikube start' afterwards.",
+	Example: "minikube snapshot restore seeded --to seeded-copy",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "Usage: minikube snapshot restore SNAPSHOT [--to PROFILE]")
+		}
+		m, err := snapshot.Load(args[0])
+		if err != nil {
+			exit.Error(reason.HostSnapshotRestore, "Failed to load snapshot", err)
+		}
+		target := snapshotRestoreProfile
+		if target == "" {
+			target = m.Profile
+		}
+		if !config.ProfileNameValid(target) {
+			exit.Message(reason.Usage, "Profile name {{.profile}} is not valid", out.V{"profile": target})
+		}
+		if config.ProfileExists(target) {
+			api, _ := mustload.Stopped(target)
+			api.Close()
+		}
+
+		out.Step(style.Waiting, "Restoring snapshot {{.snapshot}} into profile {{.profile}} ...", out.V{"snapshot": args[0], "profile": target})
+		if _, err := snapshot.Restore(args[0], target); err != nil {
+			exit.Error(reason.HostSnapshotRestore, "Failed to restore snapshot", err)
+		}
+		out.Step(style.Ready, "Restored snapshot {{.snapshot}}, run \"minikube start -p {{.profile}}\" to start the cluster", out.V{"snapshot": args[0], "profile": target})
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots",
+	Long:  "List all saved cluster snapshots.",
+	Run: func(_ *cobra.Command, _ []string) {
+		ms, err := snapshot.List()
+		if err != nil {
+			exit.Error(reason.HostSnapshotList, "Failed to list snapshots", err)
+		}
+		if len(ms) == 0 {
+			out.Step(style.Empty, "No snapshots found. To save one, run: \"minikube snapshot save SNAPSHOT\"")
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Snapshot", "Profile", "Driver", "Runtime", "Version", "Addons", "Created"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		for _, m := range ms {
+			table.Append([]string{m.Name, m.Profile, m.Driver, m.ContainerRuntime, m.KubernetesVersion, strings.Join(m.Addons, ","), m.CreationTime.Format("2006-01-02 15:04:05")})
+		}
+		table.Render()
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete SNAPSHOT",
+	Short: "Delete a snapshot",
+	Long:  "Delete a snapshot and the images saved with it.",
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "Usage: minikube snapshot delete SNAPSHOT")
+		}
+		if err := snapshot.Delete(args[0]); err != nil {
+			exit.Error(reason.HostSnapshotDelete, "Failed to delete snapshot", err)
+		}
+		out.Step(style.Deleted, "Deleted snapshot {{.snapshot}}", out.V{"snapshot": args[0]})
+	},
+}
+
+func init() {
+	snapshotRestoreCmd.Flags().StringVar(&snapshotRestoreProfile, "to", "", "The profile to restore the snapshot into. Defaults to the profile the snapshot was saved from.")
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+}
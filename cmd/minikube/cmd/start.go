@@ -28,6 +28,7 @@ import (
 	"os/user"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -47,6 +48,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"k8s.io/minikube/pkg/minikube/cni"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/firewall"
 	netutil "k8s.io/minikube/pkg/network"
@@ -66,10 +68,12 @@ import (
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/logs"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/node"
 	"k8s.io/minikube/pkg/minikube/notify"
+	"k8s.io/minikube/pkg/minikube/oidc"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/pause"
@@ -78,6 +82,7 @@ import (
 	pkgtrace "k8s.io/minikube/pkg/trace"
 
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/minikube/registrycache"
 	"k8s.io/minikube/pkg/minikube/translate"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/version"
@@ -152,10 +157,13 @@ func platform() string {
 
 // runStart handles the executes the flow of "minikube start"
 func runStart(cmd *cobra.Command, _ []string) {
+	if path := viper.GetString(clusterSpecFile); path != "" {
+		applySpecFlags(cmd, loadClusterSpec(path))
+	}
 	register.SetEventLogPath(localpath.EventLog(ClusterFlagValue()))
 	ctx := context.Background()
 	out.SetJSON(outputFormat == "json")
-	if err := pkgtrace.Initialize(viper.GetString(trace)); err != nil {
+	if err := pkgtrace.Initialize(viper.GetString(trace), ClusterFlagValue()); err != nil {
 		exit.Message(reason.Usage, "error initializing tracing: {{.Error}}", out.V{"Error": err.Error()})
 	}
 	defer pkgtrace.Cleanup()
@@ -337,7 +345,7 @@ func provisionWithDriver(cmd *cobra.Command, ds registry.DriverState, existing *
 	if err != nil {
 		return node.Starter{}, errors.Wrap(err, "Failed to generate cluster config")
 	}
-	klog.Infof("cluster config:\n%+v", cc)
+	klog.Infof("cluster config:\n%+v", logs.RedactConfig(cc))
 
 	if firewall.IsBootpdBlocked(cc) {
 		if err := firewall.UnblockBootpd(); err != nil {
@@ -1315,6 +1323,24 @@ func validateFlags(cmd *cobra.Command, drvName string) { //nolint:gocyclo
 		}
 	}
 
+	if cmd.Flags().Changed(registryCache) {
+		if err := validateRegistryCache(viper.GetString(registryCache), drvName); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(oidcIssuerURL) {
+		validateOIDC()
+	}
+
+	if cmd.Flags().Changed(apiServerAuditPolicy) || cmd.Flags().Changed(apiServerAuditWebhook) {
+		validateAudit()
+	}
+
+	if cmd.Flags().Changed(podNetworkCIDR) || cmd.Flags().Changed(serviceCIDR) || cmd.Flags().Changed(ipFamily) || cmd.Flags().Changed(cniMTU) {
+		validateNetworkOptions(cmd)
+	}
+
 	if cmd.Flags().Changed(autoPauseInterval) {
 		if err := validateAutoPauseInterval(viper.GetDuration(autoPauseInterval)); err != nil {
 			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
@@ -1365,6 +1391,77 @@ func validateFlags(cmd *cobra.Command, drvName string) { //nolint:gocyclo
 	validateInsecureRegistry()
 }
 
+// validateOIDC validates the OpenID Connect authentication of the flags, warning if the issuer cannot be reached
+func validateOIDC() {
+	o := getOIDCConfig()
+	if o == nil {
+		return
+	}
+	if err := oidc.Validate(o); err != nil {
+		exit.Message(reason.Usage, "Invalid OIDC settings: {{.err}}", out.V{"err": err})
+	}
+	for param := range config.ExtraOptions.AsMap().Get(bsutil.Apiserver) {
+		if strings.HasPrefix(param, "oidc-") || param == "authentication-config" {
+			exit.Message(reason.Usage, "--extra-config=apiserver.{{.parameter_name}} conflicts with --{{.flag}}", out.V{"parameter_name": param, "flag": oidcIssuerURL})
+		}
+	}
+	// the apiserver may reach issuers the host cannot, and the other way around
+	if _, err := oidc.Discover(o.IssuerURL, o.CAFile); err != nil {
+		out.WarningT("Unable to discover the OIDC issuer {{.url}} from the host: {{.err}}", out.V{"url": o.IssuerURL, "err": err})
+	}
+}
+
+// validateAudit validates the audit policy and webhook config of the flags
+func validateAudit() {
+	if p := auditPolicyPath(); p != "" {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			exit.Message(reason.Usage, "Unable to read the audit policy: {{.err}}", out.V{"err": err})
+		}
+		if err := bsutil.ValidateAuditPolicy(data); err != nil {
+			exit.Message(reason.Usage, "Invalid audit policy {{.file}}: {{.err}}", out.V{"file": p, "err": err})
+		}
+	}
+	if w := viper.GetString(apiServerAuditWebhook); w != "" {
+		if _, err := os.Stat(w); err != nil {
+			exit.Message(reason.Usage, "Unable to read the audit webhook config: {{.err}}", out.V{"err": err})
+		}
+	}
+	for param := range config.ExtraOptions.AsMap().Get(bsutil.Apiserver) {
+		if strings.HasPrefix(param, "audit-") {
+			exit.Message(reason.Usage, "--extra-config=apiserver.{{.parameter_name}} conflicts with --{{.flag}}", out.V{"parameter_name": param, "flag": apiServerAuditPolicy})
+		}
+	}
+}
+
+// validateNetworkOptions validates the pod and service CIDRs, IP family and MTU of the flags
+func validateNetworkOptions(cmd *cobra.Command) {
+	family := viper.GetString(ipFamily)
+	if !slices.Contains(cni.IPFamilies, family) {
+		exit.Message(reason.Usage, "Invalid --{{.flag}} {{.family}}, valid options: {{.families}}", out.V{"flag": ipFamily, "family": family, "families": strings.Join(cni.IPFamilies, ", ")})
+	}
+	// the IP family of an existing cluster is kept
+	if existing, err := config.Load(ClusterFlagValue()); err == nil && !cmd.Flags().Changed(ipFamily) {
+		family = cni.IPFamily(*existing)
+	}
+	if cidr := viper.GetString(podNetworkCIDR); cidr != "" {
+		if err := cni.ValidatePodCIDR(cidr, family); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+	if cmd.Flags().Changed(serviceCIDR) || cmd.Flags().Changed(ipFamily) {
+		if err := cni.ValidateServiceCIDR(getServiceCIDR(cmd), family); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+	if err := cni.ValidateMTU(viper.GetInt(cniMTU)); err != nil {
+		exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+	}
+	if config.ExtraOptions.Get("pod-network-cidr", bsutil.Kubeadm) != "" {
+		exit.Message(reason.Usage, "--extra-config=kubeadm.pod-network-cidr conflicts with --{{.flag}}", out.V{"flag": podNetworkCIDR})
+	}
+}
+
 // validatePorts validates that the --ports are not below 1024 for the host and not outside range
 func validatePorts(ports []string) error {
 	var exposedPorts, hostPorts, portSpecs []string
@@ -1474,6 +1571,21 @@ func validateGPUs(value, drvName, rtime string) error {
 	return errors.Errorf("The gpus flag can only be used with the docker driver and docker container-runtime")
 }
 
+// validateRegistryCache validates that the registry cache can run in the given mode with the driver
+func validateRegistryCache(value, drvName string) error {
+	mode := registrycache.Resolve(value, drvName)
+	if mode == "" {
+		return nil
+	}
+	if !slices.Contains(registrycache.Modes, mode) {
+		return errors.Errorf("The registry-cache flag must be passed one of: %v", registrycache.Modes)
+	}
+	if mode == registrycache.Container && !driver.IsKIC(drvName) {
+		return errors.Errorf("The registry cache can only run in a container with the docker and podman drivers")
+	}
+	return nil
+}
+
 func validateGPUsArch() error {
 	switch runtime.GOARCH {
 	case "amd64", "arm64", "ppc64le":
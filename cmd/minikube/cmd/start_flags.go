@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -43,6 +44,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/proxy"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/registrycache"
 	"k8s.io/minikube/pkg/minikube/style"
 	pkgutil "k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/version"
@@ -63,6 +65,10 @@ const (
 	networkPlugin           = "network-plugin"
 	enableDefaultCNI        = "enable-default-cni"
 	cniFlag                 = "cni"
+	podNetworkCIDR          = "pod-network-cidr"
+	cniMTU                  = "cni-mtu"
+	ipFamily                = "ip-family"
+	networkPolicy           = "network-policy"
 	hypervVirtualSwitch     = "hyperv-virtual-switch"
 	hypervUseExternalSwitch = "hyperv-use-external-switch"
 	hypervExternalAdapter   = "hyperv-external-adapter"
@@ -143,9 +149,26 @@ const (
 	socketVMnetPath         = "socket-vmnet-path"
 	staticIP                = "static-ip"
 	gpus                    = "gpus"
+	registryCache           = "registry-cache"
 	autoPauseInterval       = "auto-pause-interval"
+	clusterSpecFile         = "config"
+	oidcIssuerURL           = "oidc-issuer-url"
+	oidcClientID            = "oidc-client-id"
+	oidcClientSecret        = "oidc-client-secret"
+	oidcCAFile              = "oidc-ca-file"
+	oidcUsernameClaim       = "oidc-username-claim"
+	oidcUsernamePrefix      = "oidc-username-prefix"
+	oidcGroupsClaim         = "oidc-groups-claim"
+	oidcGroupsPrefix        = "oidc-groups-prefix"
+	oidcExtraScope          = "oidc-extra-scope"
+	apiServerAuditPolicy    = "apiserver-audit-policy"
+	apiServerAuditWebhook   = "apiserver-audit-webhook"
+	defaultAuditPolicy      = "default"
 )
 
+// oidcFlags are the flags of the OpenID Connect authentication of the apiserver
+var oidcFlags = []string{oidcIssuerURL, oidcClientID, oidcClientSecret, oidcCAFile, oidcUsernameClaim, oidcUsernamePrefix, oidcGroupsClaim, oidcGroupsPrefix, oidcExtraScope}
+
 var (
 	outputFormat string
 )
@@ -160,6 +183,7 @@ func initMinikubeFlags() {
 	startCmd.Flags().Bool(force, false, "Force minikube to perform possibly dangerous operations")
 	startCmd.Flags().Bool(interactive, true, "Allow user prompts for more information")
 	startCmd.Flags().Bool(dryRun, false, "dry-run mode. Validates configuration, but does not mutate system state")
+	startCmd.Flags().String(clusterSpecFile, "", "Path to a cluster spec file (see 'minikube profile export'). Flags given on the command line take precedence over the file.")
 
 	startCmd.Flags().String(cpus, "2", fmt.Sprintf("Number of CPUs allocated to Kubernetes. Use %q to use the maximum number of CPUs. Use %q to not specify a limit (Docker/Podman only)", constants.MaxResources, constants.NoLimit))
 	startCmd.Flags().String(memory, "", fmt.Sprintf("Amount of RAM to allocate to Kubernetes (format: <number>[<unit>], where unit = b, k, m or g). Use %q to use the maximum amount of memory. Use %q to not specify a limit (Docker/Podman only)", constants.MaxResources, constants.NoLimit))
@@ -186,6 +210,10 @@ func initMinikubeFlags() {
 	startCmd.Flags().String(networkPlugin, "", "DEPRECATED: Replaced by --cni")
 	startCmd.Flags().Bool(enableDefaultCNI, false, "DEPRECATED: Replaced by --cni=bridge")
 	startCmd.Flags().String(cniFlag, "", "CNI plug-in to use. Valid options: auto, bridge, calico, cilium, flannel, kindnet, or path to a CNI manifest (default: auto)")
+	startCmd.Flags().String(podNetworkCIDR, "", "The CIDR of the pods, an IPv4 and an IPv6 CIDR separated by a comma for --ip-family=dual (default: 10.244.0.0/16, and fd00:10:244::/56 for IPv6)")
+	startCmd.Flags().Int(cniMTU, 0, "The MTU of the pod interfaces, for the bridge, flannel, calico and cilium CNIs (default: chosen by the CNI)")
+	startCmd.Flags().String(ipFamily, cni.IPv4, "The IP family of the pods and services. Valid options: ipv4, dual")
+	startCmd.Flags().Bool(networkPolicy, true, "Enforce the network policies with the CNIs supporting them. Calico always enforces them")
 	startCmd.Flags().StringSlice(waitComponents, kverify.DefaultWaitList, fmt.Sprintf("comma separated list of Kubernetes components to verify and wait for after starting a cluster. defaults to %q, available options: %q . other acceptable values are 'all' or 'none', 'true' and 'false'", strings.Join(kverify.DefaultWaitList, ","), strings.Join(kverify.AllComponentsList, ",")))
 	startCmd.Flags().Duration(waitTimeout, 6*time.Minute, "max time to wait per Kubernetes or host to be healthy.")
 	startCmd.Flags().Bool(nativeSSH, true, "Use native Golang SSH client (default true). Set to 'false' to use the command line 'ssh' command when accessing the docker machine. Useful for the machine drivers when they will not start with 'Waiting for SSH'.")
@@ -199,7 +227,7 @@ func initMinikubeFlags() {
 	startCmd.Flags().Bool(forceSystemd, false, "If set, force the container runtime to use systemd as cgroup manager. Defaults to false.")
 	startCmd.Flags().String(network, "", "network to run minikube with. Now it is used by docker/podman and KVM drivers. If left empty, minikube will create a new network.")
 	startCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Format to print stdout in. Options include: [text,json]")
-	startCmd.Flags().String(trace, "", "Send trace events. Options include: [gcp]")
+	startCmd.Flags().String(trace, "", "Send trace events. Options include: [gcp, otlp-http, otlp-grpc, file]. The OTLP exporters are configured with the OTEL_EXPORTER_OTLP_* environment variables, the file exporter writes to the profile directory.")
 	startCmd.Flags().Int(extraDisks, 0, "Number of extra disks created and attached to the minikube VM (currently only implemented for hyperkit, kvm2, and qemu2 drivers)")
 	startCmd.Flags().Duration(certExpiration, constants.DefaultCertExpiration, "Duration until minikube certificate expiration, defaults to three years (26280h).")
 	startCmd.Flags().String(binaryMirror, "", "Location to fetch kubectl, kubelet, & kubeadm binaries from.")
@@ -225,6 +253,21 @@ func initKubernetesFlags() {
 	startCmd.Flags().String(apiServerName, constants.APIServerName, "The authoritative apiserver hostname for apiserver certificates and connectivity. This can be used if you want to make the apiserver available from outside the machine")
 	startCmd.Flags().StringSliceVar(&apiServerNames, "apiserver-names", nil, "A set of apiserver names which are used in the generated certificate for kubernetes.  This can be used if you want to make the apiserver available from outside the machine")
 	startCmd.Flags().IPSliceVar(&apiServerIPs, "apiserver-ips", nil, "A set of apiserver IP Addresses which are used in the generated certificate for kubernetes.  This can be used if you want to make the apiserver available from outside the machine")
+
+	// oidc
+	startCmd.Flags().String(oidcIssuerURL, "", "The https URL of an OpenID Connect issuer authenticating the users of the apiserver, a kubeconfig context logging in with kubelogin is added for them")
+	startCmd.Flags().String(oidcClientID, "", "The client ID of the OpenID Connect issuer, which must be the audience of the ID tokens")
+	startCmd.Flags().String(oidcClientSecret, "", "The client secret of the OpenID Connect issuer, if the client is confidential")
+	startCmd.Flags().String(oidcCAFile, "", "The CA certificate of the OpenID Connect issuer, if not trusted by the system")
+	startCmd.Flags().String(oidcUsernameClaim, "", "The claim of the ID tokens used as the user name (defaults to sub)")
+	startCmd.Flags().String(oidcUsernamePrefix, "", "The prefix of the user names of the OpenID Connect issuer (defaults to none)")
+	startCmd.Flags().String(oidcGroupsClaim, "", "The claim of the ID tokens used as the groups of the user")
+	startCmd.Flags().String(oidcGroupsPrefix, "", "The prefix of the groups of the OpenID Connect issuer")
+	startCmd.Flags().StringSlice(oidcExtraScope, nil, "Extra scopes requested by kubelogin when logging in, such as email or groups")
+
+	// audit
+	startCmd.Flags().String(apiServerAuditPolicy, "", "The audit policy of the apiserver, logging the requests to it as JSON events shown by 'minikube logs --apiserver-audit'. Set it to \"default\" for the built-in policy, or to \"\" to stop the logging")
+	startCmd.Flags().String(apiServerAuditWebhook, "", "The kubeconfig of a webhook also receiving the audit events of the apiserver, with the built-in policy unless --apiserver-audit-policy is set")
 }
 
 // initDriverFlags inits the commandline flags for vm drivers
@@ -273,10 +316,12 @@ func initDriverFlags() {
 // initNetworkingFlags inits the commandline flags for connectivity related flags for start
 func initNetworkingFlags() {
 	startCmd.Flags().StringSliceVar(&insecureRegistry, "insecure-registry", nil, "Insecure Docker registries to pass to the Docker daemon.  The default service CIDR range will automatically be added.")
-	startCmd.Flags().StringSliceVar(&registryMirror, "registry-mirror", nil, "Registry mirrors to pass to the Docker daemon")
+	startCmd.Flags().StringSliceVar(&registryMirror, "registry-mirror", nil, "Registry mirrors of Docker Hub for the container runtime")
+	startCmd.Flags().String(registryCache, "", fmt.Sprintf("Pull the images through a caching registry shared by the clusters, running on the host or in a container next to them, one of: %v (defaults to container for the docker and podman drivers, else host)", registrycache.Modes))
+	startCmd.Flags().Lookup(registryCache).NoOptDefVal = registrycache.Auto
 	startCmd.Flags().String(imageRepository, "", "Alternative image repository to pull docker images from. This can be used when you have limited access to gcr.io. Set it to \"auto\" to let minikube decide one for you. For Chinese mainland users, you may use local gcr.io mirrors such as registry.cn-hangzhou.aliyuncs.com/google_containers")
 	startCmd.Flags().String(imageMirrorCountry, "", "Country code of the image mirror to be used. Leave empty to use the global one. For Chinese mainland users, set it to cn.")
-	startCmd.Flags().String(serviceCIDR, constants.DefaultServiceCIDR, "The CIDR to be used for service cluster IPs.")
+	startCmd.Flags().String(serviceCIDR, constants.DefaultServiceCIDR, "The CIDR to be used for service cluster IPs, an IPv4 and an IPv6 CIDR separated by a comma for --ip-family=dual, which adds fd00:10:96::/112 to the default")
 	startCmd.Flags().StringArrayVar(&config.DockerEnv, "docker-env", nil, "Environment variables to pass to the Docker daemon. (format: key=value)")
 	startCmd.Flags().StringArrayVar(&config.DockerOpt, "docker-opt", nil, "Specify arbitrary flags to pass to the Docker daemon. (format: key=value)")
 
@@ -303,9 +348,11 @@ func generateClusterConfig(cmd *cobra.Command, existing *config.ClusterConfig, k
 		cc = updateExistingConfigFromFlags(cmd, existing)
 
 		// identify appropriate cni then configure cruntime accordingly
-		if _, err := cni.New(&cc); err != nil {
+		cnm, err := cni.New(&cc)
+		if err != nil {
 			return cc, config.Node{}, errors.Wrap(err, "cni")
 		}
+		warnNetworkOptions(cmd, cc, cnm)
 	} else {
 		klog.Info("no existing cluster config was found, will generate one from the flags ")
 		cc = generateNewConfigFromFlags(cmd, k8sVersion, rtime, drvName)
@@ -319,6 +366,7 @@ func generateClusterConfig(cmd *cobra.Command, existing *config.ClusterConfig, k
 			klog.Infof("Found %q CNI - setting NetworkPlugin=cni", cnm)
 			cc.KubernetesConfig.NetworkPlugin = "cni"
 		}
+		warnNetworkOptions(cmd, cc, cnm)
 	}
 
 	r, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime})
@@ -467,6 +515,50 @@ func getCNIConfig(cmd *cobra.Command) string {
 	return chosenCNI
 }
 
+// getServiceCIDR returns the CIDR of the services, adding the default IPv6 CIDR to the default one of the dual-stack clusters
+func getServiceCIDR(cmd *cobra.Command) string {
+	if viper.GetString(ipFamily) == cni.DualStack && !cmd.Flags().Changed(serviceCIDR) {
+		return constants.DefaultServiceCIDR + "," + constants.DefaultServiceCIDRv6
+	}
+	return viper.GetString(serviceCIDR)
+}
+
+// updateNetworkOptionsFromFlags updates the MTU and the network policy enforcement with the changed flags,
+// the pod CIDR and IP family being set when the cluster is created
+func updateNetworkOptionsFromFlags(cmd *cobra.Command, k *config.KubernetesConfig) {
+	if cmd.Flags().Changed(podNetworkCIDR) && viper.GetString(podNetworkCIDR) != cni.PodCIDR(config.ClusterConfig{KubernetesConfig: *k}) {
+		out.WarningT("You cannot change the pod CIDR of an existing minikube cluster. Please first delete the cluster.")
+	}
+	if cmd.Flags().Changed(ipFamily) && viper.GetString(ipFamily) != cni.IPFamily(config.ClusterConfig{KubernetesConfig: *k}) {
+		out.WarningT("You cannot change the IP family of an existing minikube cluster. Please first delete the cluster.")
+	}
+	updateIntFromFlag(cmd, &k.CNIMTU, cniMTU)
+	if cmd.Flags().Changed(networkPolicy) {
+		k.DisableNetworkPolicy = !viper.GetBool(networkPolicy)
+	}
+}
+
+// warnNetworkOptions warns about the changed network flags the CNI of the cluster ignores
+func warnNetworkOptions(cmd *cobra.Command, cc config.ClusterConfig, cnm cni.Manager) {
+	if _, ok := cnm.(cni.Custom); ok {
+		// the options of a custom manifest are unknown
+		return
+	}
+	if cmd.Flags().Changed(cniMTU) && cc.KubernetesConfig.CNIMTU != 0 && !cni.SupportsMTU(cnm) {
+		out.WarningT("{{.cni}} does not support --{{.flag}}, the MTU of the node interface will be used", out.V{"cni": cnm, "flag": cniMTU})
+	}
+	if !cmd.Flags().Changed(networkPolicy) {
+		return
+	}
+	enforce := !cc.KubernetesConfig.DisableNetworkPolicy
+	if enforce && !cni.EnforcesNetworkPolicy(cnm) {
+		out.WarningT("{{.cni}} does not enforce the network policies, use --cni=calico or --cni=cilium to enforce them", out.V{"cni": cnm})
+	}
+	if !enforce && cni.EnforcesNetworkPolicy(cnm) {
+		out.WarningT("{{.cni}} always enforces the network policies, use --cni=cilium to choose", out.V{"cni": cnm})
+	}
+}
+
 func getNetwork(driverName string) string {
 	n := viper.GetString(network)
 	if !driver.IsQEMU(driverName) {
@@ -544,6 +636,7 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 		DockerOpt:               config.DockerOpt,
 		InsecureRegistry:        insecureRegistry,
 		RegistryMirror:          registryMirror,
+		RegistryCache:           registrycache.Resolve(viper.GetString(registryCache), drvName),
 		HostOnlyCIDR:            viper.GetString(hostOnlyCIDR),
 		HypervVirtualSwitch:     viper.GetString(hypervVirtualSwitch),
 		HypervUseExternalSwitch: viper.GetBool(hypervUseExternalSwitch),
@@ -598,11 +691,17 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 			ContainerRuntime:       rtime,
 			CRISocket:              viper.GetString(criSocket),
 			NetworkPlugin:          chosenNetworkPlugin,
-			ServiceCIDR:            viper.GetString(serviceCIDR),
+			ServiceCIDR:            getServiceCIDR(cmd),
+			PodCIDR:                viper.GetString(podNetworkCIDR),
+			IPFamily:               viper.GetString(ipFamily),
 			ImageRepository:        getRepository(cmd, k8sVersion),
 			ExtraOptions:           getExtraOptions(),
 			ShouldLoadCachedImages: viper.GetBool(cacheImages),
 			CNI:                    getCNIConfig(cmd),
+			CNIMTU:                 viper.GetInt(cniMTU),
+			DisableNetworkPolicy:   !viper.GetBool(networkPolicy),
+			OIDC:                   getOIDCConfig(),
+			Audit:                  getAuditConfig(),
 		},
 		MultiNodeRequested: viper.GetInt(nodes) > 1 || viper.GetBool(ha),
 		GPUs:               viper.GetString(gpus),
@@ -832,6 +931,9 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	updateStringFromFlag(cmd, &cc.MountType, mountTypeFlag)
 	updateStringFromFlag(cmd, &cc.MountUID, mountUID)
 	updateStringFromFlag(cmd, &cc.BinaryMirror, binaryMirror)
+	if cmd.Flags().Changed(registryCache) {
+		cc.RegistryCache = registrycache.Resolve(viper.GetString(registryCache), cc.Driver)
+	}
 	updateBoolFromFlag(cmd, &cc.DisableOptimizations, disableOptimizations)
 	updateStringFromFlag(cmd, &cc.CustomQemuFirmwarePath, qemuFirmwarePath)
 	updateStringFromFlag(cmd, &cc.SocketVMnetClientPath, socketVMnetClientPath)
@@ -856,6 +958,10 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	if cmd.Flags().Changed(cniFlag) || cmd.Flags().Changed(enableDefaultCNI) {
 		cc.KubernetesConfig.CNI = getCNIConfig(cmd)
 	}
+	updateNetworkOptionsFromFlags(cmd, &cc.KubernetesConfig)
+
+	updateOIDCFromFlags(cmd, &cc.KubernetesConfig)
+	updateAuditFromFlags(cmd, &cc.KubernetesConfig)
 
 	if cmd.Flags().Changed(waitComponents) {
 		cc.VerifyComponents = interpretWaitFlag(*cmd)
@@ -880,6 +986,116 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	return cc
 }
 
+// getOIDCConfig returns the OpenID Connect authentication of the flags, none without an issuer
+func getOIDCConfig() *config.OIDCConfig {
+	if viper.GetString(oidcIssuerURL) == "" {
+		return nil
+	}
+	return &config.OIDCConfig{
+		IssuerURL:      viper.GetString(oidcIssuerURL),
+		ClientID:       viper.GetString(oidcClientID),
+		ClientSecret:   viper.GetString(oidcClientSecret),
+		CAFile:         absPath(viper.GetString(oidcCAFile)),
+		UsernameClaim:  viper.GetString(oidcUsernameClaim),
+		UsernamePrefix: viper.GetString(oidcUsernamePrefix),
+		GroupsClaim:    viper.GetString(oidcGroupsClaim),
+		GroupsPrefix:   viper.GetString(oidcGroupsPrefix),
+		ExtraScopes:    viper.GetStringSlice(oidcExtraScope),
+	}
+}
+
+// updateOIDCFromFlags updates the OpenID Connect authentication with the changed flags, removing it with an empty issuer
+func updateOIDCFromFlags(cmd *cobra.Command, k *config.KubernetesConfig) {
+	changed := false
+	for _, f := range oidcFlags {
+		changed = changed || cmd.Flags().Changed(f)
+	}
+	if !changed {
+		return
+	}
+
+	oidc := config.OIDCConfig{}
+	if k.OIDC != nil {
+		oidc = *k.OIDC
+	}
+	updateStringFromFlag(cmd, &oidc.IssuerURL, oidcIssuerURL)
+	updateStringFromFlag(cmd, &oidc.ClientID, oidcClientID)
+	updateStringFromFlag(cmd, &oidc.ClientSecret, oidcClientSecret)
+	if cmd.Flags().Changed(oidcCAFile) {
+		oidc.CAFile = absPath(viper.GetString(oidcCAFile))
+	}
+	updateStringFromFlag(cmd, &oidc.UsernameClaim, oidcUsernameClaim)
+	updateStringFromFlag(cmd, &oidc.UsernamePrefix, oidcUsernamePrefix)
+	updateStringFromFlag(cmd, &oidc.GroupsClaim, oidcGroupsClaim)
+	updateStringFromFlag(cmd, &oidc.GroupsPrefix, oidcGroupsPrefix)
+	updateStringSliceFromFlag(cmd, &oidc.ExtraScopes, oidcExtraScope)
+
+	if oidc.IssuerURL == "" {
+		k.OIDC = nil
+		return
+	}
+	k.OIDC = &oidc
+}
+
+// getAuditConfig returns the audit logging of the apiserver of the flags, none without a policy or a webhook
+func getAuditConfig() *config.AuditConfig {
+	if viper.GetString(apiServerAuditPolicy) == "" && viper.GetString(apiServerAuditWebhook) == "" {
+		return nil
+	}
+	return &config.AuditConfig{
+		PolicyFile:        auditPolicyPath(),
+		WebhookConfigFile: absPath(viper.GetString(apiServerAuditWebhook)),
+	}
+}
+
+// updateAuditFromFlags updates the audit logging with the changed flags, removing it with an empty policy
+func updateAuditFromFlags(cmd *cobra.Command, k *config.KubernetesConfig) {
+	if !cmd.Flags().Changed(apiServerAuditPolicy) && !cmd.Flags().Changed(apiServerAuditWebhook) {
+		return
+	}
+
+	audit := config.AuditConfig{}
+	enabled := k.Audit != nil
+	if enabled {
+		audit = *k.Audit
+	}
+	if cmd.Flags().Changed(apiServerAuditWebhook) {
+		audit.WebhookConfigFile = absPath(viper.GetString(apiServerAuditWebhook))
+		enabled = enabled || audit.WebhookConfigFile != ""
+	}
+	if cmd.Flags().Changed(apiServerAuditPolicy) {
+		audit.PolicyFile = auditPolicyPath()
+		enabled = viper.GetString(apiServerAuditPolicy) != ""
+	}
+
+	if !enabled {
+		k.Audit = nil
+		return
+	}
+	k.Audit = &audit
+}
+
+// auditPolicyPath returns the path of the audit policy of the flag, empty for the built-in policy
+func auditPolicyPath() string {
+	if p := viper.GetString(apiServerAuditPolicy); p != defaultAuditPolicy {
+		return absPath(p)
+	}
+	return ""
+}
+
+// absPath returns the absolute path of a file given relative to the working directory, as it is read by later commands
+func absPath(p string) string {
+	if p == "" {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		klog.Warningf("unable to get the absolute path of %s: %v", p, err)
+		return p
+	}
+	return abs
+}
+
 // updateStringFromFlag will update the existing string from the flag.
 func updateStringFromFlag(cmd *cobra.Command, v *string, key string) {
 	if cmd.Flags().Changed(key) {
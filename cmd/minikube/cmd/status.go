@@ -36,6 +36,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	kubevip "k8s.io/minikube/pkg/minikube/cluster/ha/kube-vip"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/driver"
@@ -149,8 +150,10 @@ type ClusterState struct {
 
 	BinaryVersion string
 	TimeToStop    string `json:",omitempty"`
-	Components    map[string]BaseState
-	Nodes         []NodeState
+	// Leader is the control-plane node serving the VIP of an HA cluster
+	Leader     string `json:",omitempty"`
+	Components map[string]BaseState
+	Nodes      []NodeState
 }
 
 // NodeState holds a node state representation
@@ -276,7 +279,7 @@ func writeStatusesAtInterval(duration time.Duration, api libmachine.API, cc *con
 		case "json":
 			// Layout is currently only supported for JSON mode
 			if layout == "cluster" {
-				if err := clusterStatusJSON(statuses, os.Stdout); err != nil {
+				if err := clusterStatusJSON(statuses, os.Stdout, api, cc); err != nil {
 					exit.Error(reason.InternalStatusJSON, "status json failure", err)
 				}
 			} else {
@@ -655,8 +658,11 @@ func statusCode(st string) int {
 	return Unknown
 }
 
-func clusterStatusJSON(statuses []*Status, w io.Writer) error {
+func clusterStatusJSON(statuses []*Status, w io.Writer, api libmachine.API, cc *config.ClusterConfig) error {
 	cs := clusterState(statuses)
+	if config.IsHA(*cc) {
+		cs.Leader = controlPlaneLeader(api, *cc, statuses)
+	}
 
 	bs, err := json.Marshal(cs)
 	if err != nil {
@@ -666,3 +672,29 @@ func clusterStatusJSON(statuses []*Status, w io.Writer) error {
 	_, err = w.Write(bs)
 	return err
 }
+
+// controlPlaneLeader returns the holder of the kube-vip lease, as seen by the first control-plane node reaching it
+func controlPlaneLeader(api libmachine.API, cc config.ClusterConfig, statuses []*Status) string {
+	for _, st := range statuses {
+		if st.Worker || st.APIServer != state.Running.String() {
+			continue
+		}
+		h, err := machine.LoadHost(api, st.Name)
+		if err != nil {
+			klog.Warningf("unable to load host %s: %v", st.Name, err)
+			continue
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			klog.Warningf("unable to get runner of %s: %v", st.Name, err)
+			continue
+		}
+		leader, err := kubevip.Leader(cc, r)
+		if err != nil {
+			klog.Warningf("unable to get kube-vip leader from %s: %v", st.Name, err)
+			continue
+		}
+		return leader
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/dirsync"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	syncWatch        bool
+	syncDirection    string
+	syncConflict     string
+	syncIgnore       []string
+	syncPollInterval time.Duration
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync <source directory> [<node name>:]<target directory>",
+	Short: "Sync a host directory with a directory of a node",
+	Long: `Sync a host directory with a directory of a node: the files which differ are copied to the node, and the files changed on the node are copied back to the host.
+
+The paths matching the .gitignore file of the source directory or the --ignore patterns are not synced. With --watch, the host changes are synced as they happen and the node is checked for changes every --poll-interval, until interrupted.`,
+	Example: `minikube sync ./src /home/docker/src --watch
+minikube sync ./src minikube-m02:/home/docker/src --direction=push --ignore=node_modules/`,
+	Run: func(_ *cobra.Command, args []string) {
+		if len(args) != 2 {
+			exit.Message(reason.Usage, `Please specify the directories to sync: 
+	minikube sync <source directory> [<node name>:]<target directory> (example: "minikube sync ./src /home/docker/src")`)
+		}
+		src := args[0]
+		dst := newRemotePath(args[1])
+		if !strings.HasPrefix(dst.path, "/") {
+			exit.Message(reason.Usage, "Target directory {{.path}} must be an absolute path", out.V{"path": dst.path})
+		}
+		fi, err := os.Stat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				exit.Message(reason.HostPathMissing, "Cannot find directory {{.path}} for sync", out.V{"path": src})
+			}
+			exit.Error(reason.HostPathStat, "stat failed", err)
+		}
+		if !fi.IsDir() {
+			exit.Message(reason.Usage, "Source {{.path}} must be a directory, use 'minikube cp' to copy a file", out.V{"path": src})
+		}
+
+		co := mustload.Running(ClusterFlagValue())
+		var runner command.Runner
+		nodeName := co.Config.Name
+		if dst.node != "" {
+			runner = remoteCommandRunner(&co, dst.node)
+			nodeName = dst.node
+		} else {
+			runner = co.CP.Runner
+		}
+
+		s, err := dirsync.New(runner, src, dst.path, dirsync.Options{
+			Direction:    syncDirection,
+			Conflict:     syncConflict,
+			Ignore:       syncIgnore,
+			PollInterval: syncPollInterval,
+		})
+		if err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+
+		out.Step(style.Copying, "Syncing {{.src}} with {{.node}}:{{.dst}} ...", out.V{"src": src, "node": nodeName, "dst": dst.path})
+		res, err := s.Sync()
+		if err != nil {
+			exit.Error(reason.HostSync, "Failed to sync", err)
+		}
+		printSyncResult(res)
+		if !syncWatch {
+			return
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		out.Styled(style.Notice, "Watching for changes, press Ctrl-C to stop ...")
+		report := func(res *dirsync.Result) {
+			if !res.Empty() {
+				printSyncResult(res)
+			}
+		}
+		if err := s.Watch(ctx, report); err != nil {
+			exit.Error(reason.HostSync, "Failed to watch for changes", err)
+		}
+	},
+}
+
+// printSyncResult prints what a sync did
+func printSyncResult(res *dirsync.Result) {
+	for _, l := range []struct {
+		name  string
+		paths []string
+	}{
+		{"pushed", res.Pushed},
+		{"pulled", res.Pulled},
+		{"deleted on the node", res.DeletedOnNode},
+		{"deleted on the host", res.DeletedOnHost},
+	} {
+		for _, p := range l.paths {
+			klog.Infof("%s: %s", l.name, p)
+		}
+	}
+	out.Step(style.Copying, "Pushed {{.pushed}}, pulled {{.pulled}} and deleted {{.deleted}} files", out.V{
+		"pushed":  len(res.Pushed),
+		"pulled":  len(res.Pulled),
+		"deleted": len(res.DeletedOnNode) + len(res.DeletedOnHost),
+	})
+	for _, p := range res.Conflicts {
+		out.WarningT("{{.path}} changed on both the host and the node, leaving both as they are", out.V{"path": p})
+	}
+	for _, p := range res.Failed {
+		out.WarningT("Failed to sync {{.path}}, see the logs for details", out.V{"path": p})
+	}
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncWatch, "watch", false, "Keep syncing the changes until interrupted")
+	syncCmd.Flags().StringVar(&syncDirection, "direction", dirsync.DirectionBoth, fmt.Sprintf("The changes to sync: those of both sides, only those of the host (push) or only those of the node (pull). One of: %s", strings.Join(dirsync.Directions, ", ")))
+	syncCmd.Flags().StringVar(&syncConflict, "conflict", dirsync.ConflictHost, fmt.Sprintf("The file to keep when a file changed on both sides: the host one, the node one, the newer one or none (skip). One of: %s", strings.Join(dirsync.ConflictPolicies, ", ")))
+	syncCmd.Flags().StringSliceVar(&syncIgnore, "ignore", []string{}, "Patterns of the paths not to sync, with the syntax of .gitignore, added to the .gitignore file of the source directory")
+	syncCmd.Flags().DurationVar(&syncPollInterval, "poll-interval", dirsync.DefaultPollInterval, "How often the node is checked for changes with --watch")
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+var systemDfOutput string
+
+// cacheUsage is the disk usage of a kind of cache items
+type cacheUsage struct {
+	Kind        string `json:"kind"`
+	Count       int    `json:"count"`
+	Size        int64  `json:"size"`
+	Reclaimable int64  `json:"reclaimable"`
+}
+
+// nodeUsage is the disk usage of the images of a node
+type nodeUsage struct {
+	Profile     string `json:"profile"`
+	Node        string `json:"node"`
+	Images      int    `json:"images"`
+	Size        int64  `json:"size"`
+	Reclaimable int64  `json:"reclaimable"`
+}
+
+// diskUsage is the output of system df
+type diskUsage struct {
+	Cache []cacheUsage `json:"cache"`
+	Nodes []nodeUsage  `json:"nodes"`
+}
+
+// systemCmd represents the system command
+var systemCmd = &cobra.Command{
+	Use:   "system",
+	Short: "Manage the resources used by minikube",
+	Long:  "Manage the resources used by minikube on this host",
+}
+
+// systemDfCmd represents the system df command
+var systemDfCmd = &cobra.Command{
+	Use:   "df",
+	Short: "Show the disk usage of minikube",
+	Long: `Show the disk space used by the local cache and by the images of each running node.
+The reclaimable space is what "minikube cache prune" and "minikube image prune --all" would remove.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		profiles := validProfiles()
+		items, err := machine.ListCache(profiles, cachePruneOptions())
+		if err != nil {
+			exit.Error(reason.InternalCacheList, "Failed to list the cache", err)
+		}
+		du := diskUsage{Cache: cacheDiskUsage(items)}
+
+		o := pruneOptions()
+		o.All = true
+		for _, p := range profiles {
+			nodes, err := machine.PruneImages(p, o, true)
+			if err != nil {
+				klog.Warningf("unable to list the images of profile %s: %v", p.Name, err)
+				continue
+			}
+			for _, n := range nodes {
+				du.Nodes = append(du.Nodes, nodeUsage{
+					Profile:     p.Name,
+					Node:        n.Node,
+					Images:      len(n.Images),
+					Size:        machine.ImagesSize(n.Images),
+					Reclaimable: machine.ImagesSize(n.Prunable),
+				})
+			}
+		}
+
+		switch systemDfOutput {
+		case "table":
+			renderDiskUsage(du)
+		case "json":
+			b, err := json.Marshal(du)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "system df json failure", err)
+			}
+			out.Ln("%s", string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'table' or 'json'")
+		}
+	},
+}
+
+// cacheDiskUsage sums up the cache items by kind
+func cacheDiskUsage(items []machine.CacheItem) []cacheUsage {
+	prune := machine.PruneCandidates(items, machine.PruneOptions{}, time.Now())
+	byKind := map[string]*cacheUsage{}
+	for _, k := range machine.CacheKinds {
+		byKind[k] = &cacheUsage{Kind: k}
+	}
+	for _, i := range items {
+		u := byKind[i.Kind]
+		u.Count++
+		u.Size += i.Size
+	}
+	for _, i := range prune {
+		byKind[i.Kind].Reclaimable += i.Size
+	}
+	var usage []cacheUsage
+	for _, k := range machine.CacheKinds {
+		usage = append(usage, *byKind[k])
+	}
+	return usage
+}
+
+func humanSize(size int64) string {
+	return units.HumanSize(float64(size))
+}
+
+func renderDiskUsage(du diskUsage) {
+	newTable := func(header []string) *tablewriter.Table {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(header)
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		return table
+	}
+
+	table := newTable([]string{"Cache", "Count", "Size", "Reclaimable"})
+	for _, u := range du.Cache {
+		table.Append([]string{u.Kind, fmt.Sprint(u.Count), humanSize(u.Size), humanSize(u.Reclaimable)})
+	}
+	table.Render()
+
+	if len(du.Nodes) == 0 {
+		return
+	}
+	table = newTable([]string{"Profile", "Node", "Images", "Size", "Reclaimable"})
+	for _, u := range du.Nodes {
+		table.Append([]string{u.Profile, u.Node, fmt.Sprint(u.Images), humanSize(u.Size), humanSize(u.Reclaimable)})
+	}
+	table.Render()
+}
+
+func init() {
+	systemDfCmd.Flags().StringVarP(&systemDfOutput, "output", "o", "table", "The output format. One of 'table', 'json'")
+	systemDfCmd.Flags().StringSliceVar(&pruneKeepK8sVersions, "keep-k8s-version", nil, "Do not count the files and images needed by these Kubernetes versions as reclaimable")
+	systemCmd.AddCommand(systemDfCmd)
+}
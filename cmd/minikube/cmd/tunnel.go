@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -46,6 +47,7 @@ import (
 
 var cleanup bool
 var bindAddress string
+var tunnelNativeSSH bool
 var lockHandle *fslock.Lock
 
 // tunnelCmd represents the tunnel command
@@ -96,6 +98,17 @@ var tunnelCmd = &cobra.Command{
 			cancel()
 		}()
 
+		// "minikube tunnel status/stop" talk to the tunnel through its status server
+		status, err := tunnel.ServeStatus(cname, cancel)
+		if errors.Is(err, tunnel.ErrAlreadyRunning) {
+			exit.Message(reason.SvcTunnelAlreadyRunning, "Another tunnel process is already running, terminate the existing instance to start a new one")
+		}
+		if err != nil {
+			klog.Warningf("unable to serve the tunnel status: %v", err)
+		} else {
+			defer status.Close()
+		}
+
 		if driver.NeedsPortForward(co.Config.Driver) || bindAddress != "" {
 			port, err := oci.ForwardedPort(co.Config.Driver, cname, 22)
 			if err != nil {
@@ -106,6 +119,8 @@ var tunnelCmd = &cobra.Command{
 
 			outputTunnelStarted()
 			kicSSHTunnel := kic.NewSSHTunnel(ctx, sshPort, sshKey, bindAddress, clientset.CoreV1(), clientset.NetworkingV1())
+			kicSSHTunnel.NativeSSH = tunnelNativeSSH
+			kicSSHTunnel.Status = status
 			err = kicSSHTunnel.Start()
 			if err != nil {
 				exit.Error(reason.SvcTunnelStart, "error starting tunnel", err)
@@ -114,6 +129,9 @@ var tunnelCmd = &cobra.Command{
 			return
 		}
 
+		if status != nil {
+			manager.ReportTo(status)
+		}
 		done, err := manager.StartTunnel(ctx, cname, co.API, config.DefaultLoader, clientset.CoreV1())
 		if err != nil {
 			exit.Error(reason.SvcTunnelStart, "error starting tunnel", err)
@@ -154,4 +172,5 @@ func outputTunnelStarted() {
 func init() {
 	tunnelCmd.Flags().BoolVarP(&cleanup, "cleanup", "c", true, "call with cleanup=true to remove old tunnels")
 	tunnelCmd.Flags().StringVar(&bindAddress, "bind-address", "", "set tunnel bind address, empty or '*' indicates the tunnel should be available for all interfaces")
+	tunnelCmd.Flags().BoolVar(&tunnelNativeSSH, "native-ssh", false, "Use native Golang SSH client to forward the ports of the docker and podman drivers, instead of the command line 'ssh' command. The 'ssh' command is still used with sudo for the ports below 1024.")
 }
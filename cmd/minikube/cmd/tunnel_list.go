@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+)
+
+var tunnelListOutput string
+
+// tunnelListCmd represents the tunnel list command
+var tunnelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the running tunnels",
+	Long:  "List the tunnels running for all the profiles",
+	Run: func(_ *cobra.Command, _ []string) {
+		reports := runningTunnels()
+
+		switch tunnelListOutput {
+		case "table":
+			if len(reports) == 0 {
+				out.Step(style.Empty, "No tunnels are running")
+				return
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Profile", "PID", "Route", "Minikube", "Exposed", "Errors"})
+			table.SetAutoFormatHeaders(false)
+			table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+			table.SetCenterSeparator("|")
+			for _, r := range reports {
+				route := r.Route
+				if route == "" {
+					route = "-"
+				}
+				table.Append([]string{r.Profile, fmt.Sprint(r.Pid), route, r.MinikubeState, tunnelSummary(r), fmt.Sprint(len(r.Errors))})
+			}
+			table.Render()
+		case "json":
+			b, err := json.Marshal(reports)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "tunnel list json failure", err)
+			}
+			out.Ln("%s", string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'table' or 'json'")
+		}
+	},
+}
+
+// runningTunnels returns the status of the tunnels of all the profiles, and of the tunnels in the registry
+func runningTunnels() []*tunnel.Report {
+	reports := []*tunnel.Report{}
+	seen := map[string]bool{}
+	for _, p := range validProfiles() {
+		r, err := tunnel.QueryStatus(p.Name)
+		if err != nil {
+			klog.Infof("no tunnel status for %s: %v", p.Name, err)
+			continue
+		}
+		reports = append(reports, r)
+		seen[p.Name] = true
+	}
+
+	ids, err := tunnel.NewManager().RunningTunnels()
+	if err != nil {
+		klog.Warningf("unable to list the tunnels: %v", err)
+	}
+	for _, id := range ids {
+		if !seen[id.MachineName] {
+			reports = append(reports, registryReport(id))
+			seen[id.MachineName] = true
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Profile < reports[j].Profile
+	})
+	return reports
+}
+
+func init() {
+	tunnelListCmd.Flags().StringVarP(&tunnelListOutput, "output", "o", "table", "The output format. One of 'table', 'json'")
+	tunnelCmd.AddCommand(tunnelListCmd)
+}
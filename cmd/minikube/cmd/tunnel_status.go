@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+)
+
+var tunnelStatusOutput string
+
+// tunnelStatusCmd represents the tunnel status command
+var tunnelStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the tunnel",
+	Long:  "Show the status of the tunnel running for the profile: its route, the LoadBalancer services it patched, the ports it forwards and its errors.",
+	Run: func(_ *cobra.Command, _ []string) {
+		cname := ClusterFlagValue()
+		r := tunnelReport(cname)
+		if r == nil {
+			exit.Message(reason.SvcTunnelNotRunning, "No tunnel is running for profile {{.profile}}", out.V{"profile": cname})
+		}
+
+		switch tunnelStatusOutput {
+		case "text":
+			printTunnelReport(r)
+		case "json":
+			b, err := json.Marshal(r)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "tunnel status json failure", err)
+			}
+			out.Ln("%s", string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'text' or 'json'")
+		}
+	},
+}
+
+// tunnelReport returns the status of the tunnel of a profile, or nil if none is running
+func tunnelReport(profile string) *tunnel.Report {
+	r, err := tunnel.QueryStatus(profile)
+	if err == nil {
+		return r
+	}
+	klog.Infof("unable to query the tunnel status of %s: %v", profile, err)
+
+	// the tunnel may have been started by an older minikube, without a status server
+	ids, err := tunnel.NewManager().RunningTunnels()
+	if err != nil {
+		klog.Warningf("unable to list the tunnels: %v", err)
+		return nil
+	}
+	for _, id := range ids {
+		if id.MachineName == profile {
+			return registryReport(id)
+		}
+	}
+	return nil
+}
+
+// registryReport returns what the registry knows about a tunnel
+func registryReport(id *tunnel.ID) *tunnel.Report {
+	return &tunnel.Report{
+		Profile:         id.MachineName,
+		Pid:             id.Pid,
+		Route:           id.Route.String(),
+		MinikubeState:   tunnel.Unknown.String(),
+		PatchedServices: []string{},
+	}
+}
+
+func printTunnelReport(r *tunnel.Report) {
+	out.Ln("profile: %s", r.Profile)
+	out.Ln("pid: %d", r.Pid)
+	if !r.Started.IsZero() {
+		out.Ln("started: %s", r.Started.Format("2006-01-02 15:04:05"))
+	}
+	if r.Route != "" {
+		out.Ln("route: %s", r.Route)
+	}
+	out.Ln("minikube: %s", r.MinikubeState)
+	out.Ln("services: [%s]", strings.Join(r.PatchedServices, ", "))
+	if len(r.Forwards) > 0 {
+		out.Ln("forwards:")
+		for _, f := range r.Forwards {
+			out.Ln("  %s: %s (%d open, sent %s, received %s)", f.Resource, strings.Join(f.Ports, ", "), f.OpenConnections,
+				units.HumanSize(float64(f.Sent)), units.HumanSize(float64(f.Received)))
+		}
+	}
+	errs := "no errors"
+	if len(r.Errors) > 0 {
+		errs = strings.Join(r.Errors, ", ")
+	}
+	out.Ln("errors: %s", errs)
+}
+
+// tunnelSummary describes what a tunnel exposes
+func tunnelSummary(r *tunnel.Report) string {
+	var resources []string
+	for _, f := range r.Forwards {
+		resources = append(resources, f.Resource)
+	}
+	if len(resources) == 0 {
+		resources = r.PatchedServices
+	}
+	if len(resources) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d: %s", len(resources), strings.Join(resources, ", "))
+}
+
+func init() {
+	tunnelStatusCmd.Flags().StringVarP(&tunnelStatusOutput, "output", "o", "text", "The output format. One of 'text', 'json'")
+	tunnelCmd.AddCommand(tunnelStatusCmd)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// tunnelStopTimeout is how long to wait for the tunnel to clean up and exit
+const tunnelStopTimeout = 30 * time.Second
+
+// tunnelStopCmd represents the tunnel stop command
+var tunnelStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the tunnel",
+	Long:  "Stop the tunnel running for the profile, removing its route and restoring the LoadBalancer services it patched.",
+	Run: func(_ *cobra.Command, _ []string) {
+		cname := ClusterFlagValue()
+		r := tunnelReport(cname)
+		if r == nil {
+			exit.Message(reason.SvcTunnelNotRunning, "No tunnel is running for profile {{.profile}}", out.V{"profile": cname})
+		}
+
+		if err := tunnel.RequestStop(cname); err != nil {
+			// a tunnel without a status server cleans up on interrupt
+			klog.Infof("unable to request the tunnel to stop, interrupting it: %v", err)
+			p, err := os.FindProcess(r.Pid)
+			if err == nil {
+				err = p.Signal(os.Interrupt)
+			}
+			if err != nil {
+				exit.Error(reason.SvcTunnelStop, "Failed to stop the tunnel", err)
+			}
+		}
+
+		stopped := func() error {
+			if tunnelReport(cname) != nil {
+				return errors.New("the tunnel is still running")
+			}
+			return nil
+		}
+		if err := retry.Local(stopped, tunnelStopTimeout); err != nil {
+			exit.Error(reason.SvcTunnelStop, "The tunnel did not stop", err)
+		}
+		out.Step(style.Stopped, "Stopped the tunnel of profile {{.profile}}", out.V{"profile": cname})
+	},
+}
+
+func init() {
+	tunnelCmd.AddCommand(tunnelStopCmd)
+}
@@ -50,6 +50,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/trace"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/retry"
 )
@@ -499,6 +500,7 @@ func Enable(wg *sync.WaitGroup, cc *config.ClusterConfig, toEnable map[string]bo
 	defer wg.Done()
 
 	start := time.Now()
+	defer trace.StartSpan(trace.AddonsEnable)()
 	klog.Infof("enable addons start: toEnable=%v", toEnable)
 	var enabledAddons []string
 	defer func() {
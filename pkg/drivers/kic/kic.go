@@ -99,7 +99,7 @@ func (d *Driver) Create() error {
 		networkName = d.NodeConfig.ClusterName
 	}
 	staticIP := d.NodeConfig.StaticIP
-	if gateway, err := oci.CreateNetwork(d.OCIBinary, networkName, d.NodeConfig.Subnet, staticIP); err != nil {
+	if gateway, err := oci.CreateNetwork(d.OCIBinary, networkName, d.NodeConfig.Subnet, staticIP, d.NodeConfig.IPv6); err != nil {
 		msg := "Unable to create dedicated network, this might result in cluster IP change after restart: {{.error}}"
 		args := out.V{"error": err}
 		if staticIP != "" {
@@ -386,8 +386,19 @@ func (d *Driver) Remove() error {
 		return fmt.Errorf("expected no container ID be found for %q after delete. but got %q", d.MachineName, id)
 	}
 
+	// the registry cache shared by the clusters would keep the network in use
+	cached, err := oci.DisconnectRegistryCache(d.OCIBinary, d.NodeConfig.ClusterName)
+	if err != nil {
+		klog.Warningf("failed to disconnect the registry cache from network %s: %v", d.NodeConfig.ClusterName, err)
+	}
 	if err := oci.RemoveNetwork(d.OCIBinary, d.NodeConfig.ClusterName); err != nil {
 		klog.Warningf("failed to remove network (which might be okay) %s: %v", d.NodeConfig.ClusterName, err)
+		if cached {
+			// other nodes still use the network, and the registry cache
+			if _, err := oci.ConnectRegistryCache(d.OCIBinary, d.NodeConfig.ClusterName); err != nil {
+				klog.Warningf("failed to reconnect the registry cache to network %s: %v", d.NodeConfig.ClusterName, err)
+			}
+		}
 	}
 	return nil
 }
@@ -55,6 +55,15 @@ func defaultBridgeName(ociBin string) string {
 	}
 }
 
+// ipv6Subnet returns the IPv6 subnet of a network, spelling its IPv4 subnet in the unique local addresses to avoid conflicts, eg fd00:192:168:49::/64 for 192.168.49.0/24
+func ipv6Subnet(ipv4 string) string {
+	ip := net.ParseIP(ipv4).To4()
+	if ip == nil {
+		return ""
+	}
+	return fmt.Sprintf("fd00:%d:%d:%d::/64", ip[0], ip[1], ip[2])
+}
+
 func firstSubnetAddr(subnet string) string {
 	if subnet == "" {
 		return defaultFirstSubnetAddr
@@ -64,7 +73,8 @@ func firstSubnetAddr(subnet string) string {
 }
 
 // CreateNetwork creates a network returns gateway and error, minikube creates one network per cluster
-func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error) {
+// ipv6 also gives the network an IPv6 subnet, for the dual-stack clusters
+func CreateNetwork(ociBin, networkName, subnet, staticIP string, ipv6 bool) (net.IP, error) {
 	defaultBridgeName := defaultBridgeName(ociBin)
 	if networkName == defaultBridgeName {
 		klog.Infof("skipping creating network since default network %s was specified", networkName)
@@ -75,6 +85,9 @@ func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error)
 	info, err := containerNetworkInspect(ociBin, networkName)
 	if err == nil {
 		klog.Infof("Found existing network %+v", info)
+		if ipv6 && info.subnetv6 == nil {
+			klog.Warningf("existing %s network %s has no IPv6 subnet, the IPv6 pod traffic will not be routed between the nodes", ociBin, networkName)
+		}
 		return info.gateway, nil
 	}
 
@@ -103,7 +116,7 @@ func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error)
 			klog.Errorf("failed to find free subnet for %s network %s after %d attempts: %v", ociBin, networkName, 20, err)
 			return nil, fmt.Errorf("un-retryable: %w", err)
 		}
-		info.gateway, err = tryCreateDockerNetwork(ociBin, subnet, info.mtu, networkName)
+		info.gateway, err = tryCreateDockerNetwork(ociBin, subnet, info.mtu, networkName, ipv6)
 		if err == nil {
 			klog.Infof("%s network %s %s created", ociBin, networkName, subnet.CIDR)
 			return info.gateway, nil
@@ -119,7 +132,7 @@ func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error)
 	return info.gateway, fmt.Errorf("failed to create %s network %s: %w", ociBin, networkName, err)
 }
 
-func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int, name string) (net.IP, error) {
+func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int, name string, ipv6 bool) (net.IP, error) {
 	gateway := net.ParseIP(subnet.Gateway)
 	klog.Infof("attempt to create %s network %s %s with gateway %s and MTU of %d ...", ociBin, name, subnet.CIDR, subnet.Gateway, mtu)
 	args := []string{
@@ -129,6 +142,10 @@ func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int,
 		fmt.Sprintf("--subnet=%s", subnet.CIDR),
 		fmt.Sprintf("--gateway=%s", subnet.Gateway),
 	}
+	if ipv6 {
+		// the gateway of the IPv6 subnet is its first address
+		args = append(args, "--ipv6", fmt.Sprintf("--subnet=%s", ipv6Subnet(subnet.IP)))
+	}
 	if ociBin == Docker {
 		// options documentation https://docs.docker.com/engine/reference/commandline/network_create/#bridge-driver-options
 		args = append(args, "-o")
@@ -164,10 +181,11 @@ func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int,
 
 // netInfo holds part of a docker or podman network information relevant to kic drivers
 type netInfo struct {
-	name    string
-	subnet  *net.IPNet
-	gateway net.IP
-	mtu     int
+	name     string
+	subnet   *net.IPNet
+	subnetv6 *net.IPNet
+	gateway  net.IP
+	mtu      int
 }
 
 func containerNetworkInspect(ociBin string, name string) (netInfo, error) {
@@ -193,7 +211,7 @@ type networkInspect struct {
 var dockerInspectGetter = func(name string) (*RunResult, error) {
 	// hack -- 'support ancient versions of docker again (template parsing issue) #10362' and resolve 'Template parsing error: template: :1: unexpected "=" in operand' / 'exit status 64'
 	// note: docker v18.09.7 and older use go v1.10.8 and older, whereas support for '=' operator in go templates came in go v1.11
-	cmd := exec.Command(Docker, "network", "inspect", name, "--format", `{"Name": "{{.Name}}","Driver": "{{.Driver}}","Subnet": "{{range .IPAM.Config}}{{.Subnet}}{{end}}","Gateway": "{{range .IPAM.Config}}{{.Gateway}}{{end}}","MTU": {{if (index .Options "com.docker.network.driver.mtu")}}{{(index .Options "com.docker.network.driver.mtu")}}{{else}}0{{end}}, "ContainerIPs": [{{range $k,$v := .Containers }}"{{$v.IPv4Address}}",{{end}}]}`)
+	cmd := exec.Command(Docker, "network", "inspect", name, "--format", `{"Name": "{{.Name}}","Driver": "{{.Driver}}","Subnet": "{{range .IPAM.Config}}{{.Subnet}} {{end}}","Gateway": "{{range .IPAM.Config}}{{.Gateway}} {{end}}","MTU": {{if (index .Options "com.docker.network.driver.mtu")}}{{(index .Options "com.docker.network.driver.mtu")}}{{else}}0{{end}}, "ContainerIPs": [{{range $k,$v := .Containers }}"{{$v.IPv4Address}}",{{end}}]}`)
 	rr, err := runCmd(cmd)
 	// remove extra ',' after the last element in the ContainerIPs slice
 	rr.Stdout = *bytes.NewBuffer(bytes.ReplaceAll(rr.Stdout.Bytes(), []byte(",]"), []byte("]")))
@@ -219,12 +237,27 @@ func dockerNetworkInspect(name string) (netInfo, error) {
 		return info, fmt.Errorf("error parsing network inspect output: %q", rr.Stdout.String())
 	}
 
-	info.gateway = net.ParseIP(vals.Gateway)
+	// the dual-stack networks have an IPv4 and an IPv6 subnet
+	for _, gw := range strings.Fields(vals.Gateway) {
+		if ip := net.ParseIP(gw); ip != nil && ip.To4() != nil {
+			info.gateway = ip
+		}
+	}
 	info.mtu = vals.MTU
 
-	_, info.subnet, err = net.ParseCIDR(vals.Subnet)
-	if err != nil {
-		return info, errors.Wrapf(err, "parse subnet for %s", name)
+	for _, s := range strings.Fields(vals.Subnet) {
+		ip, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return info, errors.Wrapf(err, "parse subnet for %s", name)
+		}
+		if ip.To4() != nil {
+			info.subnet = subnet
+		} else {
+			info.subnetv6 = subnet
+		}
+	}
+	if info.subnet == nil {
+		return info, fmt.Errorf("no IPv4 subnet found for %s in %q", name, vals.Subnet)
 	}
 
 	return info, nil
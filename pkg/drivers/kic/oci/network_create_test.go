@@ -53,6 +53,13 @@ func TestDockerInspect(t *testing.T) {
 			subnetIP:              "172.19.0.0",
 			mtu:                   0,
 		},
+		{
+			name:                  "dualStack",
+			dockerInspectResponse: `{"Name": "m2","Driver": "bridge","Subnet": "192.168.49.0/24 fd00:192:168:49::/64 ","Gateway": "192.168.49.1 ","MTU": 0, "ContainerIPs": []}`,
+			gateway:               "192.168.49.1",
+			subnetIP:              "192.168.49.0",
+			mtu:                   0,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -205,3 +212,12 @@ func TestIsNetworkNotFound(t *testing.T) {
 		}
 	}
 }
+
+func TestIPv6Subnet(t *testing.T) {
+	if got := ipv6Subnet("192.168.49.0"); got != "fd00:192:168:49::/64" {
+		t.Errorf("ipv6Subnet() = %q, want fd00:192:168:49::/64", got)
+	}
+	if _, _, err := net.ParseCIDR(ipv6Subnet("192.168.255.0")); err != nil {
+		t.Errorf("ipv6Subnet() is not a CIDR: %v", err)
+	}
+}
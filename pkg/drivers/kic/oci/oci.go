@@ -569,6 +569,35 @@ func ListImagesRepository(ctx context.Context, ociBin string) ([]string, error)
 	return names, s.Err()
 }
 
+// CommitContainer creates an image named imageName from the file system of a container
+func CommitContainer(ociBin string, container string, imageName string) error {
+	if _, err := runCmd(exec.Command(ociBin, "commit", container, imageName)); err != nil {
+		return errors.Wrapf(err, "commit %s", container)
+	}
+	return nil
+}
+
+// TagImage gives another name to an image
+func TagImage(ociBin string, imageName string, tag string) error {
+	if _, err := runCmd(exec.Command(ociBin, "tag", imageName, tag)); err != nil {
+		return errors.Wrapf(err, "tag %s as %s", imageName, tag)
+	}
+	return nil
+}
+
+// RemoveImage removes an image by name, it is not an error if the image does not exist
+func RemoveImage(ociBin string, imageName string) error {
+	rr, err := runCmd(exec.Command(ociBin, "image", "rm", imageName))
+	if err != nil {
+		if strings.Contains(strings.ToLower(rr.Output()), "no such image") ||
+			strings.Contains(strings.ToLower(rr.Output()), "image not known") {
+			return nil
+		}
+		return errors.Wrapf(err, "remove image %s", imageName)
+	}
+	return nil
+}
+
 // PointToHostDockerDaemon will unset env variables that point to docker inside minikube
 // to make sure it points to the docker daemon installed by user.
 func PointToHostDockerDaemon() error {
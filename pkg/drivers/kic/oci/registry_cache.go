@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// RegistryCacheContainer is the name of the pull-through caching registry container shared by the clusters
+	RegistryCacheContainer = "minikube-registry-cache"
+	// RegistryCacheVolume is the name of the volume storing the images pulled through the registry cache
+	RegistryCacheVolume = "minikube-registry-cache"
+	// registryCacheLabelKey is applied to the registry cache container and volume, which do not belong to a profile
+	registryCacheLabelKey = "registry-cache.minikube.sigs.k8s.io"
+)
+
+// EnsureRegistryCache creates the registry cache container running the command of the image, or starts it if it is stopped.
+// The container keeps running until it is deleted, and its volume is kept across its recreation.
+func EnsureRegistryCache(ociBin string, image string, command string) error {
+	st, err := ContainerStatus(ociBin, RegistryCacheContainer)
+	if err == nil && st == state.Running {
+		return nil
+	}
+	if err == nil && st != state.None {
+		klog.Infof("starting the registry cache container, which is %s", st)
+		return StartContainer(ociBin, RegistryCacheContainer)
+	}
+
+	label := fmt.Sprintf("%s=true", registryCacheLabelKey)
+	if !volumeExists(ociBin, RegistryCacheVolume) {
+		if _, err := runCmd(exec.Command(ociBin, "volume", "create", RegistryCacheVolume, "--label", label)); err != nil {
+			return errors.Wrap(err, "create registry cache volume")
+		}
+	}
+	if _, err := runCmd(exec.Command(ociBin, "run", "-d", "--name", RegistryCacheContainer, "--label", label,
+		"--restart", "unless-stopped", "-v", RegistryCacheVolume+":/var/lib/registry",
+		"--entrypoint", "/bin/sh", image, "-c", command)); err != nil {
+		return errors.Wrap(err, "run registry cache container")
+	}
+	return nil
+}
+
+// RegistryCacheIP returns the IP of the registry cache container on the network, empty if it is not connected to it
+func RegistryCacheIP(ociBin string, network string) (string, error) {
+	lines, err := inspect(ociBin, RegistryCacheContainer, fmt.Sprintf(`{{with index .NetworkSettings.Networks %q}}{{.IPAddress}}{{end}}`, network))
+	if err != nil {
+		return "", errors.Wrap(err, "inspect registry cache networks")
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// ConnectRegistryCache connects the registry cache container to the network of a cluster, returning its IP on it
+func ConnectRegistryCache(ociBin string, network string) (string, error) {
+	ip, err := RegistryCacheIP(ociBin, network)
+	if err != nil || ip != "" {
+		return ip, err
+	}
+	if _, err := runCmd(exec.Command(ociBin, "network", "connect", network, RegistryCacheContainer)); err != nil {
+		return "", errors.Wrapf(err, "connect registry cache to network %s", network)
+	}
+	ip, err = RegistryCacheIP(ociBin, network)
+	if err == nil && ip == "" {
+		err = fmt.Errorf("registry cache has no IP on network %s", network)
+	}
+	return ip, err
+}
+
+// DisconnectRegistryCache disconnects the registry cache container from the network, so that it can be removed,
+// returning whether it was connected
+func DisconnectRegistryCache(ociBin string, network string) (bool, error) {
+	ip, err := RegistryCacheIP(ociBin, network)
+	if err != nil || ip == "" {
+		// the container does not exist, or is not connected
+		return false, nil
+	}
+	if _, err := runCmd(exec.Command(ociBin, "network", "disconnect", network, RegistryCacheContainer)); err != nil {
+		return true, errors.Wrapf(err, "disconnect registry cache from network %s", network)
+	}
+	return true, nil
+}
+
+// DeleteRegistryCache deletes the registry cache container, and its volume with purge
+func DeleteRegistryCache(ociBin string, purge bool) error {
+	if exists, err := ContainerExists(ociBin, RegistryCacheContainer); err == nil && exists {
+		if _, err := runCmd(exec.Command(ociBin, "rm", "-f", "-v", RegistryCacheContainer)); err != nil {
+			return errors.Wrap(err, "delete registry cache container")
+		}
+	}
+	if !purge {
+		return nil
+	}
+	if err := RemoveVolume(ociBin, RegistryCacheVolume); err != nil && !errors.Is(err, ErrVolumeNotFound) {
+		return errors.Wrap(err, "delete registry cache volume")
+	}
+	return nil
+}
@@ -23,6 +23,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -167,3 +169,51 @@ func prepareVolumeSideCar(ociBin string, imageName string, nodeName string) erro
 	}
 	return nil
 }
+
+// ExportVolume runs a docker image imageName which archives the content of the volume named volumeName
+// into an uncompressed tarball at tarballPath on the host
+func ExportVolume(ociBin string, volumeName, tarballPath, imageName string) error {
+	cmdArgs := []string{"run", "--rm", "--entrypoint", "/usr/bin/tar"}
+	if ociBin == Podman && runtime.GOOS == "linux" {
+		cmdArgs = append(cmdArgs, "--security-opt", "label=disable")
+	}
+	dir, file := filepath.Split(tarballPath)
+	cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:/exportDir", dir), "-v", fmt.Sprintf("%s:/var:ro", volumeName), imageName, "-cpf", path.Join("/exportDir", file), "-C", "/var", ".")
+	if _, err := runCmd(exec.Command(ociBin, cmdArgs...)); err != nil {
+		return errors.Wrapf(err, "export volume %s", volumeName)
+	}
+	return nil
+}
+
+// ImportVolume creates the volume named volumeName for the profile and extracts the uncompressed
+// tarball at tarballPath into it, the reverse of ExportVolume
+func ImportVolume(ociBin string, profile string, tarballPath, volumeName, imageName string) error {
+	if err := createVolume(ociBin, profile, volumeName); err != nil {
+		return errors.Wrapf(err, "create volume %s", volumeName)
+	}
+	cmdArgs := []string{"run", "--rm", "--entrypoint", "/usr/bin/tar"}
+	if ociBin == Podman && runtime.GOOS == "linux" {
+		cmdArgs = append(cmdArgs, "--security-opt", "label=disable")
+	}
+	cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:/snapshot.tar:ro", tarballPath), "-v", fmt.Sprintf("%s:/var", volumeName), imageName, "-xpf", "/snapshot.tar", "-C", "/var")
+	if _, err := runCmd(exec.Command(ociBin, cmdArgs...)); err != nil {
+		return errors.Wrapf(err, "import volume %s", volumeName)
+	}
+	return nil
+}
+
+// CopyVolume creates the volume named to for the profile and copies the content of the volume named from into it
+func CopyVolume(ociBin string, profile string, from, to, imageName string) error {
+	if err := createVolume(ociBin, profile, to); err != nil {
+		return errors.Wrapf(err, "create volume %s", to)
+	}
+	cmdArgs := []string{"run", "--rm", "--entrypoint", "/bin/cp"}
+	if ociBin == Podman && runtime.GOOS == "linux" {
+		cmdArgs = append(cmdArgs, "--security-opt", "label=disable")
+	}
+	cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:/from:ro", from), "-v", fmt.Sprintf("%s:/to", to), imageName, "-a", "/from/.", "/to/")
+	if _, err := runCmd(exec.Command(ociBin, cmdArgs...)); err != nil {
+		return errors.Wrapf(err, "copy volume %s to %s", from, to)
+	}
+	return nil
+}
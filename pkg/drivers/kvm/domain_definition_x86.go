@@ -22,6 +22,12 @@ const domainTmpl = `
 <domain type='kvm'>
   <name>{{.MachineName}}</name>
   <memory unit='MiB'>{{.Memory}}</memory>
+  {{if .SharedMemory}}
+  <memoryBacking>
+    <source type='memfd'/>
+    <access mode='shared'/>
+  </memoryBacking>
+  {{end}}
   <vcpu>{{.CPU}}</vcpu>
   <features>
     <acpi/>
@@ -94,6 +94,9 @@ type Driver struct {
 
 	// Extra Disks XML
 	ExtraDisksXML []string
+
+	// Whether to share the memory of the VM with virtiofsd, for virtiofs mounts
+	SharedMemory bool
 }
 
 const (
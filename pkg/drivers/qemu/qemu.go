@@ -19,6 +19,7 @@ package qemu
 import (
 	"archive/tar"
 	"bytes"
+	crand "crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -91,6 +92,7 @@ type Driver struct {
 	SocketVMNetPath       string
 	SocketVMNetClientPath string
 	ExtraDisks            int
+	SharedMemory          bool
 }
 
 func (d *Driver) GetMachineName() string {
@@ -232,27 +234,10 @@ func (d *Driver) Create() error {
 	var err error
 	switch d.Network {
 	case "builtin", "user":
-		minPort, maxPort, err := parsePortRange(d.LocalPorts)
-		log.Debugf("port range: %d -> %d", minPort, maxPort)
+		d.SSHPort, d.EnginePort, err = ForwardedPorts(d.LocalPorts)
 		if err != nil {
 			return err
 		}
-		d.SSHPort, err = getAvailableTCPPortFromRange(minPort, maxPort)
-		if err != nil {
-			return err
-		}
-
-		for {
-			d.EnginePort, err = getAvailableTCPPortFromRange(minPort, maxPort)
-			if err != nil {
-				return err
-			}
-			if d.EnginePort == d.SSHPort {
-				// can't have both on same port
-				continue
-			}
-			break
-		}
 	case "socket_vmnet":
 		d.SSHPort, err = d.GetSSHPort()
 		if err != nil {
@@ -295,6 +280,42 @@ func (d *Driver) Create() error {
 	return d.Start()
 }
 
+// ForwardedPorts returns available host ports, within the local ports range, to forward to the ssh and docker
+// ports of a VM on the builtin network
+func ForwardedPorts(localPorts string) (sshPort int, enginePort int, err error) {
+	minPort, maxPort, err := parsePortRange(localPorts)
+	log.Debugf("port range: %d -> %d", minPort, maxPort)
+	if err != nil {
+		return 0, 0, err
+	}
+	sshPort, err = getAvailableTCPPortFromRange(minPort, maxPort)
+	if err != nil {
+		return 0, 0, err
+	}
+	for {
+		enginePort, err = getAvailableTCPPortFromRange(minPort, maxPort)
+		if err != nil {
+			return 0, 0, err
+		}
+		if enginePort != sshPort {
+			// can't have both on same port
+			return sshPort, enginePort, nil
+		}
+	}
+}
+
+// GenerateMACAddress returns a random locally administered unicast MAC address
+func GenerateMACAddress() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	// Set local bit, ensure unicast address, socket_vmnet doesn't support multicast
+	buf[0] = (buf[0] | 2) & 0xfe
+	mac := fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
+	return mac, nil
+}
+
 func parsePortRange(rawPortRange string) (int, int, error) {
 	if rawPortRange == "" {
 		return 0, 65535, nil
@@ -426,6 +447,12 @@ func (d *Driver) Start() error {
 		"-m", fmt.Sprintf("%d", d.Memory),
 		"-smp", fmt.Sprintf("%d", d.CPU),
 		"-boot", "d")
+	if d.SharedMemory && runtime.GOOS == "linux" {
+		// virtiofs devices need the guest memory to be shared with virtiofsd
+		startCmd = append(startCmd,
+			"-object", fmt.Sprintf("memory-backend-memfd,id=mem,size=%dM,share=on", d.Memory),
+			"-machine", "memory-backend=mem")
+	}
 	var isoPath = filepath.Join(machineDir, isoFilename)
 	if d.VirtioDrives {
 		startCmd = append(startCmd,
@@ -28,6 +28,7 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/minikube/deploy/addons"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 	"k8s.io/minikube/pkg/util"
@@ -939,6 +940,10 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		LegacyPodSecurityPolicy bool
 		LegacyRuntimeClass      bool
 		AutoPauseInterval       time.Duration
+		AutoPauseGracePeriod    time.Duration
+		AutoPauseNamespaces     string
+		AutoPauseListenAddress  string
+		AutoPausePort           int
 	}{
 		KubernetesVersion:      make(map[string]uint64),
 		PreOneTwentyKubernetes: false,
@@ -961,6 +966,20 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		LegacyPodSecurityPolicy: v.LT(semver.Version{Major: 1, Minor: 25}),
 		LegacyRuntimeClass:      v.LT(semver.Version{Major: 1, Minor: 25}),
 		AutoPauseInterval:       cc.AutoPauseInterval,
+		AutoPauseGracePeriod:    cc.AutoPauseGracePeriod,
+		AutoPausePort:           cc.AutoPausePort,
+	}
+	// the auto-pause binary of older kicbase and ISO images only knows its default settings,
+	// so the other flags are only passed when they are changed
+	if ns := strings.Join(cc.AutoPauseNamespaces, ","); ns != constants.AutoPauseNamespace {
+		opts.AutoPauseNamespaces = ns
+	}
+	if opts.AutoPausePort == 0 {
+		opts.AutoPausePort = constants.AutoPausePort
+	}
+	// the API server proxy connects to the auto-pause server on the control-plane node IP, so it listens on all interfaces
+	if opts.AutoPausePort != constants.AutoPausePort {
+		opts.AutoPauseListenAddress = fmt.Sprintf("0.0.0.0:%d", opts.AutoPausePort)
 	}
 	if opts.ImageRepository != "" && !strings.HasSuffix(opts.ImageRepository, "/") {
 		opts.ImageRepository += "/"
@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
+	"k8s.io/minikube/deploy/addons"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/tests"
 )
@@ -373,3 +376,44 @@ func checkMatches(t *testing.T, name string, got, expected map[string]string) {
 		t.Errorf("expected %q to be %q, but got %q", name, expected[name], got[name])
 	}
 }
+
+func TestAutoPauseServiceFlags(t *testing.T) {
+	svc, err := NewBinAsset(addons.AutoPauseAssets, "auto-pause/auto-pause.service.tmpl", "/etc/systemd/system/", "auto-pause.service", "0640")
+	if err != nil {
+		t.Fatalf("NewBinAsset: %v", err)
+	}
+	tests := []struct {
+		name string
+		cc   config.ClusterConfig
+		want string
+	}{
+		{
+			// the flags of older auto-pause binaries only
+			name: "defaults",
+			cc:   config.ClusterConfig{AutoPauseInterval: time.Minute, AutoPauseNamespaces: []string{"kube-system"}},
+			want: "ExecStart=/bin/auto-pause --container-runtime=docker --interval=1m0s\n",
+		},
+		{
+			name: "changed",
+			cc:   config.ClusterConfig{AutoPauseInterval: time.Minute, AutoPauseGracePeriod: 5 * time.Minute, AutoPauseNamespaces: []string{"default", "web"}, AutoPausePort: 9090},
+			want: "ExecStart=/bin/auto-pause --container-runtime=docker --interval=1m0s --grace-period=5m0s --namespaces=default,web --listen-address=0.0.0.0:9090\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cc.KubernetesConfig = config.KubernetesConfig{KubernetesVersion: "v1.30.0", ContainerRuntime: "docker"}
+			data := GenerateTemplateData(Addons["auto-pause"], &tc.cc, NetworkInfo{}, nil, nil, true)
+			m, err := svc.Evaluate(data)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			b := make([]byte, m.GetLength())
+			if _, err := m.Read(b); err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !strings.Contains(string(b), tc.want) {
+				t.Errorf("auto-pause.service = %s; want %q", b, tc.want)
+			}
+		})
+	}
+}
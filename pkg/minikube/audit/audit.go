@@ -22,14 +22,16 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"strconv"
 	"strings"
 	"time"
 
+	units "github.com/docker/go-units"
 	"github.com/google/uuid"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/config"
-	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/version"
 )
 
@@ -55,20 +57,31 @@ func args() string {
 	return strings.Join(os.Args[2:], " ")
 }
 
+// started is the time the command logged by this process was started.
+var started time.Time
+
 // Log details about the executed command.
 func LogCommandStart() (string, error) {
 	if !shouldLog() {
 		return "", nil
 	}
 	id := uuid.New().String()
-	r := newRow(pflag.Arg(0), args(), userName(), version.GetVersion(), time.Now(), id)
+	started = time.Now()
+	r := newRow(pflag.Arg(0), args(), userName(), version.GetVersion(), started, id)
 	if err := appendToLog(r); err != nil {
 		return "", err
 	}
 	return r.id, nil
 }
 
+// LogCommandEnd logs the successful end of the command.
 func LogCommandEnd(id string) error {
+	return LogCommandExit(id, 0)
+}
+
+// LogCommandExit logs the end of the command with its exit code and duration,
+// rotating the oldest entries into an archive if the audit log grew too large.
+func LogCommandExit(id string, exitCode int) error {
 	if id == "" {
 		return nil
 	}
@@ -89,20 +102,15 @@ func LogCommandEnd(id string) error {
 	if err != nil {
 		return fmt.Errorf("failed to convert logs to rows: %v", err)
 	}
-	// have to truncate the audit log while closed as Windows can't truncate an open file
-	if err := truncateAuditLog(); err != nil {
-		return fmt.Errorf("failed to truncate audit log: %v", err)
-	}
-	if err := openAuditLog(); err != nil {
-		return err
-	}
 	var entriesNeedsToUpdate int
 
-	startIndex := getStartIndex(len(rowSlice))
-	rowSlice = rowSlice[startIndex:]
+	now := time.Now()
+	logs = logs[:0]
 	for _, v := range rowSlice {
 		if v.id == id {
-			v.endTime = time.Now().Format(constants.TimeFormat)
+			v.endTime = now.Format(timeFormat)
+			v.exitCode = strconv.Itoa(exitCode)
+			v.duration = duration(&v, now)
 			v.Data = v.toMap()
 			entriesNeedsToUpdate++
 		}
@@ -110,16 +118,60 @@ func LogCommandEnd(id string) error {
 		if err != nil {
 			return err
 		}
-		if _, err = currentLogFile.WriteString(string(auditLog) + "\n"); err != nil {
-			return fmt.Errorf("failed to write to audit log: %v", err)
-		}
+		logs = append(logs, string(auditLog))
 	}
 	if entriesNeedsToUpdate == 0 {
 		return fmt.Errorf("failed to find a log row with id equals to %v", id)
 	}
+
+	startIndex := rotateIndex(logs)
+	if startIndex > 0 {
+		if err := archive(logs[:startIndex]); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %v", err)
+		}
+	}
+	// have to truncate the audit log while closed as Windows can't truncate an open file
+	if err := truncateAuditLog(); err != nil {
+		return fmt.Errorf("failed to truncate audit log: %v", err)
+	}
+	if err := openAuditLog(); err != nil {
+		return err
+	}
+	for _, l := range logs[startIndex:] {
+		if _, err = currentLogFile.WriteString(l + "\n"); err != nil {
+			return fmt.Errorf("failed to write to audit log: %v", err)
+		}
+	}
 	return nil
 }
 
+// duration returns how long the command of the row ran until now.
+func duration(r *row, now time.Time) string {
+	st := started
+	if st.IsZero() {
+		var err error
+		if st, err = r.started(); err != nil {
+			return ""
+		}
+	}
+	return now.Sub(st).Round(time.Millisecond).String()
+}
+
+// rotateIndex returns the index of the first entry kept in the audit log,
+// the entries before it exceed MaxAuditEntries or MaxAuditSize.
+func rotateIndex(logs []string) int {
+	startIndex := getStartIndex(len(logs))
+	maxSize := maxAuditSize()
+	var size int64
+	for i := len(logs) - 1; i >= startIndex; i-- {
+		size += int64(len(logs[i]) + 1)
+		if maxSize > 0 && size > maxSize {
+			return i + 1
+		}
+	}
+	return startIndex
+}
+
 func getStartIndex(entryCount int) int {
 	// default to 1000 entries
 	maxEntries := 1000
@@ -133,6 +185,24 @@ func getStartIndex(entryCount int) int {
 	return startIndex
 }
 
+// maxAuditSize returns the maximum size of the audit log in bytes, 0 if unlimited.
+func maxAuditSize() int64 {
+	// default to 1 MiB
+	size := "1mb"
+	if viper.IsSet(config.MaxAuditSize) {
+		size = viper.GetString(config.MaxAuditSize)
+	}
+	if size == "" || size == "0" {
+		return 0
+	}
+	b, err := units.RAMInBytes(size)
+	if err != nil {
+		klog.Warningf("invalid %s %q, the audit log is not rotated by size: %v", config.MaxAuditSize, size, err)
+		return 0
+	}
+	return b
+}
+
 // shouldLog returns if the command should be logged.
 func shouldLog() bool {
 	if viper.GetBool(config.SkipAuditFlag) {
@@ -149,7 +219,7 @@ func shouldLog() bool {
 	}
 
 	// commands that should not be logged.
-	no := []string{"status", "version", "logs", "generate-docs", "profile"}
+	no := []string{"status", "version", "logs", "generate-docs", "profile", "audit"}
 	a := pflag.Arg(0)
 	for _, c := range no {
 		if a == c {
@@ -17,11 +17,14 @@ limitations under the License.
 package audit
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -52,6 +55,12 @@ func TestAudit(t *testing.T) {
 	})
 
 	defer os.Remove(auditOverrideFilename)
+	defer func() {
+		paths, _ := archives()
+		for _, p := range paths {
+			os.Remove(p)
+		}
+	}()
 
 	t.Run("username", func(t *testing.T) {
 		u, err := user.Current()
@@ -282,3 +291,68 @@ func mockArgs(t *testing.T, args []string) {
 		t.Fatal(err)
 	}
 }
+
+func TestLogCommandExitRotation(t *testing.T) {
+	auditOverrideFilename = filepath.Join(t.TempDir(), "audit.json")
+	defer func() { auditOverrideFilename = "" }()
+	defer viper.Reset()
+	viper.Set(config.MaxAuditEntries, 0)
+	viper.Set(config.MaxAuditArchives, 2)
+
+	rotate := func(id string, exitCode int) {
+		t.Helper()
+		for i := 0; i < 5; i++ {
+			if err := appendToLog(newRow("start", "", "user1", "v1.33.0", time.Now(), fmt.Sprintf("%s-%d", id, i), "p1")); err != nil {
+				t.Fatalf("appendToLog: %v", err)
+			}
+		}
+		if err := LogCommandExit(id+"-4", exitCode); err != nil {
+			t.Fatalf("LogCommandExit: %v", err)
+		}
+	}
+
+	// a row is a few hundred bytes, so only the last two fit
+	viper.Set(config.MaxAuditSize, "1kb")
+	rotate("a", 3)
+
+	r, err := Report(100)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(r.rows) != 2 {
+		t.Fatalf("audit log has %d rows after rotation, want 2", len(r.rows))
+	}
+	last := r.rows[1]
+	if last.id != "a-4" || last.exitCode != "3" || last.duration == "" || last.endTime == "" || !last.failed() {
+		t.Errorf("exit was not recorded: %+v", last)
+	}
+	paths, err := archives()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("found %d archives, want 1", len(paths))
+	}
+	archived, err := readArchive(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 3 {
+		t.Errorf("archive has %d rows, want 3", len(archived))
+	}
+
+	// the oldest archives are removed
+	rotate("b", 0)
+	rotate("c", 0)
+	if paths, _ = archives(); len(paths) != 2 {
+		t.Errorf("found %d archives, want MaxAuditArchives=2", len(paths))
+	}
+	q, err := Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	// the first archive with a-0 to a-2 was removed
+	if len(q.rows) != 12 || q.rows[0].id != "a-3" || q.rows[11].id != "c-4" {
+		t.Errorf("Query returned %d rows from %s to %s", len(q.rows), q.rows[0].id, q.rows[len(q.rows)-1].id)
+	}
+}
@@ -17,14 +17,24 @@ limitations under the License.
 package audit
 
 import (
+	"bufio"
+	"compress/gzip"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out/register"
 )
 
+// archiveTimeFormat is the timestamp in the name of an archive, it sorts chronologically
+const archiveTimeFormat = "20060102T150405.000000000"
+
 var (
 	// currentLogFile the file that's used to store audit logs
 	currentLogFile *os.File
@@ -80,6 +90,94 @@ func truncateAuditLog() error {
 	return nil
 }
 
+// archivePath returns the path of a new archive for rows rotated out of the audit log.
+func archivePath(t time.Time) string {
+	return fmt.Sprintf("%s.%s.gz", auditPath(), t.UTC().Format(archiveTimeFormat))
+}
+
+// archives returns the paths of the archives, oldest first.
+func archives() ([]string, error) {
+	paths, err := filepath.Glob(auditPath() + ".*.gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log archives: %v", err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// archive compresses rows rotated out of the audit log into a new archive
+// and removes the oldest archives exceeding MaxAuditArchives.
+func archive(lines []string) error {
+	f, err := os.OpenFile(archivePath(time.Now()), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log archive: %v", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	for _, l := range lines {
+		if _, err := gz.Write([]byte(l + "\n")); err != nil {
+			return fmt.Errorf("failed to write audit log archive: %v", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log archive: %v", err)
+	}
+
+	paths, err := archives()
+	if err != nil {
+		return err
+	}
+	maxArchives := 5
+	if viper.IsSet(config.MaxAuditArchives) {
+		maxArchives = viper.GetInt(config.MaxAuditArchives)
+	}
+	for i := 0; i < len(paths)-maxArchives; i++ {
+		if err := os.Remove(paths[i]); err != nil {
+			return fmt.Errorf("failed to remove audit log archive: %v", err)
+		}
+	}
+	return nil
+}
+
+// readArchives reads the rows of all archives, oldest first.
+func readArchives() ([]string, error) {
+	paths, err := archives()
+	if err != nil {
+		return nil, err
+	}
+	var logs []string
+	for _, p := range paths {
+		l, err := readArchive(p)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l...)
+	}
+	return logs, nil
+}
+
+func readArchive(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log archive: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log archive %s: %v", path, err)
+	}
+	defer gz.Close()
+	var logs []string
+	s := bufio.NewScanner(gz)
+	for s.Scan() {
+		logs = append(logs, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log archive %s: %v", path, err)
+	}
+	return logs, nil
+}
+
 func auditPath() string {
 	if auditOverrideFilename != "" {
 		return auditOverrideFilename
@@ -18,9 +18,18 @@ package audit
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
+// headers are the column names of the report, in the order of row.toFields
+var headers = []string{"Command", "Args", "Profile", "User", "Version", "Start Time", "End Time", "Exit Code", "Duration"}
+
 // RawReport contains the information required to generate formatted reports.
 type RawReport struct {
 	headers []string
@@ -53,13 +62,127 @@ func Report(lastNLines int) (*RawReport, error) {
 		return nil, fmt.Errorf("failed to convert logs to rows: %v", err)
 	}
 	r := &RawReport{
-		[]string{"Command", "Args", "Profile", "User", "Version", "Start Time", "End Time"},
+		headers,
 		rows,
 	}
 	return r, nil
 }
 
+// Filter selects the rows of a query, empty fields match all rows.
+type Filter struct {
+	Profile string
+	Command string
+	User    string
+	// Since and Until bound the start time of the command
+	Since time.Time
+	Until time.Time
+	// Failed only selects commands which exited with a non-zero exit code
+	Failed bool
+}
+
+// matches returns whether the row is selected by the filter.
+func (f Filter) matches(r *row) bool {
+	if f.Profile != "" && r.profile != f.Profile {
+		return false
+	}
+	if f.Command != "" && r.command != f.Command {
+		return false
+	}
+	if f.User != "" && r.user != f.User {
+		return false
+	}
+	if f.Failed && !r.failed() {
+		return false
+	}
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+	st, err := r.started()
+	if err != nil {
+		return false
+	}
+	if !f.Since.IsZero() && st.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && st.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Query is created using the rows of the log file and its rotated archives which match the filter.
+func Query(f Filter) (*RawReport, error) {
+	logs, err := readArchives()
+	if err != nil {
+		return nil, err
+	}
+	if err := openAuditLog(); err != nil {
+		return nil, err
+	}
+	defer closeAuditLog()
+	s := bufio.NewScanner(currentLogFile)
+	for s.Scan() {
+		logs = append(logs, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read from audit file: %v", err)
+	}
+	rows, err := logsToRows(logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert logs to rows: %v", err)
+	}
+	selected := []row{}
+	for i := range rows {
+		if f.matches(&rows[i]) {
+			selected = append(selected, rows[i])
+		}
+	}
+	return &RawReport{headers, selected}, nil
+}
+
 // ASCIITable creates a formatted table using the headers and rows from the report.
 func (rr *RawReport) ASCIITable() string {
 	return rowsToASCIITable(rr.rows, rr.headers)
 }
+
+func (rr *RawReport) entries() []entry {
+	es := []entry{}
+	for i := range rr.rows {
+		es = append(es, rr.rows[i].toEntry())
+	}
+	return es
+}
+
+// JSON creates a JSON array of the rows from the report.
+func (rr *RawReport) JSON() (string, error) {
+	b, err := json.Marshal(rr.entries())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %v", err)
+	}
+	return string(b), nil
+}
+
+// YAML creates a YAML list of the rows from the report.
+func (rr *RawReport) YAML() (string, error) {
+	b, err := yaml.Marshal(rr.entries())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %v", err)
+	}
+	return string(b), nil
+}
+
+// CSV creates comma separated values using the headers and rows from the report.
+func (rr *RawReport) CSV() (string, error) {
+	b := new(bytes.Buffer)
+	w := csv.NewWriter(b)
+	if err := w.Write(rr.headers); err != nil {
+		return "", fmt.Errorf("failed to write report: %v", err)
+	}
+	for _, r := range rr.rows {
+		if err := w.Write(r.toFields()); err != nil {
+			return "", fmt.Errorf("failed to write report: %v", err)
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
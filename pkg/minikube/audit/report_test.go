@@ -19,7 +19,10 @@ package audit
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestReport(t *testing.T) {
@@ -54,3 +57,78 @@ func TestReport(t *testing.T) {
 		t.Errorf("report has %d lines of logs, want %d", len(r.rows), wantedLines)
 	}
 }
+
+func TestQuery(t *testing.T) {
+	auditOverrideFilename = filepath.Join(t.TempDir(), "audit.json")
+	defer func() { auditOverrideFilename = "" }()
+
+	archived := `{"data":{"args":"-p mini1","command":"start","endTime":"03 Feb 21 15:33 MST","exitCode":"0","duration":"2m32s","id":"a1","profile":"mini1","startTime":"03 Feb 21 15:30 MST","user":"user1"},"datacontenttype":"application/json","id":"9b7593cb-fbec-49e5-a3ce-bdc2d0bfb208","source":"https://minikube.sigs.k8s.io/","specversion":"1.0","type":"io.k8s.sigs.minikube.audit"}`
+	if err := archive([]string{archived}); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	// rows written by older versions have no exit code and use the RFC1123 time format
+	s := `{"data":{"args":"--user user2","command":"logs","endTime":"Tue, 02 Feb 2021 16:46:20 MST","profile":"minikube","startTime":"Tue, 02 Feb 2021 16:46:00 MST","user":"user2"},"datacontenttype":"application/json","id":"fec03227-2484-48b6-880a-88fd010b5efd","source":"https://minikube.sigs.k8s.io/","specversion":"1.0","type":"io.k8s.sigs.minikube.audit"}
+{"data":{"args":"-p mini1","command":"start","endTime":"05 Feb 21 10:01 MST","exitCode":"80","duration":"45.2s","id":"a2","profile":"mini1","startTime":"05 Feb 21 10:00 MST","user":"user1"},"datacontenttype":"application/json","id":"0c3b2a0e-0d1e-4c2d-9f59-6a4bfbd4b1a4","source":"https://minikube.sigs.k8s.io/","specversion":"1.0","type":"io.k8s.sigs.minikube.audit"}
+`
+	if err := os.WriteFile(auditOverrideFilename, []byte(s), 0644); err != nil {
+		t.Fatalf("failed writing to file: %v", err)
+	}
+
+	feb4 := time.Date(2021, 2, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		description string
+		filter      Filter
+		want        []string
+	}{
+		{"all", Filter{}, []string{"a1", "", "a2"}},
+		{"profile", Filter{Profile: "mini1"}, []string{"a1", "a2"}},
+		{"command", Filter{Command: "logs"}, []string{""}},
+		{"user", Filter{User: "user2"}, []string{""}},
+		{"failed", Filter{Failed: true}, []string{"a2"}},
+		{"since", Filter{Since: feb4}, []string{"a2"}},
+		{"until", Filter{Until: feb4}, []string{"a1", ""}},
+		{"none", Filter{Profile: "mini1", Command: "logs"}, []string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := Query(tc.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			got := []string{}
+			for _, row := range r.rows {
+				got = append(got, row.id)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("Query(%+v) = %q; want %q", tc.filter, got, tc.want)
+			}
+		})
+	}
+
+	r, err := Query(Filter{Failed: true})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	js, err := r.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if !strings.Contains(js, `"exitCode":80`) || !strings.Contains(js, `"duration":"45.2s"`) {
+		t.Errorf("JSON() = %s; want the exit code and duration", js)
+	}
+	ys, err := r.YAML()
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	if !strings.Contains(ys, "exitCode: 80") {
+		t.Errorf("YAML() = %s; want the exit code", ys)
+	}
+	cs, err := r.CSV()
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(cs), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "Command,Args") || !strings.HasSuffix(lines[1], ",80,45.2s") {
+		t.Errorf("CSV() = %s", cs)
+	}
+}
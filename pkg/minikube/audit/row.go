@@ -20,14 +20,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/viper"
 	"k8s.io/minikube/pkg/minikube/config"
-	"k8s.io/minikube/pkg/minikube/constants"
 )
 
+// timeFormat is the format of the times of the rows, with their UTC offset.
+const timeFormat = time.RFC3339
+
 // row is the log of a single command.
 type row struct {
 	SpecVersion     string            `json:"specversion"`
@@ -38,7 +41,9 @@ type row struct {
 	Data            map[string]string `json:"data"`
 	args            string
 	command         string
+	duration        string
 	endTime         string
+	exitCode        string
 	id              string
 	profile         string
 	startTime       string
@@ -56,7 +61,9 @@ func (e *row) Type() string {
 func (e *row) assignFields() {
 	e.args = e.Data["args"]
 	e.command = e.Data["command"]
+	e.duration = e.Data["duration"]
 	e.endTime = e.Data["endTime"]
+	e.exitCode = e.Data["exitCode"]
 	e.profile = e.Data["profile"]
 	e.startTime = e.Data["startTime"]
 	e.user = e.Data["user"]
@@ -70,7 +77,9 @@ func (e *row) toMap() map[string]string {
 	return map[string]string{
 		"args":      e.args,
 		"command":   e.command,
+		"duration":  e.duration,
 		"endTime":   e.endTime,
+		"exitCode":  e.exitCode,
 		"profile":   e.profile,
 		"startTime": e.startTime,
 		"user":      e.user,
@@ -89,7 +98,7 @@ func newRow(command string, args string, user string, version string, startTime
 		args:      args,
 		command:   command,
 		profile:   p,
-		startTime: startTime.Format(constants.TimeFormat),
+		startTime: startTime.Format(timeFormat),
 		user:      user,
 		version:   version,
 		id:        id,
@@ -99,7 +108,60 @@ func newRow(command string, args string, user string, version string, startTime
 // toFields converts a row to an array of fields,
 // to be used when converting to a table.
 func (e *row) toFields() []string {
-	return []string{e.command, e.args, e.profile, e.user, e.version, e.startTime, e.endTime}
+	return []string{e.command, e.args, e.profile, e.user, e.version, e.startTime, e.endTime, e.exitCode, e.duration}
+}
+
+// entry is the exported form of a row, used for the structured report formats.
+type entry struct {
+	ID        string `json:"id" yaml:"id"`
+	Command   string `json:"command" yaml:"command"`
+	Args      string `json:"args" yaml:"args"`
+	Profile   string `json:"profile" yaml:"profile"`
+	User      string `json:"user" yaml:"user"`
+	Version   string `json:"version" yaml:"version"`
+	StartTime string `json:"startTime" yaml:"startTime"`
+	EndTime   string `json:"endTime,omitempty" yaml:"endTime,omitempty"`
+	ExitCode  *int   `json:"exitCode,omitempty" yaml:"exitCode,omitempty"`
+	Duration  string `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// toEntry converts a row to its exported form.
+func (e *row) toEntry() entry {
+	en := entry{
+		ID:        e.id,
+		Command:   e.command,
+		Args:      e.args,
+		Profile:   e.profile,
+		User:      e.user,
+		Version:   e.version,
+		StartTime: e.startTime,
+		EndTime:   e.endTime,
+		Duration:  e.duration,
+	}
+	if c, err := strconv.Atoi(e.exitCode); err == nil {
+		en.ExitCode = &c
+	}
+	return en
+}
+
+// started parses the start time of the row.
+// Rows written by older versions of minikube used the RFC822 or RFC1123 formats,
+// whose zone abbreviations are those of the local time zone.
+func (e *row) started() (time.Time, error) {
+	if t, err := time.Parse(timeFormat, e.startTime); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.RFC822, time.RFC1123} {
+		if t, err := time.ParseInLocation(layout, e.startTime, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start time %q", e.startTime)
+}
+
+// failed returns whether the command exited with a non-zero exit code.
+func (e *row) failed() bool {
+	return e.exitCode != "" && e.exitCode != "0"
 }
 
 // logsToRows converts audit logs into arrays of rows.
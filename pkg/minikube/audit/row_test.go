@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"k8s.io/minikube/pkg/minikube/constants"
 )
 
 func TestRow(t *testing.T) {
@@ -34,13 +33,15 @@ func TestRow(t *testing.T) {
 	u := "user1"
 	v := "v0.17.1"
 	st := time.Now()
-	stFormatted := st.Format(constants.TimeFormat)
+	stFormatted := st.Format(timeFormat)
 	et := time.Now()
-	etFormatted := et.Format(constants.TimeFormat)
+	etFormatted := et.Format(timeFormat)
 	id := uuid.New().String()
 
 	r := newRow(c, a, u, v, st, id, p)
 	r.endTime = etFormatted
+	r.exitCode = "0"
+	r.duration = "1.5s"
 
 	t.Run("NewRow", func(t *testing.T) {
 		tests := []struct {
@@ -87,6 +88,8 @@ func TestRow(t *testing.T) {
 			{"version", v},
 			{"startTime", stFormatted},
 			{"id", id},
+			{"exitCode", "0"},
+			{"duration", "1.5s"},
 		}
 
 		for _, tt := range tests {
@@ -100,7 +103,7 @@ func TestRow(t *testing.T) {
 	t.Run("toFields", func(t *testing.T) {
 		got := r.toFields()
 		gotString := strings.Join(got, ",")
-		want := []string{c, a, p, u, v, stFormatted, etFormatted}
+		want := []string{c, a, p, u, v, stFormatted, etFormatted, "0", "1.5s"}
 		wantString := strings.Join(want, ",")
 
 		if gotString != wantString {
@@ -140,3 +143,23 @@ func TestRow(t *testing.T) {
 		}
 	})
 }
+
+func TestRowStarted(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	st := time.Date(2024, 6, 10, 9, 0, 0, 0, tokyo)
+	r := newRow("start", "", "user1", "v1.33.0", st, "a1", "minikube")
+
+	got, err := r.started()
+	if err != nil {
+		t.Fatalf("started: %v", err)
+	}
+	// 09:00 in Tokyo is midnight UTC, a filter in another zone must see the same instant
+	if !got.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("started() = %v; want %v", got, st)
+	}
+
+	r.startTime = "xx"
+	if _, err := r.started(); err == nil {
+		t.Errorf("started() of an invalid time should fail")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/ktmpl"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// auditDir holds the audit policy and the webhook config, mounted read-only into the apiserver
+	auditDir = "/etc/kubernetes/audit"
+	// auditPolicyFile is the audit policy of the apiserver
+	auditPolicyFile = auditDir + "/policy.yaml"
+	// auditWebhookConfigFile is the kubeconfig of the webhook receiving the audit events
+	auditWebhookConfigFile = auditDir + "/webhook.yaml"
+	// AuditLogDir holds the audit logs of the apiserver
+	AuditLogDir = "/var/log/kubernetes/audit"
+	// AuditLogFile is the audit log of the apiserver, the rotated logs are kept next to it
+	AuditLogFile = AuditLogDir + "/audit.log"
+)
+
+// auditLevels are the levels of the rules of an audit policy
+var auditLevels = []string{"None", "Metadata", "Request", "RequestResponse"}
+
+// auditExtraArgs returns the apiserver flags logging the requests as JSON events
+func auditExtraArgs(audit *config.AuditConfig) map[string]string {
+	if audit == nil {
+		return nil
+	}
+	args := map[string]string{
+		"audit-policy-file":   auditPolicyFile,
+		"audit-log-path":      AuditLogFile,
+		"audit-log-format":    "json",
+		"audit-log-maxsize":   "100",
+		"audit-log-maxbackup": "3",
+	}
+	if audit.WebhookConfigFile != "" {
+		args["audit-webhook-config-file"] = auditWebhookConfigFile
+	}
+	return args
+}
+
+// auditVolumes returns the directories of the audit config and logs, mounted into the apiserver
+func auditVolumes(audit *config.AuditConfig) []hostPathMount {
+	if audit == nil {
+		return nil
+	}
+	return []hostPathMount{
+		{Name: "audit-config", HostPath: auditDir, MountPath: auditDir, ReadOnly: true, PathType: "DirectoryOrCreate"},
+		{Name: "audit-logs", HostPath: AuditLogDir, MountPath: AuditLogDir, PathType: "DirectoryOrCreate"},
+	}
+}
+
+// ValidateAuditPolicy checks that the data is an audit policy the apiserver can load
+func ValidateAuditPolicy(data []byte) error {
+	policy := struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Rules      []struct {
+			Level string `yaml:"level"`
+		} `yaml:"rules"`
+	}{}
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return errors.Wrap(err, "parsing audit policy")
+	}
+	if policy.APIVersion != "audit.k8s.io/v1" || policy.Kind != "Policy" {
+		return fmt.Errorf("not an audit.k8s.io/v1 Policy: apiVersion %q, kind %q", policy.APIVersion, policy.Kind)
+	}
+	if len(policy.Rules) == 0 {
+		return fmt.Errorf("the audit policy has no rules")
+	}
+	for i, r := range policy.Rules {
+		if !slices.Contains(auditLevels, r.Level) {
+			return fmt.Errorf("rules[%d]: unknown level %q, must be one of %v", i, r.Level, auditLevels)
+		}
+	}
+	return nil
+}
+
+// AuditAssets returns the audit policy and webhook config of the apiserver of the control-plane nodes
+func AuditAssets(cc config.ClusterConfig) ([]assets.CopyableFile, error) {
+	audit := cc.KubernetesConfig.Audit
+	if audit == nil {
+		return nil, nil
+	}
+
+	policy := []byte(ktmpl.DefaultAuditPolicy)
+	if audit.PolicyFile != "" {
+		var err error
+		if policy, err = os.ReadFile(audit.PolicyFile); err != nil {
+			return nil, errors.Wrap(err, "reading audit policy")
+		}
+	}
+	files := []assets.CopyableFile{assets.NewMemoryAssetTarget(policy, auditPolicyFile, "0644")}
+	if audit.WebhookConfigFile != "" {
+		webhook, err := os.ReadFile(audit.WebhookConfigFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading audit webhook config")
+		}
+		// the kubeconfig of the webhook may hold credentials
+		files = append(files, assets.NewMemoryAssetTarget(webhook, auditWebhookConfigFile, "0600"))
+	}
+	return files, nil
+}
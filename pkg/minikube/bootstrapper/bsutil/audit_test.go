@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/ktmpl"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+func TestValidateAuditPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		valid  bool
+	}{
+		{"default", ktmpl.DefaultAuditPolicy, true},
+		{"metadata", "apiVersion: audit.k8s.io/v1\nkind: Policy\nrules:\n- level: Metadata\n", true},
+		{"no rules", "apiVersion: audit.k8s.io/v1\nkind: Policy\n", false},
+		{"unknown level", "apiVersion: audit.k8s.io/v1\nkind: Policy\nrules:\n- level: Everything\n", false},
+		{"beta", "apiVersion: audit.k8s.io/v1beta1\nkind: Policy\nrules:\n- level: Metadata\n", false},
+		{"not yaml", "rules: [", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateAuditPolicy([]byte(tc.policy)); (err == nil) != tc.valid {
+				t.Errorf("ValidateAuditPolicy() error = %v, want valid: %t", err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestGenerateKubeadmYAMLAudit(t *testing.T) {
+	fcr := command.NewFakeCommandRunner()
+	fcr.SetCommandToOutput(map[string]string{"docker info --format {{.CgroupDriver}}": "systemd\n"})
+	runtime, err := cruntime.New(cruntime.Config{Type: "docker", Runner: fcr, Socket: "/var/run/dockershim.sock"})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	cfg := config.ClusterConfig{
+		Name: "mk",
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: constants.DefaultKubernetesVersion,
+			ClusterName:       "kubernetes",
+			Audit:             &config.AuditConfig{WebhookConfigFile: "/home/jane/webhook.yaml"},
+		},
+		Nodes: []config.Node{{IP: "1.1.1.1", Name: "mk", ControlPlane: true}},
+	}
+	got, err := GenerateKubeadmYAML(cfg, cfg.Nodes[0], runtime)
+	if err != nil {
+		t.Fatalf("GenerateKubeadmYAML() error = %v", err)
+	}
+	for _, want := range []string{
+		`audit-policy-file: "/etc/kubernetes/audit/policy.yaml"`,
+		`audit-log-path: "/var/log/kubernetes/audit/audit.log"`,
+		`audit-webhook-config-file: "/etc/kubernetes/audit/webhook.yaml"`,
+		`  extraVolumes:
+  - name: audit-config
+    hostPath: /etc/kubernetes/audit
+    mountPath: /etc/kubernetes/audit
+    readOnly: true
+    pathType: DirectoryOrCreate
+  - name: audit-logs
+    hostPath: /var/log/kubernetes/audit
+    mountPath: /var/log/kubernetes/audit
+    readOnly: false
+    pathType: DirectoryOrCreate
+controllerManager:`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("kubeadm config does not contain:\n%s\ngot:\n%s", want, got)
+		}
+	}
+}
+
+func TestAuditAssets(t *testing.T) {
+	if files, err := AuditAssets(config.ClusterConfig{}); err != nil || len(files) != 0 {
+		t.Errorf("AuditAssets() without audit = %v, %v; want none", files, err)
+	}
+
+	webhook := filepath.Join(t.TempDir(), "webhook.yaml")
+	if err := os.WriteFile(webhook, []byte("apiVersion: v1\nkind: Config\n"), 0600); err != nil {
+		t.Fatalf("writing webhook config: %v", err)
+	}
+	cc := config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{Audit: &config.AuditConfig{WebhookConfigFile: webhook}}}
+	files, err := AuditAssets(cc)
+	if err != nil {
+		t.Fatalf("AuditAssets() error = %v", err)
+	}
+	if len(files) != 2 || files[0].GetTargetPath() != auditPolicyFile || files[1].GetTargetPath() != auditWebhookConfigFile {
+		t.Fatalf("AuditAssets() = %v; want the policy and the webhook config", files)
+	}
+	if files[1].GetPermissions() != "0600" {
+		t.Errorf("webhook config permissions = %s, want 0600", files[1].GetPermissions())
+	}
+}
@@ -39,9 +39,19 @@ const (
 
 // componentOptions holds extra args for a component
 type componentOptions struct {
-	Component string
-	ExtraArgs map[string]string
-	Pairs     map[string]string
+	Component    string
+	ExtraArgs    map[string]string
+	Pairs        map[string]string
+	ExtraVolumes []hostPathMount
+}
+
+// hostPathMount is a directory of the node mounted into the static pod of a component
+type hostPathMount struct {
+	Name      string
+	HostPath  string
+	MountPath string
+	ReadOnly  bool
+	PathType  string
 }
 
 // mapping of component to the section name in kubeadm.
@@ -225,3 +235,40 @@ func convertToFlags(opts map[string]string) string {
 	}
 	return strings.Join(flags, " ")
 }
+
+// apiServerOptions returns the options including those of the apiserver, and the index of the latter
+func apiServerOptions(opts []componentOptions, cp config.Node) ([]componentOptions, int) {
+	key := componentToKubeadmConfigKey[Apiserver]
+	for i, o := range opts {
+		if o.Component == key {
+			return opts, i
+		}
+	}
+	return append(opts, componentOptions{
+		Component: key,
+		ExtraArgs: map[string]string{},
+		Pairs:     optionPairsForComponent(Apiserver, cp),
+	}), len(opts)
+}
+
+// withAPIServerArgs adds the args to the extra args of the apiserver
+func withAPIServerArgs(opts []componentOptions, args map[string]string, cp config.Node) []componentOptions {
+	if len(args) == 0 {
+		return opts
+	}
+	opts, i := apiServerOptions(opts, cp)
+	for k, v := range args {
+		opts[i].ExtraArgs[k] = v
+	}
+	return opts
+}
+
+// withAPIServerVolumes adds the mounts to the extra volumes of the apiserver
+func withAPIServerVolumes(opts []componentOptions, mounts []hostPathMount, cp config.Node) []componentOptions {
+	if len(mounts) == 0 {
+		return opts
+	}
+	opts, i := apiServerOptions(opts, cp)
+	opts[i].ExtraVolumes = append(opts[i].ExtraVolumes, mounts...)
+	return opts
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktmpl
+
+// DefaultAuditPolicy is the audit policy of the apiserver when none is given: the metadata of all the requests,
+// and the bodies of the changes, but for the noisy requests of the components and the contents of the secrets
+const DefaultAuditPolicy = `apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages:
+- RequestReceived
+rules:
+- level: None
+  nonResourceURLs:
+  - /healthz*
+  - /livez*
+  - /readyz*
+  - /metrics
+  - /version
+- level: None
+  resources:
+  - group: ""
+    resources: ["events"]
+  - group: events.k8s.io
+    resources: ["events"]
+- level: None
+  resources:
+  - group: coordination.k8s.io
+    resources: ["leases"]
+- level: None
+  userGroups: ["system:nodes"]
+  verbs: ["get", "list", "watch"]
+- level: None
+  users:
+  - system:kube-proxy
+  - system:kube-scheduler
+  - system:kube-controller-manager
+  verbs: ["get", "list", "watch"]
+- level: Metadata
+  resources:
+  - group: ""
+    resources: ["secrets", "configmaps", "serviceaccounts/token"]
+  - group: authentication.k8s.io
+    resources: ["tokenreviews"]
+- level: Metadata
+  verbs: ["get", "list", "watch"]
+- level: Request
+`
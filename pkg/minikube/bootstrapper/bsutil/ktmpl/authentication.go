@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktmpl
+
+import "text/template"
+
+// AuthenticationConfigTemplate is the structured authentication config of the apiserver, authenticating the users
+// with the ID tokens of an OpenID Connect issuer
+var AuthenticationConfigTemplate = template.Must(template.New("authenticationConfigTemplate").Parse(`apiVersion: apiserver.config.k8s.io/v1beta1
+kind: AuthenticationConfiguration
+jwt:
+- issuer:
+    url: {{printf "%q" .IssuerURL}}
+    audiences:
+    - {{printf "%q" .ClientID}}
+{{- if .CALines}}
+    certificateAuthority: |
+{{- range .CALines}}
+      {{.}}
+{{- end}}
+{{- end}}
+  claimMappings:
+    username:
+      claim: {{printf "%q" .UsernameClaim}}
+      prefix: {{printf "%q" .UsernamePrefix}}
+{{- if .GroupsClaim}}
+    groups:
+      claim: {{printf "%q" .GroupsClaim}}
+      prefix: {{printf "%q" .GroupsPrefix}}
+{{- end}}
+`))
@@ -51,6 +51,16 @@ kind: ClusterConfiguration
 {{- range $i, $val := printMapInOrder .ExtraArgs ": " }}
     {{$val}}
 {{- end}}
+{{- if .ExtraVolumes}}
+  extraVolumes:
+{{- range .ExtraVolumes}}
+  - name: {{.Name}}
+    hostPath: {{.HostPath}}
+    mountPath: {{.MountPath}}
+    readOnly: {{.ReadOnly}}
+    pathType: {{.PathType}}
+{{- end}}
+{{- end}}
 {{end -}}
 {{if .FeatureArgs}}featureGates:
 {{range $i, $val := .FeatureArgs}}{{$i}}: {{$val}}
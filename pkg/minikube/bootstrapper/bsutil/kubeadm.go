@@ -70,6 +70,9 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 	if err != nil {
 		return nil, errors.Wrap(err, "generating extra component config for kubeadm")
 	}
+	componentOpts = withAPIServerArgs(componentOpts, oidcExtraArgs(k8s.OIDC, version), n)
+	componentOpts = withAPIServerArgs(componentOpts, auditExtraArgs(k8s.Audit), n)
+	componentOpts = withAPIServerVolumes(componentOpts, auditVolumes(k8s.Audit), n)
 
 	cnm, err := cni.New(&cc)
 	if err != nil {
@@ -353,3 +353,37 @@ func TestKubeletConfig(t *testing.T) {
 		t.Errorf("machines mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestGenerateKubeadmYAMLDualStack(t *testing.T) {
+	fcr := command.NewFakeCommandRunner()
+	fcr.SetCommandToOutput(map[string]string{
+		"docker info --format {{.CgroupDriver}}": "systemd\n",
+	})
+	runtime, err := cruntime.New(cruntime.Config{Type: constants.Docker, Runner: fcr, Socket: "/var/run/dockershim.sock"})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	cfg := config.ClusterConfig{
+		Name: "mk",
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: constants.DefaultKubernetesVersion,
+			ClusterName:       "kubernetes",
+			IPFamily:          "dual",
+			ServiceCIDR:       constants.DefaultServiceCIDR + "," + constants.DefaultServiceCIDRv6,
+		},
+		Nodes: []config.Node{{IP: "1.1.1.1", Name: "mk", ControlPlane: true}},
+	}
+	got, err := GenerateKubeadmYAML(cfg, cfg.Nodes[0], runtime)
+	if err != nil {
+		t.Fatalf("GenerateKubeadmYAML() error = %v", err)
+	}
+	for _, want := range []string{
+		`podSubnet: "10.244.0.0/16,fd00:10:244::/56"`,
+		`serviceSubnet: 10.96.0.0/12,fd00:10:96::/112`,
+		`clusterCIDR: "10.244.0.0/16,fd00:10:244::/56"`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("kubeadm config does not contain %s:\n%s", want, got)
+		}
+	}
+}
@@ -78,7 +78,7 @@ func extraKubeletOpts(mc config.ClusterConfig, nc config.Node, r cruntime.Manage
 		}
 
 		if k8s.NetworkPlugin == "kubenet" {
-			extraOpts["pod-cidr"] = cni.DefaultPodCIDR
+			extraOpts["pod-cidr"] = cni.PodCIDR(mc)
 		}
 	}
 
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/ktmpl"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/util"
+)
+
+const (
+	// oidcDir holds the files of the OpenID Connect authentication, in the certs dir already mounted into the apiserver
+	oidcDir = vmpath.GuestKubernetesCertsDir + "/oidc"
+	// oidcCAFile is the CA of the issuer
+	oidcCAFile = oidcDir + "/ca.crt"
+	// authenticationConfigFile is the structured authentication config of the apiserver
+	authenticationConfigFile = oidcDir + "/authentication-config.yaml"
+)
+
+// structuredAuthenticationVersion is the first version of the apiserver reading the structured authentication config by default
+var structuredAuthenticationVersion = semver.Version{Major: 1, Minor: 30}
+
+// usernameClaim returns the claim used as the user name
+func usernameClaim(oidc *config.OIDCConfig) string {
+	if oidc.UsernameClaim == "" {
+		return "sub"
+	}
+	return oidc.UsernameClaim
+}
+
+// oidcExtraArgs returns the apiserver flags authenticating the users with the ID tokens of the issuer,
+// the structured authentication config replacing the oidc flags on the versions reading it by default
+func oidcExtraArgs(oidc *config.OIDCConfig, version semver.Version) map[string]string {
+	if oidc == nil {
+		return nil
+	}
+	if version.GTE(structuredAuthenticationVersion) {
+		return map[string]string{"authentication-config": authenticationConfigFile}
+	}
+
+	args := map[string]string{
+		"oidc-issuer-url":      oidc.IssuerURL,
+		"oidc-client-id":       oidc.ClientID,
+		"oidc-username-claim":  usernameClaim(oidc),
+		"oidc-username-prefix": oidc.UsernamePrefix,
+	}
+	if oidc.UsernamePrefix == "" {
+		// the apiserver prefixes the user names with the issuer, unless the prefix is "-"
+		args["oidc-username-prefix"] = "-"
+	}
+	if oidc.CAFile != "" {
+		args["oidc-ca-file"] = oidcCAFile
+	}
+	if oidc.GroupsClaim != "" {
+		args["oidc-groups-claim"] = oidc.GroupsClaim
+		if oidc.GroupsPrefix != "" {
+			args["oidc-groups-prefix"] = oidc.GroupsPrefix
+		}
+	}
+	return args
+}
+
+// GenerateAuthenticationConfig generates the structured authentication config of the apiserver
+func GenerateAuthenticationConfig(oidc *config.OIDCConfig, caPEM []byte) ([]byte, error) {
+	opts := struct {
+		*config.OIDCConfig
+		UsernameClaim string
+		CALines       []string
+	}{
+		OIDCConfig:    oidc,
+		UsernameClaim: usernameClaim(oidc),
+	}
+	if len(caPEM) > 0 {
+		opts.CALines = strings.Split(strings.TrimSpace(string(caPEM)), "\n")
+	}
+
+	var b bytes.Buffer
+	if err := ktmpl.AuthenticationConfigTemplate.Execute(&b, opts); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// OIDCAssets returns the files needed by the apiserver of the control-plane nodes to authenticate the users of the issuer
+func OIDCAssets(cc config.ClusterConfig) ([]assets.CopyableFile, error) {
+	oidc := cc.KubernetesConfig.OIDC
+	if oidc == nil {
+		return nil, nil
+	}
+	version, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing Kubernetes version")
+	}
+
+	var files []assets.CopyableFile
+	var caPEM []byte
+	if oidc.CAFile != "" {
+		if caPEM, err = os.ReadFile(oidc.CAFile); err != nil {
+			return nil, errors.Wrap(err, "reading the CA of the OIDC issuer")
+		}
+		files = append(files, assets.NewMemoryAssetTarget(caPEM, oidcCAFile, "0644"))
+	}
+	if version.GTE(structuredAuthenticationVersion) {
+		authnCfg, err := GenerateAuthenticationConfig(oidc, caPEM)
+		if err != nil {
+			return nil, errors.Wrap(err, "generating authentication config")
+		}
+		files = append(files, assets.NewMemoryAssetTarget(authnCfg, authenticationConfigFile, "0644"))
+	}
+	return files, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+var testOIDC = &config.OIDCConfig{
+	IssuerURL:    "https://dex.example.com/dex",
+	ClientID:     "minikube",
+	CAFile:       "/home/jane/dex-ca.crt",
+	GroupsClaim:  "groups",
+	GroupsPrefix: "oidc:",
+}
+
+func TestOIDCExtraArgs(t *testing.T) {
+	tests := []struct {
+		version string
+		want    map[string]string
+	}{
+		{
+			version: "1.29.3",
+			want: map[string]string{
+				"oidc-issuer-url":      "https://dex.example.com/dex",
+				"oidc-client-id":       "minikube",
+				"oidc-ca-file":         "/var/lib/minikube/certs/oidc/ca.crt",
+				"oidc-username-claim":  "sub",
+				"oidc-username-prefix": "-",
+				"oidc-groups-claim":    "groups",
+				"oidc-groups-prefix":   "oidc:",
+			},
+		},
+		{
+			version: "1.30.0",
+			want:    map[string]string{"authentication-config": "/var/lib/minikube/certs/oidc/authentication-config.yaml"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			got := oidcExtraArgs(testOIDC, semver.MustParse(tc.version))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("oidcExtraArgs() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if got := oidcExtraArgs(nil, semver.MustParse("1.30.0")); got != nil {
+		t.Errorf("oidcExtraArgs() without OIDC = %v, want none", got)
+	}
+}
+
+func TestGenerateAuthenticationConfig(t *testing.T) {
+	ca := []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+	got, err := GenerateAuthenticationConfig(testOIDC, ca)
+	if err != nil {
+		t.Fatalf("GenerateAuthenticationConfig() error = %v", err)
+	}
+	want := `apiVersion: apiserver.config.k8s.io/v1beta1
+kind: AuthenticationConfiguration
+jwt:
+- issuer:
+    url: "https://dex.example.com/dex"
+    audiences:
+    - "minikube"
+    certificateAuthority: |
+      -----BEGIN CERTIFICATE-----
+      MIIB
+      -----END CERTIFICATE-----
+  claimMappings:
+    username:
+      claim: "sub"
+      prefix: ""
+    groups:
+      claim: "groups"
+      prefix: "oidc:"
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("GenerateAuthenticationConfig() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithAPIServerArgs(t *testing.T) {
+	opts := []componentOptions{{Component: "apiServer", ExtraArgs: map[string]string{"enable-admission-plugins": "NodeRestriction"}}}
+	got := withAPIServerArgs(opts, map[string]string{"authentication-config": authenticationConfigFile}, config.Node{})
+	want := []componentOptions{{Component: "apiServer", ExtraArgs: map[string]string{
+		"enable-admission-plugins": "NodeRestriction",
+		"authentication-config":    authenticationConfigFile,
+	}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("withAPIServerArgs() diff (-want +got):\n%s", diff)
+	}
+}
@@ -64,6 +64,11 @@ type sharedCACerts struct {
 
 // SetupCerts gets the generated credentials required to talk to the APIServer.
 func SetupCerts(k8s config.ClusterConfig, n config.Node, pcpCmd command.Runner, cmd command.Runner) error {
+	return setupCerts(k8s, n, pcpCmd, cmd, false)
+}
+
+// setupCerts sets up the certs of the node, regenerating the valid profile certs too with force.
+func setupCerts(k8s config.ClusterConfig, n config.Node, pcpCmd command.Runner, cmd command.Runner, force bool) error {
 	localPath := localpath.Profile(k8s.KubernetesConfig.ClusterName)
 	klog.Infof("Setting up %s for IP: %s", localPath, n.IP)
 
@@ -71,6 +76,7 @@ func SetupCerts(k8s config.ClusterConfig, n config.Node, pcpCmd command.Runner,
 	if err != nil {
 		return errors.Wrap(err, "generate shared ca certs")
 	}
+	regen = regen || force
 
 	xfer := []string{
 		sharedCerts.caCert,
@@ -219,14 +225,9 @@ func generateSharedCACerts() (sharedCACerts, bool, error) {
 		},
 	}
 
-	// create a lock for "ca-certs" to avoid race condition over multiple minikube instances rewriting ca certs
-	hold := filepath.Join(globalPath, "ca-certs")
-	spec := lock.PathMutexSpec(hold)
-	spec.Timeout = 1 * time.Minute
-	klog.Infof("acquiring lock for ca certs: %+v", spec)
-	releaser, err := mutex.Acquire(spec)
+	releaser, err := lockCACerts()
 	if err != nil {
-		return cc, false, errors.Wrapf(err, "acquire lock for ca certs %+v", spec)
+		return cc, false, err
 	}
 	defer releaser.Release()
 
@@ -246,6 +247,18 @@ func generateSharedCACerts() (sharedCACerts, bool, error) {
 	return cc, regenProfileCerts, nil
 }
 
+// lockCACerts acquires the lock for "ca-certs" to avoid race condition over multiple minikube instances rewriting ca certs.
+func lockCACerts() (mutex.Releaser, error) {
+	spec := lock.PathMutexSpec(filepath.Join(localpath.MiniPath(), "ca-certs"))
+	spec.Timeout = 1 * time.Minute
+	klog.Infof("acquiring lock for ca certs: %+v", spec)
+	releaser, err := mutex.Acquire(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "acquire lock for ca certs %+v", spec)
+	}
+	return releaser, nil
+}
+
 // generateProfileCerts generates certs for a profile, but only if missing, expired or needs regenerating.
 func generateProfileCerts(cfg config.ClusterConfig, n config.Node, shared sharedCACerts, regen bool) ([]string, error) {
 	// Only generate these certs for the api server
@@ -355,7 +368,8 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, shared shared
 			kp = kp + "." + spec.hash
 		}
 
-		if !regen && isValid(cp, kp) {
+		// the shared CA may have been replaced since, by rotating the certs of another profile
+		if !regen && isValid(cp, kp) && isSignedBy(cp, spec.caCertPath) {
 			klog.Infof("skipping valid signed profile cert regeneration for %q: %s", spec.subject, kp)
 			continue
 		}
@@ -418,9 +432,7 @@ func renewExpiredKubeadmCerts(cmd command.Runner, cc config.ClusterConfig) error
 		return nil
 	}
 	out.WarningT("kubeadm certificates have expired. Generating new ones...")
-	kubeadmPath := path.Join(vmpath.GuestPersistentDir, "binaries", cc.KubernetesConfig.KubernetesVersion)
-	bashCmd := fmt.Sprintf("sudo env PATH=\"%s:$PATH\" kubeadm certs renew all --config %s", kubeadmPath, constants.KubeadmYamlPath)
-	if _, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", bashCmd)); err != nil {
+	if _, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", kubeadmCmd(cc, "certs renew all --config "+constants.KubeadmYamlPath))); err != nil {
 		return errors.Wrap(err, "kubeadm certs renew")
 	}
 	return nil
@@ -630,6 +642,44 @@ func isValid(certPath, keyPath string) bool {
 	return true
 }
 
+// isSignedBy checks that a cert is signed by the CA cert, which it is not anymore once the CA is replaced.
+func isSignedBy(certPath, caCertPath string) bool {
+	cert, err := readCert(certPath)
+	if err != nil {
+		klog.Infof("failed to read cert %s: %v", certPath, err)
+		return false
+	}
+	ca, err := readCert(caCertPath)
+	if err != nil {
+		klog.Infof("failed to read ca cert %s: %v", caCertPath, err)
+		return false
+	}
+	if err := cert.CheckSignatureFrom(ca); err != nil {
+		klog.Infof("cert %s is not signed by %s: %v", certPath, caCertPath, err)
+		return false
+	}
+	return true
+}
+
+// readCert parses the first certificate of a PEM file.
+func readCert(certPath string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseCert(data)
+}
+
+// parseCert parses the first certificate of PEM data.
+func parseCert(data []byte) (*x509.Certificate, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+	return nil, errors.New("no certificate found")
+}
+
 func isKubeadmCertValid(cmd command.Runner, certPath string) bool {
 	_, err := cmd.RunCmd(exec.Command("openssl", "x509", "-noout", "-in", certPath, "-checkend", "86400"))
 	if err != nil {
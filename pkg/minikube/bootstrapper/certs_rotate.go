@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/util"
+	"k8s.io/minikube/pkg/util/lock"
+)
+
+const (
+	// kubeletClientCert is the client cert of the kubelet, rotated by the kubelet itself
+	kubeletClientCert = "/var/lib/kubelet/pki/kubelet-client-current.pem"
+	// kubeletConf is the kubeconfig of the kubelet
+	kubeletConf = "/etc/kubernetes/kubelet.conf"
+)
+
+// kubeadmCerts are the certs generated by kubeadm on the control-plane nodes, relative to their certs dir
+var kubeadmCerts = []string{
+	"apiserver-kubelet-client",
+	"apiserver-etcd-client",
+	"front-proxy-ca",
+	"front-proxy-client",
+	"etcd/ca",
+	"etcd/server",
+	"etcd/peer",
+	"etcd/healthcheck-client",
+}
+
+// controlPlaneComponents are the static pods loading the certs of the control-plane nodes on start
+var controlPlaneComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "etcd"}
+
+// certFile is a named certificate file
+type certFile struct {
+	name string
+	path string
+}
+
+// CertInfo describes a certificate of a cluster
+type CertInfo struct {
+	Name     string    `json:"name"`
+	Node     string    `json:"node,omitempty"`
+	Path     string    `json:"path"`
+	Subject  string    `json:"subject,omitempty"`
+	Issuer   string    `json:"issuer,omitempty"`
+	SANs     []string  `json:"sans,omitempty"`
+	NotAfter time.Time `json:"notAfter,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// newCertInfo returns the description of the PEM certificate read from the path
+func newCertInfo(name, node, p string, data []byte, err error) CertInfo {
+	ci := CertInfo{Name: name, Node: node, Path: p}
+	if err == nil {
+		var cert *x509.Certificate
+		if cert, err = parseCert(data); err == nil {
+			ci.Subject = cert.Subject.String()
+			ci.Issuer = cert.Issuer.String()
+			ci.SANs = append(ci.SANs, cert.DNSNames...)
+			for _, ip := range cert.IPAddresses {
+				ci.SANs = append(ci.SANs, ip.String())
+			}
+			ci.NotAfter = cert.NotAfter
+		}
+	}
+	if err != nil {
+		ci.Error = err.Error()
+	}
+	return ci
+}
+
+// HostCerts returns the certs of the cluster generated by minikube on the host
+func HostCerts(cc config.ClusterConfig) []CertInfo {
+	profilePath := localpath.Profile(cc.Name)
+	certs := []certFile{
+		{"ca", localpath.CACert()},
+		{"proxy-client-ca", filepath.Join(localpath.MiniPath(), "proxy-client-ca.crt")},
+		{"client", localpath.ClientCert(cc.Name)},
+		{"apiserver", filepath.Join(profilePath, "apiserver.crt")},
+		{"proxy-client", filepath.Join(profilePath, "proxy-client.crt")},
+	}
+	infos := []CertInfo{}
+	for _, c := range certs {
+		data, err := os.ReadFile(c.path)
+		infos = append(infos, newCertInfo(c.name, "", c.path, data, err))
+	}
+	return infos
+}
+
+// NodeCerts returns the certs generated by kubeadm and the kubelet on the node
+func NodeCerts(cmd command.Runner, n config.Node, nodeName string) []CertInfo {
+	certs := []certFile{}
+	if n.ControlPlane {
+		for _, c := range kubeadmCerts {
+			certs = append(certs, certFile{strings.ReplaceAll(c, "/", "-"), path.Join(vmpath.GuestKubernetesCertsDir, c+".crt")})
+		}
+	}
+	certs = append(certs, certFile{"kubelet-client", kubeletClientCert})
+
+	infos := []CertInfo{}
+	for _, c := range certs {
+		// openssl only prints the certificate of the kubelet pem, which also holds its key
+		var data []byte
+		rr, err := cmd.RunCmd(exec.Command("sudo", "openssl", "x509", "-in", c.path))
+		if err == nil {
+			data = rr.Stdout.Bytes()
+		}
+		infos = append(infos, newCertInfo(c.name, nodeName, c.path, data, err))
+	}
+	return infos
+}
+
+// InstallCA replaces the shared minikube CA with the CA cert and key, which may be an intermediate CA
+// followed by its chain. It returns whether the CA changed.
+func InstallCA(certPath, keyPath string) (bool, error) {
+	certData, err := os.ReadFile(certPath)
+	if err != nil {
+		return false, errors.Wrap(err, "read ca cert")
+	}
+	cert, err := parseCert(certData)
+	if err != nil {
+		return false, errors.Wrapf(err, "parse ca cert %s", certPath)
+	}
+	if !cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return false, fmt.Errorf("%s is not a CA certificate allowed to sign certificates", certPath)
+	}
+	if time.Now().After(cert.NotAfter) {
+		return false, fmt.Errorf("%s expired on %s", certPath, cert.NotAfter)
+	}
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return false, errors.Wrap(err, "read ca key")
+	}
+	key, err := util.ParsePrivateKey(keyData)
+	if err != nil {
+		return false, errors.Wrapf(err, "parse ca key %s", keyPath)
+	}
+	if pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(cert.PublicKey) {
+		return false, fmt.Errorf("%s is not the key of %s", keyPath, certPath)
+	}
+
+	releaser, err := lockCACerts()
+	if err != nil {
+		return false, err
+	}
+	defer releaser.Release()
+
+	if current, err := readCert(localpath.CACert()); err == nil && current.Equal(cert) {
+		klog.Infof("%s is already the minikube CA", certPath)
+		return false, nil
+	}
+	klog.Infof("installing %s as the minikube CA", certPath)
+	if err := lock.WriteFile(localpath.CACert(), certData, 0644); err != nil {
+		return false, errors.Wrap(err, "write ca cert")
+	}
+	if err := lock.WriteFile(filepath.Join(localpath.MiniPath(), "ca.key"), keyData, 0600); err != nil {
+		return false, errors.Wrap(err, "write ca key")
+	}
+	return true, nil
+}
+
+// RotateCerts regenerates the certs of the node and restarts the components using them.
+// The node must be rotated after the primary control-plane node, which signs its kubelet.conf
+// when the CA changed.
+func RotateCerts(cc config.ClusterConfig, n config.Node, pcpCmd command.Runner, cmd command.Runner, caChanged bool) error {
+	if err := setupCerts(cc, n, pcpCmd, cmd, true); err != nil {
+		return errors.Wrap(err, "setup certs")
+	}
+
+	if n.ControlPlane {
+		// only the primary control-plane node keeps its kubeadm config, the others are given the certs dir
+		args := "certs renew all --cert-dir " + vmpath.GuestKubernetesCertsDir
+		if config.IsPrimaryControlPlane(cc, n) {
+			args = "certs renew all --config " + constants.KubeadmYamlPath
+		}
+		if _, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", kubeadmCmd(cc, args))); err != nil {
+			return errors.Wrap(err, "kubeadm certs renew")
+		}
+	}
+
+	if caChanged {
+		// the kubelet keeps its client cert signed by the previous CA until it is given a new one
+		args := fmt.Sprintf("kubeconfig user --config %s --client-name system:node:%s --org system:nodes", constants.KubeadmYamlPath, config.MachineName(cc, n))
+		rr, err := pcpCmd.RunCmd(exec.Command("/bin/bash", "-c", kubeadmCmd(cc, args)))
+		if err != nil {
+			return errors.Wrap(err, "kubeadm kubeconfig user")
+		}
+		if err := cmd.Copy(assets.NewMemoryAssetTarget(rr.Stdout.Bytes(), kubeletConf, "0600")); err != nil {
+			return errors.Wrap(err, "copy kubelet.conf")
+		}
+		if _, err := cmd.RunCmd(exec.Command("sudo", "rm", "-f", kubeletClientCert)); err != nil {
+			return errors.Wrap(err, "remove kubelet client cert")
+		}
+	}
+
+	if n.ControlPlane {
+		if err := RestartComponents(cc, cmd, controlPlaneComponents...); err != nil {
+			return err
+		}
+	}
+	if err := sysinit.New(cmd).Restart("kubelet"); err != nil {
+		return errors.Wrap(err, "restart kubelet")
+	}
+	return nil
+}
+
+// RestartComponents stops the running containers of the static pods of the components, for the kubelet to
+// restart them with their new certs
+func RestartComponents(cc config.ClusterConfig, cmd command.Runner, components ...string) error {
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: cmd})
+	if err != nil {
+		return errors.Wrap(err, "runtime")
+	}
+	for _, name := range components {
+		ids, err := cr.ListContainers(cruntime.ListContainersOptions{State: cruntime.Running, Name: name, Namespaces: []string{"kube-system"}})
+		if err != nil {
+			return errors.Wrapf(err, "list %s containers", name)
+		}
+		klog.Infof("restarting %s containers %v to load the new certs", name, ids)
+		if err := cr.StopContainers(ids); err != nil {
+			return errors.Wrapf(err, "stop %s containers", name)
+		}
+	}
+	return nil
+}
+
+// RestartCAConsumers restarts the workloads of the kube-system namespace, which read the cluster CA on start
+func RestartCAConsumers(cc config.ClusterConfig, pcpCmd command.Runner) error {
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	for _, kind := range []string{"daemonset", "deployment"} {
+		c := exec.Command("sudo", "KUBECONFIG="+path.Join(vmpath.GuestPersistentDir, "kubeconfig"), kubectl, "-n", "kube-system", "rollout", "restart", kind)
+		if _, err := pcpCmd.RunCmd(c); err != nil {
+			return errors.Wrapf(err, "restart %s", kind)
+		}
+	}
+	return nil
+}
+
+// kubeadmCmd returns the bash command running kubeadm of the cluster version with the args
+func kubeadmCmd(cc config.ClusterConfig, args string) string {
+	kubeadmPath := path.Join(vmpath.GuestPersistentDir, "binaries", cc.KubernetesConfig.KubernetesVersion)
+	return fmt.Sprintf("sudo env PATH=\"%s:$PATH\" kubeadm %s", kubeadmPath, args)
+}
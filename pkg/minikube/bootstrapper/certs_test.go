@@ -17,14 +17,18 @@ limitations under the License.
 package bootstrapper
 
 import (
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/tests"
 	"k8s.io/minikube/pkg/util"
 )
@@ -68,3 +72,87 @@ func TestSetupCerts(t *testing.T) {
 		t.Fatalf("Error starting cluster: %v", err)
 	}
 }
+
+func TestInstallCA(t *testing.T) {
+	tempDir := tests.MakeTempDir(t)
+
+	caCert := filepath.Join(tempDir, "corp", "ca.crt")
+	caKey := filepath.Join(tempDir, "corp", "ca.key")
+	if err := util.GenerateCACert(caCert, caKey, "corporateCA"); err != nil {
+		t.Fatalf("error generating ca: %v", err)
+	}
+	otherCert := filepath.Join(tempDir, "other", "ca.crt")
+	otherKey := filepath.Join(tempDir, "other", "ca.key")
+	if err := util.GenerateCACert(otherCert, otherKey, "otherCA"); err != nil {
+		t.Fatalf("error generating ca: %v", err)
+	}
+	leafCert := filepath.Join(tempDir, "leaf", "leaf.crt")
+	leafKey := filepath.Join(tempDir, "leaf", "leaf.key")
+	if err := util.GenerateSignedCert(leafCert, leafKey, "leaf", nil, nil, caCert, caKey, constants.DefaultCertExpiration); err != nil {
+		t.Fatalf("error generating cert: %v", err)
+	}
+
+	if _, err := InstallCA(leafCert, leafKey); err == nil {
+		t.Errorf("InstallCA() of a leaf cert should have failed")
+	}
+	if _, err := InstallCA(caCert, otherKey); err == nil {
+		t.Errorf("InstallCA() with the key of another CA should have failed")
+	}
+	changed, err := InstallCA(caCert, caKey)
+	if err != nil {
+		t.Fatalf("InstallCA() error = %v", err)
+	}
+	if !changed {
+		t.Errorf("InstallCA() of a new CA should have changed it")
+	}
+	if changed, err := InstallCA(caCert, caKey); err != nil || changed {
+		t.Errorf("InstallCA() of the current CA = %v, %v, want false, nil", changed, err)
+	}
+
+	// the certs signed by a replaced CA are told apart, to be regenerated
+	if !isSignedBy(leafCert, localpath.CACert()) {
+		t.Errorf("%s should be signed by the installed CA", leafCert)
+	}
+	if _, err := InstallCA(otherCert, otherKey); err != nil {
+		t.Fatalf("InstallCA() error = %v", err)
+	}
+	if isSignedBy(leafCert, localpath.CACert()) {
+		t.Errorf("%s should not be signed by the replaced CA", leafCert)
+	}
+}
+
+func TestNewCertInfo(t *testing.T) {
+	tempDir := tests.MakeTempDir(t)
+
+	caCert := filepath.Join(tempDir, "ca.crt")
+	caKey := filepath.Join(tempDir, "ca.key")
+	if err := util.GenerateCACert(caCert, caKey, "minikubeCA"); err != nil {
+		t.Fatalf("error generating ca: %v", err)
+	}
+	cert := filepath.Join(tempDir, "apiserver.crt")
+	if err := util.GenerateSignedCert(cert, filepath.Join(tempDir, "apiserver.key"), "minikube", []net.IP{net.ParseIP("10.96.0.1")}, []string{"control-plane.minikube.internal"}, caCert, caKey, time.Hour); err != nil {
+		t.Fatalf("error generating cert: %v", err)
+	}
+	data, err := os.ReadFile(cert)
+	if err != nil {
+		t.Fatalf("error reading cert: %v", err)
+	}
+
+	ci := newCertInfo("apiserver", "", cert, data, nil)
+	if ci.Error != "" {
+		t.Fatalf("newCertInfo() error = %s", ci.Error)
+	}
+	if ci.Subject != "CN=minikube,O=system:masters" || ci.Issuer != "CN=minikubeCA" {
+		t.Errorf("newCertInfo() subject = %q, issuer = %q", ci.Subject, ci.Issuer)
+	}
+	if diff := cmp.Diff([]string{"control-plane.minikube.internal", "10.96.0.1"}, ci.SANs); diff != "" {
+		t.Errorf("newCertInfo() SANs diff (-want +got):\n%s", diff)
+	}
+	if ci.NotAfter.After(time.Now().Add(time.Hour)) || ci.NotAfter.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("newCertInfo() expiry = %s, want in an hour", ci.NotAfter)
+	}
+
+	if ci := newCertInfo("kubelet-client", "m02", "missing", nil, os.ErrNotExist); ci.Error == "" {
+		t.Errorf("newCertInfo() of a missing cert should have an error")
+	}
+}
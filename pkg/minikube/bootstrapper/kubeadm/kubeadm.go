@@ -64,6 +64,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
 	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/trace"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/retry"
 	"k8s.io/minikube/pkg/version"
@@ -171,6 +172,8 @@ func (k *Bootstrapper) clearStaleConfigs(cfg config.ClusterConfig) {
 
 // init initialises primary control-plane using kubeadm.
 func (k *Bootstrapper) init(cfg config.ClusterConfig) error {
+	defer trace.StartSpan(trace.KubeadmInit)()
+
 	version, err := util.ParseKubernetesVersion(cfg.KubernetesConfig.KubernetesVersion)
 	if err != nil {
 		return errors.Wrap(err, "parsing Kubernetes version")
@@ -358,6 +361,7 @@ func (k *Bootstrapper) applyCNI(cfg config.ClusterConfig, registerStep ...bool)
 		out.Styled(style.CNI, "Configuring {{.name}} (Container Networking Interface) ...", out.V{"name": cnm.String()})
 	}
 
+	defer trace.StartSpan(trace.CNIApply)()
 	if err := cnm.Apply(k.c); err != nil {
 		return errors.Wrap(err, "cni apply")
 	}
@@ -487,6 +491,7 @@ func (k *Bootstrapper) client(ip string, port int) (*kubernetes.Clientset, error
 // WaitForNode blocks until the node appears to be healthy
 func (k *Bootstrapper) WaitForNode(cfg config.ClusterConfig, n config.Node, timeout time.Duration) error {
 	start := time.Now()
+	defer trace.StartSpan(trace.WaitForNode)()
 	register.Reg.SetStep(register.VerifyingKubernetes)
 	out.Step(style.HealthCheck, "Verifying Kubernetes components...")
 	// regardless if waiting is set or not, we will make sure kubelet is not stopped
@@ -895,7 +900,10 @@ func (k *Bootstrapper) UpdateCluster(cfg config.ClusterConfig) error {
 		return errors.Wrap(err, "runtime")
 	}
 
-	if err := r.Preload(cfg); err != nil {
+	endSpan := trace.StartSpan(trace.Preload)
+	err = r.Preload(cfg)
+	endSpan()
+	if err != nil {
 		switch err.(type) {
 		case *cruntime.ErrISOFeature:
 			out.ErrT(style.Tip, "Existing disk is missing new features ({{.error}}). To upgrade, run 'minikube delete'", out.V{"error": err})
@@ -955,6 +963,16 @@ func (k *Bootstrapper) UpdateNode(cfg config.ClusterConfig, n config.Node, r cru
 			}
 			files = append(files, assets.NewMemoryAssetTarget(kubeadmCfg, constants.KubeadmYamlPath+".new", "0640"))
 		}
+		oidcFiles, err := bsutil.OIDCAssets(cfg)
+		if err != nil {
+			return errors.Wrap(err, "generating oidc files")
+		}
+		files = append(files, oidcFiles...)
+		auditFiles, err := bsutil.AuditAssets(cfg)
+		if err != nil {
+			return errors.Wrap(err, "generating audit files")
+		}
+		files = append(files, auditFiles...)
 		// deploy kube-vip for ha (multi-control plane) cluster
 		if config.IsHA(cfg) {
 			// workaround for kube-vip
@@ -964,7 +982,8 @@ func (k *Bootstrapper) UpdateNode(cfg config.ClusterConfig, n config.Node, r cru
 			if err != nil {
 				return errors.Wrapf(err, "parsing kubernetes version %q", cfg.KubernetesConfig.KubernetesVersion)
 			}
-			workaround := kv.GTE(semver.Version{Major: 1, Minor: 29}) && config.IsPrimaryControlPlane(cfg, n) && len(config.ControlPlanes(cfg)) == 1
+			// a running cluster converted to HA is past its kubeadm init
+			workaround := kv.GTE(semver.Version{Major: 1, Minor: 29}) && config.IsPrimaryControlPlane(cfg, n) && len(config.ControlPlanes(cfg)) == 1 && bsutil.ExistingConfig(k.c) != nil
 			kubevipCfg, err := kubevip.Configure(cfg, k.c, kubeadmCfg, workaround)
 			if err != nil {
 				klog.Errorf("couldn't generate kube-vip config, this might cause issues (will continue): %v", err)
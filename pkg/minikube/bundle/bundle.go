@@ -0,0 +1,425 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle exports the artifacts downloaded by minikube start into a tarball, and imports them offline
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/cni"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/version"
+)
+
+// manifestName is the first entry of a bundle, describing its files
+const manifestName = "bundle.json"
+
+// Options are the options of the clusters whose artifacts are bundled
+type Options struct {
+	KubernetesVersion string
+	ContainerRuntime  string
+	Driver            string
+	CNI               string
+	Addons            []string
+}
+
+// File is an artifact of a bundle
+type File struct {
+	// Path is relative to the minikube home directory, with slashes
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a bundle
+type Manifest struct {
+	MinikubeVersion   string   `json:"minikubeVersion"`
+	KubernetesVersion string   `json:"kubernetesVersion"`
+	ContainerRuntime  string   `json:"containerRuntime"`
+	Driver            string   `json:"driver"`
+	Arch              string   `json:"arch"`
+	Addons            []string `json:"addons,omitempty"`
+	// Images are the cached images to load into the clusters, which the preload does not provide
+	Images []string `json:"images,omitempty"`
+	Files  []File   `json:"files"`
+}
+
+// artifacts are the files of a bundle, and the cached images to load
+type artifacts struct {
+	paths  []string
+	images []string
+}
+
+// Create downloads into the caches every artifact that minikube start would download for the options, and writes them to
+// the bundle at dest
+func Create(opts Options, dest string) (*Manifest, error) {
+	var a artifacts
+	if err := a.addKubernetes(opts); err != nil {
+		return nil, err
+	}
+	if err := a.addBaseImage(opts.Driver); err != nil {
+		return nil, err
+	}
+	if err := a.addCNI(opts); err != nil {
+		return nil, err
+	}
+	if err := a.addAddons(opts.Addons); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		MinikubeVersion:   version.GetVersion(),
+		KubernetesVersion: opts.KubernetesVersion,
+		ContainerRuntime:  opts.ContainerRuntime,
+		Driver:            opts.Driver,
+		Arch:              detect.EffectiveArch(),
+		Addons:            opts.Addons,
+		Images:            a.images,
+	}
+	if err := m.addFiles(a.paths); err != nil {
+		return nil, err
+	}
+	out.Step(style.Copying, "Writing the bundle to {{.path}} ...", out.V{"path": dest})
+	if err := write(m, dest); err != nil {
+		return nil, errors.Wrapf(err, "writing %s", dest)
+	}
+	return m, nil
+}
+
+// addKubernetes adds the preload, or the Kubernetes images when there is none, and the Kubernetes binaries
+func (a *artifacts) addKubernetes(opts Options) error {
+	v, rt := opts.KubernetesVersion, opts.ContainerRuntime
+	if download.PreloadExists(v, rt, opts.Driver, true) {
+		if err := download.Preload(v, rt, opts.Driver); err != nil {
+			return errors.Wrap(err, "downloading preload")
+		}
+		a.paths = append(a.paths, download.TarballPath(v, rt))
+	} else {
+		klog.Infof("no preload for %s on %s, bundling the Kubernetes images", v, rt)
+		imgs, err := bootstrapper.GetCachedImageList("", v)
+		if err != nil {
+			return errors.Wrap(err, "Kubernetes images")
+		}
+		if err := a.addImages(imgs); err != nil {
+			return err
+		}
+	}
+
+	out.Step(style.FileDownload, "Downloading Kubernetes {{.version}} binaries ...", out.V{"version": v})
+	for _, bin := range bootstrapper.GetCachedBinaryList() {
+		p, err := download.Binary(bin, v, "linux", detect.EffectiveArch(), "")
+		if err != nil {
+			return errors.Wrapf(err, "downloading %s", bin)
+		}
+		a.paths = append(a.paths, p)
+	}
+	if runtime.GOOS != "linux" {
+		kubectl := "kubectl"
+		if runtime.GOOS == "windows" {
+			kubectl = "kubectl.exe"
+		}
+		p, err := download.Binary(kubectl, v, runtime.GOOS, detect.EffectiveArch(), "")
+		if err != nil {
+			return errors.Wrap(err, "downloading kubectl")
+		}
+		a.paths = append(a.paths, p)
+	}
+	return nil
+}
+
+// addBaseImage adds the kic base image of the container drivers, or the ISO of the VM drivers
+func (a *artifacts) addBaseImage(driverName string) error {
+	switch {
+	case driver.IsKIC(driverName):
+		out.Step(style.Pulling, "Pulling base image {{.kicVersion}} ...", out.V{"kicVersion": kic.Version})
+		if err := download.ImageToCache(kic.BaseImage); err != nil {
+			return errors.Wrap(err, "downloading kic base image")
+		}
+		a.paths = append(a.paths, download.ImagePathInCache(kic.BaseImage))
+	case driver.IsVM(driverName):
+		u, err := download.ISO(download.DefaultISOURLs(), false)
+		if err != nil {
+			return errors.Wrap(err, "downloading ISO")
+		}
+		p, err := download.ISOPathInCache(u)
+		if err != nil {
+			return err
+		}
+		a.paths = append(a.paths, p)
+	}
+	return nil
+}
+
+// addCNI adds the images of the CNI, whose manifests are part of minikube
+func (a *artifacts) addCNI(opts Options) error {
+	imgs, err := cni.Images(config.ClusterConfig{
+		Driver: opts.Driver,
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: opts.KubernetesVersion,
+			ContainerRuntime:  opts.ContainerRuntime,
+			CNI:               opts.CNI,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "CNI images")
+	}
+	return a.addImages(imgs)
+}
+
+// addAddons adds the images of the addons
+func (a *artifacts) addAddons(addons []string) error {
+	var imgs []string
+	for _, name := range addons {
+		addon, ok := assets.Addons[name]
+		if !ok {
+			return fmt.Errorf("unknown addon %q", name)
+		}
+		imgs = append(imgs, addonImages(addon)...)
+	}
+	return a.addImages(imgs)
+}
+
+// addonImages returns the default images of the addon
+func addonImages(addon *assets.Addon) []string {
+	var imgs []string
+	for name, img := range addon.Images {
+		if reg := strings.TrimSuffix(addon.Registries[name], "/"); reg != "" {
+			img = reg + "/" + img
+		}
+		imgs = append(imgs, img)
+	}
+	sort.Strings(imgs)
+	return imgs
+}
+
+// addImages caches the images, which are loaded into the clusters once the bundle is imported
+func (a *artifacts) addImages(imgs []string) error {
+	if len(imgs) == 0 {
+		return nil
+	}
+	out.Step(style.Caching, "Caching {{.count}} images ...", out.V{"count": len(imgs)})
+	if err := image.SaveToDir(imgs, detect.ImageCacheDir(), false); err != nil {
+		return err
+	}
+	for _, img := range imgs {
+		p, err := localpath.DstPath(localpath.SanitizeCacheDir(filepath.Join(detect.ImageCacheDir(), img)))
+		if err != nil {
+			return err
+		}
+		a.paths = append(a.paths, p)
+		a.images = append(a.images, img)
+	}
+	return nil
+}
+
+// addFiles adds the files to the manifest with their checksums, failing if any is missing
+func (m *Manifest) addFiles(paths []string) error {
+	home := localpath.MiniPath()
+	seen := map[string]bool{}
+	for _, p := range paths {
+		rel, err := filepath.Rel(home, p)
+		if err != nil || !filepath.IsLocal(rel) {
+			return fmt.Errorf("%s is not in %s", p, home)
+		}
+		rel = filepath.ToSlash(rel)
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		sum, size, err := checksum(p)
+		if err != nil {
+			return errors.Wrap(err, "missing artifact")
+		}
+		if size == 0 {
+			return fmt.Errorf("missing artifact: %s is empty", p)
+		}
+		m.Files = append(m.Files, File{Path: rel, Size: size, SHA256: sum})
+	}
+	return nil
+}
+
+// checksum returns the SHA-256 and the size of a file
+func checksum(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// write writes the manifest and its files from the minikube home directory to the bundle
+func write(m *Manifest, dest string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	tw := tar.NewWriter(f)
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0o644, Size: int64(len(b))}); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	for _, file := range m.Files {
+		if err := writeFile(tw, file); err != nil {
+			f.Close()
+			return errors.Wrapf(err, "adding %s", file.Path)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func writeFile(tw *tar.Writer, file File) error {
+	f, err := os.Open(filepath.Join(localpath.MiniPath(), filepath.FromSlash(file.Path)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := tw.WriteHeader(&tar.Header{Name: file.Path, Mode: 0o644, Size: file.Size}); err != nil {
+		return err
+	}
+	// the file must not have changed since its checksum, which the import verifies
+	_, err = io.CopyN(tw, f, file.Size)
+	return err
+}
+
+// Load imports the bundle into the caches of the minikube home directory, verifying the checksums of its files
+func Load(src string) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != manifestName {
+		return nil, fmt.Errorf("%s is not a minikube bundle: no %s", src, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", manifestName)
+	}
+	if m.Arch != detect.EffectiveArch() {
+		return nil, fmt.Errorf("the bundle is for %s, not %s", m.Arch, detect.EffectiveArch())
+	}
+
+	files := map[string]File{}
+	for _, file := range m.Files {
+		files[file.Path] = file
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", src)
+		}
+		file, ok := files[hdr.Name]
+		if !ok {
+			return nil, fmt.Errorf("unexpected file %s in the bundle", hdr.Name)
+		}
+		if err := loadFile(tr, file); err != nil {
+			return nil, errors.Wrapf(err, "importing %s", file.Path)
+		}
+		delete(files, hdr.Name)
+	}
+	if len(files) > 0 {
+		var missing []string
+		for p := range files {
+			missing = append(missing, p)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the bundle is missing: %s", strings.Join(missing, ", "))
+	}
+	return &m, nil
+}
+
+// loadFile writes the file to the minikube home directory if its checksum matches the manifest
+func loadFile(r io.Reader, file File) error {
+	rel := filepath.FromSlash(file.Path)
+	if !filepath.IsLocal(rel) || !strings.HasPrefix(file.Path, "cache/") {
+		return fmt.Errorf("invalid path %s", file.Path)
+	}
+	dst := filepath.Join(localpath.MiniPath(), rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); n != file.Size || sum != file.SHA256 {
+		return fmt.Errorf("checksum mismatch: got %d bytes with sha256 %s, want %d bytes with sha256 %s", n, sum, file.Size, file.SHA256)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
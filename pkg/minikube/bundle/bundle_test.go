@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// setupHome creates the files in a new minikube home directory, returning their paths
+func setupHome(t *testing.T, files map[string]string) []string {
+	t.Helper()
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	var paths []string
+	for name, content := range files {
+		p := localpath.MakeMiniPath(filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func TestWriteLoad(t *testing.T) {
+	files := map[string]string{
+		"cache/preloaded-tarball/preload.tar.lz4":      "preload",
+		"cache/linux/amd64/v1.30.0/kubeadm":            "kubeadm",
+		"cache/images/amd64/registry.k8s.io/pause_3.9": "pause",
+	}
+	paths := setupHome(t, files)
+	m := &Manifest{Arch: detect.EffectiveArch(), Images: []string{"registry.k8s.io/pause:3.9"}}
+	if err := m.addFiles(paths); err != nil {
+		t.Fatalf("addFiles: %v", err)
+	}
+	dest := filepath.Join(t.TempDir(), "bundle.tar")
+	if err := write(m, dest); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// import the bundle into an empty minikube home directory
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	got, err := Load(dest)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got.Images) != 1 || len(got.Files) != len(files) {
+		t.Errorf("Load returned %+v", got)
+	}
+	for name, want := range files {
+		b, err := os.ReadFile(localpath.MakeMiniPath(filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", name, b, want)
+		}
+	}
+}
+
+// writeTar writes a bundle with the manifest and the entries
+func writeTar(t *testing.T, m Manifest, entries map[string]string) string {
+	t.Helper()
+	dest := filepath.Join(t.TempDir(), "bundle.tar")
+	f, err := os.Create(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(name string, content []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(manifestName, b)
+	for name, content := range entries {
+		add(name, []byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dest
+}
+
+func TestLoadErrors(t *testing.T) {
+	const kicbase = "cache/kic/amd64/kicbase.tar"
+	sum := sha256.Sum256([]byte("kicbase"))
+	valid := File{Path: kicbase, Size: 7, SHA256: hex.EncodeToString(sum[:])}
+
+	tests := []struct {
+		name    string
+		arch    string
+		files   []File
+		entries map[string]string
+		want    string
+	}{
+		{"checksum", detect.EffectiveArch(), []File{{Path: kicbase, Size: 7, SHA256: strings.Repeat("0", 64)}}, map[string]string{kicbase: "kicbase"}, "checksum mismatch"},
+		{"missing", detect.EffectiveArch(), []File{valid, {Path: "cache/iso/amd64/minikube.iso", Size: 1}}, map[string]string{kicbase: "kicbase"}, "missing: cache/iso/amd64/minikube.iso"},
+		{"unexpected", detect.EffectiveArch(), nil, map[string]string{kicbase: "kicbase"}, "unexpected file"},
+		{"path", detect.EffectiveArch(), []File{{Path: "../config/config.json", Size: 7, SHA256: valid.SHA256}}, map[string]string{"../config/config.json": "kicbase"}, "invalid path"},
+		{"arch", "sparc", []File{valid}, map[string]string{kicbase: "kicbase"}, "the bundle is for sparc"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(localpath.MinikubeHome, t.TempDir())
+			dest := writeTar(t, Manifest{Arch: tc.arch, Files: tc.files}, tc.entries)
+			_, err := Load(dest)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Load error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddonImages(t *testing.T) {
+	addon := &assets.Addon{
+		Images:     map[string]string{"Registry": "registry:2.8.3", "Proxy": "kube-registry-proxy:0.4"},
+		Registries: map[string]string{"Proxy": "gcr.io/google_containers/"},
+	}
+	got := addonImages(addon)
+	want := []string{"gcr.io/google_containers/kube-registry-proxy:0.4", "registry:2.8.3"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("addonImages = %v, want %v", got, want)
+	}
+}
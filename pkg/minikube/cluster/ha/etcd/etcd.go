@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd reports the membership of the etcd cluster stacked on the control-plane nodes
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// certsDir holds the certs of etcd, mounted into its container at the same path
+var certsDir = path.Join(vmpath.GuestKubernetesCertsDir, "etcd")
+
+// Member is a member of the etcd cluster
+type Member struct {
+	Name      string
+	ClientURL string
+	Learner   bool
+	Healthy   bool
+}
+
+// Members returns the members of the etcd cluster and their health, as seen by the etcd of the node of the runner
+func Members(cc config.ClusterConfig, r command.Runner) ([]Member, error) {
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r})
+	if err != nil {
+		return nil, errors.Wrap(err, "runtime")
+	}
+	ids, err := cr.ListContainers(cruntime.ListContainersOptions{State: cruntime.Running, Name: "etcd", Namespaces: []string{"kube-system"}})
+	if err != nil {
+		return nil, errors.Wrap(err, "list etcd containers")
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("etcd is not running")
+	}
+
+	list, err := r.RunCmd(etcdctl(ids[0], "member", "list", "-w", "json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "etcdctl member list")
+	}
+	// the health check exits with an error when a member is unhealthy, still reporting all of them
+	health, err := r.RunCmd(etcdctl(ids[0], "endpoint", "health", "--cluster", "--command-timeout=3s", "-w", "json"))
+	if err != nil {
+		klog.Infof("unhealthy etcd members: %v", err)
+	}
+	var hb []byte
+	if health != nil {
+		hb = health.Stdout.Bytes()
+	}
+	return parseMembers(list.Stdout.Bytes(), hb)
+}
+
+// etcdctl returns the command running etcdctl in the etcd container
+func etcdctl(id string, args ...string) *exec.Cmd {
+	base := []string{"crictl", "exec", id, "etcdctl",
+		"--endpoints=https://127.0.0.1:2379",
+		"--cacert=" + path.Join(certsDir, "ca.crt"),
+		"--cert=" + path.Join(certsDir, "healthcheck-client.crt"),
+		"--key=" + path.Join(certsDir, "healthcheck-client.key"),
+	}
+	return exec.Command("sudo", append(base, args...)...)
+}
+
+// parseMembers merges the JSON outputs of etcdctl member list and endpoint health
+func parseMembers(list []byte, health []byte) ([]Member, error) {
+	var ml struct {
+		Members []struct {
+			Name       string   `json:"name"`
+			ClientURLs []string `json:"clientURLs"`
+			IsLearner  bool     `json:"isLearner"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal(list, &ml); err != nil {
+		return nil, errors.Wrap(err, "parse etcd members")
+	}
+
+	healthy := map[string]bool{}
+	var hl []struct {
+		Endpoint string `json:"endpoint"`
+		Health   bool   `json:"health"`
+	}
+	if len(strings.TrimSpace(string(health))) > 0 {
+		if err := json.Unmarshal(health, &hl); err != nil {
+			klog.Warningf("unable to parse etcd endpoint health: %v", err)
+		}
+	}
+	for _, h := range hl {
+		healthy[h.Endpoint] = h.Health
+	}
+
+	var members []Member
+	for _, m := range ml.Members {
+		// a member which has not started yet has no name nor client URL
+		mb := Member{Name: m.Name, Learner: m.IsLearner}
+		if len(m.ClientURLs) > 0 {
+			mb.ClientURL = m.ClientURLs[0]
+			mb.Healthy = healthy[mb.ClientURL]
+		}
+		members = append(members, mb)
+	}
+	return members, nil
+}
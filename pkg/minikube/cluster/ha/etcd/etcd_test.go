@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMembers(t *testing.T) {
+	list := `{"header":{"cluster_id":17237436991929493444,"member_id":9372538179322589801,"raft_term":3},"members":[
+{"ID":9372538179322589801,"name":"ha-demo","peerURLs":["https://192.168.49.2:2380"],"clientURLs":["https://192.168.49.2:2379"]},
+{"ID":10501334649042878790,"name":"ha-demo-m02","peerURLs":["https://192.168.49.3:2380"],"clientURLs":["https://192.168.49.3:2379"]},
+{"ID":11337318130373180522,"peerURLs":["https://192.168.49.4:2380"],"isLearner":true}]}`
+	health := `[{"endpoint":"https://192.168.49.2:2379","health":true,"took":"9.1ms"},
+{"endpoint":"https://192.168.49.3:2379","health":false,"took":"3.0s","error":"context deadline exceeded"}]`
+
+	got, err := parseMembers([]byte(list), []byte(health))
+	if err != nil {
+		t.Fatalf("parseMembers() error = %v", err)
+	}
+	want := []Member{
+		{Name: "ha-demo", ClientURL: "https://192.168.49.2:2379", Healthy: true},
+		{Name: "ha-demo-m02", ClientURL: "https://192.168.49.3:2379"},
+		{Learner: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseMembers() diff (-want +got):\n%s", diff)
+	}
+
+	// without the health check, the members are reported unhealthy
+	got, err = parseMembers([]byte(list), nil)
+	if err != nil {
+		t.Fatalf("parseMembers() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Healthy {
+		t.Errorf("parseMembers() without health = %+v", got)
+	}
+
+	if _, err := parseMembers([]byte("Error: context deadline exceeded"), nil); err == nil {
+		t.Errorf("parseMembers() of an error should have failed")
+	}
+}
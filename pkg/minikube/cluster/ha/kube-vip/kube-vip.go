@@ -20,17 +20,23 @@ import (
 	"bytes"
 	"html/template"
 	"os/exec"
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/vmpath"
 )
 
 const Manifest = "kube-vip.yaml"
 
+// LeaseName is the lease elected by the kube-vip pods of the control-plane nodes, its holder serving the VIP
+const LeaseName = "plndr-cp-lock"
+
 // KubeVipTemplate is kube-vip static pod config template
 // ref: https://kube-vip.io/docs/installation/static/
 // update: regenerate with:
@@ -65,7 +71,7 @@ spec:
     - name: vip_leaderelection
       value: "true"
     - name: vip_leasename
-      value: plndr-cp-lock
+      value: {{ .LeaseName }}
     - name: vip_leaseduration
       value: "5"
     - name: vip_renewdeadline
@@ -113,11 +119,13 @@ func Configure(cc config.ClusterConfig, r command.Runner, kubeadmCfg []byte, wor
 	params := struct {
 		VIP       string
 		Port      int
+		LeaseName string
 		AdminConf string
 		EnableLB  bool
 	}{
 		VIP:       cc.KubernetesConfig.APIServerHAVIP,
 		Port:      cc.APIServerPort,
+		LeaseName: LeaseName,
 		AdminConf: "/etc/kubernetes/admin.conf",
 		EnableLB:  enableCPLB(cc, r, kubeadmCfg),
 	}
@@ -135,6 +143,18 @@ func Configure(cc config.ClusterConfig, r command.Runner, kubeadmCfg []byte, wor
 	return b.Bytes(), nil
 }
 
+// Leader returns the name of the control-plane node holding the kube-vip lease, as seen by the node of the runner
+func Leader(cc config.ClusterConfig, r command.Runner) (string, error) {
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	c := exec.Command("sudo", "KUBECONFIG="+path.Join(vmpath.GuestPersistentDir, "kubeconfig"), kubectl, "--request-timeout=5s",
+		"-n", "kube-system", "get", "lease", LeaseName, "-o", "jsonpath={.spec.holderIdentity}")
+	rr, err := r.RunCmd(c)
+	if err != nil {
+		return "", errors.Wrap(err, "get kube-vip lease")
+	}
+	return strings.TrimSpace(rr.Stdout.String()), nil
+}
+
 // enableCPLB auto-enables control-plane load-balancing, if possible - currently only possible with ipvs.
 // ref: https://kube-vip.io/docs/about/architecture/?query=ipvs#control-plane-load-balancing
 func enableCPLB(cc config.ClusterConfig, r command.Runner, kubeadmCfg []byte) bool {
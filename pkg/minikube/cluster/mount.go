@@ -37,7 +37,7 @@ import (
 
 // MountConfig defines the options available to the Mount command
 type MountConfig struct {
-	// Type is the filesystem type (Typically 9p)
+	// Type is the filesystem type, which selects the mount backend: 9p, nfs or virtiofs
 	Type string
 	// UID is the User ID which this path will be mounted as
 	UID string
@@ -51,6 +51,12 @@ type MountConfig struct {
 	Port int
 	// Extra mount options. See https://www.kernel.org/doc/Documentation/filesystems/9p.txt
 	Options map[string]string
+	// BindAddress is the address the file server listens on, on the host
+	BindAddress string
+	// MachineName is the name of the machine the directory is mounted in
+	MachineName string
+	// KVMQemuURI is the libvirt connection of the kvm2 driver
+	KVMQemuURI string
 }
 
 // mountRunner is the subset of CommandRunner used for mounting
@@ -65,6 +71,8 @@ const (
 	MountErrorConnect
 	// MountErrorChmod failed to chmod
 	MountErrorChmod
+	// MountErrorUnsupported the guest or the driver does not support the filesystem
+	MountErrorUnsupported
 )
 
 // MountError wrapper around errors in the `Mount` function
@@ -79,8 +87,13 @@ func (m *MountError) Error() string {
 	return m.UnderlyingError.Error()
 }
 
-// Mount runs the mount command from the 9p client on the VM to the 9p server on the host
+// Mount runs the mount command of the backend of the mount type on the VM, to the file server on the host
 func Mount(r mountRunner, source string, target string, c *MountConfig, pid int) error {
+	b, err := NewMountBackend(c.Type)
+	if err != nil {
+		return &MountError{ErrorType: MountErrorUnsupported, UnderlyingError: err}
+	}
+
 	if err := Unmount(r, target); err != nil {
 		return &MountError{ErrorType: MountErrorUnknown, UnderlyingError: errors.Wrap(err, "umount")}
 	}
@@ -89,10 +102,10 @@ func Mount(r mountRunner, source string, target string, c *MountConfig, pid int)
 		return &MountError{ErrorType: MountErrorUnknown, UnderlyingError: errors.Wrap(err, "create folder pre-mount")}
 	}
 
-	rr, err := r.RunCmd(exec.Command("/bin/bash", "-c", mntCmd(source, target, c)))
+	rr, err := r.RunCmd(exec.Command("/bin/bash", "-c", b.mountCmd(source, target, c)))
 	if err != nil {
-		if strings.Contains(rr.Stderr.String(), "Connection timed out") {
-			return &MountError{ErrorType: MountErrorConnect, UnderlyingError: err}
+		if t := b.classify(rr.Stderr.String()); t != MountErrorUnknown {
+			return &MountError{ErrorType: t, UnderlyingError: err}
 		}
 		return &MountError{ErrorType: MountErrorUnknown, UnderlyingError: errors.Wrapf(err, "mount with cmd %s ", rr.Command())}
 	}
@@ -151,8 +164,17 @@ func mntCmd(source string, target string, c *MountConfig) string {
 		options["msize"] = strconv.Itoa(c.MSize)
 	}
 
+	return fmt.Sprintf("sudo mount -t %s -o %s %s %s", c.Type, mountOptions(options, c.Options), source, target)
+}
+
+// mountOptions returns the mount options, the user-supplied ones overriding the defaults
+func mountOptions(defaults map[string]string, user map[string]string) string {
+	options := map[string]string{}
+	for k, v := range defaults {
+		options[k] = v
+	}
 	// Copy in all of the user-supplied keys and values
-	for k, v := range c.Options {
+	for k, v := range user {
 		options[k] = v
 	}
 
@@ -167,7 +189,20 @@ func mntCmd(source string, target string, c *MountConfig) string {
 		opts = append(opts, fmt.Sprintf("%s=%s", k, v))
 	}
 	sort.Strings(opts)
-	return fmt.Sprintf("sudo mount -t %s -o %s %s %s", c.Type, strings.Join(opts, ","), source, target)
+	return strings.Join(opts, ",")
+}
+
+// classifyMountError returns the MountError type of the output of a failed mount command
+func classifyMountError(output string) int {
+	switch {
+	case strings.Contains(output, "Connection timed out"),
+		strings.Contains(output, "Connection refused"),
+		strings.Contains(output, "No route to host"):
+		return MountErrorConnect
+	case strings.Contains(output, "unknown filesystem type"):
+		return MountErrorUnsupported
+	}
+	return MountErrorUnknown
 }
 
 // Unmount unmounts a path
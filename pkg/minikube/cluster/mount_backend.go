@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/pkg/errors"
+	nfs "github.com/willscott/go-nfs"
+	nfshelper "github.com/willscott/go-nfs/helpers"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	pkgnetwork "k8s.io/minikube/pkg/network"
+	"k8s.io/minikube/third_party/go9p/ufs"
+)
+
+const (
+	// MountType9P serves the directory with the in-tree 9p server
+	MountType9P = "9p"
+	// MountTypeNFS serves the directory with a userspace NFSv3 server
+	MountTypeNFS = "nfs"
+	// MountTypeVirtiofs shares the directory with virtiofsd, through a virtio device of the VM
+	MountTypeVirtiofs = "virtiofs"
+)
+
+// MountBackend serves a host directory to the guest and mounts it there
+type MountBackend interface {
+	// Supported returns an error if the backend can not be used with the cluster
+	Supported(cc *config.ClusterConfig) error
+	// Serve serves the host directory until the server fails or the process exits
+	Serve(hostPath string, c *MountConfig) error
+	// Stop releases what Serve set up outside of this process, once the directory is unmounted
+	Stop(c *MountConfig) error
+	// mountCmd returns the guest command mounting the directory served from source at target
+	mountCmd(source string, target string, c *MountConfig) string
+	// classify returns the MountError type of the output of a failed mount command
+	classify(output string) int
+}
+
+var mountBackends = map[string]MountBackend{
+	MountType9P:       &ninePBackend{},
+	MountTypeNFS:      &nfsBackend{},
+	MountTypeVirtiofs: &virtiofsBackend{},
+}
+
+// MountTypes returns the supported mount types
+func MountTypes() []string {
+	var types []string
+	for t := range mountBackends {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewMountBackend returns the backend of a mount type
+func NewMountBackend(mountType string) (MountBackend, error) {
+	b, ok := mountBackends[mountType]
+	if !ok {
+		return nil, errors.Errorf("unsupported mount type %q, must be one of: %s", mountType, strings.Join(MountTypes(), ", "))
+	}
+	return b, nil
+}
+
+// networkSupported returns an error if the guest can not reach a file server on the host
+func networkSupported(cc *config.ClusterConfig) error {
+	if cc.Driver == driver.None {
+		return errors.New("the 'none' driver does not support mounts")
+	}
+	if driver.IsQEMU(cc.Driver) && pkgnetwork.IsBuiltinQEMU(cc.Network) {
+		return errors.New("the builtin network of QEMU does not support mounts from a file server on the host, try starting minikube with '--network=socket_vmnet'")
+	}
+	return nil
+}
+
+// ninePBackend serves the directory with the in-tree 9p server
+type ninePBackend struct{}
+
+func (*ninePBackend) Supported(cc *config.ClusterConfig) error {
+	return networkSupported(cc)
+}
+
+func (*ninePBackend) Serve(hostPath string, c *MountConfig) error {
+	var debugVal int
+	if klog.V(1).Enabled() {
+		debugVal = 1 // ufs.StartServer takes int debug param
+	}
+	ufs.StartServer(net.JoinHostPort(c.BindAddress, strconv.Itoa(c.Port)), debugVal, hostPath)
+	return nil
+}
+
+func (*ninePBackend) Stop(_ *MountConfig) error {
+	return nil
+}
+
+func (*ninePBackend) mountCmd(source string, target string, c *MountConfig) string {
+	return mntCmd(source, target, c)
+}
+
+func (*ninePBackend) classify(output string) int {
+	return classifyMountError(output)
+}
+
+// nfsBackend serves the directory with a userspace NFSv3 server, which needs no portmapper
+// as the mount and nfs programs are both served on the mount port
+type nfsBackend struct{}
+
+func (*nfsBackend) Supported(cc *config.ClusterConfig) error {
+	return networkSupported(cc)
+}
+
+func (*nfsBackend) Serve(hostPath string, c *MountConfig) error {
+	l, err := net.Listen("tcp", net.JoinHostPort(c.BindAddress, strconv.Itoa(c.Port)))
+	if err != nil {
+		return errors.Wrap(err, "listen")
+	}
+	handler := nfshelper.NewNullAuthHandler(changeFS{osfs.New(hostPath)})
+	return nfs.Serve(l, nfshelper.NewCachingHandler(handler, 1024))
+}
+
+func (*nfsBackend) Stop(_ *MountConfig) error {
+	return nil
+}
+
+func (*nfsBackend) mountCmd(source string, target string, c *MountConfig) string {
+	port := strconv.Itoa(c.Port)
+	options := map[string]string{
+		"vers":      "3",
+		"proto":     "tcp",
+		"port":      port,
+		"mountport": port,
+		"mountvers": "3",
+		"nolock":    "",
+		"soft":      "",
+		// without mount.nfs in the guest, the kernel needs the server address
+		"addr": source,
+	}
+	return fmt.Sprintf("sudo mount -t nfs -o %s %s:/ %s", mountOptions(options, c.Options), source, target)
+}
+
+func (*nfsBackend) classify(output string) int {
+	if strings.Contains(output, "Program not registered") || strings.Contains(output, "portmap query failed") {
+		return MountErrorConnect
+	}
+	return classifyMountError(output)
+}
+
+// changeFS lets the nfs clients change the mode, owner and times of the host files
+type changeFS struct {
+	billy.Filesystem
+}
+
+func (fs changeFS) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(fs.Join(fs.Root(), name), mode)
+}
+
+func (fs changeFS) Lchown(name string, uid, gid int) error {
+	return os.Lchown(fs.Join(fs.Root(), name), uid, gid)
+}
+
+func (fs changeFS) Chown(name string, uid, gid int) error {
+	return os.Chown(fs.Join(fs.Root(), name), uid, gid)
+}
+
+func (fs changeFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(fs.Join(fs.Root(), name), atime, mtime)
+}
@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
 )
 
 func TestMntCmd(t *testing.T) {
@@ -75,3 +78,108 @@ func TestMntCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestBackendMountCmd(t *testing.T) {
+	var tests = []struct {
+		name   string
+		source string
+		target string
+		cfg    *MountConfig
+		want   string
+	}{
+		{
+			name:   "nfs",
+			source: "10.0.0.1",
+			target: "/target",
+			cfg:    &MountConfig{Type: MountTypeNFS, Port: 2049},
+			want:   "sudo mount -t nfs -o addr=10.0.0.1,mountport=2049,mountvers=3,nolock,port=2049,proto=tcp,soft,vers=3 10.0.0.1:/ /target",
+		},
+		{
+			name:   "nfs options",
+			source: "10.0.0.1",
+			target: "/target",
+			cfg:    &MountConfig{Type: MountTypeNFS, Port: 2049, Options: map[string]string{"soft": "", "hard": "", "actimeo": "1"}},
+			want:   "sudo mount -t nfs -o actimeo=1,addr=10.0.0.1,hard,mountport=2049,mountvers=3,nolock,port=2049,proto=tcp,soft,vers=3 10.0.0.1:/ /target",
+		},
+		{
+			name:   "virtiofs",
+			source: "10.0.0.1",
+			target: "/target",
+			cfg:    &MountConfig{Type: MountTypeVirtiofs, Port: 1234},
+			want:   "for i in $(seq 30); do sudo mount -t virtiofs minikube1234 /target && exit 0; sleep 1; done; exit 1",
+		},
+		{
+			name:   "virtiofs options",
+			source: "10.0.0.1",
+			target: "/target",
+			cfg:    &MountConfig{Type: MountTypeVirtiofs, Port: 1234, Options: map[string]string{"ro": ""}},
+			want:   "for i in $(seq 30); do sudo mount -t virtiofs -o ro minikube1234 /target && exit 0; sleep 1; done; exit 1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewMountBackend(tc.cfg.Type)
+			if err != nil {
+				t.Fatalf("NewMountBackend(%q): %v", tc.cfg.Type, err)
+			}
+			got := b.mountCmd(tc.source, tc.target, tc.cfg)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("command diff (-want +got): %s", diff)
+			}
+		})
+	}
+
+	if _, err := NewMountBackend("smb"); err == nil {
+		t.Errorf("NewMountBackend(\"smb\") succeeded")
+	}
+}
+
+func TestClassifyMountError(t *testing.T) {
+	var tests = []struct {
+		mountType string
+		output    string
+		want      int
+	}{
+		{MountType9P, "mount: /target: mount(2) system call failed: Connection timed out.", MountErrorConnect},
+		{MountType9P, "mount: /target: unknown filesystem type '9p'.", MountErrorUnsupported},
+		{MountType9P, "mount: /target: permission denied.", MountErrorUnknown},
+		{MountTypeNFS, "mount: /target: mount(2) system call failed: Connection refused.", MountErrorConnect},
+		{MountTypeNFS, "mount.nfs: portmap query failed: RPC: Program not registered", MountErrorConnect},
+		{MountTypeVirtiofs, "mount: /target: wrong fs type, bad option, bad superblock on minikube1234.", MountErrorUnsupported},
+		{MountTypeVirtiofs, "mount: /target: special device minikube1234 does not exist. No such file or directory", MountErrorConnect},
+	}
+	for _, tc := range tests {
+		b, err := NewMountBackend(tc.mountType)
+		if err != nil {
+			t.Fatalf("NewMountBackend(%q): %v", tc.mountType, err)
+		}
+		if got := b.classify(tc.output); got != tc.want {
+			t.Errorf("%s classify(%q) = %d, want %d", tc.mountType, tc.output, got, tc.want)
+		}
+	}
+}
+
+func TestMountSupported(t *testing.T) {
+	var tests = []struct {
+		mountType string
+		cc        *config.ClusterConfig
+		supported bool
+	}{
+		{MountType9P, &config.ClusterConfig{Driver: driver.Docker}, true},
+		{MountType9P, &config.ClusterConfig{Driver: driver.None}, false},
+		{MountTypeNFS, &config.ClusterConfig{Driver: driver.QEMU2, Network: "socket_vmnet"}, true},
+		{MountTypeNFS, &config.ClusterConfig{Driver: driver.QEMU2, Network: "builtin"}, false},
+		{MountTypeVirtiofs, &config.ClusterConfig{Driver: driver.Docker}, false},
+		{MountTypeVirtiofs, &config.ClusterConfig{Driver: driver.QEMU2, MountType: MountType9P}, false},
+	}
+	for _, tc := range tests {
+		b, err := NewMountBackend(tc.mountType)
+		if err != nil {
+			t.Fatalf("NewMountBackend(%q): %v", tc.mountType, err)
+		}
+		if err := b.Supported(tc.cc); (err == nil) != tc.supported {
+			t.Errorf("%s Supported(%s) = %v, want supported: %v", tc.mountType, tc.cc.Driver, err, tc.supported)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// virtiofsdPaths are where the distributions install virtiofsd, outside of the PATH
+var virtiofsdPaths = []string{"/usr/libexec/virtiofsd", "/usr/lib/qemu/virtiofsd", "/usr/lib/virtiofsd"}
+
+// virtiofsBackend shares the directory with virtiofsd, through a vhost-user-fs device hot-plugged in the VM
+type virtiofsBackend struct{}
+
+func (*virtiofsBackend) Supported(cc *config.ClusterConfig) error {
+	if !driver.IsQEMU(cc.Driver) && !driver.IsKVM(cc.Driver) {
+		return errors.Errorf("virtiofs mounts are only supported by the %s and %s drivers", driver.QEMU2, driver.KVM2)
+	}
+	if runtime.GOOS != "linux" {
+		return errors.Errorf("virtiofs mounts are not supported on %s, virtiofsd only runs on linux", runtime.GOOS)
+	}
+	if cc.MountType != MountTypeVirtiofs {
+		return errors.Errorf("the VM only shares its memory with virtiofsd when created with --mount-type=%s", MountTypeVirtiofs)
+	}
+	if _, err := virtiofsdPath(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (*virtiofsBackend) Serve(hostPath string, c *MountConfig) error {
+	bin, err := virtiofsdPath()
+	if err != nil {
+		return err
+	}
+	socket := virtiofsSocket(c)
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove stale socket")
+	}
+
+	// the sandbox needs root, the file owners are mapped by the guest mount
+	cmd := exec.Command(bin, "--socket-path="+socket, "--shared-dir="+hostPath, "--cache=auto", "--sandbox=none")
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	klog.Infof("starting %s", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "start virtiofsd")
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	listening := func() error {
+		if _, err := os.Stat(socket); err != nil {
+			return err
+		}
+		return nil
+	}
+	if err := retry.Local(listening, 10*time.Second); err != nil {
+		_ = cmd.Process.Kill()
+		return errors.Wrap(err, "waiting for the virtiofsd socket")
+	}
+	if err := attachVirtiofs(socket, c); err != nil {
+		_ = cmd.Process.Kill()
+		return errors.Wrap(err, "attach the virtiofs device")
+	}
+
+	// virtiofsd exits once the device is detached
+	return <-exited
+}
+
+func (*virtiofsBackend) Stop(c *MountConfig) error {
+	if c.KVMQemuURI != "" {
+		return virsh(c, "detach-device", virtiofsSocket(c))
+	}
+	monitor := localpath.MakeMiniPath("machines", c.MachineName, "monitor")
+	err := qmpExecute(monitor, qmpCommand{Execute: "device_del", Arguments: map[string]interface{}{"id": virtiofsTag(c)}})
+	if err != nil {
+		return err
+	}
+	// the device is removed asynchronously, the chardev can only be removed afterwards
+	remove := func() error {
+		return qmpExecute(monitor, qmpCommand{Execute: "chardev-remove", Arguments: map[string]interface{}{"id": virtiofsTag(c) + "-char"}})
+	}
+	return retry.Local(remove, 10*time.Second)
+}
+
+func (*virtiofsBackend) mountCmd(_ string, target string, c *MountConfig) string {
+	options := ""
+	if opts := mountOptions(nil, c.Options); opts != "" {
+		options = "-o " + opts + " "
+	}
+	// the device shows up in the guest shortly after it is attached
+	return fmt.Sprintf("for i in $(seq 30); do sudo mount -t virtiofs %s%s %s && exit 0; sleep 1; done; exit 1", options, virtiofsTag(c), target)
+}
+
+func (*virtiofsBackend) classify(output string) int {
+	switch {
+	case strings.Contains(output, "wrong fs type"):
+		return MountErrorUnsupported
+	case strings.Contains(output, "No such file or directory"), strings.Contains(output, "No such device"):
+		// the tag never showed up: the device could not be attached
+		return MountErrorConnect
+	}
+	return classifyMountError(output)
+}
+
+// virtiofsdPath returns the path of the virtiofsd binary
+func virtiofsdPath() (string, error) {
+	if p, err := exec.LookPath("virtiofsd"); err == nil {
+		return p, nil
+	}
+	for _, p := range virtiofsdPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", errors.New("virtiofsd was not found, install the virtiofsd package of your distribution")
+}
+
+// virtiofsTag returns the tag of the device, unique as the port of each running mount is
+func virtiofsTag(c *MountConfig) string {
+	return fmt.Sprintf("minikube%d", c.Port)
+}
+
+// virtiofsSocket returns the vhost-user socket virtiofsd listens on
+func virtiofsSocket(c *MountConfig) string {
+	return localpath.MakeMiniPath("machines", c.MachineName, fmt.Sprintf("virtiofs-%d.sock", c.Port))
+}
+
+// attachVirtiofs hot-plugs a vhost-user-fs device connected to the socket in the VM
+func attachVirtiofs(socket string, c *MountConfig) error {
+	if c.KVMQemuURI != "" {
+		return virsh(c, "attach-device", socket)
+	}
+	tag := virtiofsTag(c)
+	monitor := localpath.MakeMiniPath("machines", c.MachineName, "monitor")
+	return qmpExecute(monitor,
+		qmpCommand{Execute: "chardev-add", Arguments: map[string]interface{}{
+			"id": tag + "-char",
+			"backend": map[string]interface{}{
+				"type": "socket",
+				"data": map[string]interface{}{
+					"addr":   map[string]interface{}{"type": "unix", "data": map[string]string{"path": socket}},
+					"server": false,
+				},
+			},
+		}},
+		qmpCommand{Execute: "device_add", Arguments: map[string]interface{}{
+			"driver":  "vhost-user-fs-pci",
+			"id":      tag,
+			"chardev": tag + "-char",
+			"tag":     tag,
+		}},
+	)
+}
+
+// virsh attaches or detaches the virtiofs filesystem of the libvirt domain
+func virsh(c *MountConfig, action string, socket string) error {
+	xml := fmt.Sprintf(`<filesystem type='mount' accessmode='passthrough'>
+  <driver type='virtiofs' queue='1024'/>
+  <source socket='%s'/>
+  <target dir='%s'/>
+</filesystem>`, socket, virtiofsTag(c))
+	f, err := os.CreateTemp("", "virtiofs-*.xml")
+	if err != nil {
+		return errors.Wrap(err, "create device xml")
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(xml); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write device xml")
+	}
+	f.Close()
+
+	cmd := exec.Command("virsh", "--connect", c.KVMQemuURI, action, c.MachineName, f.Name(), "--live")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "%s: %s", cmd.Args, out)
+	}
+	return nil
+}
+
+// qmpCommand is a command sent to the QEMU monitor
+type qmpCommand struct {
+	Execute   string      `json:"execute"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+// qmpResponse is a reply or an asynchronous event of the QEMU monitor
+type qmpResponse struct {
+	Return json.RawMessage `json:"return"`
+	Error  *struct {
+		Class string `json:"class"`
+		Desc  string `json:"desc"`
+	} `json:"error"`
+	Event string `json:"event"`
+}
+
+// qmpExecute runs the commands on the QEMU monitor listening on the unix socket, stopping at the first error
+func qmpExecute(monitor string, cmds ...qmpCommand) error {
+	conn, err := net.DialTimeout("unix", monitor, 5*time.Second)
+	if err != nil {
+		return errors.Wrap(err, "connect to the QEMU monitor")
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	var greeting map[string]interface{}
+	if err := dec.Decode(&greeting); err != nil {
+		return errors.Wrap(err, "read the QMP greeting")
+	}
+	for _, cmd := range append([]qmpCommand{{Execute: "qmp_capabilities"}}, cmds...) {
+		if err := enc.Encode(cmd); err != nil {
+			return errors.Wrapf(err, "send %s", cmd.Execute)
+		}
+		for {
+			var resp qmpResponse
+			if err := dec.Decode(&resp); err != nil {
+				return errors.Wrapf(err, "read the %s reply", cmd.Execute)
+			}
+			if resp.Event != "" {
+				continue
+			}
+			if resp.Error != nil {
+				return errors.Errorf("%s: %s", cmd.Execute, resp.Error.Desc)
+			}
+			break
+		}
+	}
+	return nil
+}
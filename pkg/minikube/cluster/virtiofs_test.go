@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeMonitor answers QMP commands on a unix socket, failing the ones in fail
+func fakeMonitor(t *testing.T, fail map[string]bool) (string, chan string) {
+	t.Helper()
+	monitor := filepath.Join(t.TempDir(), "monitor")
+	l, err := net.Listen("unix", monitor)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	executed := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintln(conn, `{"QMP": {"version": {"qemu": {"major": 8}}, "capabilities": []}}`)
+		dec := json.NewDecoder(conn)
+		for {
+			var cmd qmpCommand
+			if err := dec.Decode(&cmd); err != nil {
+				return
+			}
+			executed <- cmd.Execute
+			// events may come before the reply
+			fmt.Fprintln(conn, `{"event": "DEVICE_DELETED", "data": {}}`)
+			if fail[cmd.Execute] {
+				fmt.Fprintf(conn, `{"error": {"class": "GenericError", "desc": "%s failed"}}`+"\n", cmd.Execute)
+				continue
+			}
+			fmt.Fprintln(conn, `{"return": {}}`)
+		}
+	}()
+	return monitor, executed
+}
+
+func received(c chan string) []string {
+	var got []string
+	for {
+		select {
+		case s := <-c:
+			got = append(got, s)
+		default:
+			return got
+		}
+	}
+}
+
+func TestQMPExecute(t *testing.T) {
+	monitor, executed := fakeMonitor(t, nil)
+	if err := qmpExecute(monitor, qmpCommand{Execute: "chardev-add"}, qmpCommand{Execute: "device_add"}); err != nil {
+		t.Fatalf("qmpExecute: %v", err)
+	}
+	if diff := cmp.Diff([]string{"qmp_capabilities", "chardev-add", "device_add"}, received(executed)); diff != "" {
+		t.Errorf("executed commands (-want +got): %s", diff)
+	}
+
+	monitor, executed = fakeMonitor(t, map[string]bool{"chardev-add": true})
+	err := qmpExecute(monitor, qmpCommand{Execute: "chardev-add"}, qmpCommand{Execute: "device_add"})
+	if err == nil || err.Error() != "chardev-add: chardev-add failed" {
+		t.Errorf("qmpExecute error = %v, want the chardev-add error", err)
+	}
+	if diff := cmp.Diff([]string{"qmp_capabilities", "chardev-add"}, received(executed)); diff != "" {
+		t.Errorf("executed commands (-want +got): %s", diff)
+	}
+}
@@ -46,10 +46,15 @@ var bridgeConf = template.Must(template.New("bridge").Parse(`
       "isDefaultGateway": true,
       "forceAddress": false,
       "ipMasq": true,
-      "hairpinMode": true,
+      "hairpinMode": true,{{ if .MTU }}
+      "mtu": {{.MTU}},{{ end }}
       "ipam": {
-          "type": "host-local",
-          "subnet": "{{.PodCIDR}}"
+          "type": "host-local",{{ if .PodCIDRv6 }}
+          "ranges": [
+              [{ "subnet": "{{.PodCIDR}}" }],
+              [{ "subnet": "{{.PodCIDRv6}}" }]
+          ]{{ else }}
+          "subnet": "{{.PodCIDR}}"{{ end }}
       }
     },
     {
@@ -76,7 +81,8 @@ func (c Bridge) String() string {
 }
 
 func (c Bridge) netconf() (assets.CopyableFile, error) {
-	input := &tmplInput{PodCIDR: DefaultPodCIDR}
+	v4, v6 := podCIDRs(c.cc)
+	input := &tmplInput{PodCIDR: v4, PodCIDRv6: v6, MTU: c.cc.KubernetesConfig.CNIMTU}
 
 	b := bytes.Buffer{}
 	if err := bridgeConf.Execute(&b, input); err != nil {
@@ -110,5 +116,5 @@ func (c Bridge) Apply(r Runner) error {
 
 // CIDR returns the default CIDR used by this CNI
 func (c Bridge) CIDR() string {
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
@@ -47,6 +47,8 @@ type Calico struct {
 
 type calicoTmplStruct struct {
 	PodCIDR                   string
+	PodCIDRv6                 string
+	MTU                       int
 	DeploymentImageName       string
 	DaemonSetImageName        string
 	BinaryImageName           string
@@ -65,8 +67,11 @@ func (c Calico) manifest() (assets.CopyableFile, error) {
 		return nil, fmt.Errorf("failed to parse Kubernetes version: %v", err)
 	}
 
+	v4, v6 := podCIDRs(c.cc)
 	input := &calicoTmplStruct{
-		PodCIDR:                   DefaultPodCIDR,
+		PodCIDR:                   v4,
+		PodCIDRv6:                 v6,
+		MTU:                       c.cc.KubernetesConfig.CNIMTU,
 		DeploymentImageName:       images.CalicoDeployment(c.cc.KubernetesConfig.ImageRepository),
 		DaemonSetImageName:        images.CalicoDaemonSet(c.cc.KubernetesConfig.ImageRepository),
 		BinaryImageName:           images.CalicoBin(c.cc.KubernetesConfig.ImageRepository),
@@ -92,5 +97,5 @@ func (c Calico) Apply(r Runner) error {
 // CIDR returns the default CIDR used by this CNI
 func (c Calico) CIDR() string {
 	// Calico docs specify 192.168.0.0/16 - but we do this for compatibility with other CNI's.
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
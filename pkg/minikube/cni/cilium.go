@@ -69,7 +69,7 @@ data:
   # The agent can be put into the following three policy enforcement modes
   # default, always and never.
   # https://docs.cilium.io/en/latest/policy/intro/#policy-enforcement-modes
-  enable-policy: "default"
+  enable-policy: "{{.PolicyEnforcement }}"
 
   # Enable IPv4 addressing. If enabled, all endpoints are allocated an IPv4
   # address.
@@ -77,7 +77,7 @@ data:
 
   # Enable IPv6 addressing. If enabled, all endpoints are allocated an IPv6
   # address.
-  enable-ipv6: "false"
+  enable-ipv6: "{{if .PodSubnetv6 }}true{{else}}false{{end}}"
   # Users who wish to specify their own custom CNI configuration file must set
   # custom-cni-conf to "true", otherwise Cilium may overwrite the configuration.
   custom-cni-conf: "false"
@@ -140,6 +140,10 @@ data:
   #   - vxlan (default)
   #   - geneve
   tunnel: vxlan
+{{- if .MTU }}
+  # MTU of the pod interfaces, detected from the node interfaces when unset
+  mtu: "{{.MTU }}"
+{{- end }}
   # Enables L7 proxy for L7 policy enforcement and visibility
   enable-l7-proxy: "true"
 
@@ -162,7 +166,7 @@ data:
   enable-auto-protect-node-port-range: "true"
   enable-session-affinity: "true"
   k8s-require-ipv4-pod-cidr: "true"
-  k8s-require-ipv6-pod-cidr: "false"
+  k8s-require-ipv6-pod-cidr: "{{if .PodSubnetv6 }}true{{else}}false{{end}}"
   enable-endpoint-health-checking: "true"
   enable-health-checking: "true"
   enable-well-known-identities: "false"
@@ -181,6 +185,10 @@ data:
   ipam: "cluster-pool"
   cluster-pool-ipv4-cidr: "{{.PodSubnet }}"
   cluster-pool-ipv4-mask-size: "24"
+{{- if .PodSubnetv6 }}
+  cluster-pool-ipv6-cidr: "{{.PodSubnetv6 }}"
+  cluster-pool-ipv6-mask-size: "64"
+{{- end }}
   disable-cnp-status-updates: "true"
   cgroup-root: "/run/cilium/cgroupv2"
 ---
@@ -821,20 +829,29 @@ func (c Cilium) String() string {
 
 // CIDR returns the default CIDR used by this CNI
 func (c Cilium) CIDR() string {
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
 
 // GenerateCiliumYAML generates the .yaml file
-func GenerateCiliumYAML() ([]byte, error) {
+func GenerateCiliumYAML(cc config.ClusterConfig) ([]byte, error) {
 
-	podCIDR := DefaultPodCIDR
+	podCIDR, podCIDRv6 := podCIDRs(cc)
 
-	klog.Infof("Using pod CIDR: %s", podCIDR)
+	klog.Infof("Using pod CIDR: %s", PodCIDR(cc))
 
 	opts := struct {
-		PodSubnet string
+		PodSubnet         string
+		PodSubnetv6       string
+		MTU               int
+		PolicyEnforcement string
 	}{
-		PodSubnet: podCIDR,
+		PodSubnet:         podCIDR,
+		PodSubnetv6:       podCIDRv6,
+		MTU:               cc.KubernetesConfig.CNIMTU,
+		PolicyEnforcement: "default",
+	}
+	if cc.KubernetesConfig.DisableNetworkPolicy {
+		opts.PolicyEnforcement = "never"
 	}
 
 	b := bytes.Buffer{}
@@ -855,7 +872,7 @@ func (c Cilium) Apply(r Runner) error {
 		return errors.Wrap(err, "bpf mount")
 	}
 
-	ciliumCfg, err := GenerateCiliumYAML()
+	ciliumCfg, err := GenerateCiliumYAML(c.cc)
 	if err != nil {
 		return errors.Wrap(err, "generating cilium cfg")
 	}
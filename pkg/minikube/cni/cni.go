@@ -70,8 +70,10 @@ type Manager interface {
 type tmplInput struct {
 	ImageName    string
 	PodCIDR      string
+	PodCIDRv6    string
 	DefaultRoute string
 	CNIConfDir   string
+	MTU          int
 }
 
 // New returns a new CNI manager
@@ -127,7 +129,7 @@ func chooseDefault(cc config.ClusterConfig) Manager {
 	// For backwards compatibility with older profiles using --enable-default-cni
 	if cc.KubernetesConfig.EnableDefaultCNI {
 		klog.Infof("EnableDefaultCNI is true, recommending bridge")
-		return Bridge{}
+		return Bridge{cc: cc}
 	}
 
 	if len(cc.Nodes) > 1 || cc.MultiNodeRequested {
@@ -52,3 +52,68 @@ func TestChooseDefault(t *testing.T) {
 		}
 	}
 }
+
+func TestImages(t *testing.T) {
+	tests := []struct {
+		cni  string
+		want int
+	}{
+		{"bridge", 0},
+		{"false", 0},
+		{"kindnet", 1},
+		{"calico", 3},
+		{"flannel", 2},
+		{"cilium", 3},
+	}
+	for _, tc := range tests {
+		cc := config.ClusterConfig{
+			Driver: "docker",
+			KubernetesConfig: config.KubernetesConfig{
+				ContainerRuntime:  "containerd",
+				KubernetesVersion: "v1.30.0",
+				CNI:               tc.cni,
+			},
+		}
+		got, err := Images(cc)
+		if err != nil {
+			t.Fatalf("Images(%s): %v", tc.cni, err)
+		}
+		if len(got) != tc.want {
+			t.Errorf("Images(%s) = %v; want %d images", tc.cni, got, tc.want)
+		}
+	}
+}
+
+func TestSame(t *testing.T) {
+	cc := config.ClusterConfig{
+		Driver: "docker",
+		KubernetesConfig: config.KubernetesConfig{
+			ContainerRuntime:  "containerd",
+			KubernetesVersion: "v1.30.0",
+		},
+	}
+	cnm := func(name string) Manager {
+		c := cc
+		c.KubernetesConfig.CNI = name
+		m, err := New(&c)
+		if err != nil {
+			t.Fatalf("New(%s): %v", name, err)
+		}
+		return m
+	}
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		// auto resolves to kindnet with the docker driver and containerd
+		{"auto", "kindnet", true},
+		{"kindnet", "calico", false},
+		{"cilium", "cilium", true},
+		{"bridge", "false", false},
+	}
+	for _, tc := range tests {
+		if got := Same(cnm(tc.a), cnm(tc.b)); got != tc.want {
+			t.Errorf("Same(%s, %s) = %t; want = %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
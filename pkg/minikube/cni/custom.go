@@ -67,5 +67,5 @@ func (c Custom) Apply(r Runner) error {
 
 // CIDR returns the default CIDR used by this CNI
 func (c Custom) CIDR() string {
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
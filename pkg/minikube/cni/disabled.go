@@ -49,5 +49,5 @@ func (c Disabled) Apply(_ Runner) error {
 // CIDR returns the default CIDR used by this CNI
 func (c Disabled) CIDR() string {
 	// Even without any CNI we want our nodes to have spec.PodCIDR set.
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
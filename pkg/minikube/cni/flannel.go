@@ -36,7 +36,9 @@ var flannelYaml string
 var flannelTmpl = template.Must(template.New("flannel").Parse(flannelYaml))
 
 type flannelTmplStruct struct {
-	PodCIDR string
+	PodCIDR   string
+	PodCIDRv6 string
+	MTU       int
 }
 
 // Flannel is the Flannel CNI manager
@@ -66,8 +68,11 @@ func (c Flannel) Apply(r Runner) error {
 
 // manifest returns a Kubernetes manifest for a CNI
 func (c Flannel) manifest() (assets.CopyableFile, error) {
+	v4, v6 := podCIDRs(c.cc)
 	input := &flannelTmplStruct{
-		PodCIDR: DefaultPodCIDR,
+		PodCIDR:   v4,
+		PodCIDRv6: v6,
+		MTU:       c.cc.KubernetesConfig.CNIMTU,
 	}
 	b := bytes.Buffer{}
 	if err := flannelTmpl.Execute(&b, input); err != nil {
@@ -78,5 +83,5 @@ func (c Flannel) manifest() (assets.CopyableFile, error) {
 
 // CIDR returns the default CIDR used by this CNI
 func (c Flannel) CIDR() string {
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
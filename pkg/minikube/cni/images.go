@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// imageRegexp matches the images of a manifest
+var imageRegexp = regexp.MustCompile(`(?m)^\s*-?\s*image:\s*["']?([^"'\s]+)`)
+
+// manifester is a CNI applying a manifest
+type manifester interface {
+	manifest() (assets.CopyableFile, error)
+}
+
+// manifestBytes returns the manifest of the CNI, nil for the CNIs without one like bridge and disabled
+func manifestBytes(cnm Manager) ([]byte, error) {
+	switch c := cnm.(type) {
+	case manifester:
+		f, err := c.manifest()
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s manifest", c)
+		}
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s manifest", c)
+		}
+		return b, nil
+	case Cilium:
+		b, err := GenerateCiliumYAML(c.cc)
+		if err != nil {
+			return nil, errors.Wrap(err, "cilium manifest")
+		}
+		return b, nil
+	case Custom:
+		b, err := os.ReadFile(c.manifest)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading custom CNI manifest")
+		}
+		return b, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Images returns the images of the CNI of the cluster, read from its manifest
+func Images(cc config.ClusterConfig) ([]string, error) {
+	cnm, err := New(&cc)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := manifestBytes(cnm)
+	if err != nil {
+		return nil, err
+	}
+
+	var imgs []string
+	seen := map[string]bool{}
+	for _, m := range imageRegexp.FindAllSubmatch(b, -1) {
+		img := string(m[1])
+		if !seen[img] {
+			seen[img] = true
+			imgs = append(imgs, img)
+		}
+	}
+	return imgs, nil
+}
@@ -163,8 +163,8 @@ func (c KindNet) String() string {
 // manifest returns a Kubernetes manifest for a CNI
 func (c KindNet) manifest() (assets.CopyableFile, error) {
 	input := &tmplInput{
-		DefaultRoute: "0.0.0.0/0", // assumes IPv4
-		PodCIDR:      DefaultPodCIDR,
+		DefaultRoute: "0.0.0.0/0",   // assumes IPv4
+		PodCIDR:      PodCIDR(c.cc), // kindnet takes the comma separated CIDRs of a dual-stack cluster
 		ImageName:    images.KindNet(c.cc.KubernetesConfig.ImageRepository),
 		CNIConfDir:   DefaultConfDir,
 	}
@@ -192,5 +192,5 @@ func (c KindNet) Apply(r Runner) error {
 
 // CIDR returns the default CIDR used by this CNI
 func (c KindNet) CIDR() string {
-	return DefaultPodCIDR
+	return PodCIDR(c.cc)
 }
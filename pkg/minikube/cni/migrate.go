@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// links are the network interfaces left on the nodes by the CNIs
+var links = []string{"cni0", "flannel.1", "vxlan.calico", "cilium_vxlan", "cilium_host", "cilium_net"}
+
+// Same returns whether both CNI managers deploy the same CNI
+func Same(a Manager, b Manager) bool {
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b) && a.String() == b.String()
+}
+
+// Remove deletes the cluster resources of the CNI, the provided runner being for the control plane
+func Remove(cc config.ClusterConfig, cnm Manager, r Runner) error {
+	b, err := manifestBytes(cnm)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		klog.Infof("%s has no cluster resources to delete", cnm)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	if err := r.Copy(manifestAsset(b)); err != nil {
+		return errors.Wrap(err, "copy")
+	}
+	cmd := exec.CommandContext(ctx, "sudo", kubectl, "delete", "--ignore-not-found", fmt.Sprintf("--kubeconfig=%s", path.Join(vmpath.GuestPersistentDir, "kubeconfig")), "-f", manifestPath())
+	if rr, err := r.RunCmd(cmd); err != nil {
+		return errors.Wrapf(err, "cmd: %s output: %s", rr.Command(), rr.Output())
+	}
+	return nil
+}
+
+// DisableConfigs disables the CNI configs of a node (designated by runner) but the loopback one, by changing their
+// extension to "mk_disabled" like disableAllBridgeCNIs, and deletes the network interfaces of the CNIs, for the next
+// CNI not to conflict with them.
+// It is caller's responsibility to restart container runtime for these changes to take effect.
+func DisableConfigs(r Runner) error {
+	out, err := r.RunCmd(exec.Command(
+		"sudo", "find", DefaultConfDir, "-maxdepth", "1", "-type", "f", "-not", "-name", "*loopback*", "-not", "-name", "*.mk_disabled", "-printf", "%p, ", "-exec", "sh", "-c",
+		`sudo mv {} {}.mk_disabled`, ";"))
+	if err != nil {
+		return fmt.Errorf("failed to disable cni configs in %q: %v", DefaultConfDir, err)
+	}
+	klog.Infof("disabled [%s] cni config(s)", strings.Trim(out.Stdout.String(), ", "))
+
+	for _, l := range links {
+		if _, err := r.RunCmd(exec.Command("sudo", "sh", "-c", fmt.Sprintf("! ip link show %[1]s >/dev/null 2>&1 || ip link delete %[1]s", l))); err != nil {
+			klog.Warningf("unable to delete %s link: %v", l, err)
+		}
+	}
+	return nil
+}
+
+// RestartRuntime restarts the services of the node (designated by runner) reading the CNI configs: the container
+// runtime, cri-dockerd for docker, and the kubelet
+func RestartRuntime(cc config.ClusterConfig, r Runner) error {
+	svc := cc.KubernetesConfig.ContainerRuntime
+	if svc == constants.Docker {
+		svc = "cri-docker"
+	}
+	sm := sysinit.New(r)
+	for _, s := range []string{svc, "kubelet"} {
+		if err := sm.Restart(s); err != nil {
+			return errors.Wrapf(err, "restart %s", s)
+		}
+	}
+	return nil
+}
+
+// RestartPods deletes the pods not running on the host network, for their controllers to recreate them with an
+// address of the new CNI, the provided runner being for the control plane
+func RestartPods(cc config.ClusterConfig, r Runner) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	cmd := exec.CommandContext(ctx, "sudo", kubectl, "delete", "pods", "--all-namespaces", "--field-selector=spec.hostNetwork=false", "--wait=false",
+		fmt.Sprintf("--kubeconfig=%s", path.Join(vmpath.GuestPersistentDir, "kubeconfig")))
+	if rr, err := r.RunCmd(cmd); err != nil {
+		return errors.Wrapf(err, "cmd: %s output: %s", rr.Command(), rr.Output())
+	}
+	return nil
+}
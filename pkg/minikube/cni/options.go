@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// IPv4 gives an IPv4 address to the pods and services
+	IPv4 = "ipv4"
+	// DualStack gives both an IPv4 and an IPv6 address to the pods and services
+	DualStack = "dual"
+
+	// DefaultPodCIDRv6 is the default IPv6 CIDR of the pods, leaving a /64 to each of 256 nodes
+	DefaultPodCIDRv6 = "fd00:10:244::/56"
+
+	// minMTU is the lowest MTU of an IPv6 link, also used for IPv4 for simplicity
+	minMTU = 1280
+	// maxMTU is the MTU of the jumbo frames
+	maxMTU = 9000
+)
+
+// IPFamilies are the supported IP families of the pods and services
+var IPFamilies = []string{IPv4, DualStack}
+
+// PodCIDR returns the CIDR of the pods, the IPv4 and the IPv6 CIDRs separated by a comma for the dual-stack clusters
+func PodCIDR(cc config.ClusterConfig) string {
+	k := cc.KubernetesConfig
+	if k.PodCIDR != "" {
+		return k.PodCIDR
+	}
+	if IPFamily(cc) == DualStack {
+		return DefaultPodCIDR + "," + DefaultPodCIDRv6
+	}
+	return DefaultPodCIDR
+}
+
+// IPFamily returns the IP family of the cluster, IPv4 for the clusters created before it could be chosen
+func IPFamily(cc config.ClusterConfig) string {
+	if cc.KubernetesConfig.IPFamily == "" {
+		return IPv4
+	}
+	return cc.KubernetesConfig.IPFamily
+}
+
+// podCIDRs returns the IPv4 and the IPv6 CIDRs of the pods, empty when the cluster has no address of the family
+func podCIDRs(cc config.ClusterConfig) (v4 string, v6 string) {
+	for _, cidr := range strings.Split(PodCIDR(cc), ",") {
+		cidr = strings.TrimSpace(cidr)
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			v4 = cidr
+		} else {
+			v6 = cidr
+		}
+	}
+	return v4, v6
+}
+
+// ValidatePodCIDR checks the CIDR of the pods has a CIDR for each IP family of the cluster
+func ValidatePodCIDR(cidr string, family string) error {
+	return validateCIDR("pod", cidr, family)
+}
+
+// ValidateServiceCIDR checks the CIDR of the services has a CIDR for each IP family of the cluster
+func ValidateServiceCIDR(cidr string, family string) error {
+	return validateCIDR("service", cidr, family)
+}
+
+// validateCIDR checks the comma separated CIDRs of the kind of addresses have a CIDR for each IP family of the cluster
+func validateCIDR(kind string, cidr string, family string) error {
+	var v4, v6 int
+	for _, c := range strings.Split(cidr, ",") {
+		ip, _, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return fmt.Errorf("invalid %s CIDR %q: %v", kind, c, err)
+		}
+		if ip.To4() != nil {
+			v4++
+		} else {
+			v6++
+		}
+	}
+	if family == DualStack {
+		if v4 != 1 || v6 != 1 {
+			return fmt.Errorf("a dual-stack cluster needs an IPv4 and an IPv6 %s CIDR separated by a comma, not %q", kind, cidr)
+		}
+		return nil
+	}
+	if v4 != 1 || v6 != 0 {
+		return fmt.Errorf("an IPv4 cluster needs a single IPv4 %s CIDR, not %q", kind, cidr)
+	}
+	return nil
+}
+
+// ValidateMTU checks the MTU of the pod interfaces, 0 letting the CNI choose it
+func ValidateMTU(mtu int) error {
+	if mtu != 0 && (mtu < minMTU || mtu > maxMTU) {
+		return fmt.Errorf("the CNI MTU must be between %d and %d, not %d", minMTU, maxMTU, mtu)
+	}
+	return nil
+}
+
+// SupportsMTU returns whether the MTU of the pod interfaces can be set for the CNI
+func SupportsMTU(cnm Manager) bool {
+	switch cnm.(type) {
+	case Bridge, Flannel, Calico, Cilium:
+		return true
+	}
+	// kindnet uses the MTU of the node interface
+	return false
+}
+
+// EnforcesNetworkPolicy returns whether the CNI enforces the network policies of the cluster
+func EnforcesNetworkPolicy(cnm Manager) bool {
+	switch c := cnm.(type) {
+	case Calico:
+		// calico always enforces them
+		return true
+	case Cilium:
+		return !c.cc.KubernetesConfig.DisableNetworkPolicy
+	}
+	return false
+}
+
+// EnableIPv6Forwarding enables the forwarding of the IPv6 packets of the pods on a node of a dual-stack cluster, checked by kubeadm
+func EnableIPv6Forwarding(r Runner) error {
+	if _, err := r.RunCmd(exec.Command("sudo", "sysctl", "-w", "net.ipv6.conf.all.forwarding=1", "net.ipv6.conf.default.forwarding=1")); err != nil {
+		return errors.Wrap(err, "ipv6 forwarding")
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestPodCIDR(t *testing.T) {
+	tests := []struct {
+		family string
+		cidr   string
+		want   string
+		v4     string
+		v6     string
+	}{
+		{"", "", "10.244.0.0/16", "10.244.0.0/16", ""},
+		{IPv4, "10.10.0.0/16", "10.10.0.0/16", "10.10.0.0/16", ""},
+		{DualStack, "", "10.244.0.0/16,fd00:10:244::/56", "10.244.0.0/16", "fd00:10:244::/56"},
+		{DualStack, "10.10.0.0/16,fd00:10::/56", "10.10.0.0/16,fd00:10::/56", "10.10.0.0/16", "fd00:10::/56"},
+	}
+	for _, tc := range tests {
+		cc := config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{IPFamily: tc.family, PodCIDR: tc.cidr}}
+		if got := PodCIDR(cc); got != tc.want {
+			t.Errorf("PodCIDR(%q, %q) = %q, want %q", tc.family, tc.cidr, got, tc.want)
+		}
+		if v4, v6 := podCIDRs(cc); v4 != tc.v4 || v6 != tc.v6 {
+			t.Errorf("podCIDRs(%q, %q) = %q, %q, want %q, %q", tc.family, tc.cidr, v4, v6, tc.v4, tc.v6)
+		}
+	}
+}
+
+func TestValidatePodCIDR(t *testing.T) {
+	tests := []struct {
+		cidr   string
+		family string
+		err    bool
+	}{
+		{"10.10.0.0/16", IPv4, false},
+		{"10.10.0.0/16", "", false},
+		{"10.10.0.0/16,fd00:10::/56", DualStack, false},
+		{"fd00:10::/56", DualStack, true},
+		{"fd00:10::/56", IPv4, true},
+		{"10.10.0.0/16", DualStack, true},
+		{"10.10.0.0/16,10.11.0.0/16", DualStack, true},
+		{"10.10.0.0", IPv4, true},
+	}
+	for _, tc := range tests {
+		if err := ValidatePodCIDR(tc.cidr, tc.family); (err != nil) != tc.err {
+			t.Errorf("ValidatePodCIDR(%q, %q) error = %v, want error: %t", tc.cidr, tc.family, err, tc.err)
+		}
+	}
+}
+
+func TestValidateServiceCIDR(t *testing.T) {
+	tests := []struct {
+		cidr   string
+		family string
+		err    bool
+	}{
+		{"10.96.0.0/12", IPv4, false},
+		{"10.96.0.0/12,fd00:10:96::/112", DualStack, false},
+		{"10.96.0.0/12", DualStack, true},
+		{"10.96.0.0/12,fd00:10:96::/112", IPv4, true},
+		{"fd00:10:96::/112", IPv4, true},
+		{"10.96.0.0", IPv4, true},
+	}
+	for _, tc := range tests {
+		err := ValidateServiceCIDR(tc.cidr, tc.family)
+		if (err != nil) != tc.err {
+			t.Errorf("ValidateServiceCIDR(%q, %q) error = %v, want error: %t", tc.cidr, tc.family, err, tc.err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "service CIDR") {
+			t.Errorf("ValidateServiceCIDR(%q, %q) error = %v, want it to name the service CIDR", tc.cidr, tc.family, err)
+		}
+	}
+}
+
+func TestDualStackManifests(t *testing.T) {
+	cc := config.ClusterConfig{
+		Driver: "docker",
+		KubernetesConfig: config.KubernetesConfig{
+			ContainerRuntime:     "containerd",
+			KubernetesVersion:    "v1.30.0",
+			IPFamily:             DualStack,
+			CNIMTU:               1400,
+			DisableNetworkPolicy: true,
+		},
+	}
+	tests := []struct {
+		cnm  Manager
+		want []string
+	}{
+		{Flannel{cc: cc}, []string{`"IPv6Network": "fd00:10:244::/56"`, `"mtu": 1400`}},
+		{Calico{cc: cc}, []string{`value: "fd00:10:244::/56"`, `veth_mtu: "1400"`, `"assign_ipv6": "true"`}},
+		{Cilium{cc: cc}, []string{`cluster-pool-ipv6-cidr: "fd00:10:244::/56"`, `enable-ipv6: "true"`, `mtu: "1400"`, `enable-policy: "never"`}},
+		{KindNet{cc: cc}, []string{"value: 10.244.0.0/16,fd00:10:244::/56"}},
+	}
+	for _, tc := range tests {
+		b, err := manifestBytes(tc.cnm)
+		if err != nil {
+			t.Fatalf("%s manifest: %v", tc.cnm, err)
+		}
+		for _, w := range tc.want {
+			if !strings.Contains(string(b), w) {
+				t.Errorf("%s manifest does not contain %s", tc.cnm, w)
+			}
+		}
+	}
+
+	f, err := Bridge{cc: cc}.netconf()
+	if err != nil {
+		t.Fatalf("bridge netconf: %v", err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("reading bridge netconf: %v", err)
+	}
+	var conf struct {
+		Plugins []struct {
+			MTU  int `json:"mtu"`
+			IPAM struct {
+				Ranges [][]struct {
+					Subnet string `json:"subnet"`
+				} `json:"ranges"`
+			} `json:"ipam"`
+		} `json:"plugins"`
+	}
+	if err := json.Unmarshal(b, &conf); err != nil {
+		t.Fatalf("bridge netconf is not valid JSON: %v\n%s", err, b)
+	}
+	if p := conf.Plugins[0]; p.MTU != 1400 || len(p.IPAM.Ranges) != 2 || p.IPAM.Ranges[1][0].Subnet != "fd00:10:244::/56" {
+		t.Errorf("bridge netconf = %s", b)
+	}
+}
+
+func TestEnforcesNetworkPolicy(t *testing.T) {
+	cc := config.ClusterConfig{}
+	disabled := config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{DisableNetworkPolicy: true}}
+	tests := []struct {
+		cnm  Manager
+		want bool
+	}{
+		{Calico{cc: cc}, true},
+		{Calico{cc: disabled}, true},
+		{Cilium{cc: cc}, true},
+		{Cilium{cc: disabled}, false},
+		{Flannel{cc: cc}, false},
+		{KindNet{cc: cc}, false},
+		{Bridge{cc: cc}, false},
+	}
+	for i, tc := range tests {
+		if got := EnforcesNetworkPolicy(tc.cnm); got != tc.want {
+			t.Errorf("%d: EnforcesNetworkPolicy(%s) = %t, want %t", i, tc.cnm, got, tc.want)
+		}
+	}
+}
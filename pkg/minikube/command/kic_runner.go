@@ -26,6 +26,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -51,7 +52,8 @@ func NewKICRunner(containerNameOrID string, oci string) Runner {
 	}
 }
 
-func (k *kicRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
+// execCmd returns the oci command that runs cmd inside the container
+func (k *kicRunner) execCmd(cmd *exec.Cmd, allowTTY bool) *exec.Cmd {
 	args := []string{
 		"exec",
 		// run with privileges so we can remount etc..
@@ -63,7 +65,7 @@ func (k *kicRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
 		)
 	}
 	// if the command is hooked to another processes's output we want a tty
-	if isTerminal(cmd.Stderr) || isTerminal(cmd.Stdout) {
+	if allowTTY && (isTerminal(cmd.Stderr) || isTerminal(cmd.Stdout)) {
 		args = append(args,
 			"-t",
 		)
@@ -88,6 +90,11 @@ func (k *kicRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
 	oc.Stdout = cmd.Stdout
 	oc.Stderr = cmd.Stderr
 	oc.Env = cmd.Env
+	return oc
+}
+
+func (k *kicRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
+	oc := k.execCmd(cmd, true)
 
 	rr := &RunResult{Args: cmd.Args}
 	klog.Infof("Run: %v", rr.Command())
@@ -131,16 +138,193 @@ func (k *kicRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
 
 }
 
-func (k *kicRunner) StartCmd(_ *exec.Cmd) (*StartedCmd, error) {
-	return nil, fmt.Errorf("kicRunner does not support StartCmd - you could be the first to add it")
+// StartCmd implements the Command Runner interface to start a exec.Cmd object
+func (k *kicRunner) StartCmd(cmd *exec.Cmd) (*StartedCmd, error) {
+	var wg sync.WaitGroup
+	rr := &RunResult{Args: cmd.Args}
+	sc := &StartedCmd{rr: rr, wg: &wg}
+	klog.Infof("Start: %v", rr.Command())
+
+	var outb, errb io.Writer
+	if cmd.Stdout == nil {
+		var so bytes.Buffer
+		outb = io.MultiWriter(&so, &rr.Stdout)
+	} else {
+		outb = io.MultiWriter(cmd.Stdout, &rr.Stdout)
+	}
+
+	if cmd.Stderr == nil {
+		var se bytes.Buffer
+		errb = io.MultiWriter(&se, &rr.Stderr)
+	} else {
+		errb = io.MultiWriter(cmd.Stderr, &rr.Stderr)
+	}
+
+	// output is streamed through pipes, so never allocate a tty here
+	oc := oci.PrefixCmd(k.execCmd(cmd, false))
+	oc.Stdout = nil
+	oc.Stderr = nil
+	klog.Infof("Args: %v", oc.Args)
+
+	outPipe, err := oc.StdoutPipe()
+	if err != nil {
+		return sc, errors.Wrap(err, "stdout")
+	}
+
+	errPipe, err := oc.StderrPipe()
+	if err != nil {
+		return sc, errors.Wrap(err, "stderr")
+	}
+
+	if err := oc.Start(); err != nil {
+		return sc, errors.Wrap(err, "start")
+	}
+	sc.cmd = oc
+
+	wg.Add(2)
+	go func() {
+		if err := teePrefix(ErrPrefix, errPipe, errb, klog.V(8).Infof); err != nil {
+			klog.Errorf("tee stderr: %v", err)
+		}
+		wg.Done()
+	}()
+	go func() {
+		if err := teePrefix(OutPrefix, outPipe, outb, klog.V(8).Infof); err != nil {
+			klog.Errorf("tee stdout: %v", err)
+		}
+		wg.Done()
+	}()
+
+	return sc, nil
+}
+
+// WaitCmd implements the Command Runner interface to wait until a started exec.Cmd object finishes
+func (k *kicRunner) WaitCmd(sc *StartedCmd) (*RunResult, error) {
+	if sc == nil || sc.cmd == nil {
+		return nil, fmt.Errorf("there is no %s command started", k.ociBin)
+	}
+
+	rr := sc.rr
+
+	// the pipes must be drained before calling Wait, which closes them
+	sc.wg.Wait()
+	err := sc.cmd.Wait()
+	if exitError, ok := err.(*exec.ExitError); ok {
+		rr.ExitCode = exitError.ExitCode()
+	}
+
+	if err == nil {
+		return rr, nil
+	}
+
+	return rr, fmt.Errorf("%s: %v\nstdout:\n%s\nstderr:\n%s", rr.Command(), err, rr.Stdout.String(), rr.Stderr.String())
+}
+
+// kicReadableFile streams a file out of the container via `exec cat`
+type kicReadableFile struct {
+	length      int
+	sourcePath  string
+	permissions string
+	modTime     time.Time
+	cmd         *exec.Cmd
+	reader      io.ReadCloser
+}
+
+// GetLength returns length of file
+func (k *kicReadableFile) GetLength() int {
+	return k.length
+}
+
+// GetSourcePath returns asset name
+func (k *kicReadableFile) GetSourcePath() string {
+	return k.sourcePath
+}
+
+// GetPermissions returns permissions
+func (k *kicReadableFile) GetPermissions() string {
+	return k.permissions
 }
 
-func (k *kicRunner) WaitCmd(_ *StartedCmd) (*RunResult, error) {
-	return nil, fmt.Errorf("kicRunner does not support WaitCmd - you could be the first to add it")
+func (k *kicReadableFile) GetModTime() (time.Time, error) {
+	return k.modTime, nil
 }
 
-func (k *kicRunner) ReadableFile(_ string) (assets.ReadableFile, error) {
-	return nil, fmt.Errorf("kicRunner does not support ReadableFile - you could be the first to add it")
+func (k *kicReadableFile) Read(p []byte) (int, error) {
+	if k.GetLength() == 0 {
+		return 0, fmt.Errorf("attempted read from a 0 length asset")
+	}
+	return k.reader.Read(p)
+}
+
+func (k *kicReadableFile) Seek(_ int64, _ int) (int64, error) {
+	return 0, fmt.Errorf("Seek is not implemented for kicReadableFile")
+}
+
+// Close stops the reading process, whether or not the file was read to the end
+func (k *kicReadableFile) Close() error {
+	if err := k.reader.Close(); err != nil {
+		klog.Warningf("close %s: %v", k.sourcePath, err)
+	}
+	if k.cmd.ProcessState == nil {
+		if err := k.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			klog.Warningf("kill %v: %v", k.cmd.Args, err)
+		}
+	}
+	// the process was killed above if the file was not read to the end, so ignore the exit status
+	_ = k.cmd.Wait()
+	return nil
+}
+
+// ReadableFile returns assets.ReadableFile for the sourcePath (via `stat` command)
+func (k *kicRunner) ReadableFile(sourcePath string) (assets.ReadableFile, error) {
+	klog.V(4).Infof("NewkicReadableFile: %s", sourcePath)
+
+	if !strings.HasPrefix(sourcePath, "/") {
+		return nil, fmt.Errorf("sourcePath must be an absolute Path. Relative Path is not allowed")
+	}
+
+	// get file size and modtime of the destination
+	rr, err := k.RunCmd(exec.Command("stat", "-c", "%#a %s %y", sourcePath))
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := strings.TrimSpace(rr.Stdout.String())
+	outputs := strings.SplitN(stdout, " ", 3)
+	if len(outputs) != 3 {
+		return nil, fmt.Errorf("unexpected stat output for %s: %q", sourcePath, stdout)
+	}
+
+	permission := outputs[0]
+	size, err := strconv.Atoi(outputs[1])
+	if err != nil {
+		return nil, err
+	}
+
+	modTime, err := time.Parse(layout, outputs[2])
+	if err != nil {
+		return nil, err
+	}
+
+	oc := oci.PrefixCmd(k.execCmd(exec.Command("cat", sourcePath), false))
+	r, err := oc.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "StdoutPipe")
+	}
+
+	klog.Infof("Start: %v", oc.Args)
+	if err := oc.Start(); err != nil {
+		return nil, err
+	}
+
+	return &kicReadableFile{
+		length:      size,
+		sourcePath:  sourcePath,
+		permissions: permission,
+		modTime:     modTime,
+		cmd:         oc,
+		reader:      r,
+	}, nil
 }
 
 // Copy copies a file and its permissions
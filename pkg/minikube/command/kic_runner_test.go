@@ -17,7 +17,13 @@ limitations under the License.
 package command
 
 import (
+	"bytes"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -55,3 +61,91 @@ func TestKICRunner(t *testing.T) {
 		}
 	})
 }
+
+// fakeOCIBinary writes a script that behaves like `docker exec` against a
+// container named "minikube", running the command on the host instead.
+func fakeOCIBinary(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake oci binary requires a POSIX shell")
+	}
+	script := `#!/bin/sh
+while [ "$1" != "minikube" ]; do shift; done
+shift
+exec "$@"
+`
+	p := filepath.Join(t.TempDir(), "fake-oci")
+	if err := os.WriteFile(p, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake oci binary: %v", err)
+	}
+	return p
+}
+
+func TestKICRunnerStartCmd(t *testing.T) {
+	k := NewKICRunner("minikube", fakeOCIBinary(t))
+
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", "echo hello; echo oops >&2; exit 3")
+	cmd.Stdout = &out
+	sc, err := k.StartCmd(cmd)
+	if err != nil {
+		t.Fatalf("StartCmd: %v", err)
+	}
+	rr, err := k.WaitCmd(sc)
+	if err == nil {
+		t.Fatalf("WaitCmd: expected an error for a non-zero exit")
+	}
+	if rr.ExitCode != 3 {
+		t.Errorf("ExitCode = %d; want 3", rr.ExitCode)
+	}
+	if got := rr.Stdout.String(); got != "hello\n" {
+		t.Errorf("Stdout = %q; want %q", got, "hello\n")
+	}
+	if got := rr.Stderr.String(); got != "oops\n" {
+		t.Errorf("Stderr = %q; want %q", got, "oops\n")
+	}
+	if got := out.String(); got != "hello\n" {
+		t.Errorf("cmd.Stdout = %q; want %q", got, "hello\n")
+	}
+}
+
+func TestKICRunnerWaitCmdNotStarted(t *testing.T) {
+	k := NewKICRunner("minikube", "docker")
+	if _, err := k.WaitCmd(&StartedCmd{}); err == nil {
+		t.Errorf("WaitCmd: expected an error when no command was started")
+	}
+}
+
+func TestKICRunnerReadableFile(t *testing.T) {
+	k := NewKICRunner("minikube", fakeOCIBinary(t))
+
+	content := strings.Repeat("minikube\n", 1024)
+	src := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(src, []byte(content), 0o640); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+
+	if _, err := k.ReadableFile("relative/path"); err == nil {
+		t.Errorf("ReadableFile: expected an error for a relative path")
+	}
+
+	f, err := k.ReadableFile(src)
+	if err != nil {
+		t.Fatalf("ReadableFile: %v", err)
+	}
+	defer f.Close()
+
+	if f.GetLength() != len(content) {
+		t.Errorf("GetLength = %d; want %d", f.GetLength(), len(content))
+	}
+	if f.GetPermissions() != "0640" {
+		t.Errorf("GetPermissions = %s; want 0640", f.GetPermissions())
+	}
+	got, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("content mismatch: got %d bytes, want %d", len(got), len(content))
+	}
+}
@@ -58,6 +58,10 @@ const (
 	EmbedCerts = "EmbedCerts"
 	// MaxAuditEntries is the maximum number of audit entries to retain
 	MaxAuditEntries = "MaxAuditEntries"
+	// MaxAuditSize is the maximum size of the audit log before its oldest entries are rotated into an archive
+	MaxAuditSize = "MaxAuditSize"
+	// MaxAuditArchives is the maximum number of rotated audit log archives to retain
+	MaxAuditArchives = "MaxAuditArchives"
 )
 
 var (
@@ -252,5 +256,9 @@ func IsHA(cc ClusterConfig) bool {
 	if len(ControlPlanes(cc)) > 1 {
 		return true
 	}
+	// a cluster converted to HA has a VIP before its other control-plane nodes join
+	if cc.KubernetesConfig.APIServerHAVIP != "" {
+		return true
+	}
 	return viper.GetBool("ha")
 }
@@ -241,3 +241,21 @@ func TestMachineName(t *testing.T) {
 		}
 	}
 }
+
+func TestIsHA(t *testing.T) {
+	cp := Node{Name: "", ControlPlane: true, Worker: true}
+	tests := []struct {
+		name string
+		cc   ClusterConfig
+		want bool
+	}{
+		{"single control plane", ClusterConfig{Nodes: []Node{cp}}, false},
+		{"converting to HA", ClusterConfig{Nodes: []Node{cp}, KubernetesConfig: KubernetesConfig{APIServerHAVIP: "192.168.49.254"}}, true},
+		{"two control planes", ClusterConfig{Nodes: []Node{cp, {Name: "m02", ControlPlane: true, Worker: true}}}, true},
+	}
+	for _, tc := range tests {
+		if got := IsHA(tc.cc); got != tc.want {
+			t.Errorf("%s: IsHA() = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
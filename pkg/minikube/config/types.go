@@ -49,6 +49,7 @@ type ClusterConfig struct {
 	ContainerVolumeMounts   []string // Only used by container drivers: Docker, Podman
 	InsecureRegistry        []string
 	RegistryMirror          []string
+	RegistryCache           string // Empty, "host" or "container"
 	HostOnlyCIDR            string // Only used by the virtualbox driver
 	HypervVirtualSwitch     string
 	HypervUseExternalSwitch bool
@@ -109,6 +110,9 @@ type ClusterConfig struct {
 	SSHAgentPID             int
 	GPUs                    string
 	AutoPauseInterval       time.Duration // Specifies interval of time to wait before checking if cluster should be paused
+	AutoPauseGracePeriod    time.Duration // Minimum time the cluster keeps running after it was unpaused
+	AutoPauseNamespaces     []string      // Namespaces whose containers are paused, kube-system if empty
+	AutoPausePort           int           // Port the auto-pause server listens on, on all the interfaces of the node, 8080 if 0
 }
 
 // KubernetesConfig contains the parameters used to configure the VM Kubernetes.
@@ -126,6 +130,8 @@ type KubernetesConfig struct {
 	NetworkPlugin       string
 	FeatureGates        string // https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
 	ServiceCIDR         string // the subnet which Kubernetes services will be deployed to
+	PodCIDR             string // the subnet of the pods, the IPv4 and IPv6 subnets separated by a comma for dual-stack
+	IPFamily            string // ipv4, ipv6 or dual
 	ImageRepository     string
 	LoadBalancerStartIP string // currently only used by MetalLB addon
 	LoadBalancerEndIP   string // currently only used by MetalLB addon
@@ -137,6 +143,31 @@ type KubernetesConfig struct {
 
 	EnableDefaultCNI bool   // deprecated in preference to CNI
 	CNI              string // CNI to use
+	CNIMTU           int    // MTU of the pod interfaces, 0 to let the CNI choose
+
+	DisableNetworkPolicy bool // do not enforce the network policies, for the CNIs where it is optional
+
+	OIDC  *OIDCConfig  // authenticates the users with the ID tokens of an OpenID Connect issuer
+	Audit *AuditConfig // logs the requests to the apiserver
+}
+
+// OIDCConfig configures the apiserver to authenticate the users with the ID tokens of an OpenID Connect issuer
+type OIDCConfig struct {
+	IssuerURL      string
+	ClientID       string
+	ClientSecret   string   // only used by the credential plugin of the kubeconfig
+	CAFile         string   // host path of the CA of the issuer, if it is not trusted by the system
+	UsernameClaim  string   // the claim used as the user name, "sub" if empty
+	UsernamePrefix string   // prepended to the user names, none if empty
+	GroupsClaim    string   // the claim used as the groups, none if empty
+	GroupsPrefix   string   // prepended to the groups, none if empty
+	ExtraScopes    []string // requested by the credential plugin of the kubeconfig, along with openid
+}
+
+// AuditConfig configures the apiserver to log the requests to it
+type AuditConfig struct {
+	PolicyFile        string // host path of the audit policy, the built-in policy if empty
+	WebhookConfigFile string // host path of the kubeconfig of a webhook also receiving the events, none if empty
 }
 
 // Node contains information about specific nodes in a cluster
@@ -51,6 +51,10 @@ const (
 	APIServerPort = 8443
 	// AutoPauseProxyPort is the port to be used as a reverse proxy for apiserver port
 	AutoPauseProxyPort = 32443
+	// AutoPausePort is the default port the auto-pause server listens on
+	AutoPausePort = 8080
+	// AutoPauseNamespace is the default namespace whose containers are paused by auto-pause
+	AutoPauseNamespace = "kube-system"
 
 	// SSHPort is the SSH serviceport on the node vm and container
 	SSHPort = 22
@@ -78,6 +82,8 @@ const (
 	ClusterDNSDomain = "cluster.local"
 	// DefaultServiceCIDR is The CIDR to be used for service cluster IPs
 	DefaultServiceCIDR = "10.96.0.0/12"
+	// DefaultServiceCIDRv6 is the CIDR of the IPv6 service cluster IPs of the dual-stack clusters
+	DefaultServiceCIDRv6 = "fd00:10:96::/112"
 	// HostAlias is a DNS alias to the container/VM host IP
 	HostAlias = "host.minikube.internal"
 	// ControlPlaneAlias is a DNS alias pointing to the apiserver frontend
@@ -64,6 +64,7 @@ type Containerd struct {
 	KubernetesVersion semver.Version
 	Init              sysinit.Manager
 	InsecureRegistry  []string
+	RegistryMirrors   []RegistryMirror
 }
 
 // Name is a human readable name for containerd
@@ -246,6 +247,10 @@ func (r *Containerd) Enable(disOthers bool, cgroupDriver string, inUserNamespace
 		return err
 	}
 
+	// the mirrors come first, so that the hosts.toml of an insecure registry replaces them
+	if err := configureContainerdMirrors(r.Runner, r.RegistryMirrors); err != nil {
+		return err
+	}
 	if err := generateContainerdConfig(r.Runner, r.ImageRepository, r.KubernetesVersion, cgroupDriver, r.InsecureRegistry, inUserNamespace); err != nil {
 		return err
 	}
@@ -276,8 +281,13 @@ func (r *Containerd) ImageExists(name string, sha string) bool {
 }
 
 // ListImages lists images managed by this container runtime
-func (r *Containerd) ListImages(ListImagesOptions) ([]ListImage, error) {
-	return listCRIImages(r.Runner)
+func (r *Containerd) ListImages(o ListImagesOptions) ([]ListImage, error) {
+	return listCRIImages(r.Runner, o)
+}
+
+// InspectImage returns the details of an image
+func (r *Containerd) InspectImage(name string) (*ImageInspect, error) {
+	return inspectCRIImage(r.Runner, name)
 }
 
 // LoadImage loads an image into this runtime
@@ -372,14 +382,18 @@ func downloadRemote(cr CommandRunner, src string) (string, error) {
 }
 
 // BuildImage builds an image into this runtime
-func (r *Containerd) BuildImage(src string, file string, tag string, push bool, env []string, opts []string) error {
+func (r *Containerd) BuildImage(o BuildImageOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
 	// download url if not already present
-	dir, err := downloadRemote(r.Runner, src)
+	dir, err := downloadRemote(r.Runner, o.Dir)
 	if err != nil {
 		return err
 	}
+	file := o.File
 	if file != "" {
-		if dir != src {
+		if dir != o.Dir {
 			file = path.Join(dir, file)
 		}
 		// copy to standard path for Dockerfile
@@ -393,13 +407,14 @@ func (r *Containerd) BuildImage(src string, file string, tag string, push bool,
 	}
 	klog.Infof("Building image: %s", dir)
 	extra := ""
-	if tag != "" {
+	if o.Tag != "" {
+		tag := o.Tag
 		// add default tag if missing
 		if !strings.Contains(tag, ":") {
 			tag += ":latest"
 		}
 		extra = fmt.Sprintf(",name=%s", tag)
-		if push {
+		if o.Push {
 			extra += ",push=true"
 		}
 	}
@@ -408,12 +423,27 @@ func (r *Containerd) BuildImage(src string, file string, tag string, push bool,
 		"--local", fmt.Sprintf("context=%s", dir),
 		"--local", fmt.Sprintf("dockerfile=%s", dir),
 		"--output", fmt.Sprintf("type=image%s", extra)}
-	for _, opt := range opts {
+	if len(o.Platforms) > 0 {
+		args = append(args, "--opt", fmt.Sprintf("platform=%s", strings.Join(o.Platforms, ",")))
+	}
+	if o.Target != "" {
+		args = append(args, "--opt", fmt.Sprintf("target=%s", o.Target))
+	}
+	for _, s := range o.Secrets {
+		args = append(args, "--secret", s)
+	}
+	if o.CacheFrom != "" {
+		args = append(args, "--import-cache", fmt.Sprintf("type=local,src=%s", o.CacheFrom))
+	}
+	if o.CacheTo != "" {
+		args = append(args, "--export-cache", fmt.Sprintf("type=local,dest=%s,mode=max", o.CacheTo))
+	}
+	for _, opt := range o.Opts {
 		args = append(args, "--"+opt)
 	}
 	c := exec.Command("sudo", args...)
 	e := os.Environ()
-	e = append(e, env...)
+	e = append(e, o.Env...)
 	c.Env = e
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
@@ -21,10 +21,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"os/exec"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
@@ -59,6 +63,9 @@ func crictlList(cr CommandRunner, root string, o ListContainersOptions) (*comman
 	if o.Name != "" {
 		baseCmd = append(baseCmd, fmt.Sprintf("--name=%s", o.Name))
 	}
+	if o.Image != "" {
+		baseCmd = append(baseCmd, fmt.Sprintf("--image=%s", o.Image))
+	}
 
 	// shortcut for all namespaces
 	if len(o.Namespaces) == 0 {
@@ -284,7 +291,7 @@ func getCRIInfo(cr CommandRunner) (map[string]interface{}, error) {
 }
 
 // listCRIImages lists images using crictl
-func listCRIImages(cr CommandRunner) ([]ListImage, error) {
+func listCRIImages(cr CommandRunner, o ListImagesOptions) ([]ListImage, error) {
 	c := exec.Command("sudo", "crictl", "images", "--output", "json")
 	rr, err := cr.RunCmd(c)
 	if err != nil {
@@ -307,7 +314,162 @@ func listCRIImages(cr CommandRunner) ([]ListImage, error) {
 			Size:        img.Size,
 		})
 	}
-	return images, nil
+	if o.needsDetails() && len(images) > 0 {
+		if err := criImagesDetails(cr, images); err != nil {
+			klog.Warningf("unable to look up the details of images: %v", err)
+		}
+	}
+	if o.needsUsage() {
+		if err := criImagesInUse(cr, images); err != nil {
+			return nil, errors.Wrap(err, "looking up the images in use")
+		}
+	}
+	return filterImages(images, o)
+}
+
+// ociImageSpec is the part of the OCI image configuration reported by crictl inspecti
+type ociImageSpec struct {
+	Created      time.Time `json:"created"`
+	Architecture string    `json:"architecture"`
+	OS           string    `json:"os"`
+	Variant      string    `json:"variant"`
+	Config       struct {
+		User       string            `json:"User"`
+		Env        []string          `json:"Env"`
+		Entrypoint []string          `json:"Entrypoint"`
+		Cmd        []string          `json:"Cmd"`
+		WorkingDir string            `json:"WorkingDir"`
+		Labels     map[string]string `json:"Labels"`
+	} `json:"config"`
+	RootFS struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []struct {
+		Created    time.Time `json:"created"`
+		CreatedBy  string    `json:"created_by"`
+		Comment    string    `json:"comment"`
+		EmptyLayer bool      `json:"empty_layer"`
+	} `json:"history"`
+}
+
+// crictlInspectImage is the output of 'crictl inspecti'
+type crictlInspectImage struct {
+	Status struct {
+		ID          string   `json:"id"`
+		RepoTags    []string `json:"repoTags"`
+		RepoDigests []string `json:"repoDigests"`
+		Size        string   `json:"size"`
+	} `json:"status"`
+	Info struct {
+		ImageSpec ociImageSpec `json:"imageSpec"`
+	} `json:"info"`
+}
+
+// crictlInspectImages returns the details of the images, crictl writes one JSON document per image
+func crictlInspectImages(cr CommandRunner, ids []string) ([]crictlInspectImage, error) {
+	args := append([]string{"crictl", "inspecti", "--output", "json"}, ids...)
+	rr, err := cr.RunCmd(exec.Command("sudo", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "crictl inspecti")
+	}
+	var inspected []crictlInspectImage
+	d := json.NewDecoder(bytes.NewReader(rr.Stdout.Bytes()))
+	for {
+		var i crictlInspectImage
+		if err := d.Decode(&i); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "decoding crictl inspecti")
+		}
+		inspected = append(inspected, i)
+	}
+	return inspected, nil
+}
+
+// criImagesDetails fills in the creation time, platform and labels of the images by inspecting them
+func criImagesDetails(cr CommandRunner, images []ListImage) error {
+	var ids []string
+	for _, img := range images {
+		ids = append(ids, img.ID)
+	}
+	inspected, err := crictlInspectImages(cr, ids)
+	if err != nil {
+		return err
+	}
+	specs := map[string]ociImageSpec{}
+	for _, i := range inspected {
+		specs[i.Status.ID] = i.Info.ImageSpec
+	}
+	for n := range images {
+		spec := specs[images[n].ID]
+		images[n].Created = spec.Created
+		images[n].Platform = imagePlatform(spec.OS, spec.Architecture, spec.Variant)
+		images[n].Labels = spec.Config.Labels
+	}
+	return nil
+}
+
+// criImagesInUse marks the images used by the containers
+func criImagesInUse(cr CommandRunner, images []ListImage) error {
+	rr, err := cr.RunCmd(exec.Command("sudo", "crictl", "ps", "-a", "--output", "json"))
+	if err != nil {
+		return errors.Wrap(err, "crictl ps")
+	}
+	var ps struct {
+		Containers []struct {
+			ImageRef string `json:"imageRef"`
+		} `json:"containers"`
+	}
+	if err := json.Unmarshal(rr.Stdout.Bytes(), &ps); err != nil {
+		return errors.Wrap(err, "decoding crictl ps")
+	}
+	for n, img := range images {
+		for _, c := range ps.Containers {
+			// the image ref is either the image id or a repo digest
+			if sameImageID(c.ImageRef, img.ID) || slices.Contains(img.RepoDigests, c.ImageRef) {
+				images[n].InUse = true
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// inspectCRIImage returns the details of an image
+func inspectCRIImage(cr CommandRunner, name string) (*ImageInspect, error) {
+	inspected, err := crictlInspectImages(cr, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(inspected) == 0 {
+		return nil, fmt.Errorf("image %s not found", name)
+	}
+	i := inspected[0]
+	size, err := strconv.ParseInt(i.Status.Size, 10, 64)
+	if err != nil {
+		klog.Warningf("unable to parse the size %q of image %s", i.Status.Size, name)
+	}
+	spec := i.Info.ImageSpec
+	ii := &ImageInspect{
+		ID:          i.Status.ID,
+		RepoTags:    i.Status.RepoTags,
+		RepoDigests: i.Status.RepoDigests,
+		Digest:      imageDigest(i.Status.RepoDigests),
+		Platform:    imagePlatform(spec.OS, spec.Architecture, spec.Variant),
+		Size:        size,
+		Created:     spec.Created,
+		Layers:      spec.RootFS.DiffIDs,
+		Env:         spec.Config.Env,
+		Entrypoint:  spec.Config.Entrypoint,
+		Cmd:         spec.Config.Cmd,
+		WorkingDir:  spec.Config.WorkingDir,
+		User:        spec.Config.User,
+		Labels:      spec.Config.Labels,
+	}
+	for _, h := range spec.History {
+		ii.History = append(ii.History, ImageHistory{Created: h.Created, CreatedBy: h.CreatedBy, Comment: h.Comment, EmptyLayer: h.EmptyLayer})
+	}
+	return ii, nil
 }
 
 // criContainerLogCmd returns the command to retrieve the log for a container based on ID
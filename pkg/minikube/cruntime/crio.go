@@ -51,6 +51,7 @@ type CRIO struct {
 	ImageRepository   string
 	KubernetesVersion semver.Version
 	Init              sysinit.Manager
+	RegistryMirrors   []RegistryMirror
 }
 
 // generateCRIOConfig sets up pause image and cgroup manager for cri-o in crioConfigFile
@@ -226,6 +227,9 @@ func (r *CRIO) Enable(disOthers bool, cgroupDriver string, inUserNamespace bool)
 	if err := generateCRIOConfig(r.Runner, r.ImageRepository, r.KubernetesVersion, cgroupDriver); err != nil {
 		return err
 	}
+	if err := configureCRIOMirrors(r.Runner, r.RegistryMirrors); err != nil {
+		return err
+	}
 	if err := enableIPForwarding(r.Runner); err != nil {
 		return err
 	}
@@ -266,8 +270,13 @@ func (r *CRIO) ImageExists(name string, sha string) bool {
 }
 
 // ListImages returns a list of images managed by this container runtime
-func (r *CRIO) ListImages(ListImagesOptions) ([]ListImage, error) {
-	return listCRIImages(r.Runner)
+func (r *CRIO) ListImages(o ListImagesOptions) ([]ListImage, error) {
+	return listCRIImages(r.Runner, o)
+}
+
+// InspectImage returns the details of an image
+func (r *CRIO) InspectImage(name string) (*ImageInspect, error) {
+	return inspectCRIImage(r.Runner, name)
 }
 
 // LoadImage loads an image into this runtime
@@ -311,31 +320,53 @@ func (r *CRIO) TagImage(source string, target string) error {
 }
 
 // BuildImage builds an image into this runtime
-func (r *CRIO) BuildImage(src string, file string, tag string, push bool, env []string, opts []string) error {
-	klog.Infof("Building image: %s", src)
+func (r *CRIO) BuildImage(o BuildImageOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if o.CacheFrom != "" || o.CacheTo != "" {
+		// podman only imports and exports the build cache from and to registries
+		return errors.New("cri-o does not support importing or exporting the build cache to a directory")
+	}
+	klog.Infof("Building image: %s", o.Dir)
 	args := []string{"podman", "build"}
-	if file != "" {
-		args = append(args, "-f", file)
+	if o.File != "" {
+		args = append(args, "-f", o.File)
+	}
+	if o.multiPlatform() {
+		// the images of every platform are added to a manifest list
+		args = append(args, "--manifest", o.Tag)
+	} else if o.Tag != "" {
+		args = append(args, "-t", o.Tag)
+	}
+	if len(o.Platforms) > 0 {
+		args = append(args, "--platform", strings.Join(o.Platforms, ","))
 	}
-	if tag != "" {
-		args = append(args, "-t", tag)
+	if o.Target != "" {
+		args = append(args, "--target", o.Target)
 	}
-	args = append(args, src)
-	for _, opt := range opts {
+	for _, s := range o.Secrets {
+		args = append(args, "--secret", s)
+	}
+	args = append(args, o.Dir)
+	for _, opt := range o.Opts {
 		args = append(args, "--"+opt)
 	}
 	args = append(args, "--cgroup-manager=cgroupfs")
 	c := exec.Command("sudo", args...)
 	e := os.Environ()
-	e = append(e, env...)
+	e = append(e, o.Env...)
 	c.Env = e
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	if _, err := r.Runner.RunCmd(c); err != nil {
 		return errors.Wrap(err, "crio build image")
 	}
-	if tag != "" && push {
-		c := exec.Command("sudo", "podman", "push", tag)
+	if o.Tag != "" && o.Push {
+		c := exec.Command("sudo", "podman", "push", o.Tag)
+		if o.multiPlatform() {
+			c = exec.Command("sudo", "podman", "manifest", "push", "--all", o.Tag, "docker://"+o.Tag)
+		}
 		c.Stdout = os.Stdout
 		c.Stderr = os.Stderr
 		if _, err := r.Runner.RunCmd(c); err != nil {
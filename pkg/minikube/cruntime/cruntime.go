@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
@@ -103,7 +104,7 @@ type Manager interface {
 	// Pull an image to the runtime from the container registry
 	PullImage(string) error
 	// Build an image idempotently into the runtime on a host
-	BuildImage(string, string, string, bool, []string, []string) error
+	BuildImage(BuildImageOptions) error
 	// Save an image from the runtime on a host
 	SaveImage(string, string) error
 	// Tag an image
@@ -115,6 +116,8 @@ type Manager interface {
 	ImageExists(string, string) bool
 	// ListImages returns a list of images managed by this container runtime
 	ListImages(ListImagesOptions) ([]ListImage, error)
+	// InspectImage returns the details of an image
+	InspectImage(string) (*ImageInspect, error)
 
 	// RemoveImage remove image based on name
 	RemoveImage(string) error
@@ -155,10 +158,20 @@ type Config struct {
 	KubernetesVersion semver.Version
 	// InsecureRegistry list of insecure registries
 	InsecureRegistry []string
+	// RegistryMirrors are the mirrors to pull the images of each registry from
+	RegistryMirrors []RegistryMirror
 	// GPUs add GPU devices to the container
 	GPUs bool
 }
 
+// RegistryMirror is a registry with the mirrors to pull its images from
+type RegistryMirror struct {
+	// Registry is the name of the registry in the image references, such as docker.io
+	Registry string
+	// Endpoints are the URLs of the mirrors, tried in order before the registry itself
+	Endpoints []string
+}
+
 // ListContainersOptions are the options to use for listing containers
 type ListContainersOptions struct {
 	// State is the container state to filter by (All, Running, Paused)
@@ -167,10 +180,48 @@ type ListContainersOptions struct {
 	Name string
 	// Namespaces is the namespaces to look into
 	Namespaces []string
+	// Image is an image filter, matching the containers created from the image
+	Image string
+}
+
+// BuildImageOptions are the options to use for building an image
+type BuildImageOptions struct {
+	// Dir is the build context on the node, or a URL
+	Dir string
+	// File is the path of the Dockerfile, the one in Dir by default
+	File string
+	// Tag is the name of the built image
+	Tag string
+	// Push also pushes the image to its registry
+	Push bool
+	// Env are environment variables for the build, each key=value
+	Env []string
+	// Opts are extra flags for the builder, each key=value
+	Opts []string
+	// Platforms are the os/arch[/variant] platforms to build for, the one of the node by default
+	Platforms []string
+	// Target is the stage of the Dockerfile to build
+	Target string
+	// Secrets are passed to the build, each id=ID,src=PATH with the path on the node
+	Secrets []string
+	// CacheFrom is a directory on the node to import the build cache from
+	CacheFrom string
+	// CacheTo is a directory on the node to export the build cache to
+	CacheTo string
 }
 
 // ListImagesOptions are the options to use for listing images
 type ListImagesOptions struct {
+	// Details also looks up the creation time, platform, labels and usage of the images, which may need additional commands
+	Details bool
+	// Reference only lists the images with a tag matching this glob, e.g. docker.io/library/*
+	Reference string
+	// Labels only lists the images which have all these labels, each either key or key=value
+	Labels []string
+	// Dangling, if set, only lists the images without a tag, or with a tag if false
+	Dangling *bool
+	// InUse, if set, only lists the images used by a container of a pod, or not used if false
+	InUse *bool
 }
 
 type ListImage struct {
@@ -178,6 +229,40 @@ type ListImage struct {
 	RepoDigests []string `json:"repoDigests" yaml:"repoDigests"`
 	RepoTags    []string `json:"repoTags" yaml:"repoTags"`
 	Size        string   `json:"size" yaml:"size"`
+	// Created is the creation time of the image, zero if unknown
+	Created time.Time `json:"created" yaml:"created"`
+	// Platform is the os/arch[/variant] of the image
+	Platform string            `json:"platform,omitempty" yaml:"platform,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// InUse is set if a container of a pod uses the image
+	InUse bool `json:"inUse" yaml:"inUse"`
+}
+
+// ImageInspect are the details of an image
+type ImageInspect struct {
+	ID          string            `json:"id" yaml:"id"`
+	RepoTags    []string          `json:"repoTags" yaml:"repoTags"`
+	RepoDigests []string          `json:"repoDigests" yaml:"repoDigests"`
+	Digest      string            `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Platform    string            `json:"platform" yaml:"platform"`
+	Size        int64             `json:"size" yaml:"size"`
+	Created     time.Time         `json:"created" yaml:"created"`
+	Layers      []string          `json:"layers" yaml:"layers"`
+	Env         []string          `json:"env,omitempty" yaml:"env,omitempty"`
+	Entrypoint  []string          `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+	Cmd         []string          `json:"cmd,omitempty" yaml:"cmd,omitempty"`
+	WorkingDir  string            `json:"workingDir,omitempty" yaml:"workingDir,omitempty"`
+	User        string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	History     []ImageHistory    `json:"history,omitempty" yaml:"history,omitempty"`
+}
+
+// ImageHistory is a step of the build of an image
+type ImageHistory struct {
+	Created    time.Time `json:"created" yaml:"created"`
+	CreatedBy  string    `json:"createdBy" yaml:"createdBy"`
+	Comment    string    `json:"comment,omitempty" yaml:"comment,omitempty"`
+	EmptyLayer bool      `json:"emptyLayer" yaml:"emptyLayer"`
 }
 
 // ErrContainerRuntimeNotRunning is thrown when container runtime is not running
@@ -230,6 +315,7 @@ func New(c Config) (Manager, error) {
 			UseCRI:            (sp != ""), // !dockershim
 			CRIService:        cs,
 			GPUs:              c.GPUs,
+			RegistryMirrors:   c.RegistryMirrors,
 		}, nil
 	case "crio", "cri-o":
 		return &CRIO{
@@ -238,6 +324,7 @@ func New(c Config) (Manager, error) {
 			ImageRepository:   c.ImageRepository,
 			KubernetesVersion: c.KubernetesVersion,
 			Init:              sm,
+			RegistryMirrors:   c.RegistryMirrors,
 		}, nil
 	case "containerd":
 		return &Containerd{
@@ -247,6 +334,7 @@ func New(c Config) (Manager, error) {
 			KubernetesVersion: c.KubernetesVersion,
 			Init:              sm,
 			InsecureRegistry:  c.InsecureRegistry,
+			RegistryMirrors:   c.RegistryMirrors,
 		}, nil
 	default:
 		return nil, fmt.Errorf("unknown runtime type: %q", c.Type)
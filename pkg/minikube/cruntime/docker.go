@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
@@ -76,6 +77,7 @@ type Docker struct {
 	UseCRI            bool
 	CRIService        string
 	GPUs              bool
+	RegistryMirrors   []RegistryMirror
 }
 
 // Name is a human readable name for Docker
@@ -260,7 +262,7 @@ func (r *Docker) ImageExists(name string, sha string) bool {
 }
 
 // ListImages returns a list of images managed by this container runtime
-func (r *Docker) ListImages(ListImagesOptions) ([]ListImage, error) {
+func (r *Docker) ListImages(o ListImagesOptions) ([]ListImage, error) {
 	c := exec.Command("docker", "images", "--no-trunc", "--format", "{{json .}}")
 	rr, err := r.Runner.RunCmd(c)
 	if err != nil {
@@ -271,6 +273,7 @@ func (r *Docker) ListImages(ListImagesOptions) ([]ListImage, error) {
 		Repository string `json:"Repository"`
 		Tag        string `json:"Tag"`
 		Size       string `json:"Size"`
+		CreatedAt  string `json:"CreatedAt"`
 	}
 	images := strings.Split(rr.Stdout.String(), "\n")
 	result := []ListImage{}
@@ -289,14 +292,177 @@ func (r *Docker) ListImages(ListImagesOptions) ([]ListImage, error) {
 		}
 
 		repoTag := fmt.Sprintf("%s:%s", jsonImage.Repository, jsonImage.Tag)
+		// docker reports the creation time anyway, e.g. 2024-01-02 15:04:05 -0700 MST
+		created, _ := time.Parse("2006-01-02 15:04:05 -0700 MST", jsonImage.CreatedAt)
 		result = append(result, ListImage{
 			ID:          strings.TrimPrefix(jsonImage.ID, "sha256:"),
 			RepoDigests: []string{},
 			RepoTags:    []string{addDockerIO(repoTag)},
 			Size:        fmt.Sprintf("%d", size),
+			Created:     created,
 		})
 	}
-	return result, nil
+	if o.needsDetails() && len(result) > 0 {
+		if err := r.imagesDetails(result); err != nil {
+			klog.Warningf("unable to look up the details of images: %v", err)
+		}
+	}
+	if o.needsUsage() {
+		if err := r.imagesInUse(result); err != nil {
+			return nil, errors.Wrap(err, "looking up the images in use")
+		}
+	}
+	return filterImages(result, o)
+}
+
+// dockerInspectImage is the output of 'docker image inspect'
+type dockerInspectImage struct {
+	ID           string    `json:"Id"`
+	RepoTags     []string  `json:"RepoTags"`
+	RepoDigests  []string  `json:"RepoDigests"`
+	Created      time.Time `json:"Created"`
+	Size         int64     `json:"Size"`
+	Architecture string    `json:"Architecture"`
+	Variant      string    `json:"Variant"`
+	OS           string    `json:"Os"`
+	Config       struct {
+		User       string            `json:"User"`
+		Env        []string          `json:"Env"`
+		Entrypoint []string          `json:"Entrypoint"`
+		Cmd        []string          `json:"Cmd"`
+		WorkingDir string            `json:"WorkingDir"`
+		Labels     map[string]string `json:"Labels"`
+	} `json:"Config"`
+	RootFS struct {
+		Layers []string `json:"Layers"`
+	} `json:"RootFS"`
+}
+
+// inspectImages returns the details of the images, one JSON document per image
+func (r *Docker) inspectImages(names []string) ([]dockerInspectImage, error) {
+	args := append([]string{"image", "inspect", "--format", "{{json .}}"}, names...)
+	rr, err := r.Runner.RunCmd(exec.Command("docker", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "docker image inspect")
+	}
+	var inspected []dockerInspectImage
+	d := json.NewDecoder(bytes.NewReader(rr.Stdout.Bytes()))
+	for {
+		var i dockerInspectImage
+		if err := d.Decode(&i); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "decoding docker image inspect")
+		}
+		inspected = append(inspected, i)
+	}
+	return inspected, nil
+}
+
+// imagesDetails fills in the platform and labels of the images by inspecting them
+func (r *Docker) imagesDetails(images []ListImage) error {
+	var ids []string
+	seen := map[string]bool{}
+	for _, img := range images {
+		if !seen[img.ID] {
+			seen[img.ID] = true
+			ids = append(ids, img.ID)
+		}
+	}
+	inspected, err := r.inspectImages(ids)
+	if err != nil {
+		return err
+	}
+	for _, i := range inspected {
+		for n := range images {
+			if sameImageID(images[n].ID, i.ID) {
+				images[n].Created = i.Created
+				images[n].Platform = imagePlatform(i.OS, i.Architecture, i.Variant)
+				images[n].Labels = i.Config.Labels
+			}
+		}
+	}
+	return nil
+}
+
+// imagesInUse marks the images used by the containers of pods
+func (r *Docker) imagesInUse(images []ListImage) error {
+	ids, err := r.ListContainers(ListContainersOptions{State: All})
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	args := append([]string{"inspect", "--format", "{{.Image}}"}, ids...)
+	rr, err := r.Runner.RunCmd(exec.Command("docker", args...))
+	if err != nil {
+		return errors.Wrap(err, "docker inspect")
+	}
+	for _, used := range strings.Fields(rr.Stdout.String()) {
+		for n := range images {
+			if sameImageID(images[n].ID, used) {
+				images[n].InUse = true
+			}
+		}
+	}
+	return nil
+}
+
+// InspectImage returns the details of an image
+func (r *Docker) InspectImage(name string) (*ImageInspect, error) {
+	inspected, err := r.inspectImages([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(inspected) == 0 {
+		return nil, fmt.Errorf("image %s not found", name)
+	}
+	i := inspected[0]
+	ii := &ImageInspect{
+		ID:          i.ID,
+		RepoTags:    i.RepoTags,
+		RepoDigests: i.RepoDigests,
+		Digest:      imageDigest(i.RepoDigests),
+		Platform:    imagePlatform(i.OS, i.Architecture, i.Variant),
+		Size:        i.Size,
+		Created:     i.Created,
+		Layers:      i.RootFS.Layers,
+		Env:         i.Config.Env,
+		Entrypoint:  i.Config.Entrypoint,
+		Cmd:         i.Config.Cmd,
+		WorkingDir:  i.Config.WorkingDir,
+		User:        i.Config.User,
+		Labels:      i.Config.Labels,
+	}
+
+	rr, err := r.Runner.RunCmd(exec.Command("docker", "history", "--no-trunc", "--human=false", "--format", "{{json .}}", name))
+	if err != nil {
+		return nil, errors.Wrap(err, "docker history")
+	}
+	type dockerHistory struct {
+		CreatedAt string `json:"CreatedAt"`
+		CreatedBy string `json:"CreatedBy"`
+		Size      string `json:"Size"`
+		Comment   string `json:"Comment"`
+	}
+	var history []ImageHistory
+	for _, line := range strings.Split(rr.Stdout.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		var h dockerHistory
+		if err := json.Unmarshal([]byte(line), &h); err != nil {
+			return nil, errors.Wrap(err, "decoding docker history")
+		}
+		created, _ := time.Parse(time.RFC3339, h.CreatedAt)
+		history = append(history, ImageHistory{Created: created, CreatedBy: h.CreatedBy, Comment: h.Comment, EmptyLayer: h.Size == "0"})
+	}
+	// docker lists the most recent step first
+	for n := len(history) - 1; n >= 0; n-- {
+		ii.History = append(ii.History, history[n])
+	}
+	return ii, nil
 }
 
 // LoadImage loads an image into this runtime
@@ -356,30 +522,65 @@ func (r *Docker) TagImage(source string, target string) error {
 }
 
 // BuildImage builds an image into this runtime
-func (r *Docker) BuildImage(src string, file string, tag string, push bool, env []string, opts []string) error {
-	klog.Infof("Building image: %s", src)
+func (r *Docker) BuildImage(o BuildImageOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	klog.Infof("Building image: %s", o.Dir)
+	buildx := len(o.Platforms) > 0 || len(o.Secrets) > 0 || o.CacheFrom != "" || o.CacheTo != ""
+	if o.multiPlatform() && !o.Push {
+		// the docker image store can only hold a single platform
+		return errors.New("building for several platforms with docker requires pushing the image")
+	}
 	args := []string{"build"}
-	if file != "" {
-		args = append(args, "-f", file)
+	if buildx {
+		// multi-platform builds and cache export need the docker-container driver
+		if err := r.ensureBuilder(); err != nil {
+			return err
+		}
+		args = []string{"buildx", "build", "--builder", dockerBuilder}
+		if len(o.Platforms) > 0 {
+			args = append(args, "--platform", strings.Join(o.Platforms, ","))
+		}
+		for _, s := range o.Secrets {
+			args = append(args, "--secret", s)
+		}
+		if o.CacheFrom != "" {
+			args = append(args, "--cache-from", fmt.Sprintf("type=local,src=%s", o.CacheFrom))
+		}
+		if o.CacheTo != "" {
+			args = append(args, "--cache-to", fmt.Sprintf("type=local,dest=%s,mode=max", o.CacheTo))
+		}
+		if o.Push {
+			args = append(args, "--push")
+		} else {
+			args = append(args, "--load")
+		}
+	}
+	if o.File != "" {
+		args = append(args, "-f", o.File)
+	}
+	if o.Tag != "" {
+		args = append(args, "-t", o.Tag)
 	}
-	if tag != "" {
-		args = append(args, "-t", tag)
+	if o.Target != "" {
+		args = append(args, "--target", o.Target)
 	}
-	args = append(args, src)
-	for _, opt := range opts {
+	args = append(args, o.Dir)
+	for _, opt := range o.Opts {
 		args = append(args, "--"+opt)
 	}
 	c := exec.Command("docker", args...)
 	e := os.Environ()
-	e = append(e, env...)
+	e = append(e, o.Env...)
 	c.Env = e
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	if _, err := r.Runner.RunCmd(c); err != nil {
 		return errors.Wrap(err, "buildimage docker")
 	}
-	if tag != "" && push {
-		c := exec.Command("docker", "push", tag)
+	if o.Tag != "" && o.Push && !buildx {
+		c := exec.Command("docker", "push", o.Tag)
 		c.Stdout = os.Stdout
 		c.Stderr = os.Stderr
 		if _, err := r.Runner.RunCmd(c); err != nil {
@@ -389,6 +590,22 @@ func (r *Docker) BuildImage(src string, file string, tag string, push bool, env
 	return nil
 }
 
+// dockerBuilder is the name of the buildx builder used for multi-platform builds and cache export
+const dockerBuilder = "minikube"
+
+// ensureBuilder creates the buildx builder if it does not exist yet
+func (r *Docker) ensureBuilder() error {
+	if _, err := r.Runner.RunCmd(exec.Command("docker", "buildx", "inspect", dockerBuilder)); err == nil {
+		return nil
+	}
+	klog.Infof("Creating buildx builder %s", dockerBuilder)
+	c := exec.Command("docker", "buildx", "create", "--name", dockerBuilder, "--driver", "docker-container")
+	if _, err := r.Runner.RunCmd(c); err != nil {
+		return errors.Wrap(err, "docker buildx create")
+	}
+	return nil
+}
+
 // PushImage pushes an image
 func (r *Docker) PushImage(name string) error {
 	klog.Infof("Pushing image: %s", name)
@@ -441,7 +658,11 @@ func (r *Docker) ListContainers(o ListContainersOptions) ([]string, error) {
 		nameFilter = fmt.Sprintf("%s.*_(%s)_", nameFilter, strings.Join(o.Namespaces, "|"))
 	}
 
-	args = append(args, fmt.Sprintf("--filter=name=%s", nameFilter), "--format={{.ID}}")
+	args = append(args, fmt.Sprintf("--filter=name=%s", nameFilter))
+	if o.Image != "" {
+		args = append(args, fmt.Sprintf("--filter=ancestor=%s", o.Image))
+	}
+	args = append(args, "--format={{.ID}}")
 	rr, err := r.Runner.RunCmd(exec.Command("docker", args...))
 	if err != nil {
 		return nil, errors.Wrapf(err, "docker")
@@ -551,6 +772,7 @@ type dockerDaemonConfig struct {
 	LogDriver      string                `json:"log-driver"`
 	LogOpts        dockerDaemonLogOpts   `json:"log-opts"`
 	StorageDriver  string                `json:"storage-driver"`
+	Mirrors        []string              `json:"registry-mirrors,omitempty"`
 	DefaultRuntime string                `json:"default-runtime,omitempty"`
 	Runtimes       *dockerDaemonRuntimes `json:"runtimes,omitempty"`
 }
@@ -579,6 +801,8 @@ func (r *Docker) configureDocker(driver string) error {
 			MaxSize: "100m",
 		},
 		StorageDriver: "overlay2",
+		// docker only mirrors Docker Hub
+		Mirrors: mirrorEndpoints(r.RegistryMirrors, dockerHub),
 	}
 	if r.GPUs {
 		assets.Addons["nvidia-device-plugin"].EnableByDefault()
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dangling returns whether the image has no tag
+func (i ListImage) Dangling() bool {
+	for _, t := range i.RepoTags {
+		if !strings.Contains(t, "<none>") {
+			return false
+		}
+	}
+	return true
+}
+
+// needsDetails returns whether the creation time, platform and labels must be looked up
+func (o ListImagesOptions) needsDetails() bool {
+	return o.Details || len(o.Labels) > 0
+}
+
+// needsUsage returns whether the images used by pods must be looked up
+func (o ListImagesOptions) needsUsage() bool {
+	return o.Details || o.InUse != nil
+}
+
+// filterImages returns the images matching the filters of the options
+func filterImages(images []ListImage, o ListImagesOptions) ([]ListImage, error) {
+	if o.Reference != "" {
+		// fail early on a bad pattern, path.Match only reports it when reaching it
+		if _, err := path.Match(o.Reference, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid reference filter %q", o.Reference)
+		}
+	}
+	result := []ListImage{}
+	for _, img := range images {
+		if o.Dangling != nil && img.Dangling() != *o.Dangling {
+			continue
+		}
+		if o.InUse != nil && img.InUse != *o.InUse {
+			continue
+		}
+		if o.Reference != "" && !matchReference(img, o.Reference) {
+			continue
+		}
+		if !matchLabels(img.Labels, o.Labels) {
+			continue
+		}
+		result = append(result, img)
+	}
+	return result, nil
+}
+
+// matchReference returns whether a tag of the image matches the glob pattern,
+// with or without its tag and the default docker.io/library/ prefix
+func matchReference(img ListImage, pattern string) bool {
+	for _, t := range img.RepoTags {
+		if strings.Contains(t, "<none>") {
+			continue
+		}
+		name := t
+		if i := strings.LastIndex(t, ":"); i > strings.LastIndex(t, "/") {
+			name = t[:i]
+		}
+		for _, ref := range []string{t, name} {
+			short := strings.TrimPrefix(strings.TrimPrefix(ref, "docker.io/"), "library/")
+			for _, r := range []string{ref, short} {
+				if ok, _ := path.Match(pattern, r); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchLabels returns whether the labels contain all the filters, each either key or key=value
+func matchLabels(labels map[string]string, filters []string) bool {
+	for _, f := range filters {
+		k, v, hasValue := strings.Cut(f, "=")
+		val, ok := labels[k]
+		if !ok || (hasValue && val != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// imagePlatform returns the platform of an image as os/arch[/variant]
+func imagePlatform(os, arch, variant string) string {
+	if os == "" && arch == "" {
+		return ""
+	}
+	p := os + "/" + arch
+	if variant != "" {
+		p += "/" + variant
+	}
+	return p
+}
+
+// imageDigest returns the digest of the first repo digest of an image
+func imageDigest(repoDigests []string) string {
+	for _, d := range repoDigests {
+		if i := strings.LastIndex(d, "@"); i >= 0 {
+			return d[i+1:]
+		}
+	}
+	return ""
+}
+
+// sameImageID returns whether two image ids are the same, with or without the digest algorithm
+func sameImageID(a, b string) bool {
+	return strings.TrimPrefix(a, "sha256:") == strings.TrimPrefix(b, "sha256:")
+}
+
+// Validate checks the options shared by all the runtimes
+func (o BuildImageOptions) Validate() error {
+	if o.Dir == "" {
+		return errors.New("no build context")
+	}
+	for _, p := range o.Platforms {
+		if err := validatePlatform(p); err != nil {
+			return err
+		}
+	}
+	if len(o.Platforms) > 1 && o.Tag == "" {
+		return errors.New("building for several platforms requires a tag")
+	}
+	if o.Push && o.Tag == "" {
+		return errors.New("pushing requires a tag")
+	}
+	for _, s := range o.Secrets {
+		if _, _, err := SecretSource(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// multiPlatform returns whether the image is built for several platforms
+func (o BuildImageOptions) multiPlatform() bool {
+	return len(o.Platforms) > 1
+}
+
+// validatePlatform checks that a platform is os/arch[/variant]
+func validatePlatform(p string) error {
+	parts := strings.Split(p, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return errors.Errorf("invalid platform %q, must be os/arch[/variant]", p)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return errors.Errorf("invalid platform %q, must be os/arch[/variant]", p)
+		}
+	}
+	return nil
+}
+
+// SecretSource returns the id and source path of a secret given as id=ID,src=PATH
+func SecretSource(secret string) (string, string, error) {
+	var id, src string
+	for _, field := range strings.Split(secret, ",") {
+		k, v, _ := strings.Cut(field, "=")
+		switch k {
+		case "id":
+			id = v
+		case "src", "source":
+			src = v
+		case "type":
+			if v != "file" {
+				return "", "", errors.Errorf("invalid secret %q, only file secrets are supported", secret)
+			}
+		default:
+			return "", "", errors.Errorf("invalid secret %q, unknown field %q", secret, k)
+		}
+	}
+	if id == "" || src == "" {
+		return "", "", errors.Errorf("invalid secret %q, must be id=ID,src=PATH", secret)
+	}
+	return id, src, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// cannedRunner records the commands and returns their canned output, by the arguments following the binary
+type cannedRunner struct {
+	CommandRunner
+	outputs map[string]string
+	cmds    []string
+}
+
+func (r *cannedRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	r.cmds = append(r.cmds, strings.Join(cmd.Args, " "))
+	args := strings.Join(cmd.Args[1:], " ")
+	for prefix, output := range r.outputs {
+		if strings.HasPrefix(args, prefix) {
+			return &command.RunResult{Stdout: *bytes.NewBufferString(output)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected command: %s", args)
+}
+
+func TestFilterImages(t *testing.T) {
+	images := []ListImage{
+		{ID: "pause", RepoTags: []string{"registry.k8s.io/pause:3.9"}, InUse: true},
+		{ID: "nginx", RepoTags: []string{"docker.io/library/nginx:1.25", "docker.io/library/nginx:latest"}, Labels: map[string]string{"maintainer": "NGINX"}},
+		{ID: "app", RepoTags: []string{"localhost/app:v1"}, Labels: map[string]string{"app": "web", "tier": "front"}, InUse: true},
+		{ID: "dangling", RepoTags: []string{"docker.io/library/<none>:<none>"}},
+		{ID: "untagged"},
+	}
+	yes, no := true, false
+	tests := []struct {
+		name string
+		o    ListImagesOptions
+		want []string
+	}{
+		{"none", ListImagesOptions{}, []string{"pause", "nginx", "app", "dangling", "untagged"}},
+		{"reference with tag", ListImagesOptions{Reference: "nginx:1.*"}, []string{"nginx"}},
+		{"reference without tag", ListImagesOptions{Reference: "registry.k8s.io/*"}, []string{"pause"}},
+		{"full reference", ListImagesOptions{Reference: "docker.io/library/nginx"}, []string{"nginx"}},
+		{"label key", ListImagesOptions{Labels: []string{"app"}}, []string{"app"}},
+		{"label value", ListImagesOptions{Labels: []string{"app=web", "tier=back"}}, nil},
+		{"dangling", ListImagesOptions{Dangling: &yes}, []string{"dangling", "untagged"}},
+		{"tagged", ListImagesOptions{Dangling: &no}, []string{"pause", "nginx", "app"}},
+		{"in use", ListImagesOptions{InUse: &yes}, []string{"pause", "app"}},
+		{"unused tagged", ListImagesOptions{InUse: &no, Dangling: &no}, []string{"nginx"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := filterImages(images, tc.o)
+			if err != nil {
+				t.Fatalf("filterImages: %v", err)
+			}
+			var ids []string
+			for _, img := range got {
+				ids = append(ids, img.ID)
+			}
+			if diff := cmp.Diff(tc.want, ids); diff != "" {
+				t.Errorf("filterImages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := filterImages(images, ListImagesOptions{Reference: "["}); err == nil {
+		t.Errorf("filterImages accepted a bad pattern")
+	}
+}
+
+func TestDockerImages(t *testing.T) {
+	r := &cannedRunner{outputs: map[string]string{
+		"images --no-trunc": `{"CreatedAt":"2024-01-02 15:04:05 +0000 UTC","ID":"sha256:aaa","Repository":"busybox","Size":"4.26MB","Tag":"latest"}
+{"CreatedAt":"2023-06-01 10:00:00 +0200 CEST","ID":"sha256:bbb","Repository":"registry.k8s.io/pause","Size":"322kB","Tag":"3.9"}`,
+	}}
+	images, err := (&Docker{Runner: r}).ListImages(ListImagesOptions{})
+	if err != nil {
+		t.Fatalf("ListImages: %v", err)
+	}
+	want := []time.Time{time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC)}
+	if len(images) != len(want) {
+		t.Fatalf("ListImages = %+v; want %d images", images, len(want))
+	}
+	for i, img := range images {
+		if !img.Created.Equal(want[i]) {
+			t.Errorf("created of %s = %v; want %v", img.ID, img.Created, want[i])
+		}
+	}
+}
+
+func TestCRIImages(t *testing.T) {
+	r := &cannedRunner{outputs: map[string]string{
+		"crictl images": `{"images": [
+			{"id": "sha256:aaa", "repoTags": ["docker.io/library/busybox:latest"], "repoDigests": ["docker.io/library/busybox@sha256:ddd"], "size": "4261550"},
+			{"id": "sha256:bbb", "repoTags": ["registry.k8s.io/pause:3.9"], "repoDigests": [], "size": "321520"}
+		]}`,
+		"crictl inspecti": `{"status": {"id": "sha256:aaa", "repoTags": ["docker.io/library/busybox:latest"], "repoDigests": ["docker.io/library/busybox@sha256:ddd"], "size": "4261550"},
+ "info": {"imageSpec": {"created": "2024-01-02T15:04:05Z", "architecture": "arm64", "os": "linux", "variant": "v8",
+  "config": {"Env": ["PATH=/bin"], "Cmd": ["sh"], "Labels": {"maintainer": "me"}},
+  "rootfs": {"type": "layers", "diff_ids": ["sha256:l1"]},
+  "history": [{"created": "2024-01-02T15:04:05Z", "created_by": "ADD file in /"}, {"created_by": "CMD [\"sh\"]", "empty_layer": true}]}}}
+{"status": {"id": "sha256:bbb", "size": "321520"}, "info": {"imageSpec": {"os": "linux", "architecture": "arm64"}}}`,
+		"crictl ps -a --output json": `{"containers": [{"imageRef": "sha256:bbb"}]}`,
+	}}
+
+	images, err := listCRIImages(r, ListImagesOptions{Details: true, Labels: []string{"maintainer"}})
+	if err != nil {
+		t.Fatalf("listCRIImages: %v", err)
+	}
+	want := []ListImage{{
+		ID:          "sha256:aaa",
+		RepoTags:    []string{"docker.io/library/busybox:latest"},
+		RepoDigests: []string{"docker.io/library/busybox@sha256:ddd"},
+		Size:        "4261550",
+		Created:     time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Platform:    "linux/arm64/v8",
+		Labels:      map[string]string{"maintainer": "me"},
+	}}
+	if diff := cmp.Diff(want, images); diff != "" {
+		t.Errorf("listCRIImages mismatch (-want +got):\n%s", diff)
+	}
+
+	yes := true
+	images, err = listCRIImages(r, ListImagesOptions{InUse: &yes})
+	if err != nil {
+		t.Fatalf("listCRIImages: %v", err)
+	}
+	if len(images) != 1 || images[0].ID != "sha256:bbb" {
+		t.Errorf("listCRIImages in use = %+v; want only the pause image", images)
+	}
+	// the images are not inspected without the details
+	r.cmds = nil
+	images, err = listCRIImages(r, ListImagesOptions{Reference: "busybox"})
+	if err != nil {
+		t.Fatalf("listCRIImages: %v", err)
+	}
+	if len(images) != 1 || images[0].ID != "sha256:aaa" {
+		t.Errorf("listCRIImages without details = %+v; want only busybox", images)
+	}
+	for _, c := range r.cmds {
+		if strings.Contains(c, "inspecti") {
+			t.Errorf("listCRIImages without details ran %q", c)
+		}
+	}
+
+	ii, err := inspectCRIImage(r, "busybox")
+	if err != nil {
+		t.Fatalf("inspectCRIImage: %v", err)
+	}
+	if ii.Digest != "sha256:ddd" || ii.Platform != "linux/arm64/v8" || ii.Size != 4261550 || len(ii.Layers) != 1 || len(ii.History) != 2 || !ii.History[1].EmptyLayer {
+		t.Errorf("unexpected inspect result: %+v", ii)
+	}
+}
+
+func TestBuildImageOptions(t *testing.T) {
+	o := BuildImageOptions{
+		Dir:       "/build/ctx",
+		Tag:       "example.com/app:v1",
+		Push:      true,
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+		Target:    "prod",
+		Secrets:   []string{"id=npmrc,src=/build/secrets/npmrc"},
+	}
+	cached := o
+	cached.Platforms = nil
+	cached.Push = false
+	cached.Secrets = nil
+	cached.CacheFrom = "/build/cache-from"
+	cached.CacheTo = "/build/cache-to"
+
+	tests := []struct {
+		name    string
+		runtime func(r CommandRunner) Manager
+		o       BuildImageOptions
+		want    string
+	}{
+		{"docker", func(r CommandRunner) Manager { return &Docker{Runner: r} }, o,
+			"docker buildx build --builder minikube --platform linux/amd64,linux/arm64 --secret id=npmrc,src=/build/secrets/npmrc --push -t example.com/app:v1 --target prod /build/ctx"},
+		{"docker cache", func(r CommandRunner) Manager { return &Docker{Runner: r} }, cached,
+			"docker buildx build --builder minikube --cache-from type=local,src=/build/cache-from --cache-to type=local,dest=/build/cache-to,mode=max --load -t example.com/app:v1 --target prod /build/ctx"},
+		{"containerd", func(r CommandRunner) Manager { return &Containerd{Runner: r} }, o,
+			"sudo buildctl build --frontend dockerfile.v0 --local context=/build/ctx --local dockerfile=/build/ctx --output type=image,name=example.com/app:v1,push=true --opt platform=linux/amd64,linux/arm64 --opt target=prod --secret id=npmrc,src=/build/secrets/npmrc"},
+		{"containerd cache", func(r CommandRunner) Manager { return &Containerd{Runner: r} }, cached,
+			"sudo buildctl build --frontend dockerfile.v0 --local context=/build/ctx --local dockerfile=/build/ctx --output type=image,name=example.com/app:v1 --opt target=prod --import-cache type=local,src=/build/cache-from --export-cache type=local,dest=/build/cache-to,mode=max"},
+		{"crio", func(r CommandRunner) Manager { return &CRIO{Runner: r} }, o,
+			"sudo podman build --manifest example.com/app:v1 --platform linux/amd64,linux/arm64 --target prod --secret id=npmrc,src=/build/secrets/npmrc /build/ctx --cgroup-manager=cgroupfs"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &cannedRunner{outputs: map[string]string{"": ""}}
+			if err := tc.runtime(r).BuildImage(tc.o); err != nil {
+				t.Fatalf("BuildImage: %v", err)
+			}
+			found := false
+			for _, c := range r.cmds {
+				if c == tc.want {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("build command not run, want:\n%s\ngot:\n%s", tc.want, strings.Join(r.cmds, "\n"))
+			}
+		})
+	}
+
+	invalid := []struct {
+		name    string
+		runtime Manager
+		o       BuildImageOptions
+	}{
+		{"bad platform", &Docker{}, BuildImageOptions{Dir: ".", Platforms: []string{"amd64"}}},
+		{"multi-platform without tag", &Containerd{}, BuildImageOptions{Dir: ".", Platforms: []string{"linux/amd64", "linux/arm64"}}},
+		{"bad secret", &Containerd{}, BuildImageOptions{Dir: ".", Secrets: []string{"npmrc"}}},
+		{"docker multi-platform without push", &Docker{}, BuildImageOptions{Dir: ".", Tag: "app", Platforms: []string{"linux/amd64", "linux/arm64"}}},
+		{"crio cache dir", &CRIO{}, BuildImageOptions{Dir: ".", CacheTo: "/cache"}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.runtime.BuildImage(tc.o); err == nil {
+				t.Errorf("BuildImage(%+v) succeeded; want an error", tc.o)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// dockerHub is the name of the Docker Hub registry in the image references
+	dockerHub = "docker.io"
+	// mirrorsHeader marks the registry configuration files written for the mirrors, to remove them once unused
+	mirrorsHeader = "# minikube registry mirrors"
+	// crioMirrorsFile is the drop-in of registries.conf configuring the mirrors for cri-o
+	crioMirrorsFile = "/etc/containers/registries.conf.d/99-minikube-mirrors.conf"
+)
+
+// mirrorEndpoints returns the endpoints of the mirrors of the registry
+func mirrorEndpoints(mirrors []RegistryMirror, registry string) []string {
+	var endpoints []string
+	for _, m := range mirrors {
+		if m.Registry == registry {
+			endpoints = append(endpoints, m.Endpoints...)
+		}
+	}
+	return endpoints
+}
+
+// registryServer returns the URL of the API of the registry
+func registryServer(registry string) string {
+	if registry == dockerHub {
+		return "https://registry-1.docker.io"
+	}
+	return "https://" + registry
+}
+
+// containerdHostsTOML returns the hosts.toml of containerd pulling the images of the registry from its mirrors
+func containerdHostsTOML(m RegistryMirror) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nserver = %q\n", mirrorsHeader, registryServer(m.Registry))
+	for _, e := range m.Endpoints {
+		fmt.Fprintf(&b, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", e)
+	}
+	return b.String()
+}
+
+// crioRegistriesConf returns the registries.conf drop-in of cri-o pulling the images of the registries from their mirrors
+func crioRegistriesConf(mirrors []RegistryMirror) string {
+	var b strings.Builder
+	b.WriteString(mirrorsHeader + "\n")
+	for _, m := range mirrors {
+		if len(m.Endpoints) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n[[registry]]\nprefix = %q\nlocation = %q\n", m.Registry, m.Registry)
+		for _, e := range m.Endpoints {
+			// registries.conf has no scheme, plain http is an insecure mirror
+			location, insecure := e, false
+			if u, err := url.Parse(e); err == nil && u.Host != "" {
+				location = u.Host + strings.TrimSuffix(u.Path, "/")
+				insecure = u.Scheme == "http"
+			}
+			fmt.Fprintf(&b, "\n[[registry.mirror]]\nlocation = %q\ninsecure = %t\n", location, insecure)
+		}
+	}
+	return b.String()
+}
+
+// writeRuntimeFile writes the content to the path on the node, creating its directory
+func writeRuntimeFile(cr CommandRunner, p string, content string) error {
+	c := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo mkdir -p %s && printf %%s \"%s\" | base64 -d | sudo tee %s >/dev/null", path.Dir(p), base64.StdEncoding.EncodeToString([]byte(content)), p))
+	if _, err := cr.RunCmd(c); err != nil {
+		return errors.Wrapf(err, "write %s", p)
+	}
+	return nil
+}
+
+// configureContainerdMirrors writes the hosts.toml of the mirrored registries, removing those written for mirrors no longer used
+func configureContainerdMirrors(cr CommandRunner, mirrors []RegistryMirror) error {
+	c := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo grep -rlx --include=hosts.toml %q %s | xargs -r sudo rm -f", mirrorsHeader, containerdMirrorsRoot))
+	if _, err := cr.RunCmd(c); err != nil {
+		klog.Warningf("unable to remove unused registry mirrors: %v", err)
+	}
+	for _, m := range mirrors {
+		if len(m.Endpoints) == 0 {
+			continue
+		}
+		klog.Infof("configuring containerd to pull %s images from %v", m.Registry, m.Endpoints)
+		if err := writeRuntimeFile(cr, path.Join(containerdMirrorsRoot, m.Registry, "hosts.toml"), containerdHostsTOML(m)); err != nil {
+			return errors.Wrap(err, "configuring registry mirrors")
+		}
+	}
+	return nil
+}
+
+// configureCRIOMirrors writes the registries.conf drop-in of the mirrors, removing it when there are none
+func configureCRIOMirrors(cr CommandRunner, mirrors []RegistryMirror) error {
+	if len(mirrors) == 0 {
+		if _, err := cr.RunCmd(exec.Command("sudo", "rm", "-f", crioMirrorsFile)); err != nil {
+			return errors.Wrap(err, "removing registry mirrors")
+		}
+		return nil
+	}
+	klog.Infof("configuring cri-o to pull images from the mirrors %v", mirrors)
+	if err := writeRuntimeFile(cr, crioMirrorsFile, crioRegistriesConf(mirrors)); err != nil {
+		return errors.Wrap(err, "configuring registry mirrors")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testMirrors = []RegistryMirror{
+	{Registry: "docker.io", Endpoints: []string{"http://192.168.49.1:5100", "https://mirror.gcr.io"}},
+	{Registry: "registry.k8s.io", Endpoints: []string{"http://192.168.49.1:5101"}},
+	{Registry: "quay.io"},
+}
+
+func TestMirrorEndpoints(t *testing.T) {
+	got := mirrorEndpoints(testMirrors, "docker.io")
+	want := []string{"http://192.168.49.1:5100", "https://mirror.gcr.io"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("docker.io endpoints diff (-want +got):\n%s", diff)
+	}
+	if got := mirrorEndpoints(testMirrors, "ghcr.io"); got != nil {
+		t.Errorf("ghcr.io endpoints: got %v, want none", got)
+	}
+}
+
+func TestContainerdHostsTOML(t *testing.T) {
+	want := `# minikube registry mirrors
+server = "https://registry-1.docker.io"
+
+[host."http://192.168.49.1:5100"]
+  capabilities = ["pull", "resolve"]
+
+[host."https://mirror.gcr.io"]
+  capabilities = ["pull", "resolve"]
+`
+	if diff := cmp.Diff(want, containerdHostsTOML(testMirrors[0])); diff != "" {
+		t.Errorf("hosts.toml diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCRIORegistriesConf(t *testing.T) {
+	want := `# minikube registry mirrors
+
+[[registry]]
+prefix = "docker.io"
+location = "docker.io"
+
+[[registry.mirror]]
+location = "192.168.49.1:5100"
+insecure = true
+
+[[registry.mirror]]
+location = "mirror.gcr.io"
+insecure = false
+
+[[registry]]
+prefix = "registry.k8s.io"
+location = "registry.k8s.io"
+
+[[registry.mirror]]
+location = "192.168.49.1:5101"
+insecure = true
+`
+	if diff := cmp.Diff(want, crioRegistriesConf(testMirrors)); diff != "" {
+		t.Errorf("registries.conf diff (-want +got):\n%s", diff)
+	}
+}
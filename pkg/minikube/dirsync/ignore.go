@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IgnoreFile is the file of the synced directory listing the ignored paths
+const IgnoreFile = ".gitignore"
+
+// pattern is a line of a .gitignore file
+type pattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored patterns match from the root, the others match at any depth
+	anchored bool
+}
+
+// Matcher decides which paths are ignored, with the semantics of .gitignore
+type Matcher struct {
+	patterns []pattern
+}
+
+// NewMatcher returns a matcher of the .gitignore lines, the last matching line deciding
+func NewMatcher(lines []string) *Matcher {
+	m := &Matcher{}
+	// the git metadata is never synced
+	lines = append([]string{".git/"}, lines...)
+	for _, l := range lines {
+		l = strings.TrimRight(l, " \t\r")
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		var p pattern
+		if strings.HasPrefix(l, "!") {
+			p.negate = true
+			l = l[1:]
+		} else if strings.HasPrefix(l, `\`) {
+			l = l[1:]
+		}
+		if strings.HasSuffix(l, "/") {
+			p.dirOnly = true
+			l = strings.TrimSuffix(l, "/")
+		}
+		if strings.Contains(l, "/") {
+			p.anchored = true
+			l = strings.TrimPrefix(l, "/")
+		}
+		if l == "" {
+			continue
+		}
+		p.segments = strings.Split(l, "/")
+		m.patterns = append(m.patterns, p)
+	}
+	return m
+}
+
+// LoadMatcher returns a matcher of the .gitignore file of the root and of the extra patterns
+func LoadMatcher(root string, extra []string) (*Matcher, error) {
+	var lines []string
+	b, err := os.ReadFile(filepath.Join(root, IgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "read ignore file")
+	}
+	if err == nil {
+		lines = strings.Split(string(b), "\n")
+	}
+	return NewMatcher(append(lines, extra...)), nil
+}
+
+// Ignored returns whether the slash-separated path, relative to the root, is ignored
+func (m *Matcher) Ignored(rel string, isDir bool) bool {
+	segments := strings.Split(rel, "/")
+	// nothing inside an ignored directory can be included again
+	for i := 1; i < len(segments); i++ {
+		if m.match(segments[:i], true) {
+			return true
+		}
+	}
+	return m.match(segments, isDir)
+}
+
+// match returns whether the last pattern matching the path ignores it
+func (m *Matcher) match(segments []string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		var ok bool
+		if p.anchored {
+			ok = matchSegments(p.segments, segments)
+		} else {
+			ok, _ = path.Match(p.segments[0], segments[len(segments)-1])
+		}
+		if ok {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches the path segments against the pattern segments, where ** matches any number of segments
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
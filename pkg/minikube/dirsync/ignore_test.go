@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+import (
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"node_modules/",
+		"/build",
+		"docs/**/*.tmp",
+		"**/cache",
+		`\#hash`,
+	})
+	var tests = []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"main.go", false, false},
+		{"debug.log", false, true},
+		{"sub/debug.log", false, true},
+		{"keep.log", false, false},
+		{"node_modules", true, true},
+		{"node_modules", false, false},
+		{"node_modules/react/index.js", false, true},
+		{"web/node_modules/react/index.js", false, true},
+		{"build", true, true},
+		{"build/out", false, true},
+		{"web/build/out", false, false},
+		{"docs/a.tmp", false, true},
+		{"docs/a/b/c.tmp", false, true},
+		{"a.tmp", false, false},
+		{"cache/x", false, true},
+		{"a/b/cache", true, true},
+		{"#hash", false, true},
+		{".git/config", false, true},
+	}
+	for _, tc := range tests {
+		if got := m.Ignored(tc.path, tc.isDir); got != tc.ignored {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tc.path, tc.isDir, got, tc.ignored)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+const (
+	// DirectionBoth syncs the changes of the host to the node and the changes of the node back to the host
+	DirectionBoth = "both"
+	// DirectionPush only syncs the changes of the host to the node
+	DirectionPush = "push"
+	// DirectionPull only syncs the changes of the node to the host
+	DirectionPull = "pull"
+
+	// ConflictHost keeps the host file when both sides changed it
+	ConflictHost = "host"
+	// ConflictNode keeps the node file when both sides changed it
+	ConflictNode = "node"
+	// ConflictNewer keeps the most recently modified file when both sides changed it
+	ConflictNewer = "newer"
+	// ConflictSkip leaves both files as they are when both sides changed it
+	ConflictSkip = "skip"
+)
+
+// Directions are the supported sync directions
+var Directions = []string{DirectionBoth, DirectionPush, DirectionPull}
+
+// ConflictPolicies are the supported conflict policies
+var ConflictPolicies = []string{ConflictHost, ConflictNode, ConflictNewer, ConflictSkip}
+
+// action is what a sync does to a path
+type action int
+
+const (
+	// inSync records the state of the path, which needs nothing
+	inSync action = iota
+	push
+	pull
+	removeRemote
+	removeLocal
+	// conflict is a path changed on both sides, resolved once the contents are compared
+	conflict
+)
+
+// pair is the state of a path on both sides after the last sync, the base of the next one
+type pair struct {
+	Local  *File
+	Remote *File
+}
+
+// change is an action of a sync on a path
+type change struct {
+	path   string
+	action action
+}
+
+// plan returns the changes syncing the paths from their state after the last sync
+func plan(paths []string, base map[string]pair, local, remote Snapshot, direction string) []change {
+	var changes []change
+	for _, p := range paths {
+		l, lok := local[p]
+		r, rok := remote[p]
+		b := base[p]
+		localChanged := lok != (b.Local != nil) || (lok && l != *b.Local)
+		remoteChanged := rok != (b.Remote != nil) || (rok && r != *b.Remote)
+
+		switch {
+		case !localChanged && !remoteChanged:
+			continue
+		case !lok && !rok:
+			// deleted on both sides
+			changes = append(changes, change{p, inSync})
+		case localChanged && remoteChanged:
+			changes = append(changes, change{p, conflict})
+		case localChanged && direction != DirectionPull:
+			if lok {
+				changes = append(changes, change{p, push})
+			} else {
+				changes = append(changes, change{p, removeRemote})
+			}
+		case remoteChanged && direction != DirectionPush:
+			if rok {
+				changes = append(changes, change{p, pull})
+			} else {
+				changes = append(changes, change{p, removeLocal})
+			}
+		default:
+			// a change in the direction which is not synced
+			changes = append(changes, change{p, inSync})
+		}
+	}
+	return changes
+}
+
+// resolve returns the action keeping the file of the side winning a conflict, once the contents differ
+func resolve(policy string, direction string, l *File, r *File) action {
+	winner := policy
+	switch direction {
+	case DirectionPush:
+		winner = ConflictHost
+	case DirectionPull:
+		winner = ConflictNode
+	}
+	if winner == ConflictNewer {
+		// a file modified on one side wins over its deletion on the other
+		winner = ConflictHost
+		if l == nil || (r != nil && r.ModTime > l.ModTime) {
+			winner = ConflictNode
+		}
+	}
+	switch winner {
+	case ConflictHost:
+		if l == nil {
+			return removeRemote
+		}
+		return push
+	case ConflictNode:
+		if r == nil {
+			return removeLocal
+		}
+		return pull
+	}
+	return conflict
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlan(t *testing.T) {
+	old := File{Size: 1, ModTime: 100, Mode: 0644}
+	changed := File{Size: 2, ModTime: 200, Mode: 0644}
+	base := map[string]pair{
+		"same":           {Local: &old, Remote: &old},
+		"host-changed":   {Local: &old, Remote: &old},
+		"node-changed":   {Local: &old, Remote: &old},
+		"both-changed":   {Local: &old, Remote: &old},
+		"host-deleted":   {Local: &old, Remote: &old},
+		"node-deleted":   {Local: &old, Remote: &old},
+		"both-deleted":   {Local: &old, Remote: &old},
+		"deleted-edited": {Local: &old, Remote: &old},
+	}
+	local := Snapshot{
+		"same":           old,
+		"host-changed":   changed,
+		"node-changed":   old,
+		"both-changed":   changed,
+		"node-deleted":   old,
+		"deleted-edited": changed,
+		"host-new":       old,
+		"both-new":       old,
+	}
+	remote := Snapshot{
+		"same":         old,
+		"host-changed": old,
+		"node-changed": changed,
+		"both-changed": changed,
+		"host-deleted": old,
+		"node-new":     old,
+		"both-new":     changed,
+	}
+	paths := []string{"same", "host-changed", "node-changed", "both-changed", "host-deleted", "node-deleted",
+		"both-deleted", "deleted-edited", "host-new", "node-new", "both-new"}
+
+	var tests = []struct {
+		direction string
+		want      []change
+	}{
+		{DirectionBoth, []change{
+			{"host-changed", push},
+			{"node-changed", pull},
+			{"both-changed", conflict},
+			{"host-deleted", removeRemote},
+			{"node-deleted", removeLocal},
+			{"both-deleted", inSync},
+			{"deleted-edited", conflict},
+			{"host-new", push},
+			{"node-new", pull},
+			{"both-new", conflict},
+		}},
+		{DirectionPush, []change{
+			{"host-changed", push},
+			{"node-changed", inSync},
+			{"both-changed", conflict},
+			{"host-deleted", removeRemote},
+			{"node-deleted", inSync},
+			{"both-deleted", inSync},
+			{"deleted-edited", conflict},
+			{"host-new", push},
+			{"node-new", inSync},
+			{"both-new", conflict},
+		}},
+	}
+	for _, tc := range tests {
+		got := plan(paths, base, local, remote, tc.direction)
+		if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(change{})); diff != "" {
+			t.Errorf("plan(%s) mismatch (-want +got):\n%s", tc.direction, diff)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	older := &File{ModTime: 100}
+	newer := &File{ModTime: 200}
+	var tests = []struct {
+		policy    string
+		direction string
+		local     *File
+		remote    *File
+		want      action
+	}{
+		{ConflictHost, DirectionBoth, older, newer, push},
+		{ConflictHost, DirectionBoth, nil, newer, removeRemote},
+		{ConflictNode, DirectionBoth, newer, older, pull},
+		{ConflictNode, DirectionBoth, newer, nil, removeLocal},
+		{ConflictNewer, DirectionBoth, older, newer, pull},
+		{ConflictNewer, DirectionBoth, newer, older, push},
+		{ConflictNewer, DirectionBoth, nil, older, pull},
+		{ConflictNewer, DirectionBoth, older, nil, push},
+		{ConflictSkip, DirectionBoth, older, newer, conflict},
+		{ConflictSkip, DirectionPush, older, newer, push},
+		{ConflictHost, DirectionPull, newer, older, pull},
+	}
+	for _, tc := range tests {
+		if got := resolve(tc.policy, tc.direction, tc.local, tc.remote); got != tc.want {
+			t.Errorf("resolve(%s, %s, %v, %v) = %d, want %d", tc.policy, tc.direction, tc.local, tc.remote, got, tc.want)
+		}
+	}
+}
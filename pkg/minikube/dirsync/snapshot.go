@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// statFormat prints the modification time, the size, the permissions and the name of a file
+const statFormat = "%Y %s %a %n"
+
+// File is the state of a file on one side of the sync
+type File struct {
+	Size    int64
+	ModTime int64
+	Mode    fs.FileMode
+}
+
+// Snapshot is the state of the files of a directory, by slash-separated path relative to it
+type Snapshot map[string]File
+
+// fileOf returns the state of a local file
+func fileOf(fi fs.FileInfo) File {
+	return File{Size: fi.Size(), ModTime: fi.ModTime().Unix(), Mode: fi.Mode().Perm()}
+}
+
+// scanLocal returns the state of the files below the local directory which are not ignored
+func scanLocal(root string, dir string, m *Matcher) (Snapshot, error) {
+	s := Snapshot{}
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(dir)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if m.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s[rel] = fileOf(fi)
+		return nil
+	})
+	return s, err
+}
+
+// listRemote returns the state of the files below the remote directory which are not ignored
+func listRemote(r command.Runner, root string, m *Matcher) (Snapshot, error) {
+	rr, err := r.RunCmd(exec.Command("sudo", "find", root, "-type", "f", "-exec", "stat", "-c", statFormat, "{}", "+"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "list %s", root)
+	}
+	return parseStat(rr.Stdout.String(), root, m), nil
+}
+
+// statRemote returns the state of the remote files which exist among the paths
+func statRemote(r command.Runner, root string, rels []string) (Snapshot, error) {
+	if len(rels) == 0 {
+		return Snapshot{}, nil
+	}
+	args := []string{"sh", "-c", `stat -c '` + statFormat + `' "$@" 2>/dev/null; true`, "sh"}
+	for _, rel := range rels {
+		args = append(args, path.Join(root, rel))
+	}
+	rr, err := r.RunCmd(exec.Command("sudo", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "stat")
+	}
+	return parseStat(rr.Stdout.String(), root, nil), nil
+}
+
+// parseStat parses the output of stat with statFormat, skipping the ignored paths
+func parseStat(output string, root string, m *Matcher) Snapshot {
+	s := Snapshot{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		mtime, err1 := strconv.ParseInt(fields[0], 10, 64)
+		size, err2 := strconv.ParseInt(fields[1], 10, 64)
+		mode, err3 := strconv.ParseUint(fields[2], 8, 32)
+		if err1 != nil || err2 != nil || err3 != nil {
+			klog.Warningf("unexpected stat output: %q", line)
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(fields[3], root), "/")
+		if rel == "" || (m != nil && m.Ignored(rel, false)) {
+			continue
+		}
+		s[rel] = File{Size: size, ModTime: mtime, Mode: fs.FileMode(mode)}
+	}
+	return s
+}
+
+// localChecksum returns the sha256 of a local file
+func localChecksum(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteChecksums returns the sha256 of the remote files, by path relative to the root
+func remoteChecksums(r command.Runner, root string, rels []string) (map[string]string, error) {
+	sums := map[string]string{}
+	if len(rels) == 0 {
+		return sums, nil
+	}
+	args := []string{"sha256sum"}
+	for _, rel := range rels {
+		args = append(args, path.Join(root, rel))
+	}
+	rr, err := r.RunCmd(exec.Command("sudo", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "sha256sum")
+	}
+	for _, line := range strings.Split(rr.Stdout.String(), "\n") {
+		fields := strings.SplitN(line, "  ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(strings.TrimPrefix(fields[1], root), "/")] = fields[0]
+	}
+	return sums, nil
+}
@@ -0,0 +1,534 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dirsync keeps a host directory and a directory of a node in sync
+package dirsync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+const (
+	// DefaultPollInterval is how often the node directory is listed for changes while watching
+	DefaultPollInterval = 2 * time.Second
+	// batchDelay is how long the host changes are collected before they are synced together
+	batchDelay = 200 * time.Millisecond
+	// tempPattern matches the files the pulled files are written to before they are renamed
+	tempPattern = ".minikube-sync-*"
+)
+
+// Options configure a Syncer
+type Options struct {
+	// Direction is one of Directions
+	Direction string
+	// Conflict is the policy, one of ConflictPolicies, for the files changed on both sides
+	Conflict string
+	// Ignore are .gitignore patterns, added to the ones of the .gitignore file of the host directory
+	Ignore []string
+	// PollInterval is how often the node directory is listed for changes while watching
+	PollInterval time.Duration
+}
+
+// Result lists what a sync did, by slash-separated path relative to the synced directories
+type Result struct {
+	Pushed        []string
+	Pulled        []string
+	DeletedOnNode []string
+	DeletedOnHost []string
+	// Conflicts are the files changed on both sides, left as they are
+	Conflicts []string
+	// Failed are the files which could not be synced, retried by the next sync
+	Failed []string
+}
+
+// Empty returns whether the sync did nothing
+func (r *Result) Empty() bool {
+	return len(r.Pushed)+len(r.Pulled)+len(r.DeletedOnNode)+len(r.DeletedOnHost)+len(r.Conflicts)+len(r.Failed) == 0
+}
+
+// Syncer keeps a host directory and a node directory in sync
+type Syncer struct {
+	runner  command.Runner
+	local   string
+	remote  string
+	opts    Options
+	matcher *Matcher
+	// base is the state of the files on both sides after the last sync
+	base map[string]pair
+	// current is the state of the host files, kept up to date from the file system events while watching
+	current Snapshot
+}
+
+// New returns a Syncer of the host directory and of the absolute directory of the node of the runner
+func New(r command.Runner, localDir string, remoteDir string, opts Options) (*Syncer, error) {
+	if opts.Direction == "" {
+		opts.Direction = DirectionBoth
+	}
+	if !slices.Contains(Directions, opts.Direction) {
+		return nil, errors.Errorf("invalid direction %q, must be one of: %s", opts.Direction, strings.Join(Directions, ", "))
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictHost
+	}
+	if !slices.Contains(ConflictPolicies, opts.Conflict) {
+		return nil, errors.Errorf("invalid conflict policy %q, must be one of: %s", opts.Conflict, strings.Join(ConflictPolicies, ", "))
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if !path.IsAbs(remoteDir) {
+		return nil, errors.Errorf("the node directory %q must be an absolute path", remoteDir)
+	}
+
+	local, err := filepath.Abs(localDir)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(local)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, errors.Errorf("%s is not a directory", localDir)
+	}
+	m, err := LoadMatcher(local, append([]string{tempPattern}, opts.Ignore...))
+	if err != nil {
+		return nil, err
+	}
+	return &Syncer{
+		runner:  r,
+		local:   local,
+		remote:  path.Clean(remoteDir),
+		opts:    opts,
+		matcher: m,
+		base:    map[string]pair{},
+		current: Snapshot{},
+	}, nil
+}
+
+// Sync compares all the files of both directories and syncs the differences
+func (s *Syncer) Sync() (*Result, error) {
+	if _, err := s.runner.RunCmd(exec.Command("sudo", "mkdir", "-p", s.remote)); err != nil {
+		return nil, errors.Wrapf(err, "create %s", s.remote)
+	}
+	local, err := scanLocal(s.local, ".", s.matcher)
+	if err != nil {
+		return nil, errors.Wrapf(err, "scan %s", s.local)
+	}
+	s.current = local
+	remote, err := listRemote(s.runner, s.remote, s.matcher)
+	if err != nil {
+		return nil, err
+	}
+	return s.apply(s.allPaths(remote), remote), nil
+}
+
+// Watch syncs the host changes as they happen and the node changes every poll interval,
+// reporting what each sync did, until the context is done
+func (s *Syncer) Watch(ctx context.Context, report func(*Result)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "file watcher")
+	}
+	defer w.Close()
+	if err := s.watchDir(w, "."); err != nil {
+		return errors.Wrapf(err, "watch %s", s.local)
+	}
+
+	poll := time.NewTicker(s.opts.PollInterval)
+	defer poll.Stop()
+	dirty := map[string]bool{}
+	var batch <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return errors.New("the file watcher stopped")
+			}
+			rel, err := filepath.Rel(s.local, ev.Name)
+			if err != nil || rel == "." {
+				continue
+			}
+			dirty[filepath.ToSlash(rel)] = true
+			if batch == nil {
+				batch = time.After(batchDelay)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return errors.New("the file watcher stopped")
+			}
+			klog.Warningf("file watcher: %v", err)
+		case <-batch:
+			batch = nil
+			paths := s.refresh(w, dirty)
+			dirty = map[string]bool{}
+			remote, err := statRemote(s.runner, s.remote, paths)
+			if err != nil {
+				klog.Warningf("unable to stat the changed files on the node: %v", err)
+				continue
+			}
+			report(s.apply(paths, remote))
+		case <-poll.C:
+			if s.opts.Direction == DirectionPush {
+				continue
+			}
+			// the pending host changes must be known, not to be overwritten by the node
+			if len(dirty) > 0 {
+				paths := s.refresh(w, dirty)
+				dirty = map[string]bool{}
+				remote, err := statRemote(s.runner, s.remote, paths)
+				if err == nil {
+					report(s.apply(paths, remote))
+				}
+			}
+			remote, err := listRemote(s.runner, s.remote, s.matcher)
+			if err != nil {
+				klog.Warningf("unable to list the files on the node: %v", err)
+				continue
+			}
+			report(s.apply(s.allPaths(remote), remote))
+		}
+	}
+}
+
+// allPaths returns the paths known on either side
+func (s *Syncer) allPaths(remote Snapshot) []string {
+	seen := map[string]bool{}
+	for p := range s.base {
+		seen[p] = true
+	}
+	for p := range s.current {
+		seen[p] = true
+	}
+	for p := range remote {
+		seen[p] = true
+	}
+	var paths []string
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// watchDir watches the host directory and its subdirectories which are not ignored
+func (s *Syncer) watchDir(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(filepath.Join(s.local, filepath.FromSlash(dir)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.local, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && s.matcher.Ignored(filepath.ToSlash(rel), true) {
+			return filepath.SkipDir
+		}
+		return w.Add(p)
+	})
+}
+
+// refresh updates the state of the changed host paths, returning the files which may have changed
+func (s *Syncer) refresh(w *fsnotify.Watcher, dirty map[string]bool) []string {
+	changed := map[string]bool{}
+	// forget the files which are gone, or were below a path which is now something else
+	forget := func(rel string, keep Snapshot) {
+		for p := range s.current {
+			if (p == rel || strings.HasPrefix(p, rel+"/")) && !keepsPath(keep, p) {
+				delete(s.current, p)
+				changed[p] = true
+			}
+		}
+	}
+
+	for rel := range dirty {
+		fi, err := os.Lstat(filepath.Join(s.local, filepath.FromSlash(rel)))
+		switch {
+		case err != nil:
+			forget(rel, nil)
+		case fi.IsDir():
+			if s.matcher.Ignored(rel, true) {
+				continue
+			}
+			if err := s.watchDir(w, rel); err != nil {
+				klog.Warningf("unable to watch %s: %v", rel, err)
+			}
+			snap, err := scanLocal(s.local, rel, s.matcher)
+			if err != nil {
+				klog.Warningf("unable to scan %s: %v", rel, err)
+				continue
+			}
+			forget(rel, snap)
+			for p, f := range snap {
+				s.current[p] = f
+				changed[p] = true
+			}
+		case fi.Mode().IsRegular():
+			if s.matcher.Ignored(rel, false) {
+				continue
+			}
+			forget(rel, Snapshot{rel: fileOf(fi)})
+			s.current[rel] = fileOf(fi)
+			changed[rel] = true
+		}
+	}
+
+	var paths []string
+	for p := range changed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func keepsPath(keep Snapshot, p string) bool {
+	_, ok := keep[p]
+	return ok
+}
+
+// apply syncs the paths, given the current state of the host and the state of the paths on the node
+func (s *Syncer) apply(paths []string, remote Snapshot) *Result {
+	res := &Result{}
+	changes := plan(paths, s.base, s.current, remote, s.opts.Direction)
+	s.compare(changes, remote)
+
+	var pushes, pulls, removes []string
+	for i, c := range changes {
+		if c.action == conflict {
+			changes[i].action = resolve(s.opts.Conflict, s.opts.Direction, fileIn(s.current, c.path), fileIn(remote, c.path))
+		}
+		switch changes[i].action {
+		case inSync:
+			s.record(c.path, fileIn(s.current, c.path), fileIn(remote, c.path))
+		case conflict:
+			klog.Warningf("%s changed on the host and on the node, leaving both as they are", c.path)
+			res.Conflicts = append(res.Conflicts, c.path)
+			s.record(c.path, fileIn(s.current, c.path), fileIn(remote, c.path))
+		case push:
+			pushes = append(pushes, c.path)
+		case pull:
+			pulls = append(pulls, c.path)
+		case removeRemote:
+			removes = append(removes, c.path)
+		case removeLocal:
+			if err := os.Remove(filepath.Join(s.local, filepath.FromSlash(c.path))); err != nil && !os.IsNotExist(err) {
+				klog.Warningf("unable to delete %s: %v", c.path, err)
+				res.Failed = append(res.Failed, c.path)
+				continue
+			}
+			delete(s.current, c.path)
+			s.record(c.path, nil, nil)
+			res.DeletedOnHost = append(res.DeletedOnHost, c.path)
+		}
+	}
+
+	s.push(pushes, res)
+	s.removeRemote(removes, res)
+	for _, p := range pulls {
+		f, err := s.pull(p, remote[p])
+		if err != nil {
+			klog.Warningf("unable to pull %s: %v", p, err)
+			res.Failed = append(res.Failed, p)
+			continue
+		}
+		s.current[p] = f
+		s.record(p, &f, fileIn(remote, p))
+		res.Pulled = append(res.Pulled, p)
+	}
+	return res
+}
+
+// compare resolves the conflicts of the files whose contents turn out to be the same
+func (s *Syncer) compare(changes []change, remote Snapshot) {
+	var candidates []string
+	for _, c := range changes {
+		l, r := fileIn(s.current, c.path), fileIn(remote, c.path)
+		if c.action == conflict && l != nil && r != nil && l.Size == r.Size {
+			candidates = append(candidates, c.path)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	sums, err := remoteChecksums(s.runner, s.remote, candidates)
+	if err != nil {
+		klog.Warningf("unable to compare the files: %v", err)
+		return
+	}
+	for i, c := range changes {
+		sum, ok := sums[c.path]
+		if c.action != conflict || !ok {
+			continue
+		}
+		if local, err := localChecksum(filepath.Join(s.local, filepath.FromSlash(c.path))); err == nil && local == sum {
+			changes[i].action = inSync
+		}
+	}
+}
+
+// push copies the host files to the node, creating their directories first
+func (s *Syncer) push(paths []string, res *Result) {
+	if len(paths) == 0 {
+		return
+	}
+	dirs := map[string]bool{}
+	for _, p := range paths {
+		dirs[path.Dir(path.Join(s.remote, p))] = true
+	}
+	args := []string{"mkdir", "-p"}
+	for d := range dirs {
+		args = append(args, d)
+	}
+	if _, err := s.runner.RunCmd(exec.Command("sudo", args...)); err != nil {
+		klog.Warningf("unable to create the directories: %v", err)
+		res.Failed = append(res.Failed, paths...)
+		return
+	}
+
+	var copied []string
+	for _, p := range paths {
+		if err := s.copy(p); err != nil {
+			klog.Warningf("unable to push %s: %v", p, err)
+			res.Failed = append(res.Failed, p)
+			continue
+		}
+		copied = append(copied, p)
+	}
+	// the state of the copies is the base of the next sync
+	remote, err := statRemote(s.runner, s.remote, copied)
+	if err != nil {
+		klog.Warningf("unable to stat the pushed files: %v", err)
+	}
+	for _, p := range copied {
+		s.record(p, fileIn(s.current, p), fileIn(remote, p))
+	}
+	res.Pushed = append(res.Pushed, copied...)
+}
+
+// copy copies a host file to the node
+func (s *Syncer) copy(rel string) error {
+	l := s.current[rel]
+	dst := path.Join(s.remote, rel)
+	fa, err := assets.NewFileAsset(filepath.Join(s.local, filepath.FromSlash(rel)), path.Dir(dst), path.Base(dst), fmt.Sprintf("%04o", l.Mode))
+	if err != nil {
+		return err
+	}
+	defer fa.Close()
+	return s.runner.Copy(fa)
+}
+
+// removeRemote deletes the files from the node
+func (s *Syncer) removeRemote(paths []string, res *Result) {
+	if len(paths) == 0 {
+		return
+	}
+	args := []string{"rm", "-f"}
+	for _, p := range paths {
+		args = append(args, path.Join(s.remote, p))
+	}
+	if _, err := s.runner.RunCmd(exec.Command("sudo", args...)); err != nil {
+		klog.Warningf("unable to delete the files from the node: %v", err)
+		res.Failed = append(res.Failed, paths...)
+		return
+	}
+	for _, p := range paths {
+		s.record(p, nil, nil)
+	}
+	res.DeletedOnNode = append(res.DeletedOnNode, paths...)
+}
+
+// pull copies a node file to the host, returning the state of the host file
+func (s *Syncer) pull(rel string, r File) (File, error) {
+	rf, err := s.runner.ReadableFile(path.Join(s.remote, rel))
+	if err != nil {
+		return File{}, err
+	}
+	defer rf.Close()
+
+	dst := filepath.Join(s.local, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return File{}, err
+	}
+	// write next to the file, not to leave a partial file behind
+	tmp, err := os.CreateTemp(filepath.Dir(dst), tempPattern)
+	if err != nil {
+		return File{}, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, rf); err != nil {
+		tmp.Close()
+		return File{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return File{}, err
+	}
+	if err := os.Chmod(tmp.Name(), r.Mode); err != nil {
+		return File{}, err
+	}
+	mtime := time.Unix(r.ModTime, 0)
+	if err := os.Chtimes(tmp.Name(), mtime, mtime); err != nil {
+		return File{}, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return File{}, err
+	}
+	fi, err := os.Stat(dst)
+	if err != nil {
+		return File{}, err
+	}
+	return fileOf(fi), nil
+}
+
+// record sets the state of the path on both sides after a sync
+func (s *Syncer) record(p string, l *File, r *File) {
+	if l == nil && r == nil {
+		delete(s.base, p)
+		return
+	}
+	s.base[p] = pair{Local: l, Remote: r}
+}
+
+// fileIn returns a copy of the state of the path in the snapshot, or nil
+func fileIn(s Snapshot, p string) *File {
+	f, ok := s[p]
+	if !ok {
+		return nil
+	}
+	return &f
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dirsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// localRunner runs the node commands on the host, without sudo
+type localRunner struct {
+	command.Runner
+}
+
+func (r localRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	if cmd.Args[0] == "sudo" {
+		cmd = exec.Command(cmd.Args[1], cmd.Args[2:]...)
+	}
+	return r.Runner.RunCmd(cmd)
+}
+
+func (r localRunner) ReadableFile(p string) (assets.ReadableFile, error) {
+	return assets.NewFileAsset(p, filepath.Dir(p), filepath.Base(p), "0644")
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		p = filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, dir string, p string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+	if err != nil {
+		return "<missing>"
+	}
+	return string(b)
+}
+
+func sorted(r *Result) *Result {
+	for _, l := range [][]string{r.Pushed, r.Pulled, r.DeletedOnNode, r.DeletedOnHost, r.Conflicts, r.Failed} {
+		sort.Strings(l)
+	}
+	return r
+}
+
+func TestSync(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the node commands need GNU find and stat")
+	}
+	local, remote := t.TempDir(), t.TempDir()
+	writeFiles(t, local, map[string]string{
+		".gitignore":           "node_modules/\n",
+		"a.txt":                "a",
+		"sub/b.txt":            "b",
+		"node_modules/x.js":    "x",
+		"same.txt":             "same",
+		"diff.txt":             "host",
+		"skipped/ignored.tmp":  "tmp",
+		"skipped/included.txt": "included",
+	})
+	writeFiles(t, remote, map[string]string{
+		"c.txt":    "c",
+		"same.txt": "same",
+		"diff.txt": "node",
+	})
+
+	s, err := New(localRunner{command.NewExecRunner(false)}, local, remote, Options{Ignore: []string{"*.tmp"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	res, err := s.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	want := &Result{
+		Pushed: []string{".gitignore", "a.txt", "diff.txt", "skipped/included.txt", "sub/b.txt"},
+		Pulled: []string{"c.txt"},
+	}
+	if diff := cmp.Diff(want, sorted(res), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("first sync mismatch (-want +got):\n%s", diff)
+	}
+	for p, content := range map[string]string{"a.txt": "a", "sub/b.txt": "b", "diff.txt": "host", "node_modules/x.js": "<missing>", "skipped/ignored.tmp": "<missing>"} {
+		if got := readFile(t, remote, p); got != content {
+			t.Errorf("node %s = %q, want %q", p, got, content)
+		}
+	}
+	if got := readFile(t, local, "c.txt"); got != "c" {
+		t.Errorf("host c.txt = %q, want %q", got, "c")
+	}
+
+	res, err = s.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !res.Empty() {
+		t.Errorf("second sync did something: %+v", res)
+	}
+
+	writeFiles(t, remote, map[string]string{"c.txt": "changed on the node"})
+	if err := os.Remove(filepath.Join(local, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	res, err = s.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	want = &Result{Pulled: []string{"c.txt"}, DeletedOnNode: []string{"a.txt"}}
+	if diff := cmp.Diff(want, sorted(res), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("third sync mismatch (-want +got):\n%s", diff)
+	}
+	if got := readFile(t, local, "c.txt"); got != "changed on the node" {
+		t.Errorf("host c.txt = %q, want the node change", got)
+	}
+	if got := readFile(t, remote, "a.txt"); got != "<missing>" {
+		t.Errorf("node a.txt = %q, want it deleted", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the node commands need GNU find and stat")
+	}
+	local, remote := t.TempDir(), t.TempDir()
+	s, err := New(localRunner{command.NewExecRunner(false)}, local, remote, Options{PollInterval: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := s.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(ctx, func(*Result) {})
+	}()
+
+	eventually := func(dir string, p string, want string) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for readFile(t, dir, p) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s/%s = %q, want %q", dir, p, readFile(t, dir, p), want)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	// give the watcher the time to start
+	time.Sleep(200 * time.Millisecond)
+	writeFiles(t, local, map[string]string{"new/dir/host.txt": "from the host"})
+	eventually(remote, "new/dir/host.txt", "from the host")
+
+	writeFiles(t, remote, map[string]string{"node.txt": "from the node"})
+	eventually(local, "node.txt", "from the node")
+
+	if err := os.RemoveAll(filepath.Join(local, "new")); err != nil {
+		t.Fatal(err)
+	}
+	eventually(remote, "new/dir/host.txt", "<missing>")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch: %v", err)
+	}
+}
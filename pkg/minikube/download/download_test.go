@@ -242,3 +242,25 @@ func testPreloadWithCachedSizeZero(t *testing.T) {
 		t.Errorf("Expected only 1 download attempt but got %v!", downloadNum)
 	}
 }
+
+func TestParseTarballName(t *testing.T) {
+	tests := []struct {
+		name string
+		pv   string
+		kv   string
+		ok   bool
+	}{
+		{TarballName("v1.28.3", "docker"), PreloadVersion, "v1.28.3", true},
+		{TarballName("v1.31.0-rc.1", "crio"), PreloadVersion, "v1.31.0-rc.1", true},
+		{TarballName("v1.30.0-alpha.2", "containerd") + ".checksum", PreloadVersion, "v1.30.0-alpha.2", true},
+		{"preloaded-images-k8s-v1-v1.20.0-docker-overlay2-amd64.tar.lz4.lock", "v1", "v1.20.0", true},
+		{"preloaded-images-k8s-v18-v1.20.0.tar.lz4", "", "", false},
+		{"kicbase_v0.0.40.tar", "", "", false},
+	}
+	for _, tc := range tests {
+		pv, kv, ok := ParseTarballName(tc.name)
+		if pv != tc.pv || kv != tc.kv || ok != tc.ok {
+			t.Errorf("ParseTarballName(%q) = %q, %q, %t; want %q, %q, %t", tc.name, pv, kv, ok, tc.pv, tc.kv, tc.ok)
+		}
+	}
+}
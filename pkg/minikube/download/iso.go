@@ -66,6 +66,18 @@ func LocalISOResource(isoURL string) string {
 	return fileURI(localISOPath(u))
 }
 
+// ISOPathInCache returns the path of a remote ISO in the local cache directory
+func ISOPathInCache(isoURL string) (string, error) {
+	u, err := url.Parse(isoURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "url.parse %q", isoURL)
+	}
+	if u.Scheme == fileScheme {
+		return "", fmt.Errorf("%s is not a remote ISO", isoURL)
+	}
+	return localISOPath(u), nil
+}
+
 // fileURI returns a file:// URI for a path
 func fileURI(path string) string {
 	return "file://" + filepath.ToSlash(path)
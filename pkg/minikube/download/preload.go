@@ -25,6 +25,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"cloud.google.com/go/storage"
@@ -68,6 +69,18 @@ func TarballName(k8sVersion, containerRuntime string) string {
 	return fmt.Sprintf("preloaded-images-k8s-%s-%s-%s-%s-%s.tar.lz4", PreloadVersion, k8sVersion, containerRuntime, storageDriver, arch)
 }
 
+// tarballNameRe matches the names of the tarballs, and of their checksum and lock files
+var tarballNameRe = regexp.MustCompile(`^preloaded-images-k8s-(v\d+)-(v.+)-(docker|containerd|cri-o)-(overlay2?)-([^-.]+)\.tar\.lz4(\..+)?$`)
+
+// ParseTarballName returns the preload and Kubernetes versions of a tarball name returned by TarballName
+func ParseTarballName(name string) (preloadVersion string, k8sVersion string, ok bool) {
+	m := tarballNameRe.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
 // returns the name of the checksum file
 func checksumName(k8sVersion, containerRuntime string) string {
 	return fmt.Sprintf("%s.checksum", TarballName(k8sVersion, containerRuntime))
@@ -30,6 +30,8 @@ import (
 
 var (
 	shell bool
+	// hooks are called with the exit code before exiting
+	hooks []func(code int)
 )
 
 // SetShell configures if we are doing a shell configuration or not
@@ -67,8 +69,19 @@ func Message(r reason.Kind, format string, args ...out.V) {
 	Code(r.ExitCode)
 }
 
+// AddHook registers a function to be called with the exit code before exiting
+func AddHook(fn func(code int)) {
+	hooks = append(hooks, fn)
+}
+
 // Code will exit with a code
 func Code(code int) {
+	hs := hooks
+	// a hook exiting again must not run the hooks twice
+	hooks = nil
+	for _, h := range hs {
+		h(code)
+	}
 	if shell {
 		out.Output(os.Stdout, fmt.Sprintf("false exit code %d\n", code))
 	}
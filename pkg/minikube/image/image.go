@@ -193,8 +193,8 @@ func retrieveRemote(ref name.Reference, p v1.Platform) (v1.Image, error) {
 	return img, err
 }
 
-// imagePathInCache returns path in local cache directory
-func imagePathInCache(img string) string {
+// ImagePathInCache returns path in local cache directory
+func ImagePathInCache(img string) string {
 	f := filepath.Join(detect.ImageCacheDir(), img)
 	f = localpath.SanitizeCacheDir(f)
 	return f
@@ -207,7 +207,7 @@ func UploadCachedImage(imgName string) error {
 		klog.Infof("error parsing image name %s tag %v ", imgName, err)
 		return err
 	}
-	return uploadImage(tag, imagePathInCache(imgName))
+	return uploadImage(tag, ImagePathInCache(imgName))
 }
 
 func uploadImage(tag name.Tag, p string) error {
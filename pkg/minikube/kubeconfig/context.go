@@ -17,9 +17,12 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
 )
 
 // UnsetCurrentContext unsets the current-context from minikube to "" on minikube stop
@@ -92,8 +95,10 @@ func DeleteContext(machineName string, configPath ...string) error {
 	delete(kcfg.Clusters, machineName)
 	delete(kcfg.AuthInfos, machineName)
 	delete(kcfg.Contexts, machineName)
+	delete(kcfg.AuthInfos, ExecContextName(machineName))
+	delete(kcfg.Contexts, ExecContextName(machineName))
 
-	if kcfg.CurrentContext == machineName {
+	if kcfg.CurrentContext == machineName || kcfg.CurrentContext == ExecContextName(machineName) {
 		kcfg.CurrentContext = ""
 	}
 
@@ -102,3 +107,98 @@ func DeleteContext(machineName string, configPath ...string) error {
 	}
 	return nil
 }
+
+// CopyContext copies the cluster, user and context entries of machine src to machine dst,
+// pointing the client certificates of dst into its own profile directory
+func CopyContext(src string, dst string, configPath ...string) error {
+	fPath := PathFromEnv()
+	if configPath != nil {
+		fPath = configPath[0]
+	}
+	kcfg, err := readOrNew(fPath)
+	if err != nil {
+		return errors.Wrap(err, "Error getting kubeconfig status")
+	}
+
+	ctx, ok := kcfg.Contexts[src]
+	if !ok {
+		klog.Infof("kubeconfig has no context for %s, nothing to copy", src)
+		return nil
+	}
+	if _, ok := kcfg.Contexts[dst]; ok {
+		return errors.Errorf("kubeconfig already has a context named %s", dst)
+	}
+
+	nctx := ctx.DeepCopy()
+	nctx.Cluster = dst
+	nctx.AuthInfo = dst
+	kcfg.Contexts[dst] = nctx
+
+	if cluster, ok := kcfg.Clusters[ctx.Cluster]; ok {
+		kcfg.Clusters[dst] = cluster.DeepCopy()
+	}
+	if user, ok := kcfg.AuthInfos[ctx.AuthInfo]; ok {
+		nuser := user.DeepCopy()
+		srcDir, dstDir := localpath.Profile(src), localpath.Profile(dst)
+		if strings.HasPrefix(nuser.ClientCertificate, srcDir) {
+			nuser.ClientCertificate = dstDir + strings.TrimPrefix(nuser.ClientCertificate, srcDir)
+		}
+		if strings.HasPrefix(nuser.ClientKey, srcDir) {
+			nuser.ClientKey = dstDir + strings.TrimPrefix(nuser.ClientKey, srcDir)
+		}
+		kcfg.AuthInfos[dst] = nuser
+	}
+
+	if err := writeToFile(kcfg, fPath); err != nil {
+		return errors.Wrap(err, "writing kubeconfig")
+	}
+	return nil
+}
+
+// ExecContextName returns the name of the context, and of its user, logging in to the cluster of the machine
+// with an exec credential plugin
+func ExecContextName(machineName string) string {
+	return "oidc@" + machineName
+}
+
+// SetExecContext writes a context logging in to the cluster of the machine with the exec credential plugin,
+// next to the context of its admin user. The context is removed when exec is nil.
+func SetExecContext(machineName string, exec *api.ExecConfig, configPath ...string) error {
+	fPath := PathFromEnv()
+	if configPath != nil {
+		fPath = configPath[0]
+	}
+	kcfg, err := readOrNew(fPath)
+	if err != nil {
+		return errors.Wrap(err, "Error getting kubeconfig status")
+	}
+
+	name := ExecContextName(machineName)
+	if exec == nil {
+		if _, ok := kcfg.Contexts[name]; !ok {
+			return nil
+		}
+		delete(kcfg.AuthInfos, name)
+		delete(kcfg.Contexts, name)
+		if kcfg.CurrentContext == name {
+			kcfg.CurrentContext = machineName
+		}
+		return writeToFile(kcfg, fPath)
+	}
+
+	ctx, ok := kcfg.Contexts[machineName]
+	if !ok {
+		return errors.Errorf("kubeconfig has no context for %s", machineName)
+	}
+	user := api.NewAuthInfo()
+	user.Exec = exec
+	kcfg.AuthInfos[name] = user
+	nctx := ctx.DeepCopy()
+	nctx.AuthInfo = name
+	kcfg.Contexts[name] = nctx
+
+	if err := writeToFile(kcfg, fPath); err != nil {
+		return errors.Wrap(err, "writing kubeconfig")
+	}
+	return nil
+}
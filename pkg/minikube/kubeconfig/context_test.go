@@ -20,6 +20,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/minikube/pkg/minikube/localpath"
 )
 
 func TestDeleteContext(t *testing.T) {
@@ -48,6 +51,60 @@ func TestDeleteContext(t *testing.T) {
 	}
 }
 
+func TestCopyContext(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, "/home/la-croix")
+	kubeConfig := []byte(`
+apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority: /home/la-croix/.minikube/ca.crt
+    server: https://192.168.1.1:8443
+  name: la-croix
+contexts:
+- context:
+    cluster: la-croix
+    user: la-croix
+  name: la-croix
+current-context: la-croix
+kind: Config
+preferences: {}
+users:
+- name: la-croix
+  user:
+    client-certificate: /home/la-croix/.minikube/profiles/la-croix/client.crt
+    client-key: /home/la-croix/.minikube/profiles/la-croix/client.key
+`)
+	fn := tempFile(t, kubeConfig)
+	defer os.Remove(fn)
+	if err := CopyContext("la-croix", "perrier", fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyContext("la-croix", "perrier", fn); err == nil {
+		t.Errorf("expected an error when copying onto an existing context")
+	}
+
+	cfg, err := readOrNew(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, ok := cfg.Contexts["perrier"]
+	if !ok || ctx.Cluster != "perrier" || ctx.AuthInfo != "perrier" {
+		t.Fatalf("unexpected context: %+v", ctx)
+	}
+	if cfg.Clusters["perrier"].Server != "https://192.168.1.1:8443" {
+		t.Errorf("unexpected cluster: %+v", cfg.Clusters["perrier"])
+	}
+	if got := cfg.AuthInfos["perrier"].ClientCertificate; got != "/home/la-croix/.minikube/profiles/perrier/client.crt" {
+		t.Errorf("ClientCertificate = %s", got)
+	}
+	if got := cfg.AuthInfos["perrier"].ClientKey; got != "/home/la-croix/.minikube/profiles/perrier/client.key" {
+		t.Errorf("ClientKey = %s", got)
+	}
+	if cfg.CurrentContext != "la-croix" || cfg.AuthInfos["la-croix"].ClientKey != "/home/la-croix/.minikube/profiles/la-croix/client.key" {
+		t.Errorf("source context was modified")
+	}
+}
+
 func TestSetCurrentContext(t *testing.T) {
 	f, err := os.CreateTemp("/tmp", "kubeconfig")
 	if err != nil {
@@ -146,3 +203,39 @@ func TestUnsetCurrentContextOnlyChangesIfProfileIsTheCurrentContext(t *testing.T
 		t.Errorf("Expected context name %s but got %s", contextName, cfg.CurrentContext)
 	}
 }
+
+func TestSetExecContext(t *testing.T) {
+	fn := tempFile(t, kubeConfigWithoutHTTPS)
+	defer os.Remove(fn)
+
+	exec := &api.ExecConfig{APIVersion: "client.authentication.k8s.io/v1beta1", Command: "kubectl", Args: []string{"oidc-login", "get-token"}}
+	if err := SetExecContext("la-croix", exec, fn); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readOrNew(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := ExecContextName("la-croix")
+	ctx, ok := cfg.Contexts[name]
+	if !ok {
+		t.Fatalf("kubeconfig has no %s context", name)
+	}
+	if ctx.Cluster != "la-croix" || ctx.AuthInfo != name {
+		t.Errorf("%s context: cluster = %q, user = %q", name, ctx.Cluster, ctx.AuthInfo)
+	}
+	if user, ok := cfg.AuthInfos[name]; !ok || user.Exec == nil || user.Exec.Command != "kubectl" {
+		t.Errorf("%s user does not run the exec plugin: %+v", name, user)
+	}
+
+	if err := DeleteContext("la-croix", fn); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = readOrNew(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.AuthInfos) != 0 || len(cfg.Contexts) != 0 {
+		t.Errorf("DeleteContext() kept users %v and contexts %v", cfg.AuthInfos, cfg.Contexts)
+	}
+}
@@ -62,6 +62,16 @@ func Profile(name string) string {
 	return filepath.Join(MiniPath(), "profiles", name)
 }
 
+// Snapshots returns the path to the directory holding cluster snapshots
+func Snapshots() string {
+	return filepath.Join(MiniPath(), "snapshots")
+}
+
+// Snapshot returns the path to a cluster snapshot
+func Snapshot(name string) string {
+	return filepath.Join(Snapshots(), name)
+}
+
 // EventLog returns the path to a CloudEvents log
 // This log contains the transient state of minikube and the completed steps on start.
 func EventLog(name string) string {
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+)
+
+// auditFilterKeys are the fields of the audit events that can be filtered on
+var auditFilterKeys = []string{"user", "verb", "resource", "namespace", "stage"}
+
+// auditEvent holds the fields of an audit.k8s.io/v1 Event used by the filters
+type auditEvent struct {
+	Stage string `json:"stage"`
+	Verb  string `json:"verb"`
+	User  struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectRef *struct {
+		Resource  string `json:"resource"`
+		Namespace string `json:"namespace"`
+	} `json:"objectRef"`
+}
+
+// AuditFilter selects the apiserver audit events having one of the values of each of its fields
+type AuditFilter map[string][]string
+
+// ParseAuditFilter parses the key=value filters of the audit events
+func ParseAuditFilter(filters []string) (AuditFilter, error) {
+	f := AuditFilter{}
+	for _, kv := range filters {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || v == "" {
+			return nil, fmt.Errorf("invalid filter %q, expected key=value", kv)
+		}
+		if !slices.Contains(auditFilterKeys, k) {
+			return nil, fmt.Errorf("invalid filter %q, the key must be one of %v", kv, auditFilterKeys)
+		}
+		f[k] = append(f[k], v)
+	}
+	return f, nil
+}
+
+// Match returns whether the JSON audit event is selected by the filter
+func (f AuditFilter) Match(line []byte) bool {
+	if len(f) == 0 {
+		return true
+	}
+	e := auditEvent{}
+	if err := json.Unmarshal(line, &e); err != nil {
+		klog.Warningf("skipping invalid audit event %q: %v", line, err)
+		return false
+	}
+	fields := map[string]string{
+		"user":  e.User.Username,
+		"verb":  e.Verb,
+		"stage": e.Stage,
+	}
+	if e.ObjectRef != nil {
+		fields["resource"] = e.ObjectRef.Resource
+		fields["namespace"] = e.ObjectRef.Namespace
+	}
+	for k, vs := range f {
+		if !slices.Contains(vs, fields[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// OutputAPIServerAudit writes the last JSON audit events of the apiserver selected by the filter,
+// and the new ones as they are logged if follow is set
+func OutputAPIServerAudit(cr logRunner, lines int, follow bool, f AuditFilter, w io.Writer) error {
+	tail := fmt.Sprintf("sudo tail -n %d", lines)
+	if follow {
+		// the log is rotated by the apiserver
+		tail += " -F"
+	}
+	c := exec.Command("/bin/bash", "-c", tail+" "+bsutil.AuditLogFile)
+
+	pr, pw := io.Pipe()
+	c.Stdout = pw
+	done := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		// the events with the bodies of the requests can be large
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		var werr error
+		for scanner.Scan() {
+			if l := scanner.Bytes(); werr == nil && f.Match(l) {
+				_, werr = fmt.Fprintf(w, "%s\n", l)
+			}
+		}
+		// keep reading, the command would block on a full pipe
+		_, _ = io.Copy(io.Discard, pr)
+		if werr != nil {
+			done <- werr
+			return
+		}
+		done <- scanner.Err()
+	}()
+
+	_, err := cr.RunCmd(c)
+	pw.Close()
+	if serr := <-done; serr != nil && err == nil {
+		err = serr
+	}
+	if err != nil {
+		return errors.Wrap(err, "reading audit log")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+const (
+	podCreate    = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"create","user":{"username":"jane"},"objectRef":{"resource":"pods","namespace":"default","name":"web"}}`
+	secretGet    = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"get","user":{"username":"system:serviceaccount:kube-system:coredns"},"objectRef":{"resource":"secrets","namespace":"kube-system","name":"token"}}`
+	healthzCheck = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"get","user":{"username":"jane"},"requestURI":"/healthz"}`
+)
+
+// auditRunner writes the audit log to the stdout of the commands
+type auditRunner struct {
+	log  string
+	cmds []string
+}
+
+func (r *auditRunner) RunCmd(c *exec.Cmd) (*command.RunResult, error) {
+	r.cmds = append(r.cmds, strings.Join(c.Args, " "))
+	_, err := fmt.Fprint(c.Stdout, r.log)
+	return &command.RunResult{Args: c.Args}, err
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	f, err := ParseAuditFilter([]string{"user=jane", "verb=create", "verb=delete"})
+	if err != nil {
+		t.Fatalf("ParseAuditFilter() error = %v", err)
+	}
+	if len(f["verb"]) != 2 || f["user"][0] != "jane" {
+		t.Errorf("ParseAuditFilter() = %v", f)
+	}
+	for _, bad := range []string{"user", "user=", "name=web"} {
+		if _, err := ParseAuditFilter([]string{bad}); err == nil {
+			t.Errorf("ParseAuditFilter(%q) should have failed", bad)
+		}
+	}
+}
+
+func TestAuditFilterMatch(t *testing.T) {
+	tests := []struct {
+		filters []string
+		want    []bool // podCreate, secretGet, healthzCheck
+	}{
+		{nil, []bool{true, true, true}},
+		{[]string{"user=jane"}, []bool{true, false, true}},
+		{[]string{"verb=create", "verb=get"}, []bool{true, true, true}},
+		{[]string{"resource=secrets"}, []bool{false, true, false}},
+		{[]string{"namespace=default", "verb=create"}, []bool{true, false, false}},
+	}
+	for _, tc := range tests {
+		f, err := ParseAuditFilter(tc.filters)
+		if err != nil {
+			t.Fatalf("ParseAuditFilter(%v) error = %v", tc.filters, err)
+		}
+		for i, e := range []string{podCreate, secretGet, healthzCheck} {
+			if got := f.Match([]byte(e)); got != tc.want[i] {
+				t.Errorf("%v Match(%s) = %t, want %t", tc.filters, e, got, tc.want[i])
+			}
+		}
+	}
+	if (AuditFilter{"user": {"jane"}}).Match([]byte("not json")) {
+		t.Errorf("Match() of an invalid event should be false")
+	}
+}
+
+func TestOutputAPIServerAudit(t *testing.T) {
+	r := &auditRunner{log: strings.Join([]string{podCreate, secretGet, healthzCheck}, "\n") + "\n"}
+	var b bytes.Buffer
+	if err := OutputAPIServerAudit(r, 20, true, AuditFilter{"user": {"jane"}}, &b); err != nil {
+		t.Fatalf("OutputAPIServerAudit() error = %v", err)
+	}
+	if want := podCreate + "\n" + healthzCheck + "\n"; b.String() != want {
+		t.Errorf("OutputAPIServerAudit() = %q, want %q", b.String(), want)
+	}
+	if want := "/bin/bash -c sudo tail -n 20 -F /var/log/kubernetes/audit/audit.log"; len(r.cmds) != 1 || r.cmds[0] != want {
+		t.Errorf("commands = %v, want %q", r.cmds, want)
+	}
+}
@@ -0,0 +1,598 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/version"
+)
+
+const (
+	// DefaultBundleLines is how many lines of each log are collected in a bundle
+	DefaultBundleLines = 5000
+	// redacted replaces the secrets of the cluster config
+	redacted = "<redacted>"
+)
+
+// bundleResources are the resources of the cluster collected in a bundle
+var bundleResources = []string{
+	"nodes",
+	"namespaces",
+	"pods",
+	"services",
+	"endpoints",
+	"deployments",
+	"daemonsets",
+	"statefulsets",
+	"replicasets",
+	"persistentvolumes",
+	"persistentvolumeclaims",
+	"storageclasses",
+	"events",
+}
+
+// nodeConfigFiles are the kubeadm and kubelet configs collected from each node
+var nodeConfigFiles = []string{
+	constants.KubeadmYamlPath,
+	bsutil.KubeletSystemdConfFile,
+	"/var/lib/kubelet/config.yaml",
+}
+
+// secretEnvRe matches the names of the environment variables holding secrets
+var secretEnvRe = regexp.MustCompile(`(?i)(secret|password|passwd|token|key)`)
+
+// secretFlagRe matches the names of the flags holding secrets, the "key" ones holding the paths of key files
+var secretFlagRe = regexp.MustCompile(`(?i)(secret|password|passwd|token)`)
+
+// pairFlags are the flags whose values are KEY=VALUE pairs, with the regexp matching the keys of their secrets
+var pairFlags = map[string]*regexp.Regexp{
+	"docker-env":   secretEnvRe,
+	"docker-opt":   secretEnvRe,
+	"extra-config": secretFlagRe,
+}
+
+// minSecretLength is the length below which the secret values are not replaced in the files of a bundle,
+// as they would also replace the same characters everywhere in the logs
+const minSecretLength = 4
+
+// envKeys are the keys of the environment variables in the output of docker inspect and crictl inspect
+var envKeys = map[string]bool{"Env": true, "env": true, "envs": true}
+
+// unsafeNameRe matches the characters replaced in the names of the files of a bundle
+var unsafeNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// BundleNode is a node of the cluster whose logs are collected in a bundle
+type BundleNode struct {
+	Name         string
+	ControlPlane bool
+	Runner       command.Runner
+	Runtime      cruntime.Manager
+	Bootstrapper bootstrapper.Bootstrapper
+}
+
+// BundleFile describes a file of a bundle
+type BundleFile struct {
+	Name   string `json:"name"`
+	Node   string `json:"node,omitempty"`
+	Source string `json:"source"`
+	Size   int    `json:"size"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BundleManifest describes the contents of a bundle, written as its manifest.json
+type BundleManifest struct {
+	Profile           string       `json:"profile"`
+	MinikubeVersion   string       `json:"minikubeVersion"`
+	KubernetesVersion string       `json:"kubernetesVersion"`
+	Created           time.Time    `json:"created"`
+	Nodes             []string     `json:"nodes"`
+	Files             []BundleFile `json:"files"`
+}
+
+// bundle writes the files of a diagnostic archive, recording them in its manifest
+type bundle struct {
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	created  time.Time
+	manifest BundleManifest
+	// secrets are the values replaced in every file of the bundle
+	secrets []string
+}
+
+func newBundle(w io.Writer, cc config.ClusterConfig) *bundle {
+	gz := gzip.NewWriter(w)
+	now := time.Now()
+	return &bundle{
+		gz:      gz,
+		tw:      tar.NewWriter(gz),
+		created: now,
+		secrets: secretValues(cc),
+		manifest: BundleManifest{
+			Profile:           cc.Name,
+			MinikubeVersion:   version.GetVersion(),
+			KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+			Created:           now.UTC(),
+			Nodes:             []string{},
+			Files:             []BundleFile{},
+		},
+	}
+}
+
+// add writes a file of the bundle, the error of its collection being recorded in the manifest
+func (b *bundle) add(name string, node string, source string, data []byte, collectErr error) error {
+	for _, s := range b.secrets {
+		data = bytes.ReplaceAll(data, []byte(s), []byte(redacted))
+	}
+	f := BundleFile{Name: name, Node: node, Source: source, Size: len(data)}
+	if collectErr != nil {
+		klog.Warningf("collecting %s: %v", name, collectErr)
+		f.Error = collectErr.Error()
+	}
+	if err := b.write(name, data); err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, f)
+	return nil
+}
+
+func (b *bundle) write(name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: b.created}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing %s header: %w", name, err)
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// close writes the manifest and flushes the archive
+func (b *bundle) close() error {
+	m, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %w", err)
+	}
+	if err := b.write("manifest.json", m); err != nil {
+		return err
+	}
+	if err := b.tw.Close(); err != nil {
+		return fmt.Errorf("closing tar: %w", err)
+	}
+	return b.gz.Close()
+}
+
+// bundleFileName returns a file name for a log, eg "kube-apiserver_1f2e3d.log" for "kube-apiserver [1f2e3d]"
+func bundleFileName(name string, ext string) string {
+	return strings.Trim(unsafeNameRe.ReplaceAllString(name, "_"), "_") + ext
+}
+
+// RedactConfig returns a copy of the cluster config without its secrets, to be shared in bug reports
+func RedactConfig(cc config.ClusterConfig) config.ClusterConfig {
+	if cc.KubernetesConfig.OIDC != nil {
+		oidc := *cc.KubernetesConfig.OIDC
+		if oidc.ClientSecret != "" {
+			oidc.ClientSecret = redacted
+		}
+		cc.KubernetesConfig.OIDC = &oidc
+	}
+	if cc.KubernetesConfig.ExtraOptions != nil {
+		opts := make(config.ExtraOptionSlice, len(cc.KubernetesConfig.ExtraOptions))
+		for i, o := range cc.KubernetesConfig.ExtraOptions {
+			o.Value = redactValue(secretFlagRe, o.Key, o.Value)
+			opts[i] = o
+		}
+		cc.KubernetesConfig.ExtraOptions = opts
+	}
+	cc.DockerEnv = redactEnv(cc.DockerEnv)
+	cc.DockerOpt = redactEnv(cc.DockerOpt)
+	return cc
+}
+
+// secretValues returns the values of the secrets of the cluster and of the other profiles, the ones RedactConfig redacts,
+// longest first so that they are replaced before the values they contain
+func secretValues(cc config.ClusterConfig) []string {
+	ccs := []config.ClusterConfig{cc}
+	ps, err := config.ListValidProfiles()
+	if err != nil {
+		klog.Warningf("listing the profiles: %v", err)
+	}
+	for _, p := range ps {
+		ccs = append(ccs, *p.Config)
+	}
+
+	seen := map[string]bool{}
+	var secrets []string
+	add := func(original string, redactedValue string) {
+		if original == redactedValue || len(original) < minSecretLength || seen[original] {
+			return
+		}
+		seen[original] = true
+		secrets = append(secrets, original)
+		// the password of an URL is also redacted on its own, such as when the URL is logged without it
+		if u, err := url.Parse(original); err == nil && u.User != nil {
+			if p, ok := u.User.Password(); ok && len(p) >= minSecretLength && !seen[p] {
+				seen[p] = true
+				secrets = append(secrets, p)
+			}
+		}
+	}
+	for _, c := range ccs {
+		if c.KubernetesConfig.OIDC != nil {
+			add(c.KubernetesConfig.OIDC.ClientSecret, "")
+		}
+		for _, o := range c.KubernetesConfig.ExtraOptions {
+			add(o.Value, redactValue(secretFlagRe, o.Key, o.Value))
+		}
+		for _, kv := range append(append([]string{}, c.DockerEnv...), c.DockerOpt...) {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				add(v, redactValue(secretEnvRe, k, v))
+			}
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// redactLines redacts the values of the secret flags in each line of a log or a config
+func redactLines(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, l := range lines {
+		lines[i] = redactArgs(l)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// stripEnv removes the environment variables from the JSON output of docker inspect or crictl inspect, which may
+// hold secrets. The output is returned unchanged if it can not be parsed.
+func stripEnv(data []byte) []byte {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return b.Bytes()
+		}
+		if err != nil {
+			klog.Warningf("unable to remove the environment of the inspected containers: %v", err)
+			return data
+		}
+		if err := enc.Encode(deleteEnv(v)); err != nil {
+			return data
+		}
+	}
+}
+
+// deleteEnv deletes the environment variables from the decoded JSON value
+func deleteEnv(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if envKeys[k] {
+				delete(v, k)
+				continue
+			}
+			v[k] = deleteEnv(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = deleteEnv(e)
+		}
+	}
+	return v
+}
+
+// redactEnv redacts the KEY=VALUE pairs of the secrets, and the passwords of the URLs like the ones of the proxies
+func redactEnv(env []string) []string {
+	if env == nil {
+		return nil
+	}
+	r := make([]string, len(env))
+	for i, kv := range env {
+		r[i] = redactPair(secretEnvRe, kv)
+	}
+	return r
+}
+
+// redactPair redacts the value of a KEY=VALUE pair whose key is matched by re, or the password of its URL value
+func redactPair(re *regexp.Regexp, kv string) string {
+	k, v, ok := strings.Cut(kv, "=")
+	if !ok {
+		return kv
+	}
+	return k + "=" + redactValue(re, k, v)
+}
+
+// redactValue redacts the value if its name is matched by re, else the password of the value if it is an URL
+func redactValue(re *regexp.Regexp, name string, value string) string {
+	if re.MatchString(name) {
+		return redacted
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			return u.String()
+		}
+	}
+	return value
+}
+
+// redactArgs redacts the values of the secret flags in the args of a command, as recorded by the audit log
+func redactArgs(args string) string {
+	fields := strings.Split(args, " ")
+	for i := 0; i < len(fields); i++ {
+		if !strings.HasPrefix(fields[i], "--") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(fields[i], "--"), "=")
+		re, pairs := pairFlags[name]
+		if !pairs && !secretFlagRe.MatchString(name) {
+			continue
+		}
+		redact := func(v string) string {
+			if pairs {
+				return redactPair(re, v)
+			}
+			return redacted
+		}
+		switch {
+		case hasValue:
+			fields[i] = "--" + name + "=" + redact(value)
+		case i+1 < len(fields) && !strings.HasPrefix(fields[i+1], "-"):
+			// the value is the next arg
+			i++
+			fields[i] = redact(fields[i])
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// redactAudit returns the rows of the audit log with the secret flags of their args redacted
+func redactAudit(data []byte) []byte {
+	var b bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		r, err := redactAuditRow(line)
+		if err != nil {
+			// the row is left out rather than leaking its secrets
+			klog.Warningf("skipping audit row: %v", err)
+			continue
+		}
+		b.Write(r)
+	}
+	return b.Bytes()
+}
+
+// redactAuditRow redacts the args of an audit row, a cloud event with the row as its data
+func redactAuditRow(line []byte) ([]byte, error) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil, err
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal(event["data"], &row); err != nil {
+		return nil, err
+	}
+	if args, ok := row["args"].(string); ok {
+		row["args"] = redactArgs(args)
+	}
+	// the encoder keeps the brackets of the redacted values readable
+	var d bytes.Buffer
+	enc := json.NewEncoder(&d)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(row); err != nil {
+		return nil, err
+	}
+	event["data"] = bytes.TrimSpace(d.Bytes())
+	var r bytes.Buffer
+	enc = json.NewEncoder(&r)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(event); err != nil {
+		return nil, err
+	}
+	return r.Bytes(), nil
+}
+
+// runBundleCmd runs a shell command of the bundle, returning its output even when it fails
+func runBundleCmd(r logRunner, cmd string) ([]byte, error) {
+	var b bytes.Buffer
+	c := exec.Command("/bin/bash", "-c", cmd)
+	c.Stdout = &b
+	c.Stderr = &b
+	if _, err := r.RunCmd(c); err != nil {
+		return b.Bytes(), err
+	}
+	return b.Bytes(), nil
+}
+
+// containerInspectCmds returns the commands inspecting the containers and the pods of the runtime
+func containerInspectCmds(r cruntime.Manager) map[string]string {
+	if r.Name() == "Docker" {
+		return map[string]string{"docker-inspect": "sudo docker ps -aq | xargs -r sudo docker inspect"}
+	}
+	return map[string]string{
+		"crictl-inspect":  "sudo crictl ps -aq | xargs -r sudo crictl inspect",
+		"crictl-inspectp": "sudo crictl pods -q | xargs -r sudo crictl inspectp",
+	}
+}
+
+// WriteBundle writes a gzipped tar archive of the logs, configs and resources of the cluster, with each log of each node
+// in a separate file, a manifest.json listing the files and a summary.txt of the problems found in the logs
+func WriteBundle(cc config.ClusterConfig, nodes []BundleNode, lines int, w io.Writer) (*BundleManifest, error) {
+	b := newBundle(w, cc)
+
+	cfg, err := json.MarshalIndent(RedactConfig(cc), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling cluster config: %w", err)
+	}
+	if err := b.add("cluster/config.json", "", "ClusterConfig", cfg, nil); err != nil {
+		return nil, err
+	}
+	for name, p := range map[string]string{"minikube/audit.json": localpath.AuditLog(), "minikube/last-start.log": localpath.LastStartLog()} {
+		data, err := os.ReadFile(p)
+		if name == "minikube/audit.json" {
+			// the args of the commands hold the secrets given as flags
+			data = redactAudit(data)
+		} else {
+			data = redactLines(data)
+		}
+		if err := b.add(name, "", p, data, err); err != nil {
+			return nil, err
+		}
+	}
+
+	var summary strings.Builder
+	primary := true
+	for _, n := range nodes {
+		b.manifest.Nodes = append(b.manifest.Nodes, n.Name)
+		if n.ControlPlane && primary {
+			// the resources are the same from any control-plane node
+			primary = false
+			if err := addResources(b, cc, n); err != nil {
+				return nil, err
+			}
+		}
+		if err := addNode(b, cc, n, lines); err != nil {
+			return nil, err
+		}
+
+		problems := FindProblems(n.Runtime, n.Bootstrapper, cc, n.Runner)
+		fmt.Fprintf(&summary, "==> %s <==\n", n.Name)
+		if len(problems) == 0 {
+			summary.WriteString("No known problems found\n")
+		}
+		names := []string{}
+		for name := range problems {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&summary, "Problems detected in %s:\n", name)
+			for _, l := range problems[name] {
+				fmt.Fprintf(&summary, "  %s\n", l)
+			}
+		}
+		summary.WriteString("\n")
+	}
+	if err := b.add("summary.txt", "", "FindProblems", []byte(summary.String()), nil); err != nil {
+		return nil, err
+	}
+
+	if err := b.close(); err != nil {
+		return nil, err
+	}
+	return &b.manifest, nil
+}
+
+// addResources adds the resources of the cluster, listed with the kubectl of a control-plane node
+func addResources(b *bundle, cc config.ClusterConfig, n BundleNode) error {
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	kubeconfig := path.Join(vmpath.GuestPersistentDir, "kubeconfig")
+	for _, res := range bundleResources {
+		cmd := fmt.Sprintf("sudo %s --kubeconfig=%s get %s -A -o yaml", kubectl, kubeconfig, res)
+		data, err := runBundleCmd(n.Runner, cmd)
+		if err := b.add("cluster/"+res+".yaml", n.Name, cmd, data, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addNode adds the logs, container inspections, CNI and kubeadm configs of a node
+func addNode(b *bundle, cc config.ClusterConfig, n BundleNode, lines int) error {
+	dir := "nodes/" + bundleFileName(n.Name, "") + "/"
+
+	cmds := logCommands(n.Runtime, n.Bootstrapper, cc, lines, false)
+	cmds["kernel"] = "uptime && uname -a && grep PRETTY /etc/os-release"
+	if n.ControlPlane && cc.KubernetesConfig.Audit != nil {
+		cmds["apiserver-audit"] = fmt.Sprintf("sudo tail -n %d %s", lines, bsutil.AuditLogFile)
+	}
+	inspectCmds := containerInspectCmds(n.Runtime)
+	for name, cmd := range inspectCmds {
+		cmds[name] = cmd
+	}
+	names := []string{}
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		klog.Infof("Collecting %s of %s ...", name, n.Name)
+		data, err := runBundleCmd(n.Runner, cmds[name])
+		if _, inspect := inspectCmds[name]; inspect && err == nil {
+			data = stripEnv(data)
+		}
+		if err := b.add(dir+"logs/"+bundleFileName(name, ".log"), n.Name, cmds[name], data, err); err != nil {
+			return err
+		}
+	}
+
+	// the configs of the CNI, disabled ones included
+	ls, err := runBundleCmd(n.Runner, "sudo find /etc/cni/net.d -maxdepth 1 -type f")
+	if err := b.add(dir+"cni/files.txt", n.Name, "/etc/cni/net.d", ls, err); err != nil {
+		return err
+	}
+	var files []string
+	if err == nil {
+		files = strings.Fields(string(ls))
+	}
+	for _, f := range append(files, nodeConfigFiles...) {
+		sub := "config/"
+		if strings.HasPrefix(f, "/etc/cni/") {
+			sub = "cni/"
+		}
+		data, err := runBundleCmd(n.Runner, "sudo cat "+f)
+		if sub == "config/" {
+			// the kubelet flags of the extra configs
+			data = redactLines(data)
+		}
+		if err := b.add(dir+sub+path.Base(f), n.Name, f, data, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package machine
 
 import (
+	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
@@ -27,6 +29,7 @@ import (
 
 	dockerref "github.com/distribution/reference"
 
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -41,8 +44,8 @@ import (
 // buildRoot is where images should be built from within the guest VM
 var buildRoot = path.Join(vmpath.GuestPersistentDir, "build")
 
-// BuildImage builds image to all profiles
-func BuildImage(path string, file string, tag string, push bool, env []string, opt []string, profiles []*config.Profile, allNodes bool, nodeName string) error {
+// BuildImage builds image to all profiles. The cache directories and the secrets of the options are paths on the host.
+func BuildImage(path string, o cruntime.BuildImageOptions, profiles []*config.Profile, allNodes bool, nodeName string) error {
 	api, err := NewAPIClient()
 	if err != nil {
 		return errors.Wrap(err, "api")
@@ -61,12 +64,12 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 		remote = false
 	}
 
-	if tag != "" {
-		named, err := dockerref.ParseNormalizedNamed(tag)
+	if o.Tag != "" {
+		named, err := dockerref.ParseNormalizedNamed(o.Tag)
 		if err != nil {
-			return errors.Wrapf(err, "couldn't parse image reference %q", tag)
+			return errors.Wrapf(err, "couldn't parse image reference %q", o.Tag)
 		}
-		tag = named.String()
+		o.Tag = named.String()
 	}
 
 	for _, p := range profiles { // building images to all running profiles
@@ -116,9 +119,9 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 					return err
 				}
 				if remote {
-					err = buildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opt)
+					err = buildImage(cr, c.KubernetesConfig, path, o)
 				} else {
-					err = transferAndBuildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opt)
+					err = transferAndBuildImage(cr, c.KubernetesConfig, path, o)
 				}
 				if err != nil {
 					failed = append(failed, m)
@@ -132,28 +135,53 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 
 	klog.Infof("succeeded building to: %s", strings.Join(succeeded, " "))
 	klog.Infof("failed building to: %s", strings.Join(failed, " "))
+	if len(succeeded) == 0 && len(failed) > 0 {
+		return errors.Errorf("failed to build on %s", strings.Join(failed, ", "))
+	}
 	return nil
 }
 
 // buildImage builds a single image
-func buildImage(cr command.Runner, k8s config.KubernetesConfig, src string, file string, tag string, push bool, env []string, opt []string) error {
+func buildImage(cr command.Runner, k8s config.KubernetesConfig, src string, o cruntime.BuildImageOptions) error {
 	r, err := cruntime.New(cruntime.Config{Type: k8s.ContainerRuntime, Runner: cr})
 	if err != nil {
 		return errors.Wrap(err, "runtime")
 	}
 	klog.Infof("Building image from url: %s", src)
 
-	err = r.BuildImage(src, file, tag, push, env, opt)
-	if err != nil {
+	o.Dir = src
+	if err := runBuild(cr, r, o); err != nil {
 		return errors.Wrapf(err, "%s build %s", r.Name(), src)
 	}
 
-	klog.Infof("Built %s from %s", tag, src)
+	klog.Infof("Built %s from %s", o.Tag, src)
+	return nil
+}
+
+// runBuild builds the image, transferring the build cache and the secrets of the options between the host and the node
+func runBuild(cr command.Runner, r cruntime.Manager, o cruntime.BuildImageOptions) error {
+	hostCacheTo := o.CacheTo
+	nodeOpts, err := transferBuildFiles(cr, o)
+	defer func() {
+		args := []string{"rm", "-rf", buildCacheFrom, buildCacheFrom + ".tar", buildCacheTo, buildCacheTo + ".tar", buildSecrets}
+		if _, err := cr.RunCmd(exec.Command("sudo", args...)); err != nil {
+			klog.Warningf("failed to clean up the build files: %v", err)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	if err := r.BuildImage(nodeOpts); err != nil {
+		return err
+	}
+	if hostCacheTo != "" {
+		return retrieveBuildCache(cr, hostCacheTo)
+	}
 	return nil
 }
 
 // transferAndBuildImage transfers and builds a single image
-func transferAndBuildImage(cr command.Runner, k8s config.KubernetesConfig, src string, file string, tag string, push bool, env []string, opt []string) error {
+func transferAndBuildImage(cr command.Runner, k8s config.KubernetesConfig, src string, o cruntime.BuildImageOptions) error {
 	r, err := cruntime.New(cruntime.Config{Type: k8s.ContainerRuntime, Runner: cr})
 	if err != nil {
 		return errors.Wrap(err, "runtime")
@@ -197,11 +225,11 @@ func transferAndBuildImage(cr command.Runner, k8s config.KubernetesConfig, src s
 		return err
 	}
 
-	if file != "" && !path.IsAbs(file) {
-		file = path.Join(context, file)
+	if o.File != "" && !path.IsAbs(o.File) {
+		o.File = path.Join(context, o.File)
 	}
-	err = r.BuildImage(context, file, tag, push, env, opt)
-	if err != nil {
+	o.Dir = context
+	if err := runBuild(cr, r, o); err != nil {
 		return errors.Wrapf(err, "%s build %s", r.Name(), dst)
 	}
 
@@ -214,6 +242,149 @@ func transferAndBuildImage(cr command.Runner, k8s config.KubernetesConfig, src s
 		return err
 	}
 
-	klog.Infof("Built %s from %s", tag, src)
+	klog.Infof("Built %s from %s", o.Tag, src)
 	return nil
 }
+
+var (
+	// buildCacheFrom is where the build cache is imported from on the node
+	buildCacheFrom = path.Join(buildRoot, "cache-from")
+	// buildCacheTo is where the build cache is exported to on the node
+	buildCacheTo = path.Join(buildRoot, "cache-to")
+	// buildSecrets is where the build secrets are copied to on the node
+	buildSecrets = path.Join(buildRoot, "secrets")
+)
+
+// transferBuildFiles copies the build cache and the secrets from the host to the node,
+// returning the options with the paths on the node
+func transferBuildFiles(cr command.Runner, o cruntime.BuildImageOptions) (cruntime.BuildImageOptions, error) {
+	var owned []string
+	if o.CacheFrom != "" {
+		if _, err := os.Stat(o.CacheFrom); err != nil {
+			// nothing was exported there yet
+			klog.Infof("build cache %s is not available, building without it: %v", o.CacheFrom, err)
+			o.CacheFrom = ""
+		} else {
+			if err := copyDirToNode(cr, o.CacheFrom, buildCacheFrom); err != nil {
+				return o, errors.Wrap(err, "transferring the build cache")
+			}
+			o.CacheFrom = buildCacheFrom
+			owned = append(owned, buildCacheFrom)
+		}
+	}
+	if o.CacheTo != "" {
+		if _, err := cr.RunCmd(exec.Command("sudo", "mkdir", "-p", buildCacheTo)); err != nil {
+			return o, err
+		}
+		o.CacheTo = buildCacheTo
+		owned = append(owned, buildCacheTo)
+	}
+
+	var secrets []string
+	for _, s := range o.Secrets {
+		id, src, err := cruntime.SecretSource(s)
+		if err != nil {
+			return o, err
+		}
+		f, err := assets.NewFileAsset(src, buildSecrets, id, "0600")
+		if err != nil {
+			return o, errors.Wrapf(err, "creating copyable file asset: %s", src)
+		}
+		err = cr.Copy(f)
+		if cerr := f.Close(); cerr != nil {
+			klog.Warningf("error closing the file %s: %v", src, cerr)
+		}
+		if err != nil {
+			return o, errors.Wrapf(err, "transferring secret %s", id)
+		}
+		secrets = append(secrets, fmt.Sprintf("id=%s,src=%s", id, path.Join(buildSecrets, id)))
+	}
+	if len(secrets) > 0 {
+		owned = append(owned, buildSecrets)
+	}
+	o.Secrets = secrets
+
+	// the files are copied as root, but docker buildx reads and writes them as the user of the runner
+	if len(owned) > 0 {
+		chown := fmt.Sprintf("sudo chown -R $(id -u):$(id -g) %s", strings.Join(owned, " "))
+		if _, err := cr.RunCmd(exec.Command("/bin/bash", "-c", chown)); err != nil {
+			return o, errors.Wrap(err, "changing the owner of the build files")
+		}
+	}
+	return o, nil
+}
+
+// copyDirToNode copies a directory of the host to the node
+func copyDirToNode(cr command.Runner, src string, dst string) error {
+	tmp, err := os.CreateTemp("", "build-cache.*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	tar, err := archive.TarWithOptions(src, &archive.TarOptions{Compression: archive.Uncompressed})
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	_, err = io.Copy(tmp, tar)
+	tar.Close()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := assets.NewFileAsset(tmp.Name(), path.Dir(dst), path.Base(dst)+".tar", "0644")
+	if err != nil {
+		return errors.Wrapf(err, "creating copyable file asset: %s", tmp.Name())
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+		}
+	}()
+	if err := cr.Copy(f); err != nil {
+		return err
+	}
+	if _, err := cr.RunCmd(exec.Command("sudo", "mkdir", "-p", dst)); err != nil {
+		return err
+	}
+	_, err = cr.RunCmd(exec.Command("sudo", "tar", "-C", dst, "-xf", dst+".tar"))
+	return err
+}
+
+// retrieveBuildCache copies the build cache exported on the node to a directory of the host
+func retrieveBuildCache(cr command.Runner, dst string) error {
+	if _, err := cr.RunCmd(exec.Command("sudo", "tar", "-C", buildCacheTo, "-cf", buildCacheTo+".tar", ".")); err != nil {
+		return errors.Wrap(err, "archiving the build cache")
+	}
+	tmp, err := os.CreateTemp("", "build-cache.*.tar")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	f, err := assets.NewFileAsset(tmp.Name(), path.Dir(buildCacheTo), path.Base(buildCacheTo)+".tar", "0644")
+	if err != nil {
+		return errors.Wrapf(err, "creating copyable file asset: %s", tmp.Name())
+	}
+	err = cr.CopyFrom(f)
+	if cerr := f.Close(); cerr != nil {
+		klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), cerr)
+	}
+	if err != nil {
+		return errors.Wrap(err, "transferring the build cache")
+	}
+
+	r, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	return archive.Untar(r, dst, &archive.TarOptions{NoLchown: true})
+}
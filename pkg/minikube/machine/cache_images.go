@@ -671,7 +671,7 @@ func RemoveImages(images []string, profile *config.Profile) error {
 }
 
 // ListImages lists images on all nodes in profile
-func ListImages(profile *config.Profile, format string) error {
+func ListImages(profile *config.Profile, format string, o cruntime.ListImagesOptions) error {
 	api, err := NewAPIClient()
 	if err != nil {
 		return errors.Wrap(err, "error creating api client")
@@ -710,7 +710,7 @@ func ListImages(profile *config.Profile, format string) error {
 			if err != nil {
 				return errors.Wrap(err, "error creating container runtime")
 			}
-			list, err := cr.ListImages(cruntime.ListImagesOptions{})
+			list, err := cr.ListImages(o)
 			if err != nil {
 				klog.Warningf("Failed to list images for profile %s %v", pName, err.Error())
 				continue
@@ -763,6 +763,57 @@ func ListImages(profile *config.Profile, format string) error {
 	return nil
 }
 
+// InspectImage returns the details of an image on a node of the profile, the primary control-plane node by default
+func InspectImage(profile *config.Profile, img string, nodeName string) (*cruntime.ImageInspect, error) {
+	api, err := NewAPIClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating api client")
+	}
+	defer api.Close()
+
+	pName := profile.Name
+	c, err := config.Load(pName)
+	if err != nil {
+		klog.Errorf("Failed to load profile %q: %v", pName, err)
+		return nil, errors.Wrapf(err, "error loading config for profile :%v", pName)
+	}
+	cp, err := config.ControlPlane(*c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range c.Nodes {
+		m := config.MachineName(*c, n)
+		if nodeName == "" && n.Name != cp.Name {
+			continue
+		} else if nodeName != "" && nodeName != n.Name && nodeName != m {
+			continue
+		}
+
+		status, err := Status(api, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting status for %s", m)
+		}
+		if status != state.Running.String() {
+			return nil, fmt.Errorf("node %s is %s", m, status)
+		}
+		h, err := api.Load(m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load machine %q", m)
+		}
+		runner, err := CommandRunner(h)
+		if err != nil {
+			return nil, err
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: c.KubernetesConfig.ContainerRuntime, Runner: runner})
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating container runtime")
+		}
+		return cr.InspectImage(img)
+	}
+	return nil, fmt.Errorf("node %q not found in profile %s", nodeName, pName)
+}
+
 // mergeImageLists merges image lists from different nodes into a single list
 // all the repo tags of the same image will be preserved and grouped in one image item
 func mergeImageLists(lists [][]cruntime.ListImage) []cruntime.ListImage {
@@ -786,6 +837,9 @@ func mergeImageLists(lists [][]cruntime.ListImage) []cruntime.ListImage {
 					images[img.ID].RepoTags = append(images[img.ID].RepoTags, repoTag)
 				}
 			}
+			if img.InUse {
+				images[img.ID].InUse = true
+			}
 		}
 
 	}
@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// Kinds of items in the cache
+const (
+	CacheImages   = "images"
+	CachePreloads = "preloads"
+	CacheISOs     = "isos"
+	CacheKicBase  = "kic base images"
+	CacheBinaries = "kubernetes binaries"
+)
+
+// CacheKinds are the kinds of cache items, in display order
+var CacheKinds = []string{CacheImages, CachePreloads, CacheISOs, CacheKicBase, CacheBinaries}
+
+// CacheItem is a file, or a directory of kubernetes binaries, in the minikube cache
+type CacheItem struct {
+	Kind string
+	// Name is the path relative to the cache directory
+	Name     string
+	Path     string
+	Size     int64
+	Modified time.Time
+	// InUse is set for items needed by an existing profile or a kept Kubernetes version
+	InUse bool
+}
+
+// PruneOptions select what is pruned from the cache and the nodes
+type PruneOptions struct {
+	// OlderThan only prunes items created or modified longer ago
+	OlderThan time.Duration
+	// KeepK8sVersions are kept besides the Kubernetes versions of the existing profiles
+	KeepK8sVersions []string
+	// KeepImages are kept in the cache besides the images of the kept Kubernetes versions
+	KeepImages []string
+	// All prunes all unused images from the nodes instead of only the dangling ones
+	All bool
+}
+
+// prunable returns whether an unused item modified at t is old enough to be pruned
+func (o PruneOptions) prunable(t time.Time, now time.Time) bool {
+	if o.OlderThan <= 0 {
+		return true
+	}
+	if t.IsZero() {
+		// the age is unknown, play safe
+		return false
+	}
+	return now.Sub(t) >= o.OlderThan
+}
+
+// cacheKeep is what is in use in the cache
+type cacheKeep struct {
+	versions map[string]bool
+	paths    map[string]bool
+}
+
+func normalizeVersion(v string) string {
+	if v != "" && !strings.HasPrefix(v, "v") {
+		return "v" + v
+	}
+	return v
+}
+
+// newCacheKeep collects the cache items used by the profiles and the kept Kubernetes versions
+func newCacheKeep(profiles []*config.Profile, o PruneOptions) cacheKeep {
+	k := cacheKeep{versions: map[string]bool{}, paths: map[string]bool{}}
+	addImages := func(mirror string, version string) {
+		imgs, err := images.Kubeadm(mirror, version)
+		if err != nil {
+			klog.Warningf("unable to list the images of Kubernetes %s: %v", version, err)
+			return
+		}
+		k.addImages(imgs)
+	}
+	for _, p := range profiles {
+		cc := p.Config
+		if cc == nil {
+			continue
+		}
+		v := normalizeVersion(cc.KubernetesConfig.KubernetesVersion)
+		k.versions[v] = true
+		addImages(cc.KubernetesConfig.ImageRepository, v)
+		if cc.MinikubeISO != "" {
+			iso := strings.TrimPrefix(download.LocalISOResource(cc.MinikubeISO), "file://")
+			k.paths[filepath.Clean(filepath.FromSlash(iso))] = true
+		}
+		if cc.KicBaseImage != "" {
+			k.paths[download.ImagePathInCache(cc.KicBaseImage)] = true
+		}
+	}
+	for _, v := range o.KeepK8sVersions {
+		v = normalizeVersion(v)
+		k.versions[v] = true
+		addImages("", v)
+	}
+	k.addImages(o.KeepImages)
+	return k
+}
+
+func (k cacheKeep) addImages(imgs []string) {
+	for _, img := range imgs {
+		k.paths[image.ImagePathInCache(img)] = true
+	}
+}
+
+// preloadInUse returns whether a preload tarball or its checksum is for the current preload version and a kept Kubernetes version
+func (k cacheKeep) preloadInUse(name string) bool {
+	pv, kv, ok := download.ParseTarballName(name)
+	return ok && pv == download.PreloadVersion && k.versions[kv]
+}
+
+// ListCache returns the items in the cache, marking those in use by the profiles and the kept Kubernetes versions
+func ListCache(profiles []*config.Profile, o PruneOptions) ([]CacheItem, error) {
+	cacheDir := localpath.MakeMiniPath("cache")
+	k := newCacheKeep(profiles, o)
+	var items []CacheItem
+	add := func(kind string, path string, inUse bool) error {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		size, modified := fi.Size(), fi.ModTime()
+		if fi.IsDir() {
+			if size, modified, err = dirUsage(path); err != nil {
+				return err
+			}
+		}
+		name, err := filepath.Rel(cacheDir, path)
+		if err != nil {
+			name = path
+		}
+		items = append(items, CacheItem{Kind: kind, Name: name, Path: path, Size: size, Modified: modified, InUse: inUse})
+		return nil
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading cache dir")
+	}
+	for _, e := range entries {
+		dir := filepath.Join(cacheDir, e.Name())
+		if !e.IsDir() {
+			continue
+		}
+		switch e.Name() {
+		case "images":
+			err = walkFiles(dir, func(path string) error {
+				return add(CacheImages, path, k.paths[path])
+			})
+		case "preloaded-tarball":
+			err = walkFiles(dir, func(path string) error {
+				return add(CachePreloads, path, k.preloadInUse(filepath.Base(path)))
+			})
+		case "iso":
+			err = walkFiles(dir, func(path string) error {
+				return add(CacheISOs, path, k.paths[path])
+			})
+		case "kic":
+			err = walkFiles(dir, func(path string) error {
+				return add(CacheKicBase, path, k.paths[path])
+			})
+		case "linux", "darwin", "windows":
+			// <os>/<arch>/<kubernetes version>/<binary>
+			var versions []string
+			versions, err = filepath.Glob(filepath.Join(dir, "*", "*"))
+			if err != nil {
+				break
+			}
+			for _, v := range versions {
+				if err = add(CacheBinaries, v, k.versions[filepath.Base(v)]); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing %s", dir)
+		}
+	}
+	return items, nil
+}
+
+// PruneCandidates returns the items of the cache which are not in use and old enough to be pruned
+func PruneCandidates(items []CacheItem, o PruneOptions, now time.Time) []CacheItem {
+	var prune []CacheItem
+	for _, i := range items {
+		if !i.InUse && o.prunable(i.Modified, now) {
+			prune = append(prune, i)
+		}
+	}
+	return prune
+}
+
+// RemoveCacheItems removes items from the cache, returning the reclaimed space
+func RemoveCacheItems(items []CacheItem) (int64, error) {
+	var reclaimed int64
+	for _, i := range items {
+		klog.Infof("Pruning %s from the cache", i.Path)
+		if err := os.RemoveAll(i.Path); err != nil {
+			return reclaimed, errors.Wrapf(err, "removing %s", i.Path)
+		}
+		reclaimed += i.Size
+	}
+	// removes the directories of the images which are left empty
+	if err := image.DeleteFromCacheDir(nil); err != nil {
+		klog.Warningf("failed to clean up the image cache dir: %v", err)
+	}
+	return reclaimed, nil
+}
+
+// walkFiles calls fn for the regular files in dir, except download locks
+func walkFiles(dir string, fn func(path string) error) error {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && !strings.HasSuffix(path, ".lock") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirUsage returns the size of the files in dir and when the most recent one was modified
+func dirUsage(dir string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+			if info.ModTime().After(modified) {
+				modified = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return size, modified, err
+}
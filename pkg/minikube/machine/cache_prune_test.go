@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func writeCacheFile(t *testing.T, path string, size int, modified time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	cache := func(elem ...string) string {
+		return localpath.MakeMiniPath(append([]string{"cache"}, elem...)...)
+	}
+	arch := runtime.GOARCH
+
+	writeCacheFile(t, cache("images", arch, "registry.k8s.io", "pause_3.9"), 10, old)
+	writeCacheFile(t, cache("images", arch, "nginx_latest"), 20, old)
+	writeCacheFile(t, cache("images", arch, "busybox_latest"), 30, old)
+	writeCacheFile(t, cache("images", arch, "alpine_latest"), 40, now)
+	preload := func(v string) string {
+		return fmt.Sprintf("preloaded-images-k8s-%s-v1.28.3-docker-overlay2-%s.tar.lz4", v, arch)
+	}
+	writeCacheFile(t, cache("preloaded-tarball", preload(download.PreloadVersion)), 100, old)
+	writeCacheFile(t, cache("preloaded-tarball", preload(download.PreloadVersion)+".checksum"), 1, old)
+	writeCacheFile(t, cache("preloaded-tarball", preload("v1")), 200, old)
+	writeCacheFile(t, cache("preloaded-tarball", preload("v1")+".lock"), 0, old)
+	writeCacheFile(t, cache("linux", arch, "v1.28.3", "kubelet"), 300, old)
+	writeCacheFile(t, cache("linux", arch, "v1.20.0", "kubelet"), 400, old)
+	writeCacheFile(t, cache("linux", arch, "v1.20.0", "kubeadm"), 500, old)
+	writeCacheFile(t, cache("kic", arch, "kicbase_v0.0.40.tar"), 600, old)
+
+	profiles := []*config.Profile{{
+		Name: "p1",
+		Config: &config.ClusterConfig{
+			KicBaseImage:     "gcr.io/k8s-minikube/kicbase:v0.0.40",
+			KubernetesConfig: config.KubernetesConfig{KubernetesVersion: "v1.28.3"},
+		},
+	}}
+	o := PruneOptions{OlderThan: 24 * time.Hour, KeepImages: []string{"nginx:latest"}}
+	items, err := ListCache(profiles, o)
+	if err != nil {
+		t.Fatalf("ListCache: %v", err)
+	}
+	if len(items) != 10 {
+		t.Errorf("ListCache returned %d items; want 10: %+v", len(items), items)
+	}
+
+	var names []string
+	for _, i := range PruneCandidates(items, o, now) {
+		names = append(names, i.Name)
+	}
+	sort.Strings(names)
+	want := []string{
+		filepath.Join("images", arch, "busybox_latest"),
+		filepath.Join("linux", arch, "v1.20.0"),
+		filepath.Join("preloaded-tarball", preload("v1")),
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("PruneCandidates mismatch (-want +got):\n%s", diff)
+	}
+
+	// keeping the version also keeps its binaries
+	o.KeepK8sVersions = []string{"1.20.0"}
+	items, err = ListCache(profiles, o)
+	if err != nil {
+		t.Fatalf("ListCache: %v", err)
+	}
+	prune := PruneCandidates(items, o, now)
+	if len(prune) != 2 {
+		t.Fatalf("PruneCandidates with a kept version returned %+v", prune)
+	}
+	reclaimed, err := RemoveCacheItems(prune)
+	if err != nil {
+		t.Fatalf("RemoveCacheItems: %v", err)
+	}
+	if reclaimed != 230 {
+		t.Errorf("reclaimed %d bytes; want 230", reclaimed)
+	}
+	for _, i := range prune {
+		if _, err := os.Stat(i.Path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed: %v", i.Path, err)
+		}
+	}
+	if _, err := os.Stat(cache("linux", arch, "v1.20.0", "kubeadm")); err != nil {
+		t.Errorf("kept binary was removed: %v", err)
+	}
+}
+
+func TestPrunableImages(t *testing.T) {
+	now := time.Now()
+	imgs := []cruntime.ListImage{
+		{ID: "dangling", RepoTags: []string{"<none>:<none>"}, Size: "10"},
+		{ID: "untagged", Size: "20", Created: now.Add(-time.Minute)},
+		{ID: "pause", RepoTags: []string{"registry.k8s.io/pause:3.9"}, Size: "30"},
+		{ID: "nginx", RepoTags: []string{"docker.io/library/nginx:latest"}, Size: "40"},
+		{ID: "used", RepoTags: []string{"docker.io/library/redis:7"}, Size: "50"},
+		{ID: "kept", RepoTags: []string{"docker.io/library/busybox:latest"}, Size: "60"},
+	}
+	keep := map[string]bool{"registry.k8s.io/pause:3.9": true, "busybox:latest": true}
+	inUse := func(id string) (bool, error) {
+		return id == "used", nil
+	}
+	ids := func(imgs []cruntime.ListImage) []string {
+		var ids []string
+		for _, img := range imgs {
+			ids = append(ids, img.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		o    PruneOptions
+		want []string
+	}{
+		{"dangling", PruneOptions{}, []string{"dangling", "untagged"}},
+		{"all", PruneOptions{All: true}, []string{"dangling", "untagged", "nginx"}},
+		// the creation time of the other images is unknown
+		{"older than", PruneOptions{All: true, OlderThan: time.Second}, []string{"untagged"}},
+		{"too recent", PruneOptions{OlderThan: time.Hour}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := prunableImages(imgs, keep, inUse, tc.o, now)
+			if err != nil {
+				t.Fatalf("prunableImages: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, ids(got)); diff != "" {
+				t.Errorf("prunableImages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if size := ImagesSize(imgs); size != 210 {
+		t.Errorf("ImagesSize = %d; want 210", size)
+	}
+}
+
+func TestPreloadInUse(t *testing.T) {
+	k := cacheKeep{versions: map[string]bool{"v1.28.3": true, "v1.31.0-rc.1": true}}
+	tests := map[string]bool{
+		download.TarballName("v1.28.3", "docker"):                       true,
+		download.TarballName("v1.31.0-rc.1", "containerd"):              true,
+		download.TarballName("v1.31.0-rc.1", "crio") + ".checksum":      true,
+		download.TarballName("v1.31.0", "docker"):                       false,
+		"preloaded-images-k8s-v1-v1.28.3-docker-overlay2-amd64.tar.lz4": false,
+	}
+	for name, want := range tests {
+		if got := k.preloadInUse(name); got != want {
+			t.Errorf("preloadInUse(%q) = %t; want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/drivers/qemu"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/network"
+)
+
+// CopyHost copies the libmachine store directory of a machine. When the machine is copied under a different
+// name, the name and the paths of its host config are changed, and it gets its own MAC address and forwarded
+// ports so that it can run alongside the original machine.
+func CopyHost(fromDir string, toDir string, from string, to string) error {
+	if err := copy.Copy(fromDir, toDir); err != nil {
+		return errors.Wrapf(err, "copy machine %s", from)
+	}
+	if from == to {
+		return nil
+	}
+
+	p := filepath.Join(toDir, "config.json")
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	// the host config is kept as a map, as only the driver knows the fields of its config
+	var h map[string]interface{}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return errors.Wrapf(err, "parse host config of %s", from)
+	}
+	// the host config of a machine saved in a snapshot still refers to the directory of the machine
+	for _, dir := range []string{fromDir, localpath.MachinePath(from)} {
+		h = movePaths(h, dir, toDir).(map[string]interface{})
+	}
+	h["Name"] = to
+	d, ok := h["Driver"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("host config of %s has no driver config", from)
+	}
+	d["MachineName"] = to
+	if name, _ := h["DriverName"].(string); driver.IsQEMU(name) {
+		if err := resetQEMUNetwork(d); err != nil {
+			return errors.Wrapf(err, "reset network of %s", to)
+		}
+	}
+
+	data, err = json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0600)
+}
+
+// movePaths replaces the directory from by the directory to in the paths of a host config
+func movePaths(v interface{}, from string, to string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = movePaths(e, from, to)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = movePaths(e, from, to)
+		}
+	case string:
+		if v == from || strings.HasPrefix(v, from+string(filepath.Separator)) {
+			return to + strings.TrimPrefix(v, from)
+		}
+	}
+	return v
+}
+
+// resetQEMUNetwork gives a new MAC address to a copied qemu VM, and new forwarded ports on the builtin network
+func resetQEMUNetwork(d map[string]interface{}) error {
+	mac, err := qemu.GenerateMACAddress()
+	if err != nil {
+		return err
+	}
+	d["MACAddress"] = mac
+	// the address is looked up from the MAC address on the next start
+	d["IPAddress"] = ""
+
+	nw, _ := d["Network"].(string)
+	if !network.IsBuiltinQEMU(nw) {
+		return nil
+	}
+	localPorts, _ := d["LocalPorts"].(string)
+	sshPort, enginePort, err := qemu.ForwardedPorts(localPorts)
+	if err != nil {
+		return err
+	}
+	d["SSHPort"] = sshPort
+	d["EnginePort"] = enginePort
+	return nil
+}
+
+// CloneSupported returns an error if the profile of the cluster can not be renamed or cloned
+func CloneSupported(cc config.ClusterConfig) error {
+	if driver.IsKIC(cc.Driver) || driver.IsQEMU(cc.Driver) {
+		return nil
+	}
+	return fmt.Errorf("renaming or cloning a profile is not supported by the %s driver", cc.Driver)
+}
+
+// CloneProfile copies the stopped cluster cc into a new profile named name.
+// Every step is rolled back if a later one fails.
+func CloneProfile(cc *config.ClusterConfig, name string) (*config.ClusterConfig, error) {
+	return cloneProfile(cc, name)
+}
+
+// RenameProfile moves the stopped cluster cc to a new profile named name.
+// The old profile is only removed once the new one has been fully created.
+func RenameProfile(cc *config.ClusterConfig, name string) (*config.ClusterConfig, error) {
+	ncc, err := cloneProfile(cc, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range cc.Nodes {
+		machineName := config.MachineName(*cc, n)
+		if driver.IsKIC(cc.Driver) {
+			if err := oci.DeleteContainer(context.Background(), cc.Driver, machineName); err != nil {
+				klog.Warningf("failed to delete container %s: %v", machineName, err)
+			}
+			if err := oci.RemoveVolume(cc.Driver, machineName); err != nil {
+				klog.Warningf("failed to remove volume %s: %v", machineName, err)
+			}
+		}
+		if err := os.RemoveAll(localpath.MachinePath(machineName)); err != nil {
+			klog.Warningf("failed to remove machine directory of %s: %v", machineName, err)
+		}
+	}
+	// only remove the dedicated network, a user provided one may be shared with other containers
+	if driver.IsKIC(cc.Driver) && (cc.Network == "" || cc.Network == cc.Name) {
+		if err := oci.RemoveNetwork(cc.Driver, cc.Name); err != nil {
+			klog.Warningf("failed to remove network %s: %v", cc.Name, err)
+		}
+	}
+
+	current, err := kubeconfig.GetCurrentContext(kubeconfig.PathFromEnv())
+	if err != nil {
+		klog.Warningf("failed to get current kubeconfig context: %v", err)
+	}
+	if err := kubeconfig.DeleteContext(cc.Name, kubeconfig.PathFromEnv()); err != nil {
+		klog.Warningf("failed to remove kubeconfig context %s: %v", cc.Name, err)
+	}
+	if current == cc.Name {
+		if err := kubeconfig.SetCurrentContext(name, kubeconfig.PathFromEnv()); err != nil {
+			klog.Warningf("failed to set current kubeconfig context to %s: %v", name, err)
+		}
+	}
+	if err := config.DeleteProfile(cc.Name); err != nil {
+		return ncc, errors.Wrapf(err, "delete profile %s", cc.Name)
+	}
+	return ncc, nil
+}
+
+func cloneProfile(cc *config.ClusterConfig, name string) (ncc *config.ClusterConfig, err error) {
+	if err := CloneSupported(*cc); err != nil {
+		return nil, err
+	}
+	if config.ProfileExists(name) {
+		return nil, fmt.Errorf("profile %q already exists", name)
+	}
+
+	var undo []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		klog.Infof("rolling back clone of %s to %s: %v", cc.Name, name, err)
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+
+	klog.Infof("copying profile %s to %s", cc.Name, name)
+	if err := copy.Copy(localpath.Profile(cc.Name), localpath.Profile(name)); err != nil {
+		return nil, errors.Wrap(err, "copy profile")
+	}
+	undo = append(undo, func() {
+		if err := config.DeleteProfile(name); err != nil {
+			klog.Warningf("rollback: failed to delete profile %s: %v", name, err)
+		}
+	})
+
+	c := *cc
+	ncc = &c
+	ncc.Name = name
+	ncc.KubernetesConfig.ClusterName = name
+	if cc.Network == cc.Name {
+		ncc.Network = name
+	}
+	if err := config.SaveProfile(name, ncc); err != nil {
+		return nil, errors.Wrap(err, "save profile")
+	}
+
+	for _, n := range cc.Nodes {
+		from := config.MachineName(*cc, n)
+		to := config.MachineName(*ncc, n)
+		klog.Infof("copying machine %s to %s", from, to)
+		if driver.IsKIC(cc.Driver) {
+			// the container is recreated with the new name and labels on the next start
+			if err := oci.CopyVolume(cc.Driver, name, from, to, cc.KicBaseImage); err != nil {
+				return nil, err
+			}
+			undo = append(undo, func() {
+				if err := oci.RemoveVolume(cc.Driver, to); err != nil {
+					klog.Warningf("rollback: failed to remove volume %s: %v", to, err)
+				}
+			})
+			continue
+		}
+		if err := CopyHost(localpath.MachinePath(from), localpath.MachinePath(to), from, to); err != nil {
+			return nil, err
+		}
+		undo = append(undo, func() {
+			if err := os.RemoveAll(localpath.MachinePath(to)); err != nil {
+				klog.Warningf("rollback: failed to remove machine directory of %s: %v", to, err)
+			}
+		})
+	}
+
+	if err := kubeconfig.CopyContext(cc.Name, name, kubeconfig.PathFromEnv()); err != nil {
+		return nil, errors.Wrap(err, "copy kubeconfig context")
+	}
+	return ncc, nil
+}
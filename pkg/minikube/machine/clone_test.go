@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// setupCloneTest creates a stopped qemu2 profile named src with a kubeconfig context
+func setupCloneTest(t *testing.T, src string) *config.ClusterConfig {
+	t.Helper()
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	cc := &config.ClusterConfig{
+		Name:             src,
+		Driver:           "qemu2",
+		KubernetesConfig: config.KubernetesConfig{ClusterName: src},
+		Nodes:            []config.Node{{ControlPlane: true, Worker: true}, {Name: "m02", Worker: true}},
+	}
+	if err := config.SaveProfile(src, cc); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(localpath.Profile(src), "client.crt"), []byte("cert"), 0600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	for _, n := range cc.Nodes {
+		m := config.MachineName(*cc, n)
+		if err := os.MkdirAll(localpath.MachinePath(m), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		hostConfig := `{"Name": "` + m + `", "DriverName": "qemu2", "Driver": {"MachineName": "` + m + `", "StorePath": "` + localpath.MiniPath() + `", "DiskPath": "` + filepath.Join(localpath.MachinePath(m), "disk.qcow2") + `", "Network": "builtin", "SSHPort": 50022, "EnginePort": 50023, "MACAddress": "0e:00:00:00:00:01", "Program": "` + src + `"}}`
+		if err := os.WriteFile(filepath.Join(localpath.MachinePath(m), "config.json"), []byte(hostConfig), 0600); err != nil {
+			t.Fatalf("write host config: %v", err)
+		}
+	}
+
+	kcfg := []byte(`apiVersion: v1
+clusters:
+- cluster:
+    server: https://192.168.105.2:8443
+  name: ` + src + `
+contexts:
+- context:
+    cluster: ` + src + `
+    user: ` + src + `
+  name: ` + src + `
+current-context: ` + src + `
+kind: Config
+users:
+- name: ` + src + `
+  user:
+    client-certificate: ` + filepath.Join(localpath.Profile(src), "client.crt") + `
+`)
+	if err := os.WriteFile(kubeconfig, kcfg, 0600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	return cc
+}
+
+func TestCloneProfile(t *testing.T) {
+	cc := setupCloneTest(t, "src")
+
+	ncc, err := CloneProfile(cc, "dst")
+	if err != nil {
+		t.Fatalf("CloneProfile: %v", err)
+	}
+	if ncc.Name != "dst" || ncc.KubernetesConfig.ClusterName != "dst" || cc.Name != "src" {
+		t.Errorf("unexpected names: clone=%s/%s source=%s", ncc.Name, ncc.KubernetesConfig.ClusterName, cc.Name)
+	}
+	for _, m := range []string{"src", "src-m02", "dst", "dst-m02"} {
+		if _, err := os.Stat(localpath.MachinePath(m)); err != nil {
+			t.Errorf("expected machine %s to exist: %v", m, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(localpath.MachinePath("dst-m02"), "config.json"))
+	if err != nil {
+		t.Fatalf("read host config: %v", err)
+	}
+	if strings.Contains(string(data), `"src-m02"`) || strings.Contains(string(data), filepath.Join("machines", "src-m02")) {
+		t.Errorf("host config still references the source machine: %s", data)
+	}
+	var h struct {
+		Name   string
+		Driver struct {
+			MachineName string
+			DiskPath    string
+			SSHPort     int
+			EnginePort  int
+			MACAddress  string
+			Program     string
+		}
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		t.Fatalf("parse host config: %v", err)
+	}
+	d := h.Driver
+	if h.Name != "dst-m02" || d.MachineName != "dst-m02" || d.DiskPath != filepath.Join(localpath.MachinePath("dst-m02"), "disk.qcow2") {
+		t.Errorf("unexpected clone host config: %s", data)
+	}
+	if d.SSHPort == 50022 || d.EnginePort == 50023 || d.SSHPort == d.EnginePort || d.MACAddress == "0e:00:00:00:00:01" || d.MACAddress == "" {
+		t.Errorf("clone shares the network of the source machine: %s", data)
+	}
+	if _, err := config.Load("dst"); err != nil {
+		t.Errorf("Load clone: %v", err)
+	}
+
+	kcfg, err := clientcmd.LoadFromFile(os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("load kubeconfig: %v", err)
+	}
+	if _, ok := kcfg.Contexts["src"]; !ok {
+		t.Errorf("source context was removed")
+	}
+	if user, ok := kcfg.AuthInfos["dst"]; !ok || user.ClientCertificate != filepath.Join(localpath.Profile("dst"), "client.crt") {
+		t.Errorf("unexpected clone user: %+v", user)
+	}
+
+	if _, err := CloneProfile(cc, "dst"); err == nil {
+		t.Errorf("CloneProfile: expected an error for an existing profile")
+	}
+}
+
+func TestCloneProfileNamedLikeAValue(t *testing.T) {
+	// the profile name is also the value of other fields of the host config
+	cc := setupCloneTest(t, "qemu2")
+
+	if _, err := CloneProfile(cc, "dst"); err != nil {
+		t.Fatalf("CloneProfile: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(localpath.MachinePath("dst"), "config.json"))
+	if err != nil {
+		t.Fatalf("read host config: %v", err)
+	}
+	var h struct {
+		Name       string
+		DriverName string
+		Driver     struct{ Program string }
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		t.Fatalf("parse host config: %v", err)
+	}
+	if h.Name != "dst" || h.DriverName != "qemu2" || h.Driver.Program != "qemu2" {
+		t.Errorf("unexpected clone host config: %s", data)
+	}
+}
+
+func TestRenameProfile(t *testing.T) {
+	cc := setupCloneTest(t, "src")
+
+	if _, err := RenameProfile(cc, "dst"); err != nil {
+		t.Fatalf("RenameProfile: %v", err)
+	}
+	if config.ProfileExists("src") {
+		t.Errorf("expected profile src to be removed")
+	}
+	if _, err := os.Stat(localpath.MachinePath("src")); !os.IsNotExist(err) {
+		t.Errorf("expected machine src to be removed: %v", err)
+	}
+	kcfg, err := clientcmd.LoadFromFile(os.Getenv("KUBECONFIG"))
+	if err != nil {
+		t.Fatalf("load kubeconfig: %v", err)
+	}
+	if _, ok := kcfg.Contexts["src"]; ok {
+		t.Errorf("expected context src to be removed")
+	}
+	if kcfg.CurrentContext != "dst" {
+		t.Errorf("current context = %s; want dst", kcfg.CurrentContext)
+	}
+}
+
+func TestCloneProfileRollback(t *testing.T) {
+	cc := setupCloneTest(t, "src")
+	// a missing machine directory makes the second node copy fail
+	if err := os.RemoveAll(localpath.MachinePath("src-m02")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	if _, err := CloneProfile(cc, "dst"); err == nil {
+		t.Fatalf("CloneProfile: expected an error")
+	}
+	if config.ProfileExists("dst") {
+		t.Errorf("expected profile dst to be rolled back")
+	}
+	if _, err := os.Stat(localpath.MachinePath("dst")); !os.IsNotExist(err) {
+		t.Errorf("expected machine dst to be rolled back: %v", err)
+	}
+}
+
+func TestCloneSupported(t *testing.T) {
+	for drv, ok := range map[string]bool{"docker": true, "podman": true, "qemu2": true, "ssh": false, "kvm2": false, "virtualbox": false, "none": false} {
+		if err := CloneSupported(config.ClusterConfig{Driver: drv}); (err == nil) != ok {
+			t.Errorf("CloneSupported(%s) = %v; want supported=%t", drv, err, ok)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+// NodeImages are the images of a node and those which can be pruned
+type NodeImages struct {
+	Node     string
+	Images   []cruntime.ListImage
+	Prunable []cruntime.ListImage
+}
+
+// ImagesSize returns the total size of images in bytes
+func ImagesSize(imgs []cruntime.ListImage) int64 {
+	var size int64
+	for _, img := range imgs {
+		s, err := strconv.ParseInt(img.Size, 10, 64)
+		if err != nil {
+			klog.Warningf("unable to parse the size %q of image %s", img.Size, img.ID)
+			continue
+		}
+		size += s
+	}
+	return size
+}
+
+// normalizeImageName strips the default registry, which some runtimes add and others don't
+func normalizeImageName(name string) string {
+	name = strings.TrimPrefix(name, "docker.io/")
+	return strings.TrimPrefix(name, "library/")
+}
+
+// keptImages returns the images of the Kubernetes versions which are kept
+func keptImages(cc *config.ClusterConfig, o PruneOptions) map[string]bool {
+	keep := map[string]bool{}
+	versions := append([]string{cc.KubernetesConfig.KubernetesVersion}, o.KeepK8sVersions...)
+	for _, v := range versions {
+		imgs, err := images.Kubeadm(cc.KubernetesConfig.ImageRepository, normalizeVersion(v))
+		if err != nil {
+			klog.Warningf("unable to list the images of Kubernetes %s: %v", v, err)
+			continue
+		}
+		for _, img := range imgs {
+			keep[normalizeImageName(img)] = true
+		}
+	}
+	for _, img := range o.KeepImages {
+		keep[normalizeImageName(img)] = true
+	}
+	return keep
+}
+
+// prunableImages returns the images which are neither kept, used by a container nor too recent.
+// Only dangling images are returned unless all unused images are pruned.
+func prunableImages(imgs []cruntime.ListImage, keep map[string]bool, inUse func(id string) (bool, error), o PruneOptions, now time.Time) ([]cruntime.ListImage, error) {
+	var prune []cruntime.ListImage
+	for _, img := range imgs {
+		if !o.All && !img.Dangling() {
+			continue
+		}
+		kept := false
+		for _, t := range img.RepoTags {
+			if keep[normalizeImageName(t)] {
+				kept = true
+				break
+			}
+		}
+		if kept || !o.prunable(img.Created, now) {
+			continue
+		}
+		used, err := inUse(img.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking if image %s is in use", img.ID)
+		}
+		if !used {
+			prune = append(prune, img)
+		}
+	}
+	return prune, nil
+}
+
+// PruneImages removes the unused images from the running nodes of the profile, unless dryRun is set.
+// It returns the images of every node and the ones which are, or would be, removed.
+func PruneImages(profile *config.Profile, o PruneOptions, dryRun bool) ([]NodeImages, error) {
+	api, err := NewAPIClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating api client")
+	}
+	defer api.Close()
+
+	pName := profile.Name
+	c, err := config.Load(pName)
+	if err != nil {
+		klog.Errorf("Failed to load profile %q: %v", pName, err)
+		return nil, errors.Wrapf(err, "error loading config for profile :%v", pName)
+	}
+	keep := keptImages(c, o)
+	now := time.Now()
+
+	var result []NodeImages
+	for _, n := range c.Nodes {
+		m := config.MachineName(*c, n)
+
+		status, err := Status(api, m)
+		if err != nil {
+			klog.Warningf("error getting status for %s: %v", m, err)
+			continue
+		}
+		if status != state.Running.String() {
+			continue
+		}
+		h, err := api.Load(m)
+		if err != nil {
+			klog.Warningf("Failed to load machine %q: %v", m, err)
+			continue
+		}
+		runner, err := CommandRunner(h)
+		if err != nil {
+			return nil, err
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: c.KubernetesConfig.ContainerRuntime, Runner: runner})
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating container runtime")
+		}
+		list, err := cr.ListImages(cruntime.ListImagesOptions{Details: o.OlderThan > 0})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing images on %s", m)
+		}
+		inUse := func(id string) (bool, error) {
+			ids, err := cr.ListContainers(cruntime.ListContainersOptions{State: cruntime.All, Image: id})
+			return len(ids) > 0, err
+		}
+		prune, err := prunableImages(list, keep, inUse, o, now)
+		if err != nil {
+			return nil, errors.Wrapf(err, "on %s", m)
+		}
+		if !dryRun {
+			var removed []cruntime.ListImage
+			for _, img := range prune {
+				klog.Infof("Pruning image %s %v from %s", img.ID, img.RepoTags, m)
+				// containers which are not managed by Kubernetes may still use the image
+				if err := cr.RemoveImage(img.ID); err != nil {
+					klog.Warningf("Failed to remove image %s from %s: %v", img.ID, m, err)
+					continue
+				}
+				removed = append(removed, img)
+			}
+			prune = removed
+		}
+		result = append(result, NodeImages{Node: m, Images: list, Prunable: prune})
+	}
+	return result, nil
+}
+
+// ShortImageID truncates an image id for display
+func ShortImageID(id string) string {
+	return parseImageID(id)
+}
@@ -114,7 +114,6 @@ func engineOptions(cfg config.ClusterConfig) *engine.Options {
 	o := engine.Options{
 		Env:              uniqueEnvs,
 		InsecureRegistry: append([]string{constants.DefaultServiceCIDR}, cfg.InsecureRegistry...),
-		RegistryMirror:   cfg.RegistryMirror,
 		ArbitraryFlags:   cfg.DockerOpt,
 		InstallURL:       drivers.DefaultEngineInstallURL,
 	}
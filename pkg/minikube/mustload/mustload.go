@@ -80,6 +80,25 @@ func Partial(name string, miniHome ...string) (libmachine.API, *config.ClusterCo
 	return api, cc
 }
 
+// Stopped is a cmd-friendly way to load a cluster whose nodes must all be stopped.
+func Stopped(name string) (libmachine.API, *config.ClusterConfig) {
+	api, cc := Partial(name)
+
+	for _, n := range cc.Nodes {
+		machineName := config.MachineName(*cc, n)
+		status, err := machine.Status(api, machineName)
+		if err != nil {
+			exit.Message(reason.GuestStatus, `Unable to get node {{.name}} host status: {{.err}}`, out.V{"name": machineName, "err": err})
+		}
+		if status != state.Stopped.String() && status != state.None.String() {
+			out.Styled(style.Shrug, `The node {{.name}} host is not stopped: state={{.state}}`, out.V{"name": machineName, "state": status})
+			out.Styled(style.Workaround, `To stop the cluster, run: "{{.command}}"`, out.V{"command": ExampleCmd(name, "stop")})
+			exit.Code(reason.ExGuestConflict)
+		}
+	}
+	return api, cc
+}
+
 // Running is a cmd-friendly way to load a running cluster.
 func Running(name string) ClusterController {
 	if r := running(name, true); r != nil {
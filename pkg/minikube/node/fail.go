@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/cluster/ha/etcd"
+	kubevip "k8s.io/minikube/pkg/minikube/cluster/ha/kube-vip"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+const (
+	// FailStop stops the host of the node
+	FailStop = "stop"
+	// FailPause pauses the containers and the kubelet of the node
+	FailPause = "pause"
+	// FailPartition drops the traffic between the node and its peers, keeping it running
+	FailPartition = "partition"
+
+	// partitionChain is the iptables chain of the node dropping the traffic of its peers
+	partitionChain = "MINIKUBE-PARTITION"
+)
+
+// FailModes are the ways of failing a node
+var FailModes = []string{FailStop, FailPause, FailPartition}
+
+// ControlPlaneView is the state of the HA control plane, as seen by one of its nodes
+type ControlPlaneView struct {
+	// Node is the control-plane node reporting the state
+	Node string
+	// Leader is the control-plane node serving the VIP
+	Leader  string
+	Members []etcd.Member
+}
+
+// Fail injects a failure into the node, without losing its state
+func Fail(api libmachine.API, cc config.ClusterConfig, n config.Node, mode string) error {
+	machineName := config.MachineName(cc, n)
+	switch mode {
+	case FailStop:
+		return machine.StopHost(api, machineName)
+	case FailPause:
+		r, cr, err := nodeRuntime(api, cc, machineName)
+		if err != nil {
+			return err
+		}
+		ids, err := cluster.Pause(cr, r, nil)
+		klog.Infof("paused %d containers of %s", len(ids), machineName)
+		return err
+	case FailPartition:
+		r, _, err := nodeRuntime(api, cc, machineName)
+		if err != nil {
+			return err
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "sh", "-c", partitionScript(partitionPeers(cc, n)))); err != nil {
+			return errors.Wrap(err, "partition")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown failure mode %q, valid modes are: %s", mode, strings.Join(FailModes, ", "))
+	}
+}
+
+// Heal recovers the running node from a pause or a partition
+func Heal(api libmachine.API, cc config.ClusterConfig, n config.Node) error {
+	machineName := config.MachineName(cc, n)
+	r, cr, err := nodeRuntime(api, cc, machineName)
+	if err != nil {
+		return err
+	}
+	if _, err := r.RunCmd(exec.Command("sudo", "sh", "-c", healScript())); err != nil {
+		return errors.Wrap(err, "heal partition")
+	}
+	paused, err := cluster.CheckIfPaused(cr, nil)
+	if err != nil {
+		return err
+	}
+	if paused {
+		if _, err := cluster.Unpause(cr, r, nil); err != nil {
+			return errors.Wrap(err, "unpause")
+		}
+	}
+	return nil
+}
+
+// ViewControlPlane returns the state of the HA control plane seen by a running control-plane node other than the
+// failed one, waiting for the failed node to lose the lead of kube-vip
+func ViewControlPlane(api libmachine.API, cc config.ClusterConfig, failed string) (*ControlPlaneView, error) {
+	var v *ControlPlaneView
+	view := func() error {
+		var err error
+		for _, cp := range config.ControlPlanes(cc) {
+			name := config.MachineName(cc, cp)
+			if name == failed || !machine.IsRunning(api, name) {
+				continue
+			}
+			if v, err = viewFrom(api, cc, name); err != nil {
+				klog.Infof("control plane not reported by %s: %v", name, err)
+				continue
+			}
+			if v.Leader == "" || v.Leader == failed {
+				return fmt.Errorf("%s still sees %q as the kube-vip leader", name, v.Leader)
+			}
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no other running control-plane node")
+		}
+		return err
+	}
+	// the lease of kube-vip expires within seconds
+	if err := retry.Local(view, 30*time.Second); err != nil && v == nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// viewFrom returns the state of the HA control plane seen by the node
+func viewFrom(api libmachine.API, cc config.ClusterConfig, machineName string) (*ControlPlaneView, error) {
+	r, _, err := nodeRuntime(api, cc, machineName)
+	if err != nil {
+		return nil, err
+	}
+	v := &ControlPlaneView{Node: machineName}
+	if v.Members, err = etcd.Members(cc, r); err != nil {
+		return nil, errors.Wrap(err, "etcd members")
+	}
+	if v.Leader, err = kubevip.Leader(cc, r); err != nil {
+		return nil, errors.Wrap(err, "kube-vip leader")
+	}
+	return v, nil
+}
+
+// nodeRuntime returns the runner and the container runtime of the node
+func nodeRuntime(api libmachine.API, cc config.ClusterConfig, machineName string) (command.Runner, cruntime.Manager, error) {
+	h, err := machine.LoadHost(api, machineName)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "load host")
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get command runner")
+	}
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "runtime")
+	}
+	return r, cr, nil
+}
+
+// partitionPeers returns the addresses the partitioned node must not reach, the VIP included
+func partitionPeers(cc config.ClusterConfig, n config.Node) []string {
+	var peers []string
+	for _, p := range cc.Nodes {
+		if p.Name != n.Name && p.IP != "" {
+			peers = append(peers, p.IP)
+		}
+	}
+	if vip := cc.KubernetesConfig.APIServerHAVIP; vip != "" {
+		peers = append(peers, vip)
+	}
+	return peers
+}
+
+// partitionScript returns the shell script dropping the traffic from and to the peers, in a chain of its own for
+// the heal to remove it, the host reaching the node through other addresses
+func partitionScript(peers []string) string {
+	lines := []string{
+		fmt.Sprintf("iptables -N %[1]s 2>/dev/null || iptables -F %[1]s", partitionChain),
+	}
+	for _, c := range []string{"INPUT", "OUTPUT", "FORWARD"} {
+		lines = append(lines, fmt.Sprintf("iptables -C %[1]s -j %[2]s 2>/dev/null || iptables -I %[1]s -j %[2]s", c, partitionChain))
+	}
+	for _, p := range peers {
+		lines = append(lines,
+			fmt.Sprintf("iptables -A %s -s %s -j DROP", partitionChain, p),
+			fmt.Sprintf("iptables -A %s -d %s -j DROP", partitionChain, p))
+	}
+	return strings.Join(lines, " && ")
+}
+
+// healScript returns the shell script removing the partition of the node, if any
+func healScript() string {
+	var lines []string
+	for _, c := range []string{"INPUT", "OUTPUT", "FORWARD"} {
+		lines = append(lines, fmt.Sprintf("while iptables -D %s -j %s 2>/dev/null; do :; done", c, partitionChain))
+	}
+	lines = append(lines,
+		fmt.Sprintf("iptables -F %s 2>/dev/null", partitionChain),
+		fmt.Sprintf("iptables -X %s 2>/dev/null", partitionChain),
+		"true")
+	return strings.Join(lines, "; ")
+}
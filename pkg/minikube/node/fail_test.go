@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestPartitionPeers(t *testing.T) {
+	cc := config.ClusterConfig{
+		Name:             "ha-demo",
+		KubernetesConfig: config.KubernetesConfig{APIServerHAVIP: "192.168.49.254"},
+		Nodes: []config.Node{
+			{Name: "", IP: "192.168.49.2", ControlPlane: true},
+			{Name: "m02", IP: "192.168.49.3", ControlPlane: true},
+			{Name: "m03", IP: "192.168.49.4", ControlPlane: true},
+		},
+	}
+	want := []string{"192.168.49.2", "192.168.49.4", "192.168.49.254"}
+	if diff := cmp.Diff(want, partitionPeers(cc, cc.Nodes[1])); diff != "" {
+		t.Errorf("partitionPeers() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPartitionScript(t *testing.T) {
+	s := partitionScript([]string{"192.168.49.3"})
+	for _, want := range []string{
+		"iptables -N MINIKUBE-PARTITION 2>/dev/null || iptables -F MINIKUBE-PARTITION",
+		"iptables -C INPUT -j MINIKUBE-PARTITION 2>/dev/null || iptables -I INPUT -j MINIKUBE-PARTITION",
+		"iptables -A MINIKUBE-PARTITION -s 192.168.49.3 -j DROP",
+		"iptables -A MINIKUBE-PARTITION -d 192.168.49.3 -j DROP",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("partitionScript() = %q, missing %q", s, want)
+		}
+	}
+	// healing a node which is not partitioned must succeed
+	if h := healScript(); !strings.HasSuffix(h, "; true") || !strings.Contains(h, "iptables -X MINIKUBE-PARTITION") {
+		t.Errorf("healScript() = %q", h)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+
+	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/network"
+)
+
+// EnableHA turns the running cluster with a single control-plane node into an HA one, for other control-plane nodes
+// to join it: kube-vip serves the VIP of the cluster from the primary control-plane node, the apiserver cert is
+// re-issued for the VIP, and the nodes and the kubeconfig reach the apiserver through the VIP.
+func EnableHA(cc *config.ClusterConfig) error {
+	if config.IsHA(*cc) {
+		return nil
+	}
+	cp, err := config.ControlPlane(*cc)
+	if err != nil {
+		return errors.Wrap(err, "get control-plane node")
+	}
+
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return errors.Wrap(err, "get api client")
+	}
+	defer api.Close()
+	h, err := machine.LoadHost(api, config.MachineName(*cc, cp))
+	if err != nil {
+		return errors.Wrap(err, "load primary control-plane host")
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return errors.Wrap(err, "get primary control-plane runner")
+	}
+
+	// the VIP is allocated like for the clusters started with --ha
+	nw, err := network.Inspect(cp.IP)
+	if err != nil {
+		return errors.Wrap(err, "inspect network")
+	}
+	for _, n := range cc.Nodes {
+		if n.IP == nw.ClientMax {
+			return fmt.Errorf("the VIP %s of the cluster is already used by node %s", nw.ClientMax, config.MachineName(*cc, n))
+		}
+	}
+	vip := nw.ClientMax
+	klog.Infof("converting cluster %s to HA with VIP %s", cc.Name, vip)
+	cc.KubernetesConfig.APIServerHAVIP = vip
+
+	bs, err := cluster.Bootstrapper(api, viper.GetString(cmdcfg.Bootstrapper), *cc, r)
+	if err != nil {
+		return errors.Wrap(err, "get bootstrapper")
+	}
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r, Socket: cc.KubernetesConfig.CRISocket})
+	if err != nil {
+		return errors.Wrap(err, "runtime")
+	}
+	// writes the kube-vip static pod and points the control-plane alias of the node to the VIP
+	if err := bs.UpdateNode(*cc, cp, cr); err != nil {
+		return errors.Wrap(err, "update primary control-plane node")
+	}
+	if err := bs.SetupCerts(*cc, cp, r); err != nil {
+		return errors.Wrap(err, "re-issue apiserver cert")
+	}
+	if err := bootstrapper.RestartComponents(*cc, r, "kube-apiserver"); err != nil {
+		return errors.Wrap(err, "restart apiserver")
+	}
+
+	hostname, port := vip, cc.APIServerPort
+	if driver.NeedsPortForward(cc.Driver) {
+		if hostname, _, port, err = driver.ControlPlaneEndpoint(cc, &cp, h.DriverName); err != nil {
+			return errors.Wrap(err, "get control-plane endpoint")
+		}
+	}
+	if st, err := kverify.WaitForAPIServerStatus(r, 3*time.Minute, hostname, port); err != nil || st != state.Running {
+		return fmt.Errorf("apiserver is not serving the VIP %s: %s, %v", vip, st, err)
+	}
+
+	for _, n := range cc.Nodes {
+		if config.IsPrimaryControlPlane(*cc, n) {
+			continue
+		}
+		if err := setControlPlaneAlias(api, *cc, n, vip); err != nil {
+			return err
+		}
+	}
+
+	kcs := setupKubeconfig(*h, *cc, cp, cc.Name)
+	if err := kubeconfig.Update(kcs); err != nil {
+		return errors.Wrap(err, "update kubeconfig")
+	}
+	return config.SaveProfile(cc.Name, cc)
+}
+
+// setControlPlaneAlias points the control-plane alias of the node, used by its kubelet and kube-proxy, to the VIP
+func setControlPlaneAlias(api libmachine.API, cc config.ClusterConfig, n config.Node, vip string) error {
+	machineName := config.MachineName(cc, n)
+	st, err := machine.Status(api, machineName)
+	if err != nil || st != state.Running.String() {
+		// the alias is set when the node starts
+		klog.Warningf("skipping the control-plane alias of node %s, which is not running: %s, %v", machineName, st, err)
+		return nil
+	}
+	h, err := machine.LoadHost(api, machineName)
+	if err != nil {
+		return errors.Wrapf(err, "load host %s", machineName)
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return errors.Wrapf(err, "get runner of %s", machineName)
+	}
+	if err := machine.AddHostAlias(r, constants.ControlPlaneAlias, net.ParseIP(vip)); err != nil {
+		return errors.Wrapf(err, "add control-plane alias on %s", machineName)
+	}
+	return nil
+}
@@ -58,14 +58,17 @@ import (
 	"k8s.io/minikube/pkg/minikube/logs"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/oidc"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/proxy"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/minikube/registrycache"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 	"k8s.io/minikube/pkg/network"
+	"k8s.io/minikube/pkg/trace"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/retry"
 	kconst "k8s.io/minikube/third_party/kubeadm/app/constants"
@@ -99,7 +102,7 @@ func Start(starter Starter) (*kubeconfig.Settings, error) { // nolint:gocyclo
 	}
 	if stopk8s {
 		nv := semver.Version{Major: 0, Minor: 0, Patch: 0}
-		cr := configureRuntimes(starter.Runner, *starter.Cfg, nv)
+		cr := configureRuntimes(starter.Runner, *starter.Cfg, nv, registrycache.Mirrors(*starter.Cfg, starter.Host))
 
 		showNoK8sVersionInfo(cr)
 
@@ -116,7 +119,7 @@ func Start(starter Starter) (*kubeconfig.Settings, error) { // nolint:gocyclo
 	}
 
 	// configure the runtime (docker, containerd, crio)
-	cr := configureRuntimes(starter.Runner, *starter.Cfg, sv)
+	cr := configureRuntimes(starter.Runner, *starter.Cfg, sv, registrycache.Mirrors(*starter.Cfg, starter.Host))
 
 	// check if installed runtime is compatible with current minikube code
 	if err = cruntime.CheckCompatibility(cr); err != nil {
@@ -306,10 +309,25 @@ func startPrimaryControlPlane(starter Starter, cr cruntime.Manager) (*kubeconfig
 	if err := kubeconfig.Update(kcs); err != nil {
 		return nil, bs, errors.Wrap(err, "Failed kubeconfig update")
 	}
+	if err := updateOIDCContext(kcs.ClusterName, starter.Cfg.KubernetesConfig.OIDC); err != nil {
+		return nil, bs, errors.Wrap(err, "Failed OIDC kubeconfig update")
+	}
 
 	return kcs, bs, nil
 }
 
+// updateOIDCContext writes the kubeconfig context of the users of the OpenID Connect issuer, or removes it without an issuer
+func updateOIDCContext(clusterName string, o *config.OIDCConfig) error {
+	if o == nil {
+		return kubeconfig.SetExecContext(clusterName, nil)
+	}
+	if err := kubeconfig.SetExecContext(clusterName, oidc.ExecConfig(o)); err != nil {
+		return err
+	}
+	out.Styled(style.Tip, "Use the {{.context}} kubectl context to log in with {{.issuer}}", out.V{"context": kubeconfig.ExecContextName(clusterName), "issuer": o.IssuerURL})
+	return nil
+}
+
 // joinCluster adds new or prepares and then adds existing node to the cluster.
 func joinCluster(starter Starter, cpBs bootstrapper.Bootstrapper, bs bootstrapper.Bootstrapper) error {
 	start := time.Now()
@@ -368,6 +386,7 @@ func joinCluster(starter Starter, cpBs bootstrapper.Bootstrapper, bs bootstrappe
 // Provision provisions the machine/container for the node
 func Provision(cc *config.ClusterConfig, n *config.Node, delOnFail bool) (command.Runner, bool, libmachine.API, *host.Host, error) {
 	register.Reg.SetStep(register.StartingNode)
+	defer trace.StartSpan(trace.Provision)()
 	name := config.MachineName(*cc, *n)
 
 	// Be explicit with each case for the sake of translations
@@ -407,7 +426,7 @@ func Provision(cc *config.ClusterConfig, n *config.Node, delOnFail bool) (comman
 }
 
 // ConfigureRuntimes does what needs to happen to get a runtime going.
-func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, kv semver.Version) cruntime.Manager {
+func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, kv semver.Version, mirrors []cruntime.RegistryMirror) cruntime.Manager {
 	co := cruntime.Config{
 		Type:              cc.KubernetesConfig.ContainerRuntime,
 		Socket:            cc.KubernetesConfig.CRISocket,
@@ -416,6 +435,7 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 		ImageRepository:   cc.KubernetesConfig.ImageRepository,
 		KubernetesVersion: kv,
 		InsecureRegistry:  cc.InsecureRegistry,
+		RegistryMirrors:   mirrors,
 	}
 	if cc.GPUs != "" {
 		co.GPUs = true
@@ -442,6 +462,11 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 	if err := cni.ConfigureDefaultBridgeCNIs(runner, cc.KubernetesConfig.NetworkPlugin); err != nil {
 		klog.Errorf("unable to disable preinstalled bridge CNI(s): %v", err)
 	}
+	if cc.KubernetesConfig.IPFamily == cni.DualStack {
+		if err := cni.EnableIPv6Forwarding(runner); err != nil {
+			klog.Errorf("unable to enable IPv6 forwarding: %v", err)
+		}
+	}
 
 	inUserNamespace := strings.Contains(cc.KubernetesConfig.FeatureGates, "KubeletInUserNamespace=true")
 	// for docker container runtime: ensure containerd is properly configured by calling Enable(), as docker could be bound to containerd
@@ -453,7 +478,8 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 			Runner:            co.Runner,
 			ImageRepository:   co.ImageRepository,
 			KubernetesVersion: co.KubernetesVersion,
-			InsecureRegistry:  co.InsecureRegistry})
+			InsecureRegistry:  co.InsecureRegistry,
+			RegistryMirrors:   co.RegistryMirrors})
 		if err == nil {
 			err = containerd.Enable(false, cgroupDriver(cc), inUserNamespace) // do not disableOthers, as it's not primary cr
 		}
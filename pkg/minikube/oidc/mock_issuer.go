@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mockKeyID identifies the signing key of the mock issuer
+const mockKeyID = "minikube-mock"
+
+// MockIssuer is a local OpenID Connect issuer for the tests, serving its discovery document and signing keys over
+// https and issuing ID tokens for any user
+type MockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+// NewMockIssuer starts a mock issuer on a random local port
+func NewMockIssuer() (*MockIssuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating signing key")
+	}
+	m := &MockIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, Discovery{
+			Issuer:                m.URL(),
+			AuthorizationEndpoint: m.URL() + "/auth",
+			TokenEndpoint:         m.URL() + "/token",
+			JWKSURI:               m.URL() + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": mockKeyID,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	m.server = httptest.NewTLSServer(mux)
+	return m, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// URL returns the issuer URL
+func (m *MockIssuer) URL() string {
+	return m.server.URL
+}
+
+// CACert returns the PEM encoded certificate serving the issuer
+func (m *MockIssuer) CACert() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: m.server.Certificate().Raw})
+}
+
+// PublicKey returns the key verifying the tokens of the issuer
+func (m *MockIssuer) PublicKey() *rsa.PublicKey {
+	return &m.key.PublicKey
+}
+
+// Token returns an ID token of the user for the client, valid for an hour
+func (m *MockIssuer) Token(clientID string, subject string, groups []string) (string, error) {
+	now := time.Now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": mockKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":    m.URL(),
+		"aud":    clientID,
+		"sub":    subject,
+		"email":  subject + "@example.com",
+		"groups": groups,
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing token")
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Close stops the issuer
+func (m *MockIssuer) Close() {
+	m.server.Close()
+}
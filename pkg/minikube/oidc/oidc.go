@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package oidc authenticates the users of a cluster with the ID tokens of an OpenID Connect issuer
+package oidc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// discoveryPath is the path of the discovery document of an issuer
+	discoveryPath = "/.well-known/openid-configuration"
+	// pluginInstallHint is shown by kubectl when the credential plugin is missing
+	pluginInstallHint = "The OIDC context of minikube needs the kubelogin credential plugin: https://github.com/int128/kubelogin"
+)
+
+// Discovery is the discovery document of an issuer
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Validate checks the settings of the issuer, which must be served over https
+func Validate(oidc *config.OIDCConfig) error {
+	u, err := url.Parse(oidc.IssuerURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid issuer URL %q", oidc.IssuerURL)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("issuer URL %q must be an https URL", oidc.IssuerURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("issuer URL %q must not have a query or a fragment", oidc.IssuerURL)
+	}
+	if oidc.ClientID == "" {
+		return fmt.Errorf("a client ID is required to authenticate the users of %s", oidc.IssuerURL)
+	}
+	if oidc.CAFile != "" {
+		if _, err := certPool(oidc.CAFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// certPool returns the pool of the certificates of the PEM file
+func certPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the CA of the issuer")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// Discover fetches the discovery document of the issuer, trusting the CA file if set
+func Discover(issuerURL string, caFile string) (*Discovery, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		pool, err := certPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tr.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	client := &http.Client{Transport: tr, Timeout: 10 * time.Second}
+
+	resp, err := client.Get(strings.TrimSuffix(issuerURL, "/") + discoveryPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching the discovery document")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the discovery document: %s", resp.Status)
+	}
+	d := &Discovery{}
+	if err := json.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, errors.Wrap(err, "decoding the discovery document")
+	}
+	// the apiserver rejects the tokens of an issuer not matching its URL exactly
+	if d.Issuer != issuerURL {
+		return nil, fmt.Errorf("the discovery document is for the issuer %q, not %q", d.Issuer, issuerURL)
+	}
+	return d, nil
+}
+
+// ExecConfig returns the exec credential plugin of kubectl logging in to the issuer with kubelogin
+func ExecConfig(oidc *config.OIDCConfig) *api.ExecConfig {
+	args := []string{
+		"oidc-login",
+		"get-token",
+		"--oidc-issuer-url=" + oidc.IssuerURL,
+		"--oidc-client-id=" + oidc.ClientID,
+	}
+	if oidc.ClientSecret != "" {
+		args = append(args, "--oidc-client-secret="+oidc.ClientSecret)
+	}
+	for _, s := range oidc.ExtraScopes {
+		args = append(args, "--oidc-extra-scope="+s)
+	}
+	if oidc.CAFile != "" {
+		args = append(args, "--certificate-authority="+oidc.CAFile)
+	}
+	return &api.ExecConfig{
+		APIVersion:      "client.authentication.k8s.io/v1beta1",
+		Command:         "kubectl",
+		Args:            args,
+		InstallHint:     pluginInstallHint,
+		InteractiveMode: api.IfAvailableExecInteractiveMode,
+	}
+}
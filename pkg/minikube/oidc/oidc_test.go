@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func mockIssuer(t *testing.T) (*MockIssuer, string) {
+	t.Helper()
+	m, err := NewMockIssuer()
+	if err != nil {
+		t.Fatalf("NewMockIssuer() error = %v", err)
+	}
+	t.Cleanup(m.Close)
+	caFile := filepath.Join(t.TempDir(), "issuer-ca.crt")
+	if err := os.WriteFile(caFile, m.CACert(), 0644); err != nil {
+		t.Fatalf("writing CA: %v", err)
+	}
+	return m, caFile
+}
+
+func TestValidate(t *testing.T) {
+	_, caFile := mockIssuer(t)
+	tests := []struct {
+		name string
+		oidc config.OIDCConfig
+		err  bool
+	}{
+		{"valid", config.OIDCConfig{IssuerURL: "https://dex.example.com/dex", ClientID: "minikube"}, false},
+		{"valid with CA", config.OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "minikube", CAFile: caFile}, false},
+		{"http", config.OIDCConfig{IssuerURL: "http://dex.example.com", ClientID: "minikube"}, true},
+		{"query", config.OIDCConfig{IssuerURL: "https://dex.example.com?realm=a", ClientID: "minikube"}, true},
+		{"no client ID", config.OIDCConfig{IssuerURL: "https://dex.example.com"}, true},
+		{"missing CA", config.OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "minikube", CAFile: caFile + ".missing"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(&tc.oidc)
+			if (err != nil) != tc.err {
+				t.Errorf("Validate() error = %v, want error: %t", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	m, caFile := mockIssuer(t)
+
+	d, err := Discover(m.URL(), caFile)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if d.Issuer != m.URL() || d.JWKSURI != m.URL()+"/keys" {
+		t.Errorf("Discover() = %+v", d)
+	}
+
+	// the issuer is not trusted without its CA
+	if _, err := Discover(m.URL(), ""); err == nil {
+		t.Errorf("Discover() without the CA should have failed")
+	}
+	// the apiserver would reject the tokens of an issuer URL which is not the one of the tokens
+	if _, err := Discover(m.URL()+"/", caFile); err == nil || !strings.Contains(err.Error(), "is for the issuer") {
+		t.Errorf("Discover() of a mismatching issuer URL error = %v", err)
+	}
+}
+
+func TestMockIssuerToken(t *testing.T) {
+	m, _ := mockIssuer(t)
+
+	token, err := m.Token("minikube", "jane", []string{"developers"})
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Token() = %q, want a JWT", token)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decoding signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(m.PublicKey(), crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("token signature: %v", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("decoding claims: %v", err)
+	}
+	claims := map[string]interface{}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("parsing claims: %v", err)
+	}
+	if claims["iss"] != m.URL() || claims["aud"] != "minikube" || claims["sub"] != "jane" {
+		t.Errorf("token claims = %v", claims)
+	}
+}
+
+func TestExecConfig(t *testing.T) {
+	e := ExecConfig(&config.OIDCConfig{
+		IssuerURL:    "https://dex.example.com",
+		ClientID:     "minikube",
+		ClientSecret: "s3cr3t",
+		CAFile:       "/home/jane/dex-ca.crt",
+		ExtraScopes:  []string{"email", "groups"},
+	})
+	want := []string{
+		"oidc-login",
+		"get-token",
+		"--oidc-issuer-url=https://dex.example.com",
+		"--oidc-client-id=minikube",
+		"--oidc-client-secret=s3cr3t",
+		"--oidc-extra-scope=email",
+		"--oidc-extra-scope=groups",
+		"--certificate-authority=/home/jane/dex-ca.crt",
+	}
+	if diff := cmp.Diff(want, e.Args); diff != "" {
+		t.Errorf("ExecConfig() args diff (-want +got):\n%s", diff)
+	}
+	if e.Command != "kubectl" || e.APIVersion != "client.authentication.k8s.io/v1beta1" {
+		t.Errorf("ExecConfig() = %+v", e)
+	}
+}
@@ -56,6 +56,11 @@ const (
 	PowerOff  RegStep = "PowerOff"
 	Pausing   RegStep = "Pausing"
 	Unpausing RegStep = "Unpausing"
+
+	// Changing the CNI
+	RemovingCNI       RegStep = "Removing CNI"
+	RestartingRuntime RegStep = "Restarting Container Runtime"
+	RestartingPods    RegStep = "Restarting Pods"
 )
 
 // RegStep is a type representing a distinct step of `minikube start`
@@ -67,6 +72,8 @@ type Register struct {
 	steps   map[RegStep][]RegStep
 	first   RegStep
 	current RegStep
+	// endSpan ends the trace span of the current step
+	endSpan func()
 }
 
 // Reg keeps track of all possible steps and the current step we are on
@@ -103,6 +110,8 @@ func init() {
 			Pausing:   {Pausing, Done},
 			Unpausing: {Unpausing, Done},
 			Deleting:  {Deleting, Stopping, Done, Purging},
+
+			RemovingCNI: {RemovingCNI, ConfiguringCNI, RestartingRuntime, RestartingPods, Done},
 		},
 	}
 }
@@ -136,7 +145,7 @@ func (r *Register) currentStep() string {
 
 // SetStep sets the current step
 func (r *Register) SetStep(s RegStep) {
-	defer trace.StartSpan(string(s))
+	defer func() { r.endSpan = trace.StartSpan(string(s)) }()
 	if r.first == RegStep("") {
 		_, ok := r.steps[s]
 		if ok {
@@ -144,8 +153,8 @@ func (r *Register) SetStep(s RegStep) {
 		} else {
 			klog.Errorf("unexpected first step: %q", r.first)
 		}
-	} else {
-		trace.EndSpan(string(r.current))
+	} else if r.endSpan != nil {
+		r.endSpan()
 	}
 
 	r.current = s
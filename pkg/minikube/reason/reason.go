@@ -126,6 +126,8 @@ var (
 	InternalKubernetesClient = Kind{ID: "MK_K8S_CLIENT", ExitCode: ExControlPlaneUnavailable}
 	// minikube failed to list some configuration data
 	InternalListConfig = Kind{ID: "MK_LIST_CONFIG", ExitCode: ExProgramError}
+	// minikube failed to read the audit log
+	InternalAuditLog = Kind{ID: "MK_AUDIT_LOG", ExitCode: ExProgramError}
 	// minikube failed to follow or watch minikube logs
 	InternalLogFollow = Kind{ID: "MK_LOG_FOLLOW", ExitCode: ExProgramError}
 	// minikube failed to create an appropriate new runtime based on the driver in use
@@ -288,6 +290,22 @@ var (
 	HostPurge = Kind{ID: "HOST_PURGE", ExitCode: ExHostError}
 	// minikube failed to persist profile config
 	HostSaveProfile = Kind{ID: "HOST_SAVE_PROFILE", ExitCode: ExHostConfig}
+	// minikube failed to save a cluster snapshot
+	HostSnapshotSave = Kind{ID: "HOST_SNAPSHOT_SAVE", ExitCode: ExHostError}
+	// minikube failed to restore a cluster snapshot
+	HostSnapshotRestore = Kind{ID: "HOST_SNAPSHOT_RESTORE", ExitCode: ExHostError}
+	// minikube failed to list cluster snapshots
+	HostSnapshotList = Kind{ID: "HOST_SNAPSHOT_LIST", ExitCode: ExHostError}
+	// minikube failed to delete a cluster snapshot
+	HostSnapshotDelete = Kind{ID: "HOST_SNAPSHOT_DELETE", ExitCode: ExHostError}
+	// minikube failed to sync a host directory with a node
+	HostSync = Kind{ID: "HOST_SYNC", ExitCode: ExHostError}
+	// minikube failed to run the registry cache
+	HostRegistryCache = Kind{ID: "HOST_REGISTRY_CACHE", ExitCode: ExHostError}
+	// minikube failed to create or load an offline bundle
+	HostBundle = Kind{ID: "HOST_BUNDLE", ExitCode: ExHostError}
+	// minikube failed to install the CA given by the user
+	HostCerts = Kind{ID: "HOST_CERTS", ExitCode: ExHostError}
 
 	// minikube could not find a provider for the selected driver
 	ProviderNotFound = Kind{ID: "PROVIDER_NOT_FOUND", ExitCode: ExProviderNotFound}
@@ -329,6 +347,12 @@ var (
 	GuestCacheLoad = Kind{ID: "GUEST_CACHE_LOAD", ExitCode: ExGuestError}
 	// minikube failed to setup certificates
 	GuestCert = Kind{ID: "GUEST_CERT", ExitCode: ExGuestError}
+	// minikube failed to read the audit log of the apiserver
+	GuestAuditLog = Kind{ID: "GUEST_AUDIT_LOG", ExitCode: ExGuestError}
+	// minikube failed to write the diagnostic archive of the logs
+	GuestLogsBundle = Kind{ID: "GUEST_LOGS_BUNDLE", ExitCode: ExGuestError}
+	// minikube failed to change the CNI of the cluster
+	GuestCNIChange = Kind{ID: "GUEST_CNI_CHANGE", ExitCode: ExGuestError}
 	// minikube failed to access the control plane
 	GuestCpConfig = Kind{ID: "GUEST_CP_CONFIG", ExitCode: ExGuestConfig}
 	// minikube failed to properly delete a resource, such as a profile
@@ -349,6 +373,10 @@ var (
 	GuestImagePush = Kind{ID: "GUEST_IMAGE_PUSH", ExitCode: ExGuestError}
 	// minikube failed to tag an image
 	GuestImageTag = Kind{ID: "GUEST_IMAGE_TAG", ExitCode: ExGuestError}
+	// minikube failed to inspect an image
+	GuestImageInspect = Kind{ID: "GUEST_IMAGE_INSPECT", ExitCode: ExGuestError}
+	// minikube failed to prune the unused images
+	GuestImagePrune = Kind{ID: "GUEST_IMAGE_PRUNE", ExitCode: ExGuestError}
 	// minikube failed to load host
 	GuestLoadHost = Kind{ID: "GUEST_LOAD_HOST", ExitCode: ExGuestError}
 	// minkube failed to create a mount
@@ -362,12 +390,22 @@ var (
 		1. Allow a port through the firewall
 		2. Specify "--port=<port_number>" for "minikube mount"`),
 	}
+	// the guest or the driver does not support the filesystem of the mount
+	GuestMountUnsupported = Kind{
+		ID:       "GUEST_MOUNT_UNSUPPORTED",
+		ExitCode: ExGuestUnsupported,
+		Advice:   translate.T(`Try another filesystem with "--type" for "minikube mount", such as 9p or nfs`),
+	}
 	// minkube failed to update a mount
 	GuestMountConflict = Kind{ID: "GUEST_MOUNT_CONFLICT", ExitCode: ExGuestConflict}
 	// minikube failed to add a node to the cluster
 	GuestNodeAdd = Kind{ID: "GUEST_NODE_ADD", ExitCode: ExGuestError}
 	// minikube failed to remove a node from the cluster
 	GuestNodeDelete = Kind{ID: "GUEST_NODE_DELETE", ExitCode: ExGuestError}
+	// minikube failed to inject a failure into a cluster node
+	GuestNodeFail = Kind{ID: "GUEST_NODE_FAIL", ExitCode: ExGuestError}
+	// minikube failed to recover a cluster node from an injected failure
+	GuestNodeHeal = Kind{ID: "GUEST_NODE_HEAL", ExitCode: ExGuestError}
 	// minikube failed to provision a node
 	GuestNodeProvision = Kind{ID: "GUEST_NODE_PROVISION", ExitCode: ExGuestError}
 	// minikube failed to retrieve information for a cluster node
@@ -378,6 +416,10 @@ var (
 	GuestPause = Kind{ID: "GUEST_PAUSE", ExitCode: ExGuestError}
 	// minikube failed to delete a machine profile directory
 	GuestProfileDeletion = Kind{ID: "GUEST_PROFILE_DELETION", ExitCode: ExGuestError}
+	// minikube failed to rename a profile
+	GuestProfileRename = Kind{ID: "GUEST_PROFILE_RENAME", ExitCode: ExGuestError}
+	// minikube failed to clone a profile
+	GuestProfileClone = Kind{ID: "GUEST_PROFILE_CLONE", ExitCode: ExGuestError}
 	// minikube failed while attempting to provision the guest
 	GuestProvision = Kind{ID: "GUEST_PROVISION", ExitCode: ExGuestError}
 	// docker container exited prematurely during provisioning
@@ -456,6 +498,8 @@ var (
 	SvcTunnelStop = Kind{ID: "SVC_TUNNEL_STOP", ExitCode: ExSvcError}
 	// another instance of tunnel already running
 	SvcTunnelAlreadyRunning = Kind{ID: "TUNNEL_ALREADY_RUNNING", ExitCode: ExSvcConflict, Style: style.Usage}
+	// no tunnel is running for the profile
+	SvcTunnelNotRunning = Kind{ID: "TUNNEL_NOT_RUNNING", ExitCode: ExSvcNotRunning, Style: style.Usage}
 	// minikube was unable to access the service url
 	SvcURLTimeout = Kind{ID: "SVC_URL_TIMEOUT", ExitCode: ExSvcTimeout}
 	// minikube couldn't find the specified service in the specified namespace
@@ -39,6 +39,8 @@ import (
 
 const (
 	docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/kvm2/"
+	// mountTypeVirtiofs is cluster.MountTypeVirtiofs, which can not be imported by the drivers
+	mountTypeVirtiofs = "virtiofs"
 )
 
 func init() {
@@ -71,6 +73,7 @@ type kvmDriver struct {
 	ConnectionURI  string
 	NUMANodeCount  int
 	ExtraDisks     int
+	SharedMemory   bool
 }
 
 func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
@@ -94,6 +97,7 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		ConnectionURI:  cc.KVMQemuURI,
 		NUMANodeCount:  cc.KVMNUMACount,
 		ExtraDisks:     cc.ExtraDisks,
+		SharedMemory:   cc.MountType == mountTypeVirtiofs,
 	}, nil
 }
 
@@ -17,7 +17,6 @@ limitations under the License.
 package qemu2
 
 import (
-	"crypto/rand"
 	"fmt"
 	"os"
 	"os/exec"
@@ -37,7 +36,11 @@ import (
 	"k8s.io/minikube/pkg/minikube/registry"
 )
 
-const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/qemu/"
+const (
+	docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/qemu/"
+	// mountTypeVirtiofs is cluster.MountTypeVirtiofs, which can not be imported by the drivers
+	mountTypeVirtiofs = "virtiofs"
+)
 
 func init() {
 	priority := registry.Default
@@ -154,7 +157,7 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	mac, err := generateMACAddress()
+	mac, err := qemu.GenerateMACAddress()
 	if err != nil {
 		return nil, fmt.Errorf("generating MAC address: %v", err)
 	}
@@ -185,6 +188,7 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		SocketVMNetPath:       cc.SocketVMnetPath,
 		SocketVMNetClientPath: cc.SocketVMnetClientPath,
 		ExtraDisks:            cc.ExtraDisks,
+		SharedMemory:          cc.MountType == mountTypeVirtiofs,
 	}, nil
 }
 
@@ -209,14 +213,3 @@ func status() registry.State {
 
 	return registry.State{Installed: true, Healthy: true, Running: true}
 }
-
-func generateMACAddress() (string, error) {
-	buf := make([]byte, 6)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
-	}
-	// Set local bit, ensure unicast address, socket_vmnet doesn't support multicast
-	buf[0] = (buf[0] | 2) & 0xfe
-	mac := fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
-	return mac, nil
-}
@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrycache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"k8s.io/klog/v2"
+)
+
+// proxy is a read-only registry serving the images of an upstream registry, pulling them once into the cache directory.
+// The blobs and manifests are stored by digest, shared by the upstream registries, and the tags by registry and repository.
+type proxy struct {
+	upstream Upstream
+	dir      string
+	opts     []remote.Option
+}
+
+func newProxy(u Upstream, dir string) *proxy {
+	// the cache is shared over the network, so only anonymous pulls are made and no credential of the user is exposed
+	return &proxy{upstream: u, dir: dir, opts: []remote.Option{remote.WithUserAgent("minikube-registry-cache")}}
+}
+
+// ServeHTTP serves the pull requests of the registry API
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "the registry cache is read-only")
+		return
+	}
+	if r.URL.Path == "/v2/" || r.URL.Path == "/v2" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if i := strings.LastIndex(path, "/manifests/"); i > 0 {
+		p.manifest(w, r, path[:i], path[i+len("/manifests/"):])
+		return
+	}
+	if i := strings.LastIndex(path, "/blobs/"); i > 0 {
+		p.blob(w, r, path[:i], path[i+len("/blobs/"):])
+		return
+	}
+	writeError(w, http.StatusNotFound, "UNSUPPORTED", "unsupported request")
+}
+
+// repositoryRegexp matches the repositories, whose path components never are "." or ".."
+var repositoryRegexp = regexp.MustCompile(`^(?:` + reference.NameRegexp.String() + `)$`)
+
+// repository validates the repository of a request, which also makes it safe to use in a path
+func (p *proxy) repository(w http.ResponseWriter, repo string) (name.Repository, bool) {
+	if !repositoryRegexp.MatchString(repo) {
+		writeError(w, http.StatusBadRequest, "NAME_INVALID", fmt.Sprintf("invalid repository %q", repo))
+		return name.Repository{}, false
+	}
+	r, err := name.NewRepository(p.upstream.Host+"/"+repo, name.StrictValidation)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "NAME_INVALID", err.Error())
+		return name.Repository{}, false
+	}
+	return r, true
+}
+
+func (p *proxy) blobPath(h v1.Hash) string {
+	return filepath.Join(p.dir, "blobs", h.Algorithm, h.Hex)
+}
+
+func (p *proxy) mediaTypePath(h v1.Hash) string {
+	return filepath.Join(p.dir, "manifests", h.Algorithm, h.Hex)
+}
+
+func (p *proxy) tagPath(repo name.Repository, tag string) string {
+	return filepath.Join(p.dir, "tags", p.upstream.Registry, filepath.FromSlash(repo.RepositoryStr()), tag)
+}
+
+// manifest serves the manifest of a tag or a digest. The digest of a tag is looked up upstream on every pull, with a
+// HEAD request which does not count in the rate limits of Docker Hub, and the last one is kept for when it is offline.
+func (p *proxy) manifest(w http.ResponseWriter, r *http.Request, repoName string, ref string) {
+	repo, ok := p.repository(w, repoName)
+	if !ok {
+		return
+	}
+	if h, err := v1.NewHash(ref); err == nil {
+		if p.serveManifest(w, r, h) {
+			return
+		}
+		desc, err := remote.Get(repo.Digest(ref), p.remoteOptions(r)...)
+		if err != nil {
+			upstreamError(w, err)
+			return
+		}
+		if err := p.storeManifest(desc); err != nil {
+			klog.Warningf("unable to cache manifest %s: %v", repo.Digest(ref), err)
+		}
+		serveManifestBytes(w, r, desc.Digest, string(desc.MediaType), desc.Manifest)
+		return
+	}
+
+	tag, err := name.NewTag(repo.String()+":"+ref, name.StrictValidation)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "TAG_INVALID", err.Error())
+		return
+	}
+	tagFile := p.tagPath(repo, tag.TagStr())
+	head, err := remote.Head(tag, p.remoteOptions(r)...)
+	if err == nil && p.serveManifest(w, r, head.Digest) {
+		p.storeTag(tagFile, head.Digest)
+		return
+	}
+	desc, err := remote.Get(tag, p.remoteOptions(r)...)
+	if err != nil {
+		// the upstream registry is unreachable, serve the last manifest of the tag
+		if h, ok := readTag(tagFile); ok && p.serveManifest(w, r, h) {
+			klog.Warningf("serving cached %s: %v", tag, err)
+			return
+		}
+		upstreamError(w, err)
+		return
+	}
+	if err := p.storeManifest(desc); err != nil {
+		klog.Warningf("unable to cache manifest %s: %v", tag, err)
+	}
+	p.storeTag(tagFile, desc.Digest)
+	serveManifestBytes(w, r, desc.Digest, string(desc.MediaType), desc.Manifest)
+}
+
+// serveManifest serves the cached manifest, returning false if it is not cached
+func (p *proxy) serveManifest(w http.ResponseWriter, r *http.Request, h v1.Hash) bool {
+	mediaType, err := os.ReadFile(p.mediaTypePath(h))
+	if err != nil {
+		return false
+	}
+	b, err := os.ReadFile(p.blobPath(h))
+	if err != nil {
+		return false
+	}
+	serveManifestBytes(w, r, h, string(mediaType), b)
+	return true
+}
+
+func serveManifestBytes(w http.ResponseWriter, r *http.Request, h v1.Hash, mediaType string, b []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", h.String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(b)
+	}
+}
+
+func (p *proxy) storeManifest(desc *remote.Descriptor) error {
+	if err := writeFile(p.blobPath(desc.Digest), desc.Manifest); err != nil {
+		return err
+	}
+	return writeFile(p.mediaTypePath(desc.Digest), []byte(desc.MediaType))
+}
+
+func (p *proxy) storeTag(tagFile string, h v1.Hash) {
+	if err := writeFile(tagFile, []byte(h.String())); err != nil {
+		klog.Warningf("unable to cache tag: %v", err)
+	}
+}
+
+func readTag(tagFile string) (v1.Hash, bool) {
+	b, err := os.ReadFile(tagFile)
+	if err != nil {
+		return v1.Hash{}, false
+	}
+	h, err := v1.NewHash(string(b))
+	return h, err == nil
+}
+
+// blob serves a blob, which is pulled from the upstream registry into the cache while it is streamed to the client
+func (p *proxy) blob(w http.ResponseWriter, r *http.Request, repoName string, digest string) {
+	repo, ok := p.repository(w, repoName)
+	if !ok {
+		return
+	}
+	h, err := v1.NewHash(digest)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "DIGEST_INVALID", err.Error())
+		return
+	}
+	path := p.blobPath(h)
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", h.String())
+		http.ServeContent(w, r, "", time.Time{}, f)
+		return
+	}
+
+	layer, err := remote.Layer(repo.Digest(digest), p.remoteOptions(r)...)
+	if err != nil {
+		upstreamError(w, err)
+		return
+	}
+	if r.Method == http.MethodHead {
+		size, err := layer.Size()
+		if err != nil {
+			upstreamError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", h.String())
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		upstreamError(w, err)
+		return
+	}
+	defer rc.Close()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, "UNKNOWN", err.Error())
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "UNKNOWN", err.Error())
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", h.String())
+	w.WriteHeader(http.StatusOK)
+	// the blob is cached even if the client goes away, the reader verifying its digest at the end
+	_, err = io.Copy(tmp, io.TeeReader(rc, &clientWriter{w: w}))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		klog.Warningf("unable to cache blob %s: %v", repo.Digest(digest), err)
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		klog.Warningf("unable to cache blob %s: %v", repo.Digest(digest), err)
+	}
+}
+
+func (p *proxy) remoteOptions(r *http.Request) []remote.Option {
+	return append([]remote.Option{remote.WithContext(r.Context())}, p.opts...)
+}
+
+// clientWriter writes to the client until it fails, ignoring its errors so that the download completes
+type clientWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (c *clientWriter) Write(b []byte) (int, error) {
+	if c.err == nil {
+		_, c.err = c.w.Write(b)
+	}
+	return len(b), nil
+}
+
+// writeFile writes the file atomically, creating its directory
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".write-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// upstreamError forwards the error of the upstream registry, or reports it as unavailable
+func upstreamError(w http.ResponseWriter, err error) {
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode != 0 {
+		code := "UNKNOWN"
+		if len(terr.Errors) > 0 {
+			code = string(terr.Errors[0].Code)
+		}
+		writeError(w, terr.StatusCode, code, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadGateway, "UNAVAILABLE", err.Error())
+}
+
+// writeError writes an error in the format of the registry API
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	type apiError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Errors []apiError `json:"errors"`
+	}{[]apiError{{Code: code, Message: message}}})
+}
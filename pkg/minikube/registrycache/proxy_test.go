@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrycache
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// pull pulls the image and all its layers, returning its digest
+func pull(t *testing.T, ref string) string {
+	t.Helper()
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatalf("parse %s: %v", ref, err)
+	}
+	img, err := remote.Image(r)
+	if err != nil {
+		t.Fatalf("pull %s: %v", ref, err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatalf("layers of %s: %v", ref, err)
+	}
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatalf("layer of %s: %v", ref, err)
+		}
+		// the reader verifies the digest of the layer at the end
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatalf("read layer of %s: %v", ref, err)
+		}
+		rc.Close()
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatalf("digest of %s: %v", ref, err)
+	}
+	return d.String()
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	upstreamHost := strings.TrimPrefix(upstream.URL, "http://")
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatalf("random image: %v", err)
+	}
+	tag, err := name.NewTag(upstreamHost + "/test/image:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := httptest.NewServer(newProxy(Upstream{Registry: "example.io", Host: upstreamHost}, t.TempDir()))
+	defer cache.Close()
+	cacheHost := strings.TrimPrefix(cache.URL, "http://")
+
+	if got := pull(t, cacheHost+"/test/image:v1"); got != want.String() {
+		t.Errorf("pulled through the cache %s, want %s", got, want)
+	}
+
+	// the cached image is served once the upstream registry is gone, by tag and by digest
+	upstream.Close()
+	if got := pull(t, cacheHost+"/test/image:v1"); got != want.String() {
+		t.Errorf("pulled from the cache %s, want %s", got, want)
+	}
+	if got := pull(t, cacheHost+"/test/image@"+want.String()); got != want.String() {
+		t.Errorf("pulled from the cache by digest %s, want %s", got, want)
+	}
+	unknown, err := name.NewTag(cacheHost + "/test/image:v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.Image(unknown); err == nil {
+		t.Errorf("pulled an unknown tag from the cache")
+	}
+}
+
+func TestProxyRequests(t *testing.T) {
+	cache := httptest.NewServer(newProxy(Upstream{Registry: "example.io", Host: "127.0.0.1:1"}, t.TempDir()))
+	defer cache.Close()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/v2/", http.StatusOK},
+		{http.MethodPut, "/v2/test/image/manifests/v1", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/v2/Test/image/manifests/v1", http.StatusBadRequest},
+		{http.MethodGet, "/v2/test/../image/manifests/v1", http.StatusBadRequest},
+		{http.MethodGet, "/v2/test/image/blobs/sha256:..", http.StatusBadRequest},
+		{http.MethodGet, "/v2/test/image/tags/list", http.StatusNotFound},
+		{http.MethodGet, "/v2/test/image/manifests/v1", http.StatusBadGateway},
+	}
+	var got, want []int
+	for _, tc := range tests {
+		req, err := http.NewRequest(tc.method, cache.URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// keep the path as is, for the client not to clean it
+		req.URL.Opaque = tc.path
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+		resp.Body.Close()
+		got = append(got, resp.StatusCode)
+		want = append(want, tc.want)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("status codes diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	got := mirrors("192.168.49.1", []string{"https://mirror.gcr.io"})
+	if len(got) != len(Upstreams) {
+		t.Fatalf("got %d mirrors, want %d", len(got), len(Upstreams))
+	}
+	want := []string{"http://192.168.49.1:5100", "https://mirror.gcr.io"}
+	if diff := cmp.Diff(want, got[0].Endpoints); diff != "" {
+		t.Errorf("docker.io endpoints diff (-want +got):\n%s", diff)
+	}
+	if got[1].Registry != "registry.k8s.io" || got[1].Endpoints[0] != "http://192.168.49.1:5101" {
+		t.Errorf("second mirror: got %+v", got[1])
+	}
+
+	got = mirrors("", []string{"https://mirror.gcr.io"})
+	if len(got) != 1 || got[0].Registry != "docker.io" {
+		t.Errorf("mirrors without cache: got %+v, want docker.io only", got)
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	if got := listenAddress("127.0.0.1"); got != loopback {
+		t.Errorf("listenAddress(127.0.0.1) = %s, want %s", got, loopback)
+	}
+	// the documentation network is not assigned to the host, like the gateway of the QEMU user network
+	if got := listenAddress("192.0.2.1"); got != loopback {
+		t.Errorf("listenAddress(192.0.2.1) = %s, want %s", got, loopback)
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range addrs {
+		n, ok := a.(*net.IPNet)
+		if !ok || n.IP.IsLoopback() {
+			continue
+		}
+		if got := listenAddress(n.IP.String()); got != n.IP.String() {
+			t.Errorf("listenAddress(%s) = %s, want the address of the host", n.IP, got)
+		}
+	}
+}
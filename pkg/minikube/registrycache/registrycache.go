@@ -0,0 +1,378 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registrycache runs the pull-through caching registry shared by the clusters, on the host or in a container
+package registrycache
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/mitchellh/go-ps"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+const (
+	// Host runs the registry cache as a minikube process on the host
+	Host = "host"
+	// Container runs the registry cache as a container next to the clusters of the docker and podman drivers
+	Container = "container"
+	// Auto runs the registry cache in a container with the docker and podman drivers, else on the host
+	Auto = "auto"
+
+	// BasePort is the port of the cache of the first upstream registry, the others following it
+	BasePort = 5100
+	// loopback is the address the registry cache on the host always listens on
+	loopback = "127.0.0.1"
+
+	// image is the registry running the caches in the container, the one of the registry addon
+	image = "registry:2.8.3@sha256:fb9c9aef62af3955f6014613456551c92e88a67dcf1fc51f5f91bcbd1832813f"
+)
+
+// Modes are the ways to run the registry cache
+var Modes = []string{Host, Container}
+
+// Upstream is a registry whose images are cached
+type Upstream struct {
+	// Registry is the name of the registry in the image references
+	Registry string
+	// Host serves the API of the registry
+	Host string
+}
+
+// Upstreams are the cached registries, each on its own port following BasePort
+var Upstreams = []Upstream{
+	{Registry: "docker.io", Host: "registry-1.docker.io"},
+	{Registry: "registry.k8s.io", Host: "registry.k8s.io"},
+	{Registry: "quay.io", Host: "quay.io"},
+	{Registry: "gcr.io", Host: "gcr.io"},
+	{Registry: "ghcr.io", Host: "ghcr.io"},
+}
+
+// Resolve returns the mode of the registry cache for the driver, resolving Auto
+func Resolve(mode string, driverName string) string {
+	if mode != Auto {
+		return mode
+	}
+	if driver.IsKIC(driverName) {
+		return Container
+	}
+	return Host
+}
+
+// Dir returns the directory storing the images cached on the host
+func Dir() string {
+	return localpath.MakeMiniPath("registry-cache")
+}
+
+func pidFile() string {
+	return filepath.Join(Dir(), "registry-cache.pid")
+}
+
+// addressesFile lists the addresses the registry cache process on the host listens on, besides the loopback one
+func addressesFile() string {
+	return filepath.Join(Dir(), "registry-cache.addresses")
+}
+
+// Mirrors starts the registry cache of the cluster if it is enabled, and returns the mirrors of the registries for the
+// container runtime: the registry cache first, then the --registry-mirror ones for Docker Hub. The images are pulled
+// from their registries if the registry cache fails to start.
+func Mirrors(cc config.ClusterConfig, h *host.Host) []cruntime.RegistryMirror {
+	ip := ""
+	if cc.RegistryCache != "" {
+		var err error
+		if ip, err = start(cc, h); err != nil {
+			klog.Warningf("unable to start the registry cache: %v", err)
+			out.WarningT("Unable to start the registry cache, images will be pulled from their registries: {{.error}}", out.V{"error": err})
+		}
+	}
+	return mirrors(ip, cc.RegistryMirror)
+}
+
+// mirrors returns the mirrors of the registries, from the registry cache at the IP if any and the Docker Hub mirrors
+func mirrors(ip string, dockerHubMirrors []string) []cruntime.RegistryMirror {
+	var ms []cruntime.RegistryMirror
+	for i, u := range Upstreams {
+		m := cruntime.RegistryMirror{Registry: u.Registry}
+		if ip != "" {
+			m.Endpoints = append(m.Endpoints, "http://"+net.JoinHostPort(ip, strconv.Itoa(BasePort+i)))
+		}
+		if u.Registry == "docker.io" {
+			m.Endpoints = append(m.Endpoints, dockerHubMirrors...)
+		}
+		if len(m.Endpoints) > 0 {
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+// start starts the registry cache of the cluster if needed, returning its IP for the cluster
+func start(cc config.ClusterConfig, h *host.Host) (string, error) {
+	if cc.RegistryCache == Container {
+		if !driver.IsKIC(cc.Driver) {
+			return "", fmt.Errorf("the %s registry cache requires the docker or podman driver", Container)
+		}
+		if err := oci.EnsureRegistryCache(cc.Driver, image, containerCommand()); err != nil {
+			return "", err
+		}
+		network := cc.Network
+		if network == "" {
+			network = cc.Name
+		}
+		return oci.ConnectRegistryCache(cc.Driver, network)
+	}
+
+	ip := loopback
+	if cc.Driver != driver.None {
+		hostIP, err := cluster.HostIP(h, cc.Name)
+		if err != nil {
+			return "", errors.Wrap(err, "host IP")
+		}
+		ip = hostIP.String()
+	}
+	if err := startHost(listenAddress(ip)); err != nil {
+		return "", err
+	}
+	return ip, nil
+}
+
+// listenAddress returns the address the registry cache on the host listens on to be reached at the IP by a cluster.
+// The IPs which are not assigned to an interface of the host, such as the gateway of the QEMU user network or
+// host.docker.internal with Docker Desktop, are forwarded to the loopback address.
+func listenAddress(ip string) string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		klog.Warningf("listing the addresses of the host: %v", err)
+		return loopback
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(net.ParseIP(ip)) {
+			return ip
+		}
+	}
+	return loopback
+}
+
+// hostAddresses returns the addresses the registry cache process on the host was started with
+func hostAddresses() []string {
+	b, err := os.ReadFile(addressesFile())
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(b))
+}
+
+// containerCommand returns the shell command running a registry in proxy mode for every upstream in the container
+func containerCommand() string {
+	var cmds []string
+	for i, u := range Upstreams {
+		cmds = append(cmds, fmt.Sprintf("REGISTRY_HTTP_ADDR=:%d REGISTRY_PROXY_REMOTEURL=https://%s REGISTRY_STORAGE_FILESYSTEM_ROOTDIRECTORY=/var/lib/registry/%s registry serve /etc/docker/registry/config.yml &",
+			BasePort+i, u.Host, u.Registry))
+	}
+	return strings.Join(append(cmds, "wait"), "\n")
+}
+
+// startHost starts the registry cache process on the host, unless it is running and listens on the address
+func startHost(addr string) error {
+	if hostRunning(addr) {
+		return nil
+	}
+	addrs := hostAddresses()
+	if addr != loopback && !slices.Contains(addrs, addr) {
+		addrs = append(addrs, addr)
+	}
+	// a running process does not listen on the address of the cluster, it is restarted to also listen on it
+	if err := stopHost(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir(), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(addressesFile(), []byte(strings.Join(addrs, "\n")), 0o644); err != nil {
+		return errors.Wrap(err, "writing registry cache addresses")
+	}
+	cmd := exec.Command(os.Args[0], "registry-cache", "serve", "--addresses="+strings.Join(addrs, ","))
+	cmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "starting registry cache process")
+	}
+	if err := os.WriteFile(pidFile(), []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		return errors.Wrap(err, "writing registry cache pid")
+	}
+	if err := cmd.Process.Release(); err != nil {
+		klog.Warningf("release registry cache process: %v", err)
+	}
+	return retry.Expo(func() error {
+		if !hostRunning(addr) {
+			return fmt.Errorf("registry cache is not serving on %s", net.JoinHostPort(addr, strconv.Itoa(BasePort)))
+		}
+		return nil
+	}, 250*time.Millisecond, 15*time.Second)
+}
+
+// stopHost stops the registry cache process on the host, waiting for it to release its ports
+func stopHost() error {
+	if pid := hostPid(); pid != 0 {
+		klog.Infof("killing registry cache process %d", pid)
+		p, err := os.FindProcess(pid)
+		if err == nil {
+			err = p.Kill()
+		}
+		if err != nil {
+			return errors.Wrapf(err, "killing registry cache process %d", pid)
+		}
+		exited := func() error {
+			if hostPid() != 0 {
+				return fmt.Errorf("registry cache process %d is running", pid)
+			}
+			return nil
+		}
+		if err := retry.Local(exited, 5*time.Second); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(pidFile()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// hostRunning returns whether the registry cache serves on the address of the host
+func hostRunning(addr string) bool {
+	c := http.Client{Timeout: 2 * time.Second}
+	resp, err := c.Get(fmt.Sprintf("http://%s/v2/", net.JoinHostPort(addr, strconv.Itoa(BasePort))))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK && resp.Header.Get("Docker-Distribution-API-Version") != ""
+}
+
+// hostPid returns the pid of the registry cache process on the host, 0 if it is not running
+func hostPid() int {
+	b, err := os.ReadFile(pidFile())
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	entry, err := ps.FindProcess(pid)
+	if err != nil || entry == nil || !strings.Contains(entry.Executable(), "minikube") {
+		return 0
+	}
+	return pid
+}
+
+// Serve runs the registry caches of the upstream registries on the loopback and the given addresses of the host,
+// until one of them fails. The clusters reach the host on these addresses, which keeps the caches off other networks.
+func Serve(addrs []string) error {
+	dir := Dir()
+	addrs = append([]string{loopback}, addrs...)
+	errc := make(chan error, len(Upstreams)*len(addrs))
+	for i, u := range Upstreams {
+		handler := newProxy(u, dir)
+		for _, addr := range addrs {
+			srv := &http.Server{
+				Addr:              net.JoinHostPort(addr, strconv.Itoa(BasePort+i)),
+				Handler:           handler,
+				ReadHeaderTimeout: 30 * time.Second,
+			}
+			go func() {
+				errc <- errors.Wrapf(srv.ListenAndServe(), "serving %s cache on %s", u.Registry, addr)
+			}()
+		}
+	}
+	return <-errc
+}
+
+// Status is the state of the registry cache, on the host and in the containers
+type Status struct {
+	// Host is the pid of the registry cache process on the host, 0 if it is not running
+	Host int
+	// Containers is the state of the registry cache container of the container runtimes
+	Containers map[string]state.State
+}
+
+// GetStatus returns the state of the registry cache
+func GetStatus() Status {
+	st := Status{Host: hostPid(), Containers: map[string]state.State{}}
+	for _, ociBin := range ociBins() {
+		s, err := oci.ContainerStatus(ociBin, oci.RegistryCacheContainer)
+		if err != nil {
+			klog.Infof("%s registry cache status: %v", ociBin, err)
+			continue
+		}
+		if s != state.None {
+			st.Containers[ociBin] = s
+		}
+	}
+	return st
+}
+
+// Stop stops the registry cache on the host and in the containers, also deleting the cached images with purge
+func Stop(purge bool) error {
+	if err := stopHost(); err != nil {
+		return err
+	}
+	for _, ociBin := range ociBins() {
+		if _, err := oci.ContainerExists(ociBin, oci.RegistryCacheContainer); err != nil {
+			klog.Warningf("skipping %s registry cache: %v", ociBin, err)
+			continue
+		}
+		if err := oci.DeleteRegistryCache(ociBin, purge); err != nil {
+			return errors.Wrapf(err, "%s registry cache", ociBin)
+		}
+	}
+	if purge {
+		return os.RemoveAll(Dir())
+	}
+	return nil
+}
+
+// ociBins returns the container runtimes which are installed
+func ociBins() []string {
+	var bins []string
+	for _, b := range []string{oci.Docker, oci.Podman} {
+		if _, err := exec.LookPath(b); err == nil {
+			bins = append(bins, b)
+		}
+	}
+	return bins
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot saves the state of a stopped cluster and restores it into the same or a new profile.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/version"
+)
+
+const (
+	metadataFile = "metadata.json"
+	// imageRepository is the repository kic node containers are committed to
+	imageRepository = "minikube-snapshot"
+	// restoredRepository is the repository the images of the restored profiles are tagged in, so that they outlive the snapshot
+	restoredRepository = "minikube-restored"
+	// qemuDiskFile is the name of the disk image created by the qemu driver in the machine directory
+	qemuDiskFile = "disk.qcow2"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// ErrNotFound is returned when a snapshot does not exist
+var ErrNotFound = errors.New("snapshot not found")
+
+// Metadata describes a saved snapshot
+type Metadata struct {
+	Name              string
+	Profile           string
+	Driver            string
+	MinikubeVersion   string
+	KubernetesVersion string
+	ContainerRuntime  string
+	Addons            []string
+	Machines          []string
+	Images            map[string]string `json:",omitempty"` // kic only: machine name to committed image
+	CreationTime      time.Time
+}
+
+// ValidName returns an error if name can not be used as a snapshot name
+func ValidName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: must be lowercase alphanumeric and may contain '-', '_' or '.'", name)
+	}
+	return nil
+}
+
+// Supported returns an error if snapshots are not supported for the cluster
+func Supported(cc config.ClusterConfig) error {
+	switch {
+	case driver.IsKIC(cc.Driver):
+		if len(cc.Nodes) > 1 {
+			return fmt.Errorf("snapshots of multi-node clusters are not supported by the %s driver", cc.Driver)
+		}
+		return nil
+	case driver.IsQEMU(cc.Driver), driver.IsKVM(cc.Driver):
+		return nil
+	default:
+		return fmt.Errorf("snapshots are not supported by the %s driver", cc.Driver)
+	}
+}
+
+// Save saves the state of a stopped cluster as a snapshot named name
+func Save(cc *config.ClusterConfig, name string) (*Metadata, error) {
+	if err := ValidName(name); err != nil {
+		return nil, err
+	}
+	if err := Supported(*cc); err != nil {
+		return nil, err
+	}
+	dir := localpath.Snapshot(name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("snapshot %q already exists", name)
+	}
+
+	m := &Metadata{
+		Name:              name,
+		Profile:           cc.Name,
+		Driver:            cc.Driver,
+		MinikubeVersion:   version.GetVersion(),
+		KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+		ContainerRuntime:  cc.KubernetesConfig.ContainerRuntime,
+		Addons:            enabledAddons(cc),
+		CreationTime:      time.Now(),
+	}
+	for _, n := range cc.Nodes {
+		m.Machines = append(m.Machines, config.MachineName(*cc, n))
+	}
+
+	if err := save(cc, m, dir); err != nil {
+		cleanup(m, dir)
+		return nil, err
+	}
+	return m, nil
+}
+
+func save(cc *config.ClusterConfig, m *Metadata, dir string) error {
+	if err := config.SaveProfile(cc.Name, cc, dir); err != nil {
+		return errors.Wrap(err, "save profile")
+	}
+	if err := copyCerts(localpath.Profile(cc.Name), config.ProfileFolderPath(cc.Name, dir)); err != nil {
+		return errors.Wrap(err, "copy certs")
+	}
+
+	for _, machineName := range m.Machines {
+		klog.Infof("saving disk of %s into snapshot %s", machineName, m.Name)
+		if driver.IsKIC(cc.Driver) {
+			if m.Images == nil {
+				m.Images = map[string]string{}
+			}
+			img := fmt.Sprintf("%s/%s:%s", imageRepository, m.Name, machineName)
+			if err := oci.CommitContainer(cc.Driver, machineName, img); err != nil {
+				return err
+			}
+			m.Images[machineName] = img
+			if err := oci.ExportVolume(cc.Driver, machineName, volumeTarball(dir, machineName), cc.KicBaseImage); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := machine.CopyHost(localpath.MachinePath(machineName), localpath.MachinePath(machineName, dir), machineName, machineName); err != nil {
+			return err
+		}
+	}
+	return writeMetadata(dir, m)
+}
+
+// Restore restores the snapshot named name into the stopped or missing profile target
+func Restore(name string, target string) (*config.ClusterConfig, error) {
+	m, err := Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if m.MinikubeVersion != version.GetVersion() {
+		return nil, fmt.Errorf("snapshot %q was created by minikube %s and can not be restored by minikube %s", name, m.MinikubeVersion, version.GetVersion())
+	}
+	if target == "" {
+		target = m.Profile
+	}
+	if driver.IsKVM(m.Driver) && target != m.Profile {
+		return nil, fmt.Errorf("snapshots of the %s driver can only be restored into the %q profile", m.Driver, m.Profile)
+	}
+
+	dir := localpath.Snapshot(name)
+	p, err := config.LoadProfile(m.Profile, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "load snapshot profile")
+	}
+	cc := p.Config
+	src := *cc
+	cc.Name = target
+	cc.KubernetesConfig.ClusterName = target
+
+	if err := copyCerts(config.ProfileFolderPath(m.Profile, dir), localpath.Profile(target)); err != nil {
+		return nil, errors.Wrap(err, "copy certs")
+	}
+
+	for _, n := range cc.Nodes {
+		from := config.MachineName(src, n)
+		to := config.MachineName(*cc, n)
+		klog.Infof("restoring disk of %s from snapshot %s into %s", from, name, to)
+		if driver.IsKIC(cc.Driver) {
+			// the profile keeps its own tag of the image, which is removed with the snapshot
+			img := fmt.Sprintf("%s/%s:%s", restoredRepository, target, to)
+			if err := oci.TagImage(cc.Driver, m.Images[from], img); err != nil {
+				return nil, err
+			}
+			cc.KicBaseImage = img
+			if err := restoreKIC(cc, volumeTarball(dir, from), to); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := restoreVM(cc.Driver, dir, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.SaveProfile(target, cc); err != nil {
+		return nil, errors.Wrap(err, "save profile")
+	}
+	return cc, nil
+}
+
+// restoreKIC replaces the container and /var volume of the machine, the container is recreated
+// from the committed image on the next start
+func restoreKIC(cc *config.ClusterConfig, tarball string, machine string) error {
+	if err := oci.DeleteContainer(context.Background(), cc.Driver, machine); err != nil {
+		klog.Infof("delete container %s (might be okay): %v", machine, err)
+	}
+	if err := os.RemoveAll(localpath.MachinePath(machine)); err != nil {
+		return errors.Wrapf(err, "remove machine %s", machine)
+	}
+	if err := oci.RemoveVolume(cc.Driver, machine); err != nil && !errors.Is(err, oci.ErrVolumeNotFound) {
+		return errors.Wrapf(err, "remove volume %s", machine)
+	}
+	return oci.ImportVolume(cc.Driver, cc.Name, tarball, machine, cc.KicBaseImage)
+}
+
+// restoreVM copies the disk of a saved machine into an existing machine,
+// or the whole machine directory if the target machine does not exist
+func restoreVM(driverName string, dir string, from string, to string) error {
+	if _, err := os.Stat(localpath.MachinePath(to)); err == nil {
+		disk := diskFile(driverName, to)
+		return copy.Copy(filepath.Join(localpath.MachinePath(from, dir), diskFile(driverName, from)), filepath.Join(localpath.MachinePath(to), disk))
+	}
+	return machine.CopyHost(localpath.MachinePath(from, dir), localpath.MachinePath(to), from, to)
+}
+
+// diskFile returns the name of the disk image of a machine inside its machine directory
+func diskFile(driverName string, machine string) string {
+	if driver.IsQEMU(driverName) {
+		return qemuDiskFile
+	}
+	d := &drivers.BaseDriver{MachineName: machine, StorePath: localpath.MiniPath()}
+	return filepath.Base(pkgdrivers.GetDiskPath(d))
+}
+
+// Load returns the metadata of a snapshot
+func Load(name string) (*Metadata, error) {
+	data, err := os.ReadFile(filepath.Join(localpath.Snapshot(name), metadataFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	m := &Metadata{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "parse snapshot %s", name)
+	}
+	return m, nil
+}
+
+// List returns the metadata of all snapshots, sorted by creation time
+func List() ([]*Metadata, error) {
+	entries, err := os.ReadDir(localpath.Snapshots())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ms []*Metadata
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		m, err := Load(e.Name())
+		if err != nil {
+			klog.Warningf("skipping snapshot %s: %v", e.Name(), err)
+			continue
+		}
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].CreationTime.Before(ms[j].CreationTime) })
+	return ms, nil
+}
+
+// Delete removes a snapshot and the images committed for it
+func Delete(name string) error {
+	m, err := Load(name)
+	if err != nil {
+		return err
+	}
+	cleanup(m, localpath.Snapshot(name))
+	return nil
+}
+
+// RemoveRestoredImage removes the image a deleted profile was restored from, if any
+func RemoveRestoredImage(cc *config.ClusterConfig) error {
+	if !strings.HasPrefix(cc.KicBaseImage, fmt.Sprintf("%s/%s:", restoredRepository, cc.Name)) {
+		return nil
+	}
+	return oci.RemoveImage(cc.Driver, cc.KicBaseImage)
+}
+
+func cleanup(m *Metadata, dir string) {
+	for _, img := range m.Images {
+		if err := oci.RemoveImage(m.Driver, img); err != nil {
+			klog.Warningf("failed to remove snapshot image %s: %v", img, err)
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		klog.Warningf("failed to remove snapshot directory %s: %v", dir, err)
+	}
+}
+
+func writeMetadata(dir string, m *Metadata) error {
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, metadataFile), data, 0600)
+}
+
+// copyCerts copies the certificates and keys of a profile directory
+func copyCerts(src string, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".crt" && ext != ".key") {
+			continue
+		}
+		if err := copy.Copy(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func volumeTarball(dir string, machine string) string {
+	return filepath.Join(dir, machine+"-var.tar")
+}
+
+func enabledAddons(cc *config.ClusterConfig) []string {
+	var addons []string
+	for name, enabled := range cc.Addons {
+		if enabled {
+			addons = append(addons, name)
+		}
+	}
+	sort.Strings(addons)
+	return addons
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"seeded", true},
+		{"seeded-1.30_v2", true},
+		{"Seeded", false},
+		{"-seeded", false},
+		{"", false},
+		{"seeded/1", false},
+	}
+	for _, tc := range tests {
+		if err := ValidName(tc.name); (err == nil) != tc.valid {
+			t.Errorf("ValidName(%q) = %v; want valid=%t", tc.name, err, tc.valid)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		driver string
+		nodes  int
+		valid  bool
+	}{
+		{"docker", 1, true},
+		{"docker", 2, false},
+		{"qemu2", 3, true},
+		{"kvm2", 2, true},
+		{"virtualbox", 1, false},
+		{"none", 1, false},
+	}
+	for _, tc := range tests {
+		cc := config.ClusterConfig{Driver: tc.driver, Nodes: make([]config.Node, tc.nodes)}
+		if err := Supported(cc); (err == nil) != tc.valid {
+			t.Errorf("Supported(%s, %d nodes) = %v; want valid=%t", tc.driver, tc.nodes, err, tc.valid)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestSaveRestoreQEMU(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+
+	cc := &config.ClusterConfig{
+		Name:   "seeded",
+		Driver: "qemu2",
+		KubernetesConfig: config.KubernetesConfig{
+			ClusterName:       "seeded",
+			KubernetesVersion: "v1.30.0",
+			ContainerRuntime:  "containerd",
+		},
+		Nodes:  []config.Node{{ControlPlane: true, Worker: true}},
+		Addons: map[string]bool{"storage-provisioner": true, "dashboard": false, "default-storageclass": true},
+	}
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	writeFile(t, filepath.Join(localpath.Profile("seeded"), "client.crt"), "cert")
+	writeFile(t, filepath.Join(localpath.Profile("seeded"), "client.key"), "key")
+	writeFile(t, filepath.Join(localpath.Profile("seeded"), "events.json"), "{}")
+	machineDir := localpath.MachinePath("seeded")
+	writeFile(t, filepath.Join(machineDir, qemuDiskFile), "seeded disk")
+	storePath := filepath.Join(localpath.MiniPath(), "machines", "seeded")
+	writeFile(t, filepath.Join(machineDir, "config.json"), `{"Name": "seeded", "DriverName": "qemu2", "Driver": {"MachineName": "seeded", "StorePath": "`+storePath+`", "Network": "socket_vmnet"}}`)
+
+	m, err := Save(cc, "snap")
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got := strings.Join(m.Addons, ","); got != "default-storageclass,storage-provisioner" {
+		t.Errorf("Addons = %s; want default-storageclass,storage-provisioner", got)
+	}
+	if _, err := Save(cc, "snap"); err == nil {
+		t.Errorf("Save: expected an error for an existing snapshot")
+	}
+
+	// the same profile only gets its disk replaced
+	writeFile(t, filepath.Join(machineDir, qemuDiskFile), "changed disk")
+	if _, err := Restore("snap", ""); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := readFile(t, filepath.Join(machineDir, qemuDiskFile)); got != "seeded disk" {
+		t.Errorf("restored disk = %q; want %q", got, "seeded disk")
+	}
+
+	// a new profile gets the whole machine, renamed
+	restored, err := Restore("snap", "copy")
+	if err != nil {
+		t.Fatalf("Restore into new profile: %v", err)
+	}
+	if restored.Name != "copy" || restored.KubernetesConfig.ClusterName != "copy" {
+		t.Errorf("restored names = %s/%s; want copy/copy", restored.Name, restored.KubernetesConfig.ClusterName)
+	}
+	if _, err := config.Load("copy"); err != nil {
+		t.Errorf("Load restored profile: %v", err)
+	}
+	if got := readFile(t, filepath.Join(localpath.Profile("copy"), "client.crt")); got != "cert" {
+		t.Errorf("restored cert = %q; want %q", got, "cert")
+	}
+	if _, err := os.Stat(filepath.Join(localpath.Profile("copy"), "events.json")); !os.IsNotExist(err) {
+		t.Errorf("expected only certs to be copied into the profile, stat events.json: %v", err)
+	}
+	if got := readFile(t, filepath.Join(localpath.MachinePath("copy"), qemuDiskFile)); got != "seeded disk" {
+		t.Errorf("restored disk = %q; want %q", got, "seeded disk")
+	}
+	hostConfig := readFile(t, filepath.Join(localpath.MachinePath("copy"), "config.json"))
+	if strings.Contains(hostConfig, `"seeded"`) || strings.Contains(hostConfig, filepath.Join("machines", "seeded")) {
+		t.Errorf("machine config still references the old machine: %s", hostConfig)
+	}
+
+	ms, err := List()
+	if err != nil || len(ms) != 1 || ms[0].Name != "snap" {
+		t.Errorf("List = %v, %v; want [snap]", ms, err)
+	}
+	if err := Delete("snap"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := Load("snap"); err != ErrNotFound {
+		t.Errorf("Load after Delete = %v; want ErrNotFound", err)
+	}
+}
+
+func TestRestoreVersionMismatch(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+
+	if err := os.MkdirAll(localpath.Snapshot("old"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := writeMetadata(localpath.Snapshot("old"), &Metadata{Name: "old", Profile: "minikube", Driver: "qemu2", MinikubeVersion: "v0.0.1"}); err != nil {
+		t.Fatalf("writeMetadata: %v", err)
+	}
+	if _, err := Restore("old", ""); err == nil || !strings.Contains(err.Error(), "v0.0.1") {
+		t.Errorf("Restore = %v; want a version mismatch error", err)
+	}
+}
+
+func TestRemoveRestoredImage(t *testing.T) {
+	// only the images tagged for the restored profile are removed, without running the container runtime here
+	for _, img := range []string{"gcr.io/k8s-minikube/kicbase:v0.0.44", "minikube-restored/other:other", "minikube-snapshot/snap:p1"} {
+		cc := &config.ClusterConfig{Name: "p1", Driver: "no-such-runtime", KicBaseImage: img}
+		if err := RemoveRestoredImage(cc); err != nil {
+			t.Errorf("RemoveRestoredImage(%s) = %v; want nil", img, err)
+		}
+	}
+	cc := &config.ClusterConfig{Name: "p1", Driver: "no-such-runtime", KicBaseImage: "minikube-restored/p1:p1"}
+	if err := RemoveRestoredImage(cc); err == nil {
+		t.Errorf("RemoveRestoredImage(%s) did not remove the image", cc.KicBaseImage)
+	}
+}
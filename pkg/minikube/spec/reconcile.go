@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/minikube/pkg/minikube/cni"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/util"
+)
+
+// Change is a difference between the spec and an existing cluster which is not reconciled
+type Change struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// Plan lists the changes needed to reconcile an existing cluster with the spec
+type Plan struct {
+	// AddNodes are the nodes to add, named by the caller
+	AddNodes []config.Node
+	// DeleteNodes are the names of the nodes to delete
+	DeleteNodes []string
+	// EnableAddons are the addons to enable
+	EnableAddons []string
+	// DisableAddons are the addons to disable
+	DisableAddons []string
+	// CNI is the CNI to migrate the cluster to
+	CNI string
+	// Unreconciled are the fields which can only be changed by recreating the cluster
+	Unreconciled []Change
+}
+
+// Empty returns whether the cluster already matches the spec
+func (p *Plan) Empty() bool {
+	return len(p.AddNodes) == 0 && len(p.DeleteNodes) == 0 && len(p.EnableAddons) == 0 && len(p.DisableAddons) == 0 && p.CNI == ""
+}
+
+// Plan compares the spec with an existing cluster. Nodes are matched by position,
+// addons that are not listed in the spec are left untouched.
+func (c *Cluster) Plan(cc *config.ClusterConfig) (*Plan, error) {
+	p := &Plan{}
+	s := c.Spec
+	k := cc.KubernetesConfig
+
+	p.compare("spec.driver", cc.Driver, s.Driver)
+	if s.CPUs != "" {
+		if _, err := strconv.Atoi(s.CPUs); err == nil {
+			p.compare("spec.cpus", strconv.Itoa(cc.CPUs), s.CPUs)
+		}
+	}
+	p.compareSize("spec.memory", cc.Memory, s.Memory)
+	p.compareSize("spec.diskSize", cc.DiskSize, s.DiskSize)
+	p.compare("spec.network", cc.Network, s.Network)
+	// aliases like "stable" or "latest" are resolved at start
+	if v := s.Kubernetes.Version; v != "" && v[0] >= '0' && v[0] <= '9' {
+		p.compare("spec.kubernetes.version", k.KubernetesVersion, "v"+v)
+	} else if strings.HasPrefix(v, "v") {
+		p.compare("spec.kubernetes.version", k.KubernetesVersion, v)
+	}
+	p.compare("spec.kubernetes.containerRuntime", k.ContainerRuntime, s.Kubernetes.ContainerRuntime)
+	if s.Kubernetes.CNI != "" && s.Kubernetes.CNI != k.CNI {
+		p.CNI = s.Kubernetes.CNI
+	}
+	p.compare("spec.kubernetes.featureGates", k.FeatureGates, s.Kubernetes.FeatureGates)
+	p.compare("spec.kubernetes.serviceCIDR", k.ServiceCIDR, s.Kubernetes.ServiceCIDR)
+	p.compare("spec.kubernetes.podCIDR", cni.PodCIDR(*cc), s.Kubernetes.PodCIDR)
+	p.compare("spec.kubernetes.ipFamily", cni.IPFamily(*cc), s.Kubernetes.IPFamily)
+	p.compare("spec.kubernetes.dnsDomain", k.DNSDomain, s.Kubernetes.DNSDomain)
+	p.compare("spec.kubernetes.imageRepository", k.ImageRepository, s.Kubernetes.ImageRepository)
+	if o := s.Kubernetes.OIDC; o != nil {
+		current := config.OIDCConfig{}
+		if k.OIDC != nil {
+			current = *k.OIDC
+		}
+		p.compare("spec.kubernetes.oidc.issuerURL", current.IssuerURL, o.IssuerURL)
+		p.compare("spec.kubernetes.oidc.clientID", current.ClientID, o.ClientID)
+	}
+	if s.Mount != nil {
+		p.compare("spec.mount", cc.MountString, s.Mount.HostPath+":"+s.Mount.GuestPath)
+	}
+
+	if len(s.Nodes) > 0 {
+		if err := p.planNodes(cc, s.Nodes); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range s.Addons {
+		enabled := cc.Addons[a.Name]
+		switch {
+		case a.IsEnabled() && !enabled:
+			p.EnableAddons = append(p.EnableAddons, a.Name)
+		case !a.IsEnabled() && enabled:
+			p.DisableAddons = append(p.DisableAddons, a.Name)
+		}
+	}
+	return p, nil
+}
+
+func (p *Plan) planNodes(cc *config.ClusterConfig, nodes []Node) error {
+	for i, n := range nodes {
+		if i >= len(cc.Nodes) {
+			p.AddNodes = append(p.AddNodes, config.Node{
+				ControlPlane:      n.HasRole(RoleControlPlane),
+				Worker:            n.HasRole(RoleWorker),
+				KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+				ContainerRuntime:  cc.KubernetesConfig.ContainerRuntime,
+			})
+			continue
+		}
+		desired := roles(config.Node{ControlPlane: n.HasRole(RoleControlPlane), Worker: n.HasRole(RoleWorker)})
+		p.compare(fmt.Sprintf("spec.nodes[%d].roles", i), strings.Join(roles(cc.Nodes[i]), ","), strings.Join(desired, ","))
+	}
+	for i := len(nodes); i < len(cc.Nodes); i++ {
+		p.DeleteNodes = append(p.DeleteNodes, cc.Nodes[i].Name)
+	}
+	return nil
+}
+
+func (p *Plan) compare(field string, current string, desired string) {
+	if desired != "" && desired != current {
+		p.Unreconciled = append(p.Unreconciled, Change{Field: field, Current: current, Desired: desired})
+	}
+}
+
+func (p *Plan) compareSize(field string, current int, desired string) {
+	if desired == "" {
+		return
+	}
+	mb, err := util.CalculateSizeInMB(desired)
+	if err != nil {
+		// "max" and "no-limit" are resolved at start
+		return
+	}
+	if mb != current {
+		p.Unreconciled = append(p.Unreconciled, Change{Field: field, Current: fmt.Sprintf("%dmb", current), Desired: desired})
+	}
+}
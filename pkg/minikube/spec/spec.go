@@ -0,0 +1,445 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package spec implements the declarative cluster spec file read by "minikube start --config" and "minikube apply".
+package spec
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// APIVersion is the only supported version of the spec file schema
+	APIVersion = "minikube.sigs.k8s.io/v1alpha1"
+	// Kind is the kind of the spec file
+	Kind = "Cluster"
+
+	// RoleControlPlane marks a node as control-plane
+	RoleControlPlane = "control-plane"
+	// RoleWorker marks a node as worker
+	RoleWorker = "worker"
+)
+
+// Cluster is the declarative description of a minikube cluster
+type Cluster struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   Metadata    `yaml:"metadata"`
+	Spec       ClusterSpec `yaml:"spec"`
+}
+
+// Metadata identifies the cluster
+type Metadata struct {
+	// Name is the profile name of the cluster
+	Name string `yaml:"name"`
+}
+
+// ClusterSpec describes the machines of the cluster and what runs on them
+type ClusterSpec struct {
+	Driver     string     `yaml:"driver,omitempty"`
+	CPUs       string     `yaml:"cpus,omitempty"`
+	Memory     string     `yaml:"memory,omitempty"`
+	DiskSize   string     `yaml:"diskSize,omitempty"`
+	BaseImage  string     `yaml:"baseImage,omitempty"`
+	ISOURL     string     `yaml:"isoURL,omitempty"`
+	Network    string     `yaml:"network,omitempty"`
+	Kubernetes Kubernetes `yaml:"kubernetes,omitempty"`
+	Nodes      []Node     `yaml:"nodes,omitempty"`
+	Addons     []Addon    `yaml:"addons,omitempty"`
+	Mount      *Mount     `yaml:"mount,omitempty"`
+}
+
+// Kubernetes describes the Kubernetes installation of the cluster
+type Kubernetes struct {
+	Version          string   `yaml:"version,omitempty"`
+	ContainerRuntime string   `yaml:"containerRuntime,omitempty"`
+	CNI              string   `yaml:"cni,omitempty"`
+	FeatureGates     string   `yaml:"featureGates,omitempty"`
+	ExtraConfig      []string `yaml:"extraConfig,omitempty"`
+	ServiceCIDR      string   `yaml:"serviceCIDR,omitempty"`
+	PodCIDR          string   `yaml:"podCIDR,omitempty"`
+	IPFamily         string   `yaml:"ipFamily,omitempty"`
+	DNSDomain        string   `yaml:"dnsDomain,omitempty"`
+	ImageRepository  string   `yaml:"imageRepository,omitempty"`
+	APIServerNames   []string `yaml:"apiServerNames,omitempty"`
+	OIDC             *OIDC    `yaml:"oidc,omitempty"`
+	// AuditPolicy is the audit policy file of the apiserver, or "default" for the built-in policy
+	AuditPolicy  string `yaml:"auditPolicy,omitempty"`
+	AuditWebhook string `yaml:"auditWebhook,omitempty"`
+}
+
+// OIDC describes the OpenID Connect issuer authenticating the users of the apiserver
+type OIDC struct {
+	IssuerURL      string   `yaml:"issuerURL"`
+	ClientID       string   `yaml:"clientID"`
+	ClientSecret   string   `yaml:"clientSecret,omitempty"`
+	CAFile         string   `yaml:"caFile,omitempty"`
+	UsernameClaim  string   `yaml:"usernameClaim,omitempty"`
+	UsernamePrefix string   `yaml:"usernamePrefix,omitempty"`
+	GroupsClaim    string   `yaml:"groupsClaim,omitempty"`
+	GroupsPrefix   string   `yaml:"groupsPrefix,omitempty"`
+	ExtraScopes    []string `yaml:"extraScopes,omitempty"`
+}
+
+// Node describes a node of the cluster, the first node is the primary control-plane
+type Node struct {
+	Roles []string `yaml:"roles"`
+}
+
+// Addon describes an addon and its custom images
+type Addon struct {
+	Name string `yaml:"name"`
+	// Enabled defaults to true, set it to false to make sure the addon is disabled
+	Enabled    *bool             `yaml:"enabled,omitempty"`
+	Images     map[string]string `yaml:"images,omitempty"`
+	Registries map[string]string `yaml:"registries,omitempty"`
+}
+
+// Mount describes a host directory mounted into the nodes
+type Mount struct {
+	HostPath  string `yaml:"hostPath"`
+	GuestPath string `yaml:"guestPath"`
+}
+
+// Flag is a start flag set from the spec
+type Flag struct {
+	Name  string
+	Value string
+}
+
+// IsEnabled returns whether the addon should be enabled
+func (a Addon) IsEnabled() bool {
+	return a.Enabled == nil || *a.Enabled
+}
+
+// HasRole returns whether the node has the role
+func (n Node) HasRole(role string) bool {
+	for _, r := range n.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads and parses the spec file at path
+func Load(path string) (*Cluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+	return c, nil
+}
+
+// Parse parses a spec file, unknown fields are rejected
+func Parse(data []byte) (*Cluster, error) {
+	c := &Cluster{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+	if c.APIVersion != APIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion %q, expected %q", c.APIVersion, APIVersion)
+	}
+	if c.Kind != Kind {
+		return nil, fmt.Errorf("unsupported kind %q, expected %q", c.Kind, Kind)
+	}
+	return c, nil
+}
+
+// Marshal returns the spec file contents
+func (c *Cluster) Marshal() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// Validate checks the structure of the spec, values of the individual fields are checked by the config validators
+func (c *Cluster) Validate() error {
+	if c.Metadata.Name != "" && !config.ProfileNameValid(c.Metadata.Name) {
+		return fmt.Errorf("metadata.name: profile name %q is not valid", c.Metadata.Name)
+	}
+
+	cps := 0
+	for i, n := range c.Spec.Nodes {
+		if len(n.Roles) == 0 {
+			return fmt.Errorf("spec.nodes[%d]: at least one role is required", i)
+		}
+		for _, r := range n.Roles {
+			if r != RoleControlPlane && r != RoleWorker {
+				return fmt.Errorf("spec.nodes[%d]: unknown role %q, must be %q or %q", i, r, RoleControlPlane, RoleWorker)
+			}
+		}
+		if n.HasRole(RoleControlPlane) {
+			cps++
+		}
+	}
+	if len(c.Spec.Nodes) > 0 {
+		if pcp := c.Spec.Nodes[0]; !pcp.HasRole(RoleControlPlane) || !pcp.HasRole(RoleWorker) {
+			return fmt.Errorf("spec.nodes[0]: the primary node must have the %q and %q roles", RoleControlPlane, RoleWorker)
+		}
+	}
+	if cps == 2 {
+		return fmt.Errorf("spec.nodes: a highly available cluster needs at least three control-plane nodes")
+	}
+
+	seen := map[string]bool{}
+	for i, a := range c.Spec.Addons {
+		if a.Name == "" {
+			return fmt.Errorf("spec.addons[%d]: name is required", i)
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("spec.addons[%d]: addon %q is listed more than once", i, a.Name)
+		}
+		seen[a.Name] = true
+	}
+
+	if m := c.Spec.Mount; m != nil && (m.HostPath == "" || m.GuestPath == "") {
+		return fmt.Errorf("spec.mount: hostPath and guestPath are required")
+	}
+	if o := c.Spec.Kubernetes.OIDC; o != nil && (o.IssuerURL == "" || o.ClientID == "") {
+		return fmt.Errorf("spec.kubernetes.oidc: issuerURL and clientID are required")
+	}
+	return nil
+}
+
+// Flags returns the start flags that create the cluster described by the spec
+func (c *Cluster) Flags() ([]Flag, error) {
+	var fs []Flag
+	add := func(name string, value string) {
+		if value != "" {
+			fs = append(fs, Flag{Name: name, Value: value})
+		}
+	}
+
+	s := c.Spec
+	add("driver", s.Driver)
+	add("cpus", s.CPUs)
+	add("memory", s.Memory)
+	add("disk-size", s.DiskSize)
+	add("base-image", s.BaseImage)
+	add("iso-url", s.ISOURL)
+	add("network", s.Network)
+
+	k := s.Kubernetes
+	add("kubernetes-version", k.Version)
+	add("container-runtime", k.ContainerRuntime)
+	add("cni", k.CNI)
+	add("feature-gates", k.FeatureGates)
+	add("service-cluster-ip-range", k.ServiceCIDR)
+	add("pod-network-cidr", k.PodCIDR)
+	add("ip-family", k.IPFamily)
+	add("dns-domain", k.DNSDomain)
+	add("image-repository", k.ImageRepository)
+	add("apiserver-names", strings.Join(k.APIServerNames, ","))
+	for _, eo := range k.ExtraConfig {
+		add("extra-config", eo)
+	}
+	if o := k.OIDC; o != nil {
+		add("oidc-issuer-url", o.IssuerURL)
+		add("oidc-client-id", o.ClientID)
+		add("oidc-client-secret", o.ClientSecret)
+		add("oidc-ca-file", o.CAFile)
+		add("oidc-username-claim", o.UsernameClaim)
+		add("oidc-username-prefix", o.UsernamePrefix)
+		add("oidc-groups-claim", o.GroupsClaim)
+		add("oidc-groups-prefix", o.GroupsPrefix)
+		add("oidc-extra-scope", strings.Join(o.ExtraScopes, ","))
+	}
+	add("apiserver-audit-policy", k.AuditPolicy)
+	add("apiserver-audit-webhook", k.AuditWebhook)
+
+	if len(s.Nodes) > 0 {
+		// start creates the control-plane nodes first, all nodes are also workers
+		cps := 0
+		for i, n := range s.Nodes {
+			if !n.HasRole(RoleWorker) || (n.HasRole(RoleControlPlane) && i != cps) {
+				return nil, fmt.Errorf("spec.nodes[%d]: a new cluster can only be created with control-plane nodes listed first and all nodes being workers, use \"minikube apply\" to add other nodes afterwards", i)
+			}
+			if n.HasRole(RoleControlPlane) {
+				cps++
+			}
+		}
+		if cps != 1 && cps != 3 {
+			return nil, fmt.Errorf("spec.nodes: a new cluster can only be created with one or three control-plane nodes, found %d", cps)
+		}
+		add("nodes", strconv.Itoa(len(s.Nodes)))
+		if cps == 3 {
+			add("ha", "true")
+		}
+	}
+
+	var addons []string
+	for _, a := range s.Addons {
+		if a.IsEnabled() {
+			addons = append(addons, a.Name)
+		}
+	}
+	add("addons", strings.Join(addons, ","))
+
+	if s.Mount != nil {
+		add("mount", "true")
+		add("mount-string", s.Mount.HostPath+":"+s.Mount.GuestPath)
+	}
+	return fs, nil
+}
+
+// AddonImages returns the custom images and registries of all addons in the
+// comma separated NAME=VALUE format of "minikube addons enable --images"
+func (c *Cluster) AddonImages() (images string, registries string) {
+	var is, rs []string
+	for _, a := range c.Spec.Addons {
+		is = append(is, pairs(a.Images)...)
+		rs = append(rs, pairs(a.Registries)...)
+	}
+	return strings.Join(is, ","), strings.Join(rs, ",")
+}
+
+func pairs(m map[string]string) []string {
+	var ps []string
+	for k, v := range m {
+		ps = append(ps, k+"="+v)
+	}
+	sort.Strings(ps)
+	return ps
+}
+
+// FromClusterConfig returns the spec describing an existing cluster
+func FromClusterConfig(cc *config.ClusterConfig) *Cluster {
+	k := cc.KubernetesConfig
+	c := &Cluster{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Metadata:   Metadata{Name: cc.Name},
+		Spec: ClusterSpec{
+			Driver:    cc.Driver,
+			BaseImage: cc.KicBaseImage,
+			ISOURL:    cc.MinikubeISO,
+			Network:   cc.Network,
+			Kubernetes: Kubernetes{
+				Version:          k.KubernetesVersion,
+				ContainerRuntime: k.ContainerRuntime,
+				CNI:              k.CNI,
+				FeatureGates:     k.FeatureGates,
+				ServiceCIDR:      k.ServiceCIDR,
+				PodCIDR:          k.PodCIDR,
+				IPFamily:         k.IPFamily,
+				DNSDomain:        k.DNSDomain,
+				ImageRepository:  k.ImageRepository,
+				APIServerNames:   k.APIServerNames,
+			},
+		},
+	}
+	if cc.CPUs > 0 {
+		c.Spec.CPUs = strconv.Itoa(cc.CPUs)
+	}
+	if cc.Memory > 0 {
+		c.Spec.Memory = fmt.Sprintf("%dmb", cc.Memory)
+	}
+	if cc.DiskSize > 0 {
+		c.Spec.DiskSize = fmt.Sprintf("%dmb", cc.DiskSize)
+	}
+	for _, eo := range k.ExtraOptions {
+		c.Spec.Kubernetes.ExtraConfig = append(c.Spec.Kubernetes.ExtraConfig, eo.String())
+	}
+
+	for _, n := range cc.Nodes {
+		c.Spec.Nodes = append(c.Spec.Nodes, Node{Roles: roles(n)})
+	}
+
+	var names []string
+	for name, enabled := range cc.Addons {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := Addon{Name: name}
+		// custom images are stored by image name, assign them to the addons using those images
+		if bundle, ok := assets.Addons[name]; ok {
+			a.Images = filterImages(bundle.Images, cc.CustomAddonImages)
+			a.Registries = filterImages(bundle.Images, cc.CustomAddonRegistries)
+		}
+		c.Spec.Addons = append(c.Spec.Addons, a)
+	}
+
+	// the client secret is left out of the exported spec, which may be shared
+	if o := cc.KubernetesConfig.OIDC; o != nil {
+		c.Spec.Kubernetes.OIDC = &OIDC{
+			IssuerURL:      o.IssuerURL,
+			ClientID:       o.ClientID,
+			CAFile:         o.CAFile,
+			UsernameClaim:  o.UsernameClaim,
+			UsernamePrefix: o.UsernamePrefix,
+			GroupsClaim:    o.GroupsClaim,
+			GroupsPrefix:   o.GroupsPrefix,
+			ExtraScopes:    o.ExtraScopes,
+		}
+	}
+
+	if a := cc.KubernetesConfig.Audit; a != nil {
+		c.Spec.Kubernetes.AuditPolicy = a.PolicyFile
+		if a.PolicyFile == "" {
+			c.Spec.Kubernetes.AuditPolicy = "default"
+		}
+		c.Spec.Kubernetes.AuditWebhook = a.WebhookConfigFile
+	}
+
+	if cc.Mount && cc.MountString != "" {
+		if i := strings.LastIndex(cc.MountString, ":"); i > 0 {
+			c.Spec.Mount = &Mount{HostPath: cc.MountString[:i], GuestPath: cc.MountString[i+1:]}
+		}
+	}
+	return c
+}
+
+// filterImages returns the entries of custom that are images of the addon
+func filterImages(addonImages map[string]string, custom map[string]string) map[string]string {
+	var m map[string]string
+	for name, v := range custom {
+		if _, ok := addonImages[name]; !ok {
+			continue
+		}
+		if m == nil {
+			m = map[string]string{}
+		}
+		m[name] = v
+	}
+	return m
+}
+
+func roles(n config.Node) []string {
+	var rs []string
+	if n.ControlPlane {
+		rs = append(rs, RoleControlPlane)
+	}
+	if n.Worker {
+		rs = append(rs, RoleWorker)
+	}
+	return rs
+}
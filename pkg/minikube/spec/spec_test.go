@@ -0,0 +1,326 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const testSpec = `apiVersion: minikube.sigs.k8s.io/v1alpha1
+kind: Cluster
+metadata:
+  name: dev
+spec:
+  driver: docker
+  cpus: 4
+  memory: 8g
+  kubernetes:
+    version: v1.30.0
+    containerRuntime: containerd
+    extraConfig:
+    - kubelet.max-pods=150
+    - apiserver.v=4
+    apiServerNames: [dev.local, dev.example.com]
+    oidc:
+      issuerURL: https://dex.example.com
+      clientID: minikube
+      extraScopes: [email, groups]
+  nodes:
+  - roles: [control-plane, worker]
+  - roles: [worker]
+  addons:
+  - name: dashboard
+    images:
+      Dashboard: example.com/dashboard:v1
+  - name: ingress
+    enabled: false
+  mount:
+    hostPath: /src
+    guestPath: /mnt/src
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if c.Metadata.Name != "dev" || c.Spec.CPUs != "4" || len(c.Spec.Nodes) != 2 || c.Spec.Addons[1].IsEnabled() {
+		t.Errorf("unexpected spec: %+v", c)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		description string
+		data        string
+		err         string
+	}{
+		{"unknown field", strings.Replace(testSpec, "cpus:", "cpu:", 1), "cpu"},
+		{"wrong version", strings.Replace(testSpec, "v1alpha1", "v1", 1), "unsupported apiVersion"},
+		{"wrong kind", strings.Replace(testSpec, "kind: Cluster", "kind: Node", 1), "unsupported kind"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if _, err := Parse([]byte(tc.data)); err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Parse = %v; want an error containing %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cp := Node{Roles: []string{RoleControlPlane, RoleWorker}}
+	tests := []struct {
+		description string
+		spec        ClusterSpec
+		valid       bool
+	}{
+		{"empty", ClusterSpec{}, true},
+		{"ha", ClusterSpec{Nodes: []Node{cp, cp, cp, {Roles: []string{RoleWorker}}}}, true},
+		{"worker primary", ClusterSpec{Nodes: []Node{{Roles: []string{RoleWorker}}}}, false},
+		{"unknown role", ClusterSpec{Nodes: []Node{cp, {Roles: []string{"etcd"}}}}, false},
+		{"no roles", ClusterSpec{Nodes: []Node{cp, {}}}, false},
+		{"two control-planes", ClusterSpec{Nodes: []Node{cp, cp}}, false},
+		{"duplicate addon", ClusterSpec{Addons: []Addon{{Name: "dashboard"}, {Name: "dashboard"}}}, false},
+		{"incomplete mount", ClusterSpec{Mount: &Mount{HostPath: "/src"}}, false},
+		{"oidc without client", ClusterSpec{Kubernetes: Kubernetes{OIDC: &OIDC{IssuerURL: "https://dex.example.com"}}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			c := &Cluster{Spec: tc.spec}
+			if err := c.Validate(); (err == nil) != tc.valid {
+				t.Errorf("Validate = %v; want valid=%t", err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	c, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fs, err := c.Flags()
+	if err != nil {
+		t.Fatalf("Flags: %v", err)
+	}
+	want := []Flag{
+		{"driver", "docker"},
+		{"cpus", "4"},
+		{"memory", "8g"},
+		{"kubernetes-version", "v1.30.0"},
+		{"container-runtime", "containerd"},
+		{"apiserver-names", "dev.local,dev.example.com"},
+		{"extra-config", "kubelet.max-pods=150"},
+		{"extra-config", "apiserver.v=4"},
+		{"oidc-issuer-url", "https://dex.example.com"},
+		{"oidc-client-id", "minikube"},
+		{"oidc-extra-scope", "email,groups"},
+		{"nodes", "2"},
+		{"addons", "dashboard"},
+		{"mount", "true"},
+		{"mount-string", "/src:/mnt/src"},
+	}
+	if diff := cmp.Diff(want, fs); diff != "" {
+		t.Errorf("Flags mismatch (-want +got):\n%s", diff)
+	}
+
+	images, registries := c.AddonImages()
+	if images != "Dashboard=example.com/dashboard:v1" || registries != "" {
+		t.Errorf("AddonImages = %q, %q", images, registries)
+	}
+
+	// start can only create control-plane nodes before the workers
+	w := Node{Roles: []string{RoleWorker}}
+	cp := Node{Roles: []string{RoleControlPlane, RoleWorker}}
+	c.Spec.Nodes = []Node{cp, w, cp, cp}
+	if _, err := c.Flags(); err == nil {
+		t.Errorf("Flags: expected an error for control-plane nodes after a worker")
+	}
+	c.Spec.Nodes = []Node{cp, cp, cp, w}
+	if fs, err := c.Flags(); err != nil || !containsFlag(fs, Flag{"ha", "true"}) || !containsFlag(fs, Flag{"nodes", "4"}) {
+		t.Errorf("Flags = %v, %v; want --ha and --nodes=4", fs, err)
+	}
+}
+
+func containsFlag(fs []Flag, f Flag) bool {
+	for _, ff := range fs {
+		if ff == f {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFromClusterConfig(t *testing.T) {
+	cc := &config.ClusterConfig{
+		Name:     "dev",
+		Driver:   "docker",
+		CPUs:     4,
+		Memory:   8192,
+		DiskSize: 20000,
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: "v1.30.0",
+			ContainerRuntime:  "containerd",
+			ExtraOptions:      config.ExtraOptionSlice{{Component: "kubelet", Key: "max-pods", Value: "150"}},
+			OIDC:              &config.OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "minikube", ClientSecret: "s3cr3t"},
+			Audit:             &config.AuditConfig{},
+		},
+		Nodes: []config.Node{
+			{ControlPlane: true, Worker: true},
+			{Name: "m02", Worker: true},
+		},
+		Addons:            map[string]bool{"dashboard": true, "ingress": false, "storage-provisioner": true},
+		CustomAddonImages: map[string]string{"Dashboard": "example.com/dashboard:v1"},
+		Mount:             true,
+		MountString:       "/src:/mnt/src",
+	}
+
+	c := FromClusterConfig(cc)
+	data, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse of exported spec: %v\n%s", err, data)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("Validate of exported spec: %v", err)
+	}
+	if diff := cmp.Diff(c, parsed); diff != "" {
+		t.Errorf("exported spec changed by a round trip (-want +got):\n%s", diff)
+	}
+
+	want := []Addon{
+		{Name: "dashboard", Images: map[string]string{"Dashboard": "example.com/dashboard:v1"}},
+		{Name: "storage-provisioner"},
+	}
+	if diff := cmp.Diff(want, parsed.Spec.Addons); diff != "" {
+		t.Errorf("addons mismatch (-want +got):\n%s", diff)
+	}
+	if o := parsed.Spec.Kubernetes.OIDC; o == nil || o.IssuerURL != "https://dex.example.com" || o.ClientSecret != "" {
+		t.Errorf("exported OIDC = %+v; want the issuer without the client secret", o)
+	}
+	if parsed.Spec.Kubernetes.AuditPolicy != "default" {
+		t.Errorf("exported audit policy = %q, want the built-in policy", parsed.Spec.Kubernetes.AuditPolicy)
+	}
+	if parsed.Spec.Memory != "8192mb" || parsed.Spec.Mount.GuestPath != "/mnt/src" || parsed.Spec.Kubernetes.ExtraConfig[0] != "kubelet.max-pods=150" {
+		t.Errorf("unexpected exported spec:\n%s", data)
+	}
+
+	// an exported spec describes the cluster it was exported from
+	p, err := parsed.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if !p.Empty() || len(p.Unreconciled) != 0 {
+		t.Errorf("Plan of the exported spec = %+v; want no changes", p)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	cc := &config.ClusterConfig{
+		Name:   "dev",
+		Driver: "docker",
+		Memory: 4096,
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: "v1.30.0",
+		},
+		Nodes: []config.Node{
+			{ControlPlane: true, Worker: true},
+			{Name: "m02", Worker: true},
+			{Name: "m03", Worker: true},
+		},
+		Addons: map[string]bool{"dashboard": true, "ingress": false},
+	}
+	disabled := false
+	cp := Node{Roles: []string{RoleWorker, RoleControlPlane}}
+	w := Node{Roles: []string{RoleWorker}}
+
+	c := &Cluster{Spec: ClusterSpec{
+		Driver: "docker",
+		Memory: "4g",
+		Kubernetes: Kubernetes{
+			Version: "1.31.0",
+		},
+		Nodes:  []Node{cp, w},
+		Addons: []Addon{{Name: "dashboard", Enabled: &disabled}, {Name: "ingress"}, {Name: "metrics-server", Enabled: &disabled}},
+	}}
+	p, err := c.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	want := &Plan{
+		DeleteNodes:   []string{"m03"},
+		EnableAddons:  []string{"ingress"},
+		DisableAddons: []string{"dashboard"},
+		Unreconciled:  []Change{{Field: "spec.kubernetes.version", Current: "v1.30.0", Desired: "v1.31.0"}},
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("Plan mismatch (-want +got):\n%s", diff)
+	}
+
+	c.Spec.Nodes = []Node{cp, w, w, w}
+	c.Spec.Kubernetes.Version = "stable"
+	p, err = c.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(p.AddNodes) != 1 || p.AddNodes[0].ControlPlane || !p.AddNodes[0].Worker || len(p.DeleteNodes) != 0 || len(p.Unreconciled) != 0 {
+		t.Errorf("Plan = %+v; want one worker to be added", p)
+	}
+
+	// the cluster is converted into a highly available one by adding control-plane nodes
+	c.Spec.Nodes = []Node{cp, w, w, cp, cp}
+	p, err = c.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(p.AddNodes) != 2 || !p.AddNodes[0].ControlPlane || !p.AddNodes[1].ControlPlane {
+		t.Errorf("Plan = %+v; want two control-plane nodes to be added", p)
+	}
+
+	// the CNI is migrated in place
+	c.Spec.Nodes = nil
+	c.Spec.Kubernetes.CNI = "calico"
+	p, err = c.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if p.CNI != "calico" || p.Empty() || len(p.Unreconciled) != 0 {
+		t.Errorf("Plan = %+v; want the CNI to be changed to calico", p)
+	}
+
+	// the pods keep their addresses, the IPv4 defaults matching the clusters created before they could be chosen
+	c.Spec.Kubernetes.CNI = ""
+	c.Spec.Kubernetes.PodCIDR = "10.244.0.0/16"
+	c.Spec.Kubernetes.IPFamily = "dual"
+	p, err = c.Plan(cc)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	want.Unreconciled = []Change{{Field: "spec.kubernetes.ipFamily", Current: "ipv4", Desired: "dual"}}
+	if diff := cmp.Diff(want.Unreconciled, p.Unreconciled); diff != "" {
+		t.Errorf("Plan unreconciled mismatch (-want +got):\n%s", diff)
+	}
+}
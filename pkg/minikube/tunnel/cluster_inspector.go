@@ -86,7 +86,7 @@ func getRoute(host *host.Host, clusterConfig config.ClusterConfig) (*Route, erro
 		return nil, errors.Wrapf(err, "error getting host IP for %s", host.Name)
 	}
 
-	_, ipNet, err := net.ParseCIDR(clusterConfig.KubernetesConfig.ServiceCIDR)
+	_, ipNet, err := net.ParseCIDR(util.PrimaryCIDR(clusterConfig.KubernetesConfig.ServiceCIDR))
 	if err != nil {
 		return nil, fmt.Errorf("error parsing service CIDR: %s", err)
 	}
@@ -0,0 +1,594 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	machinessh "github.com/docker/machine/libmachine/ssh"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+)
+
+const (
+	// minBackoff and maxBackoff bound the delay between two reconnection attempts
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+	// keepAliveInterval is how often the ssh connection is checked
+	keepAliveInterval = 10 * time.Second
+	// udpIdleTimeout is how long an UDP session is kept without traffic, by the relay on the node
+	udpIdleTimeout = 60 * time.Second
+	// udpBufferSize is the maximum size of a forwarded datagram, which fits in the length of a frame
+	udpBufferSize = 0xffff
+)
+
+// udpRelay forwards the datagrams of a session to the remote address given as first argument,
+// each datagram on stdin and stdout being prefixed by its length on two bytes in network order.
+// It is written in perl, which is part of the base system of the node image, and exits on the
+// end of stdin or after the number of idle seconds given as second argument.
+const udpRelay = `use IO::Socket::INET; use IO::Select;
+my ($remote, $timeout) = @ARGV;
+my $s = IO::Socket::INET->new(Proto => "udp", PeerAddr => $remote) or die "$remote: $!\n";
+binmode STDIN; binmode STDOUT;
+my $sel = IO::Select->new(\*STDIN, $s);
+my $in = "";
+while (my @ready = $sel->can_read($timeout)) {
+	for my $fh (@ready) {
+		if ($fh == $s) {
+			defined(recv($s, my $d, 65535, 0)) or next;
+			my $f = pack("n", length $d) . $d;
+			while (length $f) { my $w = syswrite(STDOUT, $f); defined $w or exit; substr($f, 0, $w) = ""; }
+			next;
+		}
+		sysread(STDIN, $in, 65536, length $in) or exit;
+		while (length $in >= 2) {
+			my $l = unpack("n", $in);
+			last if length $in < 2 + $l;
+			send($s, substr($in, 2, $l), 0);
+			substr($in, 0, 2 + $l) = "";
+		}
+	}
+}`
+
+// writeFrame writes a datagram prefixed by its length, as read by the relay on the node
+func writeFrame(w io.Writer, b []byte) error {
+	frame := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(frame, uint16(len(b)))
+	copy(frame[2:], b)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame reads a datagram written by the relay on the node into buf and returns its size
+func readFrame(r io.Reader, buf []byte) (int, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	n := int(binary.BigEndian.Uint16(header[:]))
+	if n > len(buf) {
+		return 0, errors.Errorf("datagram of %d bytes exceeds the buffer", n)
+	}
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// forward is a port forwarded from the host to the cluster
+type forward struct {
+	protocol v1.Protocol
+	local    string
+	remote   string
+}
+
+// connStats are the byte counters of a forwarded connection
+type connStats struct {
+	protocol v1.Protocol
+	local    string
+	remote   string
+	peer     string
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w     io.Writer
+	count *atomic.Int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// nativeConn forwards the ports of a service or an ingress over the in-process ssh client,
+// reconnecting with a backoff when the connection to the node is lost
+type nativeConn struct {
+	name           string
+	service        string
+	addr           string
+	config         ssh.ClientConfig
+	forwards       []forward
+	ports          []int
+	suppressStdOut bool
+
+	mu        sync.Mutex
+	client    *ssh.Client
+	listeners []io.Closer
+	open      map[*connStats]io.Closer
+	sent      int64
+	received  int64
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+func newNativeConn(name, resourceName, sshPort, sshKey string, forwards []forward) (*nativeConn, error) {
+	config, err := machinessh.NewNativeConfig("docker", &machinessh.Auth{Keys: []string{sshKey}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating ssh config with key %s", sshKey)
+	}
+	config.Timeout = 10 * time.Second
+	return &nativeConn{
+		name:     name,
+		service:  resourceName,
+		addr:     net.JoinHostPort("127.0.0.1", sshPort),
+		config:   config,
+		forwards: forwards,
+		open:     map[*connStats]io.Closer{},
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// createNativeConn forwards the ports of a resource to the same ports on the host
+func createNativeConn(name, sshPort, sshKey, bindAddress string, resourcePorts []v1.ServicePort, resourceIP, resourceName string) (*nativeConn, error) {
+	if bindAddress == "*" {
+		bindAddress = ""
+	}
+	var forwards []forward
+	for _, port := range resourcePorts {
+		forwards = append(forwards, forward{
+			protocol: port.Protocol,
+			local:    net.JoinHostPort(bindAddress, strconv.Itoa(int(port.Port))),
+			remote:   net.JoinHostPort(resourceIP, strconv.Itoa(int(port.Port))),
+		})
+	}
+	return newNativeConn(name, resourceName, sshPort, sshKey, forwards)
+}
+
+// createNativeConnWithRandomPorts forwards the ports of a service to free ports on the host loopback
+func createNativeConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service) (*nativeConn, error) {
+	var forwards []forward
+	for _, port := range svc.Spec.Ports {
+		forwards = append(forwards, forward{
+			protocol: port.Protocol,
+			local:    "127.0.0.1:0",
+			remote:   net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(port.Port))),
+		})
+	}
+	c, err := newNativeConn(name, svc.Name, sshPort, sshKey, forwards)
+	if err != nil {
+		return nil, err
+	}
+	// listen now, so that the ports are known before the tunnel is started
+	if err := c.listen(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// listen opens the local listeners of the forwards, once
+func (c *nativeConn) listen() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listeners != nil {
+		return nil
+	}
+	var listeners []io.Closer
+	var ports []int
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+	for i, f := range c.forwards {
+		var l io.Closer
+		var addr net.Addr
+		switch f.protocol {
+		case v1.ProtocolUDP:
+			pc, err := net.ListenPacket("udp", f.local)
+			if err != nil {
+				closeAll()
+				return errors.Wrapf(err, "listening on udp %s", f.local)
+			}
+			l, addr = pc, pc.LocalAddr()
+		case v1.ProtocolSCTP:
+			out.WarningT("The tunnel for {{.resource}} does not support SCTP, skipping {{.address}}", out.V{"resource": c.service, "address": f.remote})
+			continue
+		default:
+			tl, err := net.Listen("tcp", f.local)
+			if err != nil {
+				closeAll()
+				return errors.Wrapf(err, "listening on tcp %s", f.local)
+			}
+			l, addr = tl, tl.Addr()
+		}
+		listeners = append(listeners, l)
+		// with a random port, the actual address is what the connections are recorded with
+		_, port, _ := net.SplitHostPort(addr.String())
+		p, _ := strconv.Atoi(port)
+		ports = append(ports, p)
+		c.forwards[i].local = addr.String()
+	}
+	c.listeners = listeners
+	c.ports = ports
+	return nil
+}
+
+// startAndWait forwards the ports until the connection is stopped
+func (c *nativeConn) startAndWait() error {
+	if !c.suppressStdOut {
+		out.Step(style.Running, "Starting tunnel for service {{.service}}.", out.V{"service": c.service})
+	}
+	if err := c.listen(); err != nil {
+		out.WarningT("Unable to forward the ports of {{.resource}}: {{.error}}", out.V{"resource": c.service, "error": err})
+		out.Styled(style.Tip, "Ports below 1024 may require running as root, or the ssh executable with '--native-ssh=false'")
+		return err
+	}
+
+	c.mu.Lock()
+	listeners := c.listeners
+	c.mu.Unlock()
+	i := 0
+	for _, f := range c.forwards {
+		if f.protocol == v1.ProtocolSCTP {
+			continue
+		}
+		switch l := listeners[i].(type) {
+		case net.Listener:
+			go c.serveTCP(l, f)
+		case net.PacketConn:
+			go c.serveUDP(l, f)
+		}
+		i++
+	}
+
+	backoff := minBackoff
+	for {
+		client, err := ssh.Dial("tcp", c.addr, &c.config)
+		if err != nil {
+			klog.Warningf("%s tunnel: unable to connect to %s, retrying in %s: %v", c.service, c.addr, backoff, err)
+			select {
+			case <-c.done:
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+		backoff = minBackoff
+		klog.Infof("%s tunnel: connected to %s", c.service, c.addr)
+		c.setClient(client)
+
+		lost := make(chan error, 1)
+		go func() {
+			lost <- client.Wait()
+		}()
+		alive := make(chan struct{})
+		go keepAlive(client, alive)
+		select {
+		case <-c.done:
+			close(alive)
+			client.Close()
+			return nil
+		case err := <-lost:
+			klog.Warningf("%s tunnel: connection to %s lost, reconnecting: %v", c.service, c.addr, err)
+			close(alive)
+			client.Close()
+			c.setClient(nil)
+		}
+	}
+}
+
+// keepAlive closes the client once the server stops answering, until alive is closed
+func keepAlive(client *ssh.Client, alive <-chan struct{}) {
+	t := time.NewTicker(keepAliveInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-alive:
+			return
+		case <-t.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				client.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *nativeConn) setClient(client *ssh.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = client
+}
+
+func (c *nativeConn) currentClient() *ssh.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client
+}
+
+// track records an open connection, returning false if the tunnel is stopped
+func (c *nativeConn) track(s *connStats, closer io.Closer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	c.open[s] = closer
+	return true
+}
+
+// untrack adds the counters of a closed connection to the totals
+func (c *nativeConn) untrack(s *connStats) {
+	c.mu.Lock()
+	delete(c.open, s)
+	c.sent += s.sent.Load()
+	c.received += s.received.Load()
+	c.mu.Unlock()
+	klog.Infof("%s tunnel: closed %s %s -> %s, sent %d bytes, received %d bytes", c.service, s.protocol, s.peer, s.remote, s.sent.Load(), s.received.Load())
+}
+
+// stats returns the open connections and the bytes transferred by all the connections
+func (c *nativeConn) stats() ([]*connStats, int64, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sent, received := c.sent, c.received
+	var open []*connStats
+	for s := range c.open {
+		open = append(open, s)
+		sent += s.sent.Load()
+		received += s.received.Load()
+	}
+	return open, sent, received
+}
+
+func (c *nativeConn) serveTCP(l net.Listener, f forward) {
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			select {
+			case <-c.done:
+			default:
+				klog.Warningf("%s tunnel: accepting on %s: %v", c.service, f.local, err)
+			}
+			return
+		}
+		go c.forwardTCP(local, f)
+	}
+}
+
+func (c *nativeConn) forwardTCP(local net.Conn, f forward) {
+	defer local.Close()
+	client := c.currentClient()
+	if client == nil {
+		klog.Warningf("%s tunnel: not connected, dropping connection from %s", c.service, local.RemoteAddr())
+		return
+	}
+	remote, err := client.Dial("tcp", f.remote)
+	if err != nil {
+		klog.Warningf("%s tunnel: dialing %s: %v", c.service, f.remote, err)
+		return
+	}
+	defer remote.Close()
+
+	s := &connStats{protocol: v1.ProtocolTCP, local: f.local, remote: f.remote, peer: local.RemoteAddr().String()}
+	if !c.track(s, local) {
+		return
+	}
+	defer c.untrack(s)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(countingWriter{remote, &s.sent}, local)
+		// let the remote side know that no more data will be sent
+		if cw, ok := remote.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		}
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(countingWriter{local, &s.received}, remote)
+		if cw, ok := local.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		}
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+}
+
+// udpSession relays the datagrams of a peer through a relay process on the node
+type udpSession struct {
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stats   *connStats
+}
+
+func (u *udpSession) Close() error {
+	return u.session.Close()
+}
+
+func (c *nativeConn) serveUDP(pc net.PacketConn, f forward) {
+	var mu sync.Mutex
+	sessions := map[string]*udpSession{}
+	buf := make([]byte, udpBufferSize)
+	for {
+		n, peer, err := pc.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-c.done:
+			default:
+				klog.Warningf("%s tunnel: reading on %s: %v", c.service, f.local, err)
+			}
+			return
+		}
+
+		mu.Lock()
+		u, ok := sessions[peer.String()]
+		mu.Unlock()
+		if !ok {
+			u, err = c.newUDPSession(pc, peer, f)
+			if err != nil {
+				klog.Warningf("%s tunnel: relaying udp to %s: %v", c.service, f.remote, err)
+				continue
+			}
+			mu.Lock()
+			sessions[peer.String()] = u
+			mu.Unlock()
+			go func(key string) {
+				_ = u.session.Wait()
+				mu.Lock()
+				delete(sessions, key)
+				mu.Unlock()
+				c.untrack(u.stats)
+			}(peer.String())
+		}
+		if err := writeFrame(u.stdin, buf[:n]); err != nil {
+			klog.Warningf("%s tunnel: relaying udp to %s: %v", c.service, f.remote, err)
+			u.Close()
+			continue
+		}
+		u.stats.sent.Add(int64(n))
+	}
+}
+
+func (c *nativeConn) newUDPSession(pc net.PacketConn, peer net.Addr, f forward) (*udpSession, error) {
+	client := c.currentClient()
+	if client == nil {
+		return nil, errors.New("not connected")
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "new session")
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "stdin")
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "stdout")
+	}
+	if err := session.Start(fmt.Sprintf("perl -e '%s' %s %d", udpRelay, f.remote, int(udpIdleTimeout.Seconds()))); err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "starting the udp relay")
+	}
+
+	u := &udpSession{
+		session: session,
+		stdin:   stdin,
+		stats:   &connStats{protocol: v1.ProtocolUDP, local: f.local, remote: f.remote, peer: peer.String()},
+	}
+	if !c.track(u.stats, u) {
+		session.Close()
+		return nil, errors.New("tunnel stopped")
+	}
+	go func() {
+		buf := make([]byte, udpBufferSize)
+		for {
+			n, err := readFrame(stdout, buf)
+			if err != nil {
+				u.Close()
+				return
+			}
+			if _, werr := pc.WriteTo(buf[:n], peer); werr != nil {
+				klog.Warningf("%s tunnel: writing udp to %s: %v", c.service, peer, werr)
+			}
+			u.stats.received.Add(int64(n))
+		}
+	}()
+	return u, nil
+}
+
+func (c *nativeConn) stop() error {
+	c.stopOnce.Do(func() {
+		if !c.suppressStdOut {
+			out.Step(style.Stopping, "Stopping tunnel for service {{.service}}.", out.V{"service": c.service})
+		}
+		c.mu.Lock()
+		close(c.done)
+		for _, l := range c.listeners {
+			l.Close()
+		}
+		for _, conn := range c.open {
+			conn.Close()
+		}
+		if c.client != nil {
+			c.client.Close()
+		}
+		c.mu.Unlock()
+	})
+	_, sent, received := c.stats()
+	klog.Infof("%s tunnel: stopped, sent %d bytes, received %d bytes", c.service, sent, received)
+	return nil
+}
+
+// state returns the forwarded ports and the bytes transferred through them
+func (c *nativeConn) state() tunnel.Forward {
+	open, sent, received := c.stats()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := tunnel.Forward{Resource: c.service, OpenConnections: len(open), Sent: sent, Received: received}
+	for _, fw := range c.forwards {
+		protocol := fw.protocol
+		if protocol == "" {
+			protocol = v1.ProtocolTCP
+		}
+		f.Ports = append(f.Ports, fmt.Sprintf("%s->%s/%s", fw.local, fw.remote, protocol))
+	}
+	return f
+}
+
+func (c *nativeConn) localPorts() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ports
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+)
+
+// forwardingServer is an ssh server which only supports forwarding tcp connections
+type forwardingServer struct {
+	config   *ssh.ServerConfig
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func newForwardingServer(t *testing.T) *forwardingServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &forwardingServer{config: &ssh.ServerConfig{NoClientAuth: true}}
+	s.config.AddHostKey(signer)
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+	go func() {
+		for {
+			c, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, c)
+			s.mu.Unlock()
+			go s.handle(c)
+		}
+	}()
+	return s
+}
+
+func (s *forwardingServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+// disconnect drops the connections of the clients
+func (s *forwardingServer) disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *forwardingServer) handle(c net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(c, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only direct-tcpip is supported")
+			continue
+		}
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		remote, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			remote.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			_, _ = io.Copy(channel, remote)
+			channel.Close()
+			remote.Close()
+		}()
+		go func() {
+			_, _ = io.Copy(remote, channel)
+			_ = remote.(*net.TCPConn).CloseWrite()
+		}()
+	}
+}
+
+func writeKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_rsa")
+	b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func echoServer(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(c, c)
+				c.Close()
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// roundTrip sends a message through the tunnel and checks that it is echoed back
+func roundTrip(addr, msg string) error {
+	c, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if err := c.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return err
+	}
+	if _, err := c.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := c.(*net.TCPConn).CloseWrite(); err != nil {
+		return err
+	}
+	b, err := io.ReadAll(c)
+	if err != nil {
+		return err
+	}
+	if string(b) != msg {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// eventually retries the check until it succeeds or a timeout
+func eventually(t *testing.T, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestNativeConn(t *testing.T) {
+	server := newForwardingServer(t)
+	remote := echoServer(t)
+	c, err := newNativeConn("echo", "echo", server.port(), writeKey(t), []forward{{protocol: v1.ProtocolTCP, local: "127.0.0.1:0", remote: remote}})
+	if err != nil {
+		t.Fatalf("newNativeConn: %v", err)
+	}
+	c.suppressStdOut = true
+	if err := c.listen(); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ports := c.localPorts()
+	if len(ports) != 1 || ports[0] == 0 {
+		t.Fatalf("localPorts = %v; want one port", ports)
+	}
+	local := net.JoinHostPort("127.0.0.1", strconv.Itoa(ports[0]))
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- c.startAndWait()
+	}()
+
+	eventually(t, func() error { return roundTrip(local, "hello") })
+
+	// the tunnel reconnects after losing the connection to the node
+	server.disconnect()
+	eventually(t, func() error { return roundTrip(local, "world!") })
+
+	eventually(t, func() error {
+		open, sent, received := c.stats()
+		if len(open) != 0 || sent < 11 || sent != received {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+
+	if err := c.stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("startAndWait: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("startAndWait did not return after stop")
+	}
+	if _, err := net.DialTimeout("tcp", local, time.Second); err == nil {
+		t.Errorf("the tunnel still listens on %s after stop", local)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	datagrams := []string{"hello", "", "world!"}
+	var b bytes.Buffer
+	for _, d := range datagrams {
+		if err := writeFrame(&b, []byte(d)); err != nil {
+			t.Fatalf("writeFrame: %v", err)
+		}
+	}
+	var got []string
+	buf := make([]byte, udpBufferSize)
+	for {
+		n, err := readFrame(&b, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		got = append(got, string(buf[:n]))
+	}
+	if diff := cmp.Diff(datagrams, got); diff != "" {
+		t.Errorf("datagrams mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := readFrame(bytes.NewReader([]byte{0, 8, 'a'}), buf); err != io.ErrUnexpectedEOF {
+		t.Errorf("readFrame of a truncated frame = %v; want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestUDPRelay(t *testing.T) {
+	if _, err := exec.LookPath("perl"); err != nil {
+		t.Skip("perl is not available")
+	}
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, udpBufferSize)
+		for {
+			n, peer, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = pc.WriteTo(buf[:n], peer)
+		}
+	}()
+
+	cmd := exec.Command("perl", "-e", udpRelay, pc.LocalAddr().String(), "10")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stdin.Close()
+		_ = cmd.Wait()
+	}()
+
+	// the datagrams are sent back to back, and each one must be echoed on its own
+	datagrams := []string{"hello", "world!", string(bytes.Repeat([]byte("x"), 4096))}
+	var b bytes.Buffer
+	for _, d := range datagrams {
+		if err := writeFrame(&b, []byte(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := stdin.Write(b.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	buf := make([]byte, udpBufferSize)
+	for range datagrams {
+		n, err := readFrame(stdout, buf)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		got[string(buf[:n])] = true
+	}
+	for _, d := range datagrams {
+		if !got[d] {
+			t.Errorf("datagram of %d bytes was not echoed", len(d))
+		}
+	}
+}
@@ -33,8 +33,10 @@ type ServiceTunnel struct {
 	sshPort        string
 	sshKey         string
 	v1Core         typed_core.CoreV1Interface
-	sshConn        *sshConn
+	conn           tunnelConn
 	suppressStdOut bool
+	// NativeSSH forwards the ports with the in-process ssh client instead of the ssh executable
+	NativeSSH bool
 }
 
 // NewServiceTunnel ...
@@ -54,21 +56,31 @@ func (t *ServiceTunnel) Start(svcName, namespace string) ([]string, error) {
 		return nil, errors.Wrapf(err, "Service %s was not found in %q namespace. You may select another namespace by using 'minikube service %s -n <namespace>", svcName, namespace, svcName)
 	}
 
-	t.sshConn, err = createSSHConnWithRandomPorts(svcName, t.sshPort, t.sshKey, svc)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating ssh conn")
+	if t.NativeSSH || !sshInstalled() {
+		c, err := createNativeConnWithRandomPorts(svcName, t.sshPort, t.sshKey, svc)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating ssh conn")
+		}
+		c.suppressStdOut = t.suppressStdOut
+		t.conn = c
+	} else {
+		c, err := createSSHConnWithRandomPorts(svcName, t.sshPort, t.sshKey, svc)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating ssh conn")
+		}
+		c.suppressStdOut = t.suppressStdOut
+		t.conn = c
 	}
 
 	go func() {
-		t.sshConn.suppressStdOut = t.suppressStdOut
-		err = t.sshConn.startAndWait()
+		err := t.conn.startAndWait()
 		if err != nil {
 			klog.Errorf("error starting ssh tunnel: %v", err)
 		}
 	}()
 
 	urls := make([]string, 0, len(svc.Spec.Ports))
-	for _, port := range t.sshConn.ports {
+	for _, port := range t.conn.localPorts() {
 		urls = append(urls, fmt.Sprintf("http://127.0.0.1:%d", port))
 	}
 
@@ -77,7 +89,7 @@ func (t *ServiceTunnel) Start(svcName, namespace string) ([]string, error) {
 
 // Stop ...
 func (t *ServiceTunnel) Stop() {
-	err := t.sshConn.stop()
+	err := t.conn.stop()
 	if err != nil {
 		klog.Warningf("Failed to stop ssh tunnel: %v", err)
 	}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"runtime"
@@ -30,13 +31,42 @@ import (
 
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tunnel"
 )
 
+// tunnelConn forwards the ports of a service or an ingress to the host
+type tunnelConn interface {
+	startAndWait() error
+	stop() error
+	localPorts() []int
+	state() tunnel.Forward
+}
+
+// sshInstalled returns whether the ssh client is available on the host
+func sshInstalled() bool {
+	_, err := exec.LookPath("ssh")
+	return err == nil
+}
+
+// needsSudo returns whether binding one of the ports requires root permission, which the ssh client gets with sudo
+func needsSudo(ports []v1.ServicePort) bool {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return false
+	}
+	for _, p := range ports {
+		if p.Port < 1024 {
+			return true
+		}
+	}
+	return false
+}
+
 type sshConn struct {
 	name           string
 	service        string
 	cmd            *exec.Cmd
 	ports          []int
+	forwards       []string
 	activeConn     bool
 	suppressStdOut bool
 }
@@ -56,6 +86,7 @@ func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []in
 
 	askForSudo := false
 	var privilegedPorts []int32
+	var forwards []string
 	for _, port := range resourcePorts {
 		var arg string
 		if bindAddress == "" || bindAddress == "*" {
@@ -77,6 +108,8 @@ func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []in
 			)
 		}
 
+		forwards = append(forwards, fmt.Sprintf("%s->%s/TCP", net.JoinHostPort(bindAddress, fmt.Sprint(port)), net.JoinHostPort(resourceIP, fmt.Sprint(port))))
+
 		// check if any port is privileged
 		if port < 1024 {
 			privilegedPorts = append(privilegedPorts, port)
@@ -110,6 +143,7 @@ func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []in
 		name:       name,
 		service:    resourceName,
 		cmd:        cmd,
+		forwards:   forwards,
 		activeConn: false,
 	}
 }
@@ -128,6 +162,7 @@ func createSSHConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service)
 	}
 
 	usedPorts := make([]int, 0, len(svc.Spec.Ports))
+	var forwards []string
 
 	for _, port := range svc.Spec.Ports {
 		freeport, err := freeport.GetFreePort()
@@ -144,6 +179,7 @@ func createSSHConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service)
 
 		sshArgs = append(sshArgs, arg)
 		usedPorts = append(usedPorts, freeport)
+		forwards = append(forwards, fmt.Sprintf("127.0.0.1:%d->%s/TCP", freeport, net.JoinHostPort(svc.Spec.ClusterIP, fmt.Sprint(port.Port))))
 	}
 
 	cmd := exec.Command("ssh", sshArgs...)
@@ -153,6 +189,7 @@ func createSSHConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service)
 		service:    svc.Name,
 		cmd:        cmd,
 		ports:      usedPorts,
+		forwards:   forwards,
 		activeConn: false,
 	}, nil
 }
@@ -191,6 +228,15 @@ func logOutput(r io.Reader, service string) {
 	}
 }
 
+func (c *sshConn) localPorts() []int {
+	return c.ports
+}
+
+// state returns the forwarded ports, the ssh executable does not count the bytes
+func (c *sshConn) state() tunnel.Forward {
+	return tunnel.Forward{Resource: c.service, Ports: c.forwards}
+}
+
 func (c *sshConn) stop() error {
 	if c.activeConn {
 		c.activeConn = false
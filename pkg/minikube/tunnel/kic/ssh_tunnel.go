@@ -19,6 +19,7 @@ package kic
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -41,8 +42,12 @@ type SSHTunnel struct {
 	v1Core               typed_core.CoreV1Interface
 	v1Networking         typed_networking.NetworkingV1Interface
 	LoadBalancerEmulator tunnel.LoadBalancerEmulator
-	conns                map[string]*sshConn
-	connsToStop          map[string]*sshConn
+	// NativeSSH forwards the ports with the in-process ssh client instead of the ssh executable
+	NativeSSH bool
+	// Status receives the status of the tunnel, if set
+	Status      *tunnel.StatusServer
+	conns       map[string]tunnelConn
+	connsToStop map[string]tunnelConn
 }
 
 // NewSSHTunnel ...
@@ -55,8 +60,8 @@ func NewSSHTunnel(ctx context.Context, sshPort, sshKey, bindAddress string, v1Co
 		v1Core:               v1Core,
 		LoadBalancerEmulator: tunnel.NewLoadBalancerEmulator(v1Core),
 		v1Networking:         v1Networking,
-		conns:                make(map[string]*sshConn),
-		connsToStop:          make(map[string]*sshConn),
+		conns:                make(map[string]tunnelConn),
+		connsToStop:          make(map[string]tunnelConn),
 	}
 }
 
@@ -74,14 +79,17 @@ func (t *SSHTunnel) Start() error {
 		default:
 		}
 
+		status := &tunnel.Status{MinikubeState: tunnel.Running}
 		services, err := t.v1Core.Services("").List(context.Background(), metav1.ListOptions{})
 		if err != nil {
 			klog.Errorf("error listing services: %v", err)
+			status.LoadBalancerEmulatorError = err
 		}
 
 		ingresses, err := t.v1Networking.Ingresses("").List(context.Background(), metav1.ListOptions{})
 		if err != nil {
 			klog.Errorf("error listing ingresses: %v", err)
+			status.LoadBalancerEmulatorError = err
 		}
 
 		t.markConnectionsToBeStopped()
@@ -89,6 +97,7 @@ func (t *SSHTunnel) Start() error {
 		for _, svc := range services.Items {
 			if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
 				t.startConnection(svc)
+				status.PatchedServices = append(status.PatchedServices, svc.Name)
 			}
 		}
 
@@ -97,44 +106,47 @@ func (t *SSHTunnel) Start() error {
 		}
 
 		t.stopMarkedConnections()
+		t.reportStatus(status)
 
 		// TODO: which time to use?
 		time.Sleep(1 * time.Second)
 	}
 }
 
+// reportStatus sends the status of the tunnel with the state of its connections
+func (t *SSHTunnel) reportStatus(status *tunnel.Status) {
+	if t.Status == nil {
+		return
+	}
+	names := make([]string, 0, len(t.conns))
+	for name := range t.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		status.Forwards = append(status.Forwards, t.conns[name].state())
+	}
+	t.Status.Report(status)
+}
+
 func (t *SSHTunnel) markConnectionsToBeStopped() {
-	for _, conn := range t.conns {
-		t.connsToStop[conn.name] = conn
+	for name, conn := range t.conns {
+		t.connsToStop[name] = conn
 	}
 }
 
 func (t *SSHTunnel) startConnection(svc v1.Service) {
 	uniqName := sshConnUniqName(svc)
-	existingSSHConn, ok := t.conns[uniqName]
-
-	if ok {
+	if _, ok := t.conns[uniqName]; ok {
 		// if the svc still exist we remove the conn from the stopping list
-		delete(t.connsToStop, existingSSHConn.name)
+		delete(t.connsToStop, uniqName)
 		return
 	}
 
-	resourcePorts := []int32{}
-
-	for _, port := range svc.Spec.Ports {
-		resourcePorts = append(resourcePorts, port.Port)
-	}
-
 	// create new ssh conn
-	newSSHConn := createSSHConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, resourcePorts, svc.Spec.ClusterIP, svc.Name)
-	t.conns[newSSHConn.name] = newSSHConn
-
-	go func() {
-		err := newSSHConn.startAndWait()
-		if err != nil {
-			klog.Errorf("error starting ssh tunnel: %v", err)
-		}
-	}()
+	if !t.createConn(uniqName, svc.Spec.Ports, svc.Spec.ClusterIP, svc.Name) {
+		return
+	}
 
 	err := t.LoadBalancerEmulator.PatchServiceIP(t.v1Core.RESTClient(), svc, "127.0.0.1")
 	if err != nil {
@@ -144,27 +156,46 @@ func (t *SSHTunnel) startConnection(svc v1.Service) {
 
 func (t *SSHTunnel) startConnectionIngress(ingress v1_networking.Ingress) {
 	uniqName := sshConnUniqNameIngress(ingress)
-	existingSSHConn, ok := t.conns[uniqName]
-
-	if ok {
+	if _, ok := t.conns[uniqName]; ok {
 		// if the svc still exist we remove the conn from the stopping list
-		delete(t.connsToStop, existingSSHConn.name)
+		delete(t.connsToStop, uniqName)
 		return
 	}
 
-	resourcePorts := []int32{80, 443}
+	resourcePorts := []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}, {Port: 443, Protocol: v1.ProtocolTCP}}
 	resourceIP := "127.0.0.1"
 
 	// create new ssh conn
-	newSSHConn := createSSHConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, resourcePorts, resourceIP, ingress.Name)
-	t.conns[newSSHConn.name] = newSSHConn
+	t.createConn(uniqName, resourcePorts, resourceIP, ingress.Name)
+}
+
+// createConn starts forwarding the ports of a resource, with the in-process ssh client
+// if requested or if the ssh executable is not installed
+func (t *SSHTunnel) createConn(uniqName string, resourcePorts []v1.ServicePort, resourceIP, resourceName string) bool {
+	var conn tunnelConn
+	if (t.NativeSSH && !needsSudo(resourcePorts)) || !sshInstalled() {
+		nc, err := createNativeConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, resourcePorts, resourceIP, resourceName)
+		if err != nil {
+			klog.Errorf("error creating ssh tunnel: %v", err)
+			return false
+		}
+		conn = nc
+	} else {
+		var ports []int32
+		for _, port := range resourcePorts {
+			ports = append(ports, port.Port)
+		}
+		conn = createSSHConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, ports, resourceIP, resourceName)
+	}
+	t.conns[uniqName] = conn
 
 	go func() {
-		err := newSSHConn.startAndWait()
+		err := conn.startAndWait()
 		if err != nil {
 			klog.Errorf("error starting ssh tunnel: %v", err)
 		}
 	}()
+	return true
 }
 
 func (t *SSHTunnel) stopActiveConnections() {
@@ -177,13 +208,13 @@ func (t *SSHTunnel) stopActiveConnections() {
 }
 
 func (t *SSHTunnel) stopMarkedConnections() {
-	for _, sshConn := range t.connsToStop {
-		err := sshConn.stop()
+	for name, conn := range t.connsToStop {
+		err := conn.stop()
 		if err != nil {
 			klog.Errorf("error stopping ssh tunnel: %v", err)
 		}
-		delete(t.conns, sshConn.name)
-		delete(t.connsToStop, sshConn.name)
+		delete(t.conns, name)
+		delete(t.connsToStop, name)
 	}
 }
 
@@ -31,6 +31,15 @@ type reporter interface {
 	Report(tunnelState *Status)
 }
 
+// multiReporter reports the status to several reporters
+type multiReporter []reporter
+
+func (m multiReporter) Report(tunnelState *Status) {
+	for _, r := range m {
+		r.Report(tunnelState)
+	}
+}
+
 type simpleReporter struct {
 	out       io.Writer
 	lastState *Status
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+const (
+	// statusPath serves the status of the tunnel
+	statusPath = "/status"
+	// stopPath stops the tunnel
+	stopPath = "/stop"
+)
+
+// ErrAlreadyRunning is returned by ServeStatus when another tunnel answers on the status socket of the profile
+var ErrAlreadyRunning = errors.New("a tunnel is already running")
+
+// Report is the status of a running tunnel, as served on its status socket
+type Report struct {
+	Profile         string    `json:"profile"`
+	Pid             int       `json:"pid"`
+	Started         time.Time `json:"started"`
+	Route           string    `json:"route,omitempty"`
+	MinikubeState   string    `json:"minikubeState"`
+	PatchedServices []string  `json:"patchedServices"`
+	Forwards        []Forward `json:"forwards,omitempty"`
+	Errors          []string  `json:"errors,omitempty"`
+}
+
+// StatusSocketPath returns the path of the status socket of the tunnel of a profile
+func StatusSocketPath(profile string) string {
+	return filepath.Join(localpath.Profile(profile), "tunnel.sock")
+}
+
+// StatusServer serves the status of a running tunnel on a unix socket, and stops it on request
+type StatusServer struct {
+	profile  string
+	started  time.Time
+	stop     func()
+	listener net.Listener
+	server   *http.Server
+
+	mu     sync.Mutex
+	status *Status
+}
+
+// ServeStatus starts serving the status of the tunnel of a profile, stop is called when a stop is requested
+func ServeStatus(profile string, stop func()) (*StatusServer, error) {
+	path := StatusSocketPath(profile)
+	if r, err := QueryStatus(profile); err == nil {
+		return nil, errors.Wrapf(ErrAlreadyRunning, "pid %d", r.Pid)
+	}
+	// the socket of a tunnel which did not exit cleanly is left behind
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "removing %s", path)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listening on %s", path)
+	}
+	s := &StatusServer{
+		profile:  profile,
+		started:  time.Now(),
+		stop:     stop,
+		listener: l,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(statusPath, s.serveStatus)
+	mux.HandleFunc(stopPath, s.serveStop)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.server.Serve(l); err != nil && err != http.ErrServerClosed {
+			klog.Warningf("tunnel status server: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// Report records the current status of the tunnel
+func (s *StatusServer) Report(status *Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status.Clone()
+}
+
+// Close stops serving the status
+func (s *StatusServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	if rerr := os.Remove(StatusSocketPath(s.profile)); rerr != nil && !os.IsNotExist(rerr) {
+		klog.Warningf("removing tunnel status socket: %v", rerr)
+	}
+	return err
+}
+
+// report returns the status of the tunnel
+func (s *StatusServer) report() Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := Report{
+		Profile:         s.profile,
+		Pid:             getPid(),
+		Started:         s.started,
+		MinikubeState:   Unknown.String(),
+		PatchedServices: []string{},
+	}
+	if s.status == nil {
+		return r
+	}
+	if s.status.TunnelID.Route != nil {
+		r.Route = s.status.TunnelID.Route.String()
+	}
+	r.MinikubeState = s.status.MinikubeState.String()
+	if s.status.PatchedServices != nil {
+		r.PatchedServices = s.status.PatchedServices
+	}
+	r.Forwards = s.status.Forwards
+	for _, err := range []struct {
+		kind string
+		err  error
+	}{
+		{"minikube", s.status.MinikubeError},
+		{"route", s.status.RouteError},
+		{"loadbalancer emulator", s.status.LoadBalancerEmulatorError},
+	} {
+		if err.err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", err.kind, err.err))
+		}
+	}
+	return r
+}
+
+func (s *StatusServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.report()); err != nil {
+		klog.Warningf("tunnel status server: %v", err)
+	}
+}
+
+func (s *StatusServer) serveStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	klog.Infof("tunnel stop requested")
+	w.WriteHeader(http.StatusAccepted)
+	s.stop()
+}
+
+// statusClient returns an http client connecting to the status socket of a profile
+func statusClient(profile string) *http.Client {
+	path := StatusSocketPath(profile)
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// QueryStatus returns the status of the running tunnel of a profile
+func QueryStatus(profile string) (*Report, error) {
+	resp, err := statusClient(profile).Get("http://tunnel" + statusPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to the tunnel of %s", profile)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("tunnel status: %s", resp.Status)
+	}
+	var r Report
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, errors.Wrap(err, "decoding tunnel status")
+	}
+	return &r, nil
+}
+
+// RequestStop asks the running tunnel of a profile to stop
+func RequestStop(profile string) error {
+	resp, err := statusClient(profile).Post("http://tunnel"+stopPath, "", nil)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to the tunnel of %s", profile)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return errors.Errorf("tunnel stop: %s", resp.Status)
+	}
+	return nil
+}
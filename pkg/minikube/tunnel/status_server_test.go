@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestStatusServer(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	if err := os.MkdirAll(localpath.Profile("p1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := QueryStatus("p1"); err == nil {
+		t.Fatalf("QueryStatus succeeded without a running tunnel")
+	}
+
+	// the socket left behind by a tunnel which did not exit cleanly is replaced
+	if err := os.WriteFile(StatusSocketPath("p1"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan bool, 1)
+	s, err := ServeStatus("p1", func() { stopped <- true })
+	if err != nil {
+		t.Fatalf("ServeStatus: %v", err)
+	}
+
+	// a second tunnel of the profile does not take over the socket
+	if _, err := ServeStatus("p1", func() {}); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("ServeStatus with a running tunnel = %v; want %v", err, ErrAlreadyRunning)
+	}
+
+	r, err := QueryStatus("p1")
+	if err != nil {
+		t.Fatalf("QueryStatus: %v", err)
+	}
+	if r.MinikubeState != "Unknown" || r.Pid != os.Getpid() {
+		t.Errorf("status before the first report = %+v", r)
+	}
+
+	s.Report(&Status{
+		TunnelID:        ID{Route: unsafeParseRoute("1.2.3.4", "10.96.0.0/12"), MachineName: "p1"},
+		MinikubeState:   Running,
+		RouteError:      errors.New("route conflict"),
+		PatchedServices: []string{"svc1"},
+		Forwards:        []Forward{{Resource: "svc1", Ports: []string{"127.0.0.1:80->10.96.0.10:80/TCP"}, Sent: 10, Received: 20}},
+	})
+	r, err = QueryStatus("p1")
+	if err != nil {
+		t.Fatalf("QueryStatus: %v", err)
+	}
+	want := &Report{
+		Profile:         "p1",
+		Pid:             os.Getpid(),
+		Route:           "10.96.0.0/12 -> 1.2.3.4",
+		MinikubeState:   "Running",
+		PatchedServices: []string{"svc1"},
+		Forwards:        []Forward{{Resource: "svc1", Ports: []string{"127.0.0.1:80->10.96.0.10:80/TCP"}, Sent: 10, Received: 20}},
+		Errors:          []string{"route: route conflict"},
+	}
+	if diff := cmp.Diff(want, r, cmpopts.IgnoreFields(Report{}, "Started")); diff != "" {
+		t.Errorf("QueryStatus mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := RequestStop("p1"); err != nil {
+		t.Fatalf("RequestStop: %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Errorf("the stop function was not called")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, err := os.Stat(StatusSocketPath("p1")); !os.IsNotExist(err) {
+		t.Errorf("the status socket was not removed: %v", err)
+	}
+}
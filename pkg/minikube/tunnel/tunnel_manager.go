@@ -37,6 +37,7 @@ type Manager struct {
 	delay    time.Duration
 	registry *persistentRegistry
 	router   router
+	status   *StatusServer
 }
 
 // stateCheckInterval defines how frequently the cluster and route states are checked
@@ -64,9 +65,17 @@ func (mgr *Manager) StartTunnel(ctx context.Context, machineName string, machine
 	if err != nil {
 		return nil, fmt.Errorf("error creating tunnel: %s", err)
 	}
+	if mgr.status != nil {
+		tunnel.reporter = multiReporter{tunnel.reporter, mgr.status}
+	}
 	return mgr.startTunnel(ctx, tunnel)
 
 }
+// ReportTo makes the tunnels report their status to the status server
+func (mgr *Manager) ReportTo(s *StatusServer) {
+	mgr.status = s
+}
+
 func (mgr *Manager) startTunnel(ctx context.Context, tunnel controller) (done chan bool, err error) {
 	klog.Info("Setting up tunnel...")
 
@@ -152,3 +161,22 @@ func (mgr *Manager) CleanupNotRunningTunnels() error {
 	}
 	return nil
 }
+
+// RunningTunnels returns the tunnels of the registry whose process is running
+func (mgr *Manager) RunningTunnels() ([]*ID, error) {
+	tunnels, err := mgr.registry.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing tunnels from registry: %s", err)
+	}
+	var running []*ID
+	for _, tunnel := range tunnels {
+		isRunning, err := checkIfRunning(tunnel.Pid)
+		if err != nil {
+			return nil, fmt.Errorf("error checking if tunnel is running: %s", err)
+		}
+		if isRunning {
+			running = append(running, tunnel)
+		}
+	}
+	return running, nil
+}
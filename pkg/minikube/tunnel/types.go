@@ -34,6 +34,19 @@ type Status struct {
 
 	PatchedServices           []string
 	LoadBalancerEmulatorError error
+
+	// Forwards are the port forwards of the tunnels without a route
+	Forwards []Forward
+}
+
+// Forward is a service or an ingress whose ports are forwarded to the host
+type Forward struct {
+	Resource string `json:"resource"`
+	// Ports are the forwarded ports, as local->remote/protocol
+	Ports           []string `json:"ports"`
+	OpenConnections int      `json:"openConnections"`
+	Sent            int64    `json:"sent"`
+	Received        int64    `json:"received"`
 }
 
 // Clone clones an existing Status
@@ -45,6 +58,7 @@ func (t *Status) Clone() *Status {
 		RouteError:                t.RouteError,
 		PatchedServices:           t.PatchedServices,
 		LoadBalancerEmulatorError: t.LoadBalancerEmulatorError,
+		Forwards:                  t.Forwards,
 	}
 }
 
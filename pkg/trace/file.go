@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Span is a finished span as written by the file tracer, one JSON object per line
+type Span struct {
+	Name         string            `json:"name"`
+	TraceID      string            `json:"traceId"`
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	StartTime    time.Time         `json:"startTime"`
+	EndTime      time.Time         `json:"endTime"`
+	Duration     string            `json:"duration"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// fileExporter appends finished spans to a file as JSON lines
+type fileExporter struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileExporter{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// ExportSpans writes the spans to the file
+func (e *fileExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range spans {
+		js := Span{
+			Name:      s.Name(),
+			TraceID:   s.SpanContext().TraceID().String(),
+			SpanID:    s.SpanContext().SpanID().String(),
+			StartTime: s.StartTime(),
+			EndTime:   s.EndTime(),
+			Duration:  s.EndTime().Sub(s.StartTime()).String(),
+		}
+		if s.Parent().IsValid() {
+			js.ParentSpanID = s.Parent().SpanID().String()
+		}
+		for _, kv := range s.Attributes() {
+			if js.Attributes == nil {
+				js.Attributes = map[string]string{}
+			}
+			js.Attributes[string(kv.Key)] = kv.Value.Emit()
+		}
+		if err := e.enc.Encode(js); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown closes the file
+func (e *fileExporter) Shutdown(_ context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.f.Close()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/version"
+)
+
+const (
+	// this is the name of the parent span to help identify it
+	// in the tracing backend UI.
+	parentSpanName = "minikube start"
+)
+
+// otelTracer records the steps of `minikube start` as children of a single
+// parent span and sends them to an OpenTelemetry exporter
+type otelTracer struct {
+	trace.Tracer
+	parentCtx context.Context
+	parent    trace.Span
+	cleanup   func(context.Context) error
+
+	// spans may be started and ended from concurrent goroutines
+	mu     sync.Mutex
+	nextID int
+	spans  map[int]trace.Span
+}
+
+func newOtelTracer(exporter sdktrace.SpanExporter) *otelTracer {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "minikube"),
+			attribute.String("service.version", version.GetVersion()),
+		)),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	t := otel.Tracer(parentSpanName)
+
+	ctx, span := t.Start(context.Background(), parentSpanName)
+	return &otelTracer{
+		parentCtx: ctx,
+		cleanup:   tp.Shutdown,
+		Tracer:    t,
+		parent:    span,
+		spans:     map[int]trace.Span{},
+	}
+}
+
+// StartSpan starts a span for the next step of `minikube start`, and returns
+// the function ending it once the step has completed
+func (t *otelTracer) StartSpan(name string) func() {
+	_, span := t.Tracer.Start(t.parentCtx, name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.spans[id] = span
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.spans[id]; !ok {
+			klog.Warningf("span %s was already ended", name)
+			return
+		}
+		span.End()
+		delete(t.spans, id)
+	}
+}
+
+// Cleanup ends the spans which are still open, such as the ones
+// of a failed step, and flushes all spans to the exporter
+func (t *otelTracer) Cleanup() {
+	t.mu.Lock()
+	for _, span := range t.spans {
+		span.End()
+	}
+	t.parent.End()
+	t.spans = map[int]trace.Span{}
+	t.mu.Unlock()
+
+	if err := t.cleanup(context.Background()); err != nil {
+		klog.Warningf("Fail to cleanup the trace: %s", err)
+	}
+}
@@ -17,24 +17,46 @@ limitations under the License.
 package trace
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// Names of the spans around the phases of starting a node
+const (
+	Provision    = "provision"
+	Preload      = "preload"
+	KubeadmInit  = "kubeadm init"
+	CNIApply     = "cni apply"
+	AddonsEnable = "addons enable"
+	WaitForNode  = "kverify wait"
 )
 
+// File is the name of the file the file tracer writes spans to, in the profile directory
+const File = "traces.jsonl"
+
 var (
 	tracer minikubeTracer
+
+	tracers = []string{"gcp", "otlp-http", "otlp-grpc", "file"}
 )
 
 type minikubeTracer interface {
-	StartSpan(string)
-	EndSpan(string)
+	StartSpan(string) func()
 	Cleanup()
 }
 
-// Initialize initializes the global tracer variable
-func Initialize(t string) error {
-	tr, err := getTracer(t)
+// Initialize initializes the global tracer variable, the file tracer writes to the directory of profile
+func Initialize(t string, profile string) error {
+	tr, err := getTracer(t, profile)
 	if err != nil {
 		return errors.Wrap(err, "getting tracer")
 	}
@@ -42,30 +64,37 @@ func Initialize(t string) error {
 	return nil
 }
 
-func getTracer(t string) (minikubeTracer, error) {
+func getTracer(t string, profile string) (minikubeTracer, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
 	switch t {
-	case "gcp":
-		return initGCPTracer()
 	case "":
 		return nil, nil
+	case "gcp":
+		exporter, err = gcpExporter()
+	case "otlp-http":
+		// the endpoint and headers are configured with the standard OTEL_EXPORTER_OTLP_* env variables
+		exporter, err = otlptracehttp.New(context.Background())
+	case "otlp-grpc":
+		exporter, err = otlptracegrpc.New(context.Background())
+	case "file":
+		exporter, err = newFileExporter(filepath.Join(localpath.Profile(profile), File))
+	default:
+		return nil, fmt.Errorf("%s is not a valid tracer, valid tracers include: [%s]", t, strings.Join(tracers, ", "))
 	}
-	return nil, fmt.Errorf("%s is not a valid tracer, valid tracers include: [gcp]", t)
-}
-
-// StartSpan starts a span with the given name
-func StartSpan(name string) {
-	if tracer == nil {
-		return
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating %s exporter", t)
 	}
-	tracer.StartSpan(name)
+	return newOtelTracer(exporter), nil
 }
 
-// EndSpan ends a span with the given name
-func EndSpan(name string) {
+// StartSpan starts a span with the given name, and returns the function ending it.
+// Spans with the same name, such as the ones of nodes started at once, are distinct.
+func StartSpan(name string) func() {
 	if tracer == nil {
-		return
+		return func() {}
 	}
-	tracer.EndSpan(name)
+	return tracer.StartSpan(name)
 }
 
 // Cleanup is responsible for trace related cleanup,
@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// collector is a stand-in for an OpenTelemetry collector, recording the names of the exported spans
+type collector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu    sync.Mutex
+	names []string
+}
+
+func (c *collector) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				c.names = append(c.names, s.GetName())
+			}
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *collector) spanNames() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := append([]string{}, c.names...)
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// memoryExporter keeps the exported spans, even once shut down
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *memoryExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// recordSpans runs a few steps with the tracer t
+func recordSpans(t *testing.T, name string, profile string) {
+	t.Helper()
+	if err := Initialize(name, profile); err != nil {
+		t.Fatalf("Initialize(%s): %v", name, err)
+	}
+	defer func() { tracer = nil }()
+
+	StartSpan(Provision)()
+	// a step which is still running when minikube exits is ended by Cleanup
+	StartSpan(KubeadmInit)
+	Cleanup()
+}
+
+const wantSpans = "kubeadm init,minikube start,provision"
+
+func TestInitializeInvalid(t *testing.T) {
+	if err := Initialize("jaeger", "minikube"); err == nil || !strings.Contains(err.Error(), "otlp-grpc") {
+		t.Errorf("Initialize = %v; want an error listing the valid tracers", err)
+	}
+	if err := Initialize("", "minikube"); err != nil || tracer != nil {
+		t.Errorf("Initialize with no tracer = %v, %v; want no tracer", tracer, err)
+	}
+	// without a tracer all calls are no-ops
+	StartSpan(Provision)()
+	Cleanup()
+}
+
+func TestConcurrentSpans(t *testing.T) {
+	exporter := &memoryExporter{}
+	tr := newOtelTracer(exporter)
+
+	// the nodes of a cluster are provisioned at once, in spans with the same name
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		end := tr.StartSpan(Provision)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			end()
+		}()
+	}
+	wg.Wait()
+	if len(tr.spans) != 0 {
+		t.Errorf("%d spans were not ended", len(tr.spans))
+	}
+	tr.Cleanup()
+
+	var provisions int
+	for _, s := range exporter.spans {
+		if s.Name() == Provision {
+			provisions++
+		}
+	}
+	if provisions != 3 {
+		t.Errorf("exported %d %s spans; want 3", provisions, Provision)
+	}
+}
+
+func TestFileTracer(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+
+	recordSpans(t, "file", "p1")
+
+	f, err := os.Open(filepath.Join(localpath.Profile("p1"), File))
+	if err != nil {
+		t.Fatalf("open trace file: %v", err)
+	}
+	defer f.Close()
+
+	spans := map[string]Span{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Span
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		spans[s.Name] = s
+	}
+	var names []string
+	for name := range spans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if got := strings.Join(names, ","); got != wantSpans {
+		t.Fatalf("spans = %s; want %s", got, wantSpans)
+	}
+
+	root := spans[parentSpanName]
+	if root.ParentSpanID != "" {
+		t.Errorf("root span has parent %s", root.ParentSpanID)
+	}
+	for _, name := range []string{Provision, KubeadmInit} {
+		s := spans[name]
+		if s.ParentSpanID != root.SpanID || s.TraceID != root.TraceID {
+			t.Errorf("span %s is not a child of the root span: %+v", name, s)
+		}
+		if s.EndTime.Before(s.StartTime) {
+			t.Errorf("span %s ends before it starts: %+v", name, s)
+		}
+	}
+}
+
+func TestOTLPHTTPTracer(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := c.Export(r.Context(), req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+
+	recordSpans(t, "otlp-http", "minikube")
+
+	if got := c.spanNames(); got != wantSpans {
+		t.Errorf("collected spans = %s; want %s", got, wantSpans)
+	}
+}
+
+func TestOTLPGRPCTracer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	c := &collector{}
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, c)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	defer srv.Stop()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+lis.Addr().String())
+
+	recordSpans(t, "otlp-grpc", "minikube")
+
+	if got := c.spanNames(); got != wantSpans {
+		t.Errorf("collected spans = %s; want %s", got, wantSpans)
+	}
+}
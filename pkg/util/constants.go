@@ -18,6 +18,7 @@ package util
 
 import (
 	"net"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -35,9 +36,14 @@ var DefaultAdmissionControllers = []string{
 	"ResourceQuota",
 }
 
+// PrimaryCIDR returns the first of the comma separated CIDRs of a dual-stack cluster, the one of its primary IP family
+func PrimaryCIDR(cidrs string) string {
+	return strings.TrimSpace(strings.Split(cidrs, ",")[0])
+}
+
 // ServiceClusterIP returns the first IP of the ServiceCIDR
 func ServiceClusterIP(serviceCIDR string) (net.IP, error) {
-	ip, _, err := net.ParseCIDR(serviceCIDR)
+	ip, _, err := net.ParseCIDR(PrimaryCIDR(serviceCIDR))
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing default service cidr")
 	}
@@ -48,7 +54,7 @@ func ServiceClusterIP(serviceCIDR string) (net.IP, error) {
 
 // DNSIP returns x.x.x.10 of the service CIDR
 func DNSIP(serviceCIDR string) (net.IP, error) {
-	ip, _, err := net.ParseCIDR(serviceCIDR)
+	ip, _, err := net.ParseCIDR(PrimaryCIDR(serviceCIDR))
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing default service cidr")
 	}
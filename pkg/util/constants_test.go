@@ -28,6 +28,7 @@ func TestGetServiceClusterIP(t *testing.T) {
 	}{
 		{"1111.0.0.1/12", "", true},
 		{"10.96.0.0/24", "10.96.0.1", false},
+		{"10.96.0.0/12,fd00:10:96::/112", "10.96.0.1", false},
 	}
 
 	for _, tt := range testData {
@@ -18,6 +18,7 @@ package util
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -70,7 +71,7 @@ func GenerateSignedCert(certPath, keyPath, cn string, ips []net.IP, alternateDNS
 	if err != nil {
 		return errors.Wrap(err, "Error reading file: signerCertPath")
 	}
-	decodedSignerCert, _ := pem.Decode(signerCertBytes)
+	decodedSignerCert, rest := pem.Decode(signerCertBytes)
 	if decodedSignerCert == nil {
 		return errors.New("Unable to decode certificate")
 	}
@@ -82,17 +83,17 @@ func GenerateSignedCert(certPath, keyPath, cn string, ips []net.IP, alternateDNS
 	if err != nil {
 		return errors.Wrap(err, "Error reading file: signerKeyPath")
 	}
-	decodedSignerKey, _ := pem.Decode(signerKeyBytes)
-	if decodedSignerKey == nil {
-		return errors.New("Unable to decode key")
+	signerKey, err := ParsePrivateKey(signerKeyBytes)
+	if err != nil {
+		return errors.Wrap(err, "Error parsing private key: signerKeyPath")
 	}
-	signerKey, err := x509.ParsePKCS1PrivateKey(decodedSignerKey.Bytes)
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	if err != nil {
-		return errors.Wrap(err, "Error parsing private key: decodedSignerKey.Bytes")
+		return errors.Wrap(err, "Error generating serial number")
 	}
 
 	template := x509.Certificate{
-		SerialNumber: big.NewInt(2),
+		SerialNumber: serial,
 		Subject: pkix.Name{
 			CommonName:   cn,
 			Organization: []string{"system:masters"},
@@ -113,7 +114,39 @@ func GenerateSignedCert(certPath, keyPath, cn string, ips []net.IP, alternateDNS
 		return errors.Wrap(err, "Error loading or generating private key: keyPath")
 	}
 
-	return writeCertsAndKeys(&template, certPath, priv, keyPath, signerCert, signerKey)
+	// an intermediate CA is followed by its chain, which the certificate is served with
+	var chain []byte
+	if signerCert.CheckSignatureFrom(signerCert) != nil {
+		chain = append(pem.EncodeToMemory(decodedSignerCert), rest...)
+	}
+	return writeCertsAndKeys(&template, certPath, priv, keyPath, signerCert, signerKey, chain...)
+}
+
+// ParsePrivateKey parses the first private key of the PEM data, in the PKCS#1, PKCS#8 or SEC 1 format
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no private key found")
+		}
+		data = rest
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, errors.Errorf("unsupported private key type %T", key)
+			}
+			return signer, nil
+		}
+	}
 }
 
 func loadOrGeneratePrivateKey(keyPath string) (*rsa.PrivateKey, error) {
@@ -134,7 +167,7 @@ func loadOrGeneratePrivateKey(keyPath string) (*rsa.PrivateKey, error) {
 	return priv, nil
 }
 
-func writeCertsAndKeys(template *x509.Certificate, certPath string, signeeKey *rsa.PrivateKey, keyPath string, parent *x509.Certificate, signingKey *rsa.PrivateKey) error {
+func writeCertsAndKeys(template *x509.Certificate, certPath string, signeeKey *rsa.PrivateKey, keyPath string, parent *x509.Certificate, signingKey crypto.Signer, chain ...byte) error {
 	derBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &signeeKey.PublicKey, signingKey)
 	if err != nil {
 		return errors.Wrap(err, "Error creating certificate")
@@ -144,6 +177,7 @@ func writeCertsAndKeys(template *x509.Certificate, certPath string, signeeKey *r
 	if err := pem.Encode(&certBuffer, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes}); err != nil {
 		return errors.Wrap(err, "Error encoding certificate")
 	}
+	certBuffer.Write(chain)
 
 	keyBuffer := bytes.Buffer{}
 	if err := pem.Encode(&keyBuffer, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(signeeKey)}); err != nil {
@@ -17,12 +17,18 @@ limitations under the License.
 package util
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"math/big"
 	"net"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"k8s.io/minikube/pkg/minikube/constants"
 )
@@ -133,3 +139,91 @@ func TestGenerateSignedCert(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateSignedCertIntermediateCA(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	rootCertPath := filepath.Join(tmpDir, "root.crt")
+	rootKeyPath := filepath.Join(tmpDir, "root.key")
+	if err := GenerateCACert(rootCertPath, rootKeyPath, "corporateRootCA"); err != nil {
+		t.Fatalf("GenerateCACert() error = %v", err)
+	}
+	rootCertBytes, err := os.ReadFile(rootCertPath)
+	if err != nil {
+		t.Fatalf("Error reading root cert: %v", err)
+	}
+	block, _ := pem.Decode(rootCertBytes)
+	root, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("Error parsing root cert: %v", err)
+	}
+	rootKeyBytes, err := os.ReadFile(rootKeyPath)
+	if err != nil {
+		t.Fatalf("Error reading root key: %v", err)
+	}
+	rootKey, err := ParsePrivateKey(rootKeyBytes)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+
+	// the intermediate CA has an EC key in the PKCS#8 format, as issued by most corporate PKIs
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(10),
+		Subject:               pkix.Name{CommonName: "corporateIntermediateCA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, root, &key.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("Error creating intermediate cert: %v", err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error marshaling key: %v", err)
+	}
+	caCertPath := filepath.Join(tmpDir, "ca.crt")
+	caKeyPath := filepath.Join(tmpDir, "ca.key")
+	if err := os.WriteFile(caCertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatalf("Error writing intermediate cert: %v", err)
+	}
+	if err := os.WriteFile(caKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatalf("Error writing intermediate key: %v", err)
+	}
+
+	certPath := filepath.Join(tmpDir, "apiserver.crt")
+	keyPath := filepath.Join(tmpDir, "apiserver.key")
+	if err := GenerateSignedCert(certPath, keyPath, "minikube", []net.IP{net.ParseIP("10.0.0.1")}, nil, caCertPath, caKeyPath, constants.DefaultCertExpiration); err != nil {
+		t.Fatalf("GenerateSignedCert() error = %v", err)
+	}
+
+	// the certificate is followed by the intermediate CA, so that clients trusting the root can verify it
+	certBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		t.Fatalf("Error reading cert data: %v", err)
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(certBytes); block != nil; block, rest = pem.Decode(rest) {
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("Error parsing certificate: %v", err)
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("got %d certificates, want the certificate and the intermediate CA", len(certs))
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(certs[1])
+	if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+		t.Errorf("certificate does not chain to the root CA: %v", err)
+	}
+}
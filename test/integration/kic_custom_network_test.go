@@ -74,7 +74,7 @@ func TestKicExistingNetwork(t *testing.T) {
 	}
 	// create custom network
 	networkName := "existing-network"
-	if _, err := oci.CreateNetwork(oci.Docker, networkName, "", ""); err != nil {
+	if _, err := oci.CreateNetwork(oci.Docker, networkName, "", "", false); err != nil {
 		t.Fatalf("error creating network: %v", err)
 	}
 	defer func() {
@@ -45,6 +45,62 @@ const (
 	Tlast
 )
 
+// 9P2000.L message types
+const (
+	Tlerror = 6 + iota
+	Rlerror
+	Tstatfs
+	Rstatfs
+)
+
+const (
+	Tlopen = 12 + iota
+	Rlopen
+	Tlcreate
+	Rlcreate
+	Tsymlink
+	Rsymlink
+	Tmknod
+	Rmknod
+	Trename
+	Rrename
+	Treadlink
+	Rreadlink
+	Tgetattr
+	Rgetattr
+	Tsetattr
+	Rsetattr
+)
+
+const (
+	Txattrwalk = 30 + iota
+	Rxattrwalk
+	Txattrcreate
+	Rxattrcreate
+)
+
+const (
+	Treaddir = 40
+	Rreaddir = 41
+	Tfsync   = 50
+	Rfsync   = 51
+	Tlock    = 52
+	Rlock    = 53
+	Tgetlock = 54
+	Rgetlock = 55
+)
+
+const (
+	Tlink = 70 + iota
+	Rlink
+	Tmkdir
+	Rmkdir
+	Trenameat
+	Rrenameat
+	Tunlinkat
+	Runlinkat
+)
+
 const (
 	MSIZE   = 1048576 + IOHDRSZ // default message size (1048576+IOHdrSz)
 	IOHDRSZ = 24                // the non-data size of the Twrite messages
@@ -108,7 +164,9 @@ const (
 	EIO     = 5
 	EEXIST  = 17
 	ENOTDIR = 20
+	EISDIR  = 21
 	EINVAL  = 22
+	ENOTSUP = 95 // the Linux value, as 9P2000.L clients are Linux kernels
 )
 
 // Error represents a 9P2000 (and 9P2000.u) error
@@ -177,6 +235,19 @@ type Fcall struct {
 	Ext      string // special file description, 9P2000.u only (used by Tcreate)
 	Unamenum uint32 // user ID, 9P2000.u only (used by Tauth, Tattach)
 
+	/* 9P2000.L extensions */
+	Ecode   uint32 // error code (used by Rlerror)
+	Flags   uint32 // Linux open or unlink flags (used by Tlopen, Tlcreate, Tunlinkat, Tfsync)
+	Ngid    uint32 // group ID of the new file (used by Tlcreate, Tsymlink, Tmknod, Tmkdir)
+	Major   uint32 // major device number (used by Tmknod)
+	Minor   uint32 // minor device number (used by Tmknod)
+	Newname string // new name of the file (used by Trenameat)
+	Mask    uint64 // requested attributes (used by Tgetattr)
+	Attr    Lattr  // file attributes (used by Rgetattr, Tsetattr)
+	Statfs  Statfs // file system information (used by Rstatfs)
+	Flock   Flock  // POSIX lock (used by Tlock, Tgetlock, Rgetlock)
+	Status  uint8  // lock status (used by Rlock)
+
 	Pkt []uint8 // raw packet data
 	Buf []uint8 // buffer to put the raw data in
 }
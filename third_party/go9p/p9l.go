@@ -0,0 +1,409 @@
+// Copyright 2009 The Go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package go9p
+
+// Attribute bits of the request mask of Tgetattr and the valid mask of Rgetattr
+const (
+	GAMODE        = 0x00000001
+	GANLINK       = 0x00000002
+	GAUID         = 0x00000004
+	GAGID         = 0x00000008
+	GARDEV        = 0x00000010
+	GAATIME       = 0x00000020
+	GAMTIME       = 0x00000040
+	GACTIME       = 0x00000080
+	GAINO         = 0x00000100
+	GASIZE        = 0x00000200
+	GABLOCKS      = 0x00000400
+	GABASIC       = 0x000007ff // all the attributes of a Linux stat
+	GABTIME       = 0x00000800
+	GAGEN         = 0x00001000
+	GADATAVERSION = 0x00002000
+)
+
+// Attribute bits of the valid mask of Tsetattr
+const (
+	SAMODE     = 0x00000001
+	SAUID      = 0x00000002
+	SAGID      = 0x00000004
+	SASIZE     = 0x00000008
+	SAATIME    = 0x00000010 // set the access time to the current time
+	SAMTIME    = 0x00000020 // set the modification time to the current time
+	SACTIME    = 0x00000040
+	SAATIMESET = 0x00000080 // set the access time to the one of the message
+	SAMTIMESET = 0x00000100 // set the modification time to the one of the message
+)
+
+// Linux open flags, used by Tlopen and Tlcreate
+const (
+	LORDONLY = 00000000
+	LOWRONLY = 00000001
+	LORDWR   = 00000002
+	LOCREAT  = 00000100
+	LOEXCL   = 00000200
+	LOTRUNC  = 00001000
+	LOAPPEND = 00002000
+)
+
+// Linux file types of the mode of Lattr
+const (
+	LSIFMT   = 0170000
+	LSIFSOCK = 0140000
+	LSIFLNK  = 0120000
+	LSIFREG  = 0100000
+	LSIFBLK  = 0060000
+	LSIFDIR  = 0040000
+	LSIFCHR  = 0020000
+	LSIFIFO  = 0010000
+	LSISUID  = 0004000
+	LSISGID  = 0002000
+	LSISVTX  = 0001000
+)
+
+// LATREMOVEDIR is the flag of Tunlinkat removing a directory
+const LATREMOVEDIR = 0x200
+
+// Lock types and statuses, used by Tlock, Rlock, Tgetlock and Rgetlock
+const (
+	LOCKRDLCK = 0
+	LOCKWRLCK = 1
+	LOCKUNLCK = 2
+
+	LOCKSUCCESS = 0
+	LOCKBLOCKED = 1
+	LOCKERROR   = 2
+	LOCKGRACE   = 3
+)
+
+// Lattr describes a file, as a Linux stat does (9P2000.L)
+type Lattr struct {
+	Valid       uint64 // mask of the valid attributes (GA* or SA* bits)
+	Qid         Qid    // file's Qid
+	Mode        uint32 // file type and permissions (LSIF* bits)
+	Uid         uint32 // owner ID
+	Gid         uint32 // group ID
+	Nlink       uint64 // number of hard links
+	Rdev        uint64 // device ID of special files
+	Size        uint64 // file length in bytes
+	Blksize     uint64 // block size for the I/O
+	Blocks      uint64 // number of 512 bytes blocks allocated
+	AtimeSec    uint64 // last access time
+	AtimeNsec   uint64
+	MtimeSec    uint64 // last modification time
+	MtimeNsec   uint64
+	CtimeSec    uint64 // last status change time
+	CtimeNsec   uint64
+	BtimeSec    uint64 // creation time, reserved
+	BtimeNsec   uint64
+	Gen         uint64 // reserved
+	DataVersion uint64 // reserved
+}
+
+// Statfs describes a file system (9P2000.L)
+type Statfs struct {
+	Type    uint32 // type of the file system
+	Bsize   uint32 // block size
+	Blocks  uint64 // total number of blocks
+	Bfree   uint64 // number of free blocks
+	Bavail  uint64 // number of blocks available to unprivileged users
+	Files   uint64 // total number of files
+	Ffree   uint64 // number of free files
+	Fsid    uint64 // file system ID
+	Namelen uint32 // maximum length of file names
+}
+
+// Flock describes a POSIX record lock (9P2000.L)
+type Flock struct {
+	Type     uint8  // LOCK* type
+	Flags    uint32 // lock flags (used by Tlock)
+	Start    uint64 // first byte of the lock
+	Length   uint64 // number of bytes locked, 0 up to the end of the file
+	ProcID   uint32 // process holding the lock
+	ClientID string // client holding the lock
+}
+
+// Dirent is a directory entry returned by Rreaddir (9P2000.L)
+type Dirent struct {
+	Qid           // file's Qid
+	Offset uint64 // offset of the next entry
+	Type   uint8  // file type (the LSIF* bits of the mode shifted by 12)
+	Name   string // file name
+}
+
+// direntsz returns the on-the-wire size of a directory entry
+func direntsz(name string) int {
+	return 13 + 8 + 1 + 2 + len(name) /* qid[13] offset[8] type[1] name[s] */
+}
+
+// PackDirent writes the directory entry to the buffer. Returns the number of
+// bytes written, 0 if there is not enough space.
+func PackDirent(d *Dirent, buf []byte) int {
+	sz := direntsz(d.Name)
+	if len(buf) < sz {
+		return 0
+	}
+
+	p := pqid(&d.Qid, buf)
+	p = pint64(d.Offset, p)
+	p = pint8(d.Type, p)
+	pstr(d.Name, p)
+	return sz
+}
+
+// UnpackDirents converts the data of a Rreaddir message to directory entries.
+func UnpackDirents(buf []byte) ([]Dirent, error) {
+	var ds []Dirent
+
+	r := &lreader{p: buf}
+	for len(r.p) > 0 {
+		var d Dirent
+		r.qid(&d.Qid)
+		d.Offset = r.int64()
+		d.Type = r.int8()
+		d.Name = r.str()
+		if r.short {
+			return nil, &Error{"invalid directory entry", EINVAL}
+		}
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+// lreader reads the fields of 9P2000.L messages, recording when the buffer is too short
+// instead of relying on a minimum size per message type
+type lreader struct {
+	p     []byte
+	short bool
+}
+
+func (r *lreader) has(n int) bool {
+	if r.short || len(r.p) < n {
+		r.short = true
+		return false
+	}
+	return true
+}
+
+func (r *lreader) int8() (v uint8) {
+	if r.has(1) {
+		v, r.p = gint8(r.p)
+	}
+	return v
+}
+
+func (r *lreader) int32() (v uint32) {
+	if r.has(4) {
+		v, r.p = gint32(r.p)
+	}
+	return v
+}
+
+func (r *lreader) int64() (v uint64) {
+	if r.has(8) {
+		v, r.p = gint64(r.p)
+	}
+	return v
+}
+
+func (r *lreader) str() string {
+	if !r.has(2) {
+		return ""
+	}
+	s, p := gstr(r.p)
+	if p == nil {
+		r.short = true
+		return ""
+	}
+	r.p = p
+	return s
+}
+
+func (r *lreader) qid(qid *Qid) {
+	if r.has(13) {
+		r.p = gqid(r.p, qid)
+	}
+}
+
+func (r *lreader) flock(l *Flock, flags bool) {
+	l.Type = r.int8()
+	if flags {
+		l.Flags = r.int32()
+	}
+	l.Start = r.int64()
+	l.Length = r.int64()
+	l.ProcID = r.int32()
+	l.ClientID = r.str()
+}
+
+// unpackDotl reads the body of a 9P2000.L message in the Fcall
+func unpackDotl(fc *Fcall, p []byte) error {
+	r := &lreader{p: p}
+	a := &fc.Attr
+	switch fc.Type {
+	default:
+		return &Error{"invalid message id", EINVAL}
+
+	case Rlerror:
+		fc.Ecode = r.int32()
+
+	case Tstatfs, Treadlink:
+		fc.Fid = r.int32()
+
+	case Rstatfs:
+		s := &fc.Statfs
+		s.Type = r.int32()
+		s.Bsize = r.int32()
+		s.Blocks = r.int64()
+		s.Bfree = r.int64()
+		s.Bavail = r.int64()
+		s.Files = r.int64()
+		s.Ffree = r.int64()
+		s.Fsid = r.int64()
+		s.Namelen = r.int32()
+
+	case Tlopen:
+		fc.Fid = r.int32()
+		fc.Flags = r.int32()
+
+	case Rlopen, Rlcreate:
+		r.qid(&fc.Qid)
+		fc.Iounit = r.int32()
+
+	case Tlcreate:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Flags = r.int32()
+		fc.Perm = r.int32()
+		fc.Ngid = r.int32()
+
+	case Tsymlink:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Ext = r.str()
+		fc.Ngid = r.int32()
+
+	case Rsymlink, Rmknod, Rmkdir:
+		r.qid(&fc.Qid)
+
+	case Tmknod:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Perm = r.int32()
+		fc.Major = r.int32()
+		fc.Minor = r.int32()
+		fc.Ngid = r.int32()
+
+	case Trename:
+		fc.Fid = r.int32()
+		fc.Newfid = r.int32()
+		fc.Name = r.str()
+
+	case Rreadlink:
+		fc.Ext = r.str()
+
+	case Tgetattr:
+		fc.Fid = r.int32()
+		fc.Mask = r.int64()
+
+	case Rgetattr:
+		a.Valid = r.int64()
+		r.qid(&a.Qid)
+		a.Mode = r.int32()
+		a.Uid = r.int32()
+		a.Gid = r.int32()
+		for _, v := range []*uint64{&a.Nlink, &a.Rdev, &a.Size, &a.Blksize, &a.Blocks,
+			&a.AtimeSec, &a.AtimeNsec, &a.MtimeSec, &a.MtimeNsec, &a.CtimeSec, &a.CtimeNsec,
+			&a.BtimeSec, &a.BtimeNsec, &a.Gen, &a.DataVersion} {
+			*v = r.int64()
+		}
+
+	case Tsetattr:
+		fc.Fid = r.int32()
+		a.Valid = uint64(r.int32())
+		a.Mode = r.int32()
+		a.Uid = r.int32()
+		a.Gid = r.int32()
+		a.Size = r.int64()
+		a.AtimeSec = r.int64()
+		a.AtimeNsec = r.int64()
+		a.MtimeSec = r.int64()
+		a.MtimeNsec = r.int64()
+
+	case Txattrwalk:
+		fc.Fid = r.int32()
+		fc.Newfid = r.int32()
+		fc.Name = r.str()
+
+	case Rxattrwalk:
+		fc.Offset = r.int64()
+
+	case Txattrcreate:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Offset = r.int64()
+		fc.Flags = r.int32()
+
+	case Treaddir:
+		fc.Fid = r.int32()
+		fc.Offset = r.int64()
+		fc.Count = r.int32()
+
+	case Rreaddir:
+		fc.Count = r.int32()
+		if r.has(int(fc.Count)) {
+			fc.Data = r.p[:fc.Count]
+			r.p = r.p[fc.Count:]
+		}
+
+	case Tfsync:
+		fc.Fid = r.int32()
+		fc.Flags = r.int32()
+
+	case Tlock:
+		fc.Fid = r.int32()
+		r.flock(&fc.Flock, true)
+
+	case Rlock:
+		fc.Status = r.int8()
+
+	case Tgetlock:
+		fc.Fid = r.int32()
+		r.flock(&fc.Flock, false)
+
+	case Rgetlock:
+		r.flock(&fc.Flock, false)
+
+	case Tlink:
+		fc.Fid = r.int32()
+		fc.Newfid = r.int32()
+		fc.Name = r.str()
+
+	case Tmkdir:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Perm = r.int32()
+		fc.Ngid = r.int32()
+
+	case Trenameat:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Newfid = r.int32()
+		fc.Newname = r.str()
+
+	case Tunlinkat:
+		fc.Fid = r.int32()
+		fc.Name = r.str()
+		fc.Flags = r.int32()
+
+	case Rrename, Rsetattr, Rxattrcreate, Rfsync, Rlink, Rrenameat, Runlinkat:
+	}
+
+	if r.short || len(r.p) > 0 {
+		return &Error{"invalid size", EINVAL}
+	}
+
+	return nil
+}
@@ -0,0 +1,375 @@
+// Copyright 2009 The Go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package go9p
+
+// Create a Tlopen message in the specified Fcall.
+func PackTlopen(fc *Fcall, fid uint32, flags uint32) error {
+	p, err := packCommon(fc, 4+4, Tlopen) /* fid[4] flags[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Flags = flags
+	p = pint32(fid, p)
+	p = pint32(flags, p)
+	return nil
+}
+
+// Create a Tlcreate message in the specified Fcall.
+func PackTlcreate(fc *Fcall, fid uint32, name string, flags uint32, mode uint32, gid uint32) error {
+	size := 4 + 2 + len(name) + 4 + 4 + 4 /* fid[4] name[s] flags[4] mode[4] gid[4] */
+	p, err := packCommon(fc, size, Tlcreate)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Name = name
+	fc.Flags = flags
+	fc.Perm = mode
+	fc.Ngid = gid
+	p = pint32(fid, p)
+	p = pstr(name, p)
+	p = pint32(flags, p)
+	p = pint32(mode, p)
+	p = pint32(gid, p)
+	return nil
+}
+
+// Create a Tsymlink message in the specified Fcall.
+func PackTsymlink(fc *Fcall, dfid uint32, name string, target string, gid uint32) error {
+	size := 4 + 2 + len(name) + 2 + len(target) + 4 /* fid[4] name[s] symtgt[s] gid[4] */
+	p, err := packCommon(fc, size, Tsymlink)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = dfid
+	fc.Name = name
+	fc.Ext = target
+	fc.Ngid = gid
+	p = pint32(dfid, p)
+	p = pstr(name, p)
+	p = pstr(target, p)
+	p = pint32(gid, p)
+	return nil
+}
+
+// Create a Treadlink message in the specified Fcall.
+func PackTreadlink(fc *Fcall, fid uint32) error {
+	p, err := packCommon(fc, 4, Treadlink) /* fid[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	p = pint32(fid, p)
+	return nil
+}
+
+// Create a Tstatfs message in the specified Fcall.
+func PackTstatfs(fc *Fcall, fid uint32) error {
+	p, err := packCommon(fc, 4, Tstatfs) /* fid[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	p = pint32(fid, p)
+	return nil
+}
+
+// Create a Tgetattr message in the specified Fcall.
+func PackTgetattr(fc *Fcall, fid uint32, mask uint64) error {
+	p, err := packCommon(fc, 4+8, Tgetattr) /* fid[4] request_mask[8] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Mask = mask
+	p = pint32(fid, p)
+	p = pint64(mask, p)
+	return nil
+}
+
+// Create a Tsetattr message in the specified Fcall. Only the fields of
+// the attributes sent by Tsetattr are used, the valid mask holds SA* bits.
+func PackTsetattr(fc *Fcall, fid uint32, a *Lattr) error {
+	size := 4 + 4 + 4 + 4 + 4 + 8 + 8 + 8 + 8 + 8 /* fid[4] valid[4] mode[4] uid[4] gid[4] size[8] atime[16] mtime[16] */
+	p, err := packCommon(fc, size, Tsetattr)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Attr = *a
+	p = pint32(fid, p)
+	p = pint32(uint32(a.Valid), p)
+	p = pint32(a.Mode, p)
+	p = pint32(a.Uid, p)
+	p = pint32(a.Gid, p)
+	p = pint64(a.Size, p)
+	p = pint64(a.AtimeSec, p)
+	p = pint64(a.AtimeNsec, p)
+	p = pint64(a.MtimeSec, p)
+	p = pint64(a.MtimeNsec, p)
+	return nil
+}
+
+// Create a Treaddir message in the specified Fcall.
+func PackTreaddir(fc *Fcall, fid uint32, offset uint64, count uint32) error {
+	p, err := packCommon(fc, 4+8+4, Treaddir) /* fid[4] offset[8] count[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Offset = offset
+	fc.Count = count
+	p = pint32(fid, p)
+	p = pint64(offset, p)
+	p = pint32(count, p)
+	return nil
+}
+
+// Create a Tfsync message in the specified Fcall.
+func PackTfsync(fc *Fcall, fid uint32, datasync uint32) error {
+	p, err := packCommon(fc, 4+4, Tfsync) /* fid[4] datasync[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = fid
+	fc.Flags = datasync
+	p = pint32(fid, p)
+	p = pint32(datasync, p)
+	return nil
+}
+
+// Create a Tmkdir message in the specified Fcall.
+func PackTmkdir(fc *Fcall, dfid uint32, name string, mode uint32, gid uint32) error {
+	size := 4 + 2 + len(name) + 4 + 4 /* dfid[4] name[s] mode[4] gid[4] */
+	p, err := packCommon(fc, size, Tmkdir)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = dfid
+	fc.Name = name
+	fc.Perm = mode
+	fc.Ngid = gid
+	p = pint32(dfid, p)
+	p = pstr(name, p)
+	p = pint32(mode, p)
+	p = pint32(gid, p)
+	return nil
+}
+
+// Create a Trenameat message in the specified Fcall.
+func PackTrenameat(fc *Fcall, olddfid uint32, oldname string, newdfid uint32, newname string) error {
+	size := 4 + 2 + len(oldname) + 4 + 2 + len(newname) /* olddirfid[4] oldname[s] newdirfid[4] newname[s] */
+	p, err := packCommon(fc, size, Trenameat)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = olddfid
+	fc.Name = oldname
+	fc.Newfid = newdfid
+	fc.Newname = newname
+	p = pint32(olddfid, p)
+	p = pstr(oldname, p)
+	p = pint32(newdfid, p)
+	p = pstr(newname, p)
+	return nil
+}
+
+// Create a Tunlinkat message in the specified Fcall.
+func PackTunlinkat(fc *Fcall, dfid uint32, name string, flags uint32) error {
+	size := 4 + 2 + len(name) + 4 /* dirfd[4] name[s] flags[4] */
+	p, err := packCommon(fc, size, Tunlinkat)
+	if err != nil {
+		return err
+	}
+
+	fc.Fid = dfid
+	fc.Name = name
+	fc.Flags = flags
+	p = pint32(dfid, p)
+	p = pstr(name, p)
+	p = pint32(flags, p)
+	return nil
+}
+
+// Create a Rlerror message in the specified Fcall.
+func PackRlerror(fc *Fcall, ecode uint32) error {
+	p, err := packCommon(fc, 4, Rlerror) /* ecode[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Ecode = ecode
+	p = pint32(ecode, p)
+	return nil
+}
+
+// Create a Rstatfs message in the specified Fcall.
+func PackRstatfs(fc *Fcall, s *Statfs) error {
+	size := 4 + 4 + 8*6 + 4 /* type[4] bsize[4] blocks[8] bfree[8] bavail[8] files[8] ffree[8] fsid[8] namelen[4] */
+	p, err := packCommon(fc, size, Rstatfs)
+	if err != nil {
+		return err
+	}
+
+	fc.Statfs = *s
+	p = pint32(s.Type, p)
+	p = pint32(s.Bsize, p)
+	p = pint64(s.Blocks, p)
+	p = pint64(s.Bfree, p)
+	p = pint64(s.Bavail, p)
+	p = pint64(s.Files, p)
+	p = pint64(s.Ffree, p)
+	p = pint64(s.Fsid, p)
+	p = pint32(s.Namelen, p)
+	return nil
+}
+
+// packRqid creates a message only holding a qid in the specified Fcall.
+func packRqid(fc *Fcall, qid *Qid, id uint8) error {
+	p, err := packCommon(fc, 13, id) /* qid[13] */
+	if err != nil {
+		return err
+	}
+
+	fc.Qid = *qid
+	p = pqid(qid, p)
+	return nil
+}
+
+// Create a Rlopen message in the specified Fcall.
+func PackRlopen(fc *Fcall, qid *Qid, iounit uint32) error {
+	p, err := packCommon(fc, 13+4, Rlopen) /* qid[13] iounit[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Qid = *qid
+	fc.Iounit = iounit
+	p = pqid(qid, p)
+	p = pint32(iounit, p)
+	return nil
+}
+
+// Create a Rlcreate message in the specified Fcall.
+func PackRlcreate(fc *Fcall, qid *Qid, iounit uint32) error {
+	p, err := packCommon(fc, 13+4, Rlcreate) /* qid[13] iounit[4] */
+	if err != nil {
+		return err
+	}
+
+	fc.Qid = *qid
+	fc.Iounit = iounit
+	p = pqid(qid, p)
+	p = pint32(iounit, p)
+	return nil
+}
+
+// Create a Rsymlink message in the specified Fcall.
+func PackRsymlink(fc *Fcall, qid *Qid) error { return packRqid(fc, qid, Rsymlink) }
+
+// Create a Rmknod message in the specified Fcall.
+func PackRmknod(fc *Fcall, qid *Qid) error { return packRqid(fc, qid, Rmknod) }
+
+// Create a Rmkdir message in the specified Fcall.
+func PackRmkdir(fc *Fcall, qid *Qid) error { return packRqid(fc, qid, Rmkdir) }
+
+// Create a Rreadlink message in the specified Fcall.
+func PackRreadlink(fc *Fcall, target string) error {
+	p, err := packCommon(fc, 2+len(target), Rreadlink) /* target[s] */
+	if err != nil {
+		return err
+	}
+
+	fc.Ext = target
+	p = pstr(target, p)
+	return nil
+}
+
+// Create a Rgetattr message in the specified Fcall.
+func PackRgetattr(fc *Fcall, a *Lattr) error {
+	size := 8 + 13 + 4 + 4 + 4 + 8*15 /* valid[8] qid[13] mode[4] uid[4] gid[4] nlink..data_version[8*15] */
+	p, err := packCommon(fc, size, Rgetattr)
+	if err != nil {
+		return err
+	}
+
+	fc.Attr = *a
+	p = pint64(a.Valid, p)
+	p = pqid(&a.Qid, p)
+	p = pint32(a.Mode, p)
+	p = pint32(a.Uid, p)
+	p = pint32(a.Gid, p)
+	for _, v := range []uint64{a.Nlink, a.Rdev, a.Size, a.Blksize, a.Blocks,
+		a.AtimeSec, a.AtimeNsec, a.MtimeSec, a.MtimeNsec, a.CtimeSec, a.CtimeNsec,
+		a.BtimeSec, a.BtimeNsec, a.Gen, a.DataVersion} {
+		p = pint64(v, p)
+	}
+	return nil
+}
+
+// Initializes the specified Fcall value to contain Rreaddir message.
+// The user should write the entries with PackDirent to the slice pointed
+// by fc.Data and call SetRreaddirCount to update the data size to the
+// actual value.
+func InitRreaddir(fc *Fcall, count uint32) error {
+	if err := InitRread(fc, count); err != nil {
+		return err
+	}
+
+	fc.Type = Rreaddir
+	pint8(Rreaddir, fc.Pkt[4:])
+	return nil
+}
+
+// Updates the size of the data returned by Rreaddir. Expects that
+// the Fcall value is already initialized by InitRreaddir.
+func SetRreaddirCount(fc *Fcall, count uint32) { SetRreadCount(fc, count) }
+
+// Create a Rlock message in the specified Fcall.
+func PackRlock(fc *Fcall, status uint8) error {
+	p, err := packCommon(fc, 1, Rlock) /* status[1] */
+	if err != nil {
+		return err
+	}
+
+	fc.Status = status
+	p = pint8(status, p)
+	return nil
+}
+
+// Create a Rgetlock message in the specified Fcall.
+func PackRgetlock(fc *Fcall, l *Flock) error {
+	size := 1 + 8 + 8 + 4 + 2 + len(l.ClientID) /* type[1] start[8] length[8] proc_id[4] client_id[s] */
+	p, err := packCommon(fc, size, Rgetlock)
+	if err != nil {
+		return err
+	}
+
+	fc.Flock = *l
+	p = pint8(l.Type, p)
+	p = pint64(l.Start, p)
+	p = pint64(l.Length, p)
+	p = pint32(l.ProcID, p)
+	p = pstr(l.ClientID, p)
+	return nil
+}
+
+// Create a message without a body, such as Rsetattr, in the specified Fcall.
+func PackRempty(fc *Fcall, id uint8) error {
+	_, err := packCommon(fc, 0, id)
+	return err
+}
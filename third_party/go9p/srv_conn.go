@@ -5,6 +5,7 @@
 package go9p
 
 import (
+	"bufio"
 	"fmt"
 	"log"
 	"net"
@@ -20,6 +21,7 @@ func (srv *Srv) NewConn(c net.Conn) {
 	conn.fidpool = make(map[uint32]*SrvFid)
 	conn.reqs = make(map[uint16]*SrvReq)
 	conn.reqout = make(chan *SrvReq, srv.Maxpend)
+	conn.work = make(chan *SrvReq, srv.Workers)
 	conn.done = make(chan bool)
 	conn.rchan = make(chan *Fcall, 64)
 
@@ -39,12 +41,24 @@ func (srv *Srv) NewConn(c net.Conn) {
 		sop.statsRegister()
 	}
 
+	for i := 0; i < srv.Workers; i++ {
+		go conn.worker()
+	}
 	go conn.recv()
 	go conn.send()
 }
 
+// worker processes the requests of the connection, the number of workers
+// bounding how many requests are processed at the same time
+func (conn *Conn) worker() {
+	for req := range conn.work {
+		req.process()
+	}
+}
+
 func (conn *Conn) close() {
 	conn.done <- true
+	close(conn.work)
 	conn.Srv.Lock()
 	delete(conn.Srv.conns, conn)
 	conn.Srv.Unlock()
@@ -154,10 +168,13 @@ func (conn *Conn) recv() {
 				// connection, so we block on it. Otherwise,
 				// we may loop back to reading and that is a race.
 				// This fix brought to you by the race detector.
-				if req.Tc.Type == Tversion {
+				// Tflush is not queued behind the requests it
+				// flushes, which may be waiting for a worker.
+				switch req.Tc.Type {
+				case Tversion, Tflush:
 					req.process()
-				} else {
-					go req.process()
+				default:
+					conn.work <- req
 				}
 			}
 
@@ -169,6 +186,8 @@ func (conn *Conn) recv() {
 }
 
 func (conn *Conn) send() {
+	// the responses are written together while more of them are pending
+	w := bufio.NewWriterSize(conn.conn, int(conn.Msize))
 	for {
 		select {
 		case <-conn.done:
@@ -191,16 +210,15 @@ func (conn *Conn) send() {
 				}
 			}
 
-			for buf := req.Rc.Pkt; len(buf) > 0; {
-				n, err := conn.conn.Write(buf)
-				if err != nil {
-					/* just close the socket, will get signal on conn.done */
-					log.Println("error while writing")
-					conn.conn.Close()
-					break
-				}
-
-				buf = buf[n:]
+			_, err := w.Write(req.Rc.Pkt)
+			if err == nil && len(conn.reqout) == 0 {
+				err = w.Flush()
+			}
+			if err != nil {
+				/* just close the socket, will get signal on conn.done */
+				log.Println("error while writing")
+				conn.conn.Close()
+				w.Reset(conn.conn)
 			}
 
 			select {
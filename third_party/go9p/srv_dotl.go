@@ -0,0 +1,234 @@
+// Copyright 2009 The Go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package go9p
+
+var Enotsup error = &Error{"operation not supported", ENOTSUP}
+
+// 9P2000.L request operations. The file server should implement them,
+// in addition to the SrvReqOps, to speak 9P2000.L. Modern Linux clients
+// ask for 9P2000.L and use these messages instead of Topen, Tcreate,
+// Tstat, Twstat and the reads of directories.
+type SrvReqOpsDotl interface {
+	Lopen(*SrvReq)
+	Lcreate(*SrvReq)
+	Getattr(*SrvReq)
+	Setattr(*SrvReq)
+	Readdir(*SrvReq)
+	Mkdir(*SrvReq)
+	Symlink(*SrvReq)
+	Mknod(*SrvReq)
+	Readlink(*SrvReq)
+	Link(*SrvReq)
+	Rename(*SrvReq)
+	Renameat(*SrvReq)
+	Unlinkat(*SrvReq)
+	Statfs(*SrvReq)
+	Fsync(*SrvReq)
+	Flock(*SrvReq)
+	Getlock(*SrvReq)
+}
+
+func (srv *Srv) dotl(req *SrvReq) {
+	conn := req.Conn
+	tc := req.Tc
+	fid := req.Fid
+	ops, ok := (srv.ops).(SrvReqOpsDotl)
+	if !ok || !conn.Dotl {
+		req.RespondError(&Error{"unknown message type", EINVAL})
+		return
+	}
+
+	switch tc.Type {
+	default:
+		req.RespondError(&Error{"unknown message type", EINVAL})
+
+	case Tlopen:
+		if fid.opened {
+			req.RespondError(Eopen)
+			return
+		}
+
+		fid.Omode = uint8(tc.Flags & 3)
+		ops.Lopen(req)
+
+	case Tlcreate:
+		if fid.opened {
+			req.RespondError(Eopen)
+			return
+		}
+
+		if (fid.Type & QTDIR) == 0 {
+			req.RespondError(Enotdir)
+			return
+		}
+
+		fid.Omode = uint8(tc.Flags & 3)
+		ops.Lcreate(req)
+
+	case Tgetattr:
+		ops.Getattr(req)
+
+	case Tsetattr:
+		ops.Setattr(req)
+
+	case Treaddir:
+		if !fid.opened || (fid.Type&QTDIR) == 0 {
+			req.RespondError(Ebaduse)
+			return
+		}
+
+		if tc.Count+IOHDRSZ > conn.Msize {
+			req.RespondError(Etoolarge)
+			return
+		}
+
+		ops.Readdir(req)
+
+	case Tmkdir, Tsymlink, Tmknod, Tunlinkat:
+		if (fid.Type & QTDIR) == 0 {
+			req.RespondError(Enotdir)
+			return
+		}
+
+		switch tc.Type {
+		case Tmkdir:
+			ops.Mkdir(req)
+		case Tsymlink:
+			ops.Symlink(req)
+		case Tmknod:
+			ops.Mknod(req)
+		case Tunlinkat:
+			ops.Unlinkat(req)
+		}
+
+	case Treadlink:
+		ops.Readlink(req)
+
+	case Tlink, Trename, Trenameat:
+		/* the second fid of the message is kept in Newfid */
+		req.Newfid = conn.FidGet(tc.Newfid)
+		if req.Newfid == nil {
+			req.RespondError(Eunknownfid)
+			return
+		}
+
+		switch tc.Type {
+		case Tlink:
+			if (fid.Type & QTDIR) == 0 {
+				req.RespondError(Enotdir)
+				return
+			}
+
+			ops.Link(req)
+		case Trename:
+			if (req.Newfid.Type & QTDIR) == 0 {
+				req.RespondError(Enotdir)
+				return
+			}
+
+			ops.Rename(req)
+		case Trenameat:
+			if (fid.Type&QTDIR) == 0 || (req.Newfid.Type&QTDIR) == 0 {
+				req.RespondError(Enotdir)
+				return
+			}
+
+			ops.Renameat(req)
+		}
+
+	case Tstatfs:
+		ops.Statfs(req)
+
+	case Tfsync:
+		ops.Fsync(req)
+
+	case Tlock:
+		ops.Flock(req)
+
+	case Tgetlock:
+		ops.Getlock(req)
+
+	case Txattrwalk, Txattrcreate:
+		/* extended attributes are not supported */
+		req.RespondError(Enotsup)
+	}
+}
+
+func (srv *Srv) lopenPost(req *SrvReq) {
+	if req.Fid != nil {
+		req.Fid.opened = req.Rc != nil && req.Rc.Type == Rlopen
+	}
+}
+
+func (srv *Srv) lcreatePost(req *SrvReq) {
+	if req.Rc != nil && req.Rc.Type == Rlcreate && req.Fid != nil {
+		req.Fid.Type = req.Rc.Qid.Type
+		req.Fid.opened = true
+	}
+}
+
+// Respond to the request with Rlopen message
+func (req *SrvReq) RespondRlopen(qid *Qid, iounit uint32) {
+	req.respondPacked(PackRlopen(req.Rc, qid, iounit))
+}
+
+// Respond to the request with Rlcreate message
+func (req *SrvReq) RespondRlcreate(qid *Qid, iounit uint32) {
+	req.respondPacked(PackRlcreate(req.Rc, qid, iounit))
+}
+
+// Respond to the request with Rgetattr message
+func (req *SrvReq) RespondRgetattr(a *Lattr) {
+	req.respondPacked(PackRgetattr(req.Rc, a))
+}
+
+// Respond to the request with Rmkdir message
+func (req *SrvReq) RespondRmkdir(qid *Qid) {
+	req.respondPacked(PackRmkdir(req.Rc, qid))
+}
+
+// Respond to the request with Rsymlink message
+func (req *SrvReq) RespondRsymlink(qid *Qid) {
+	req.respondPacked(PackRsymlink(req.Rc, qid))
+}
+
+// Respond to the request with Rmknod message
+func (req *SrvReq) RespondRmknod(qid *Qid) {
+	req.respondPacked(PackRmknod(req.Rc, qid))
+}
+
+// Respond to the request with Rreadlink message
+func (req *SrvReq) RespondRreadlink(target string) {
+	req.respondPacked(PackRreadlink(req.Rc, target))
+}
+
+// Respond to the request with Rstatfs message
+func (req *SrvReq) RespondRstatfs(s *Statfs) {
+	req.respondPacked(PackRstatfs(req.Rc, s))
+}
+
+// Respond to the request with Rlock message
+func (req *SrvReq) RespondRlock(status uint8) {
+	req.respondPacked(PackRlock(req.Rc, status))
+}
+
+// Respond to the request with Rgetlock message
+func (req *SrvReq) RespondRgetlock(l *Flock) {
+	req.respondPacked(PackRgetlock(req.Rc, l))
+}
+
+// Respond to the request with the message without a body answering it,
+// such as Rsetattr for Tsetattr
+func (req *SrvReq) RespondRempty() {
+	req.respondPacked(PackRempty(req.Rc, req.Tc.Type+1))
+}
+
+func (req *SrvReq) respondPacked(err error) {
+	if err != nil {
+		req.RespondError(err)
+	} else {
+		req.Respond()
+	}
+}
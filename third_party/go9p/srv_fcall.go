@@ -19,9 +19,15 @@ func (srv *Srv) version(req *SrvReq) {
 		conn.Msize = tc.Msize
 	}
 
-	conn.Dotu = tc.Version == "9P2000.u" && srv.Dotu
+	_, dotl := (srv.ops).(SrvReqOpsDotl)
+	conn.Dotl = tc.Version == "9P2000.L" && srv.Dotl && dotl
+	/* 9P2000.L attaches with the numeric user ID of 9P2000.u */
+	conn.Dotu = (tc.Version == "9P2000.u" && srv.Dotu) || conn.Dotl
 	ver := "9P2000"
-	if conn.Dotu {
+	switch {
+	case conn.Dotl:
+		ver = "9P2000.L"
+	case conn.Dotu:
 		ver = "9P2000.u"
 	}
 
@@ -23,6 +23,18 @@ type SrvReqOps interface {
 
 // Respond to the request with Rerror message
 func (req *SrvReq) RespondError(err interface{}) {
+	if req.Conn.Dotl {
+		/* 9P2000.L only sends the error number */
+		ecode := uint32(EIO)
+		if e, ok := err.(*Error); ok && e.Errornum != 0 {
+			ecode = e.Errornum
+		}
+
+		PackRlerror(req.Rc, ecode)
+		req.Respond()
+		return
+	}
+
 	switch e := err.(type) {
 	case *Error:
 		PackRerror(req.Rc, e.Error(), uint32(e.Errornum), req.Conn.Dotu)
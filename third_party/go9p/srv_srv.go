@@ -119,9 +119,11 @@ type Srv struct {
 	Id         string // Used for debugging and stats
 	Msize      uint32 // Maximum size of the 9P2000 messages supported by the server
 	Dotu       bool   // If true, the server supports the 9P2000.u extension
+	Dotl       bool   // If true, the server supports the 9P2000.L extension (the ops must implement SrvReqOpsDotl)
 	Debuglevel int    // debug level
 	Upool      Users  // Interface for finding users and groups known to the file server
 	Maxpend    int    // Maximum pending outgoing requests
+	Workers    int    // Number of goroutines processing the requests of each connection
 	Log        *Logger
 
 	ops   interface{}     // operations
@@ -134,6 +136,7 @@ type Conn struct {
 	Srv        *Srv
 	Msize      uint32 // maximum size of 9P2000 messages for the connection
 	Dotu       bool   // if true, both the client and the server speak 9P2000.u
+	Dotl       bool   // if true, both the client and the server speak 9P2000.L
 	Id         string // used for debugging and stats
 	Debuglevel int
 
@@ -142,6 +145,7 @@ type Conn struct {
 	reqs    map[uint16]*SrvReq // all outstanding requests
 
 	reqout chan *SrvReq
+	work   chan *SrvReq // requests waiting for a worker
 	rchan  chan *Fcall
 	done   chan bool
 
@@ -213,6 +217,14 @@ func (srv *Srv) Start(ops interface{}) bool {
 		srv.Msize = MSIZE
 	}
 
+	if srv.Workers <= 0 {
+		srv.Workers = 4 * runtime.NumCPU()
+	}
+
+	if srv.Maxpend <= 0 {
+		srv.Maxpend = srv.Workers
+	}
+
 	if srv.Log == nil {
 		srv.Log = NewLogger(1024)
 	}
@@ -238,6 +250,7 @@ func (req *SrvReq) process() {
 
 	if flushed {
 		req.Respond()
+		return
 	}
 
 	if rop, ok := (req.Conn.Srv.ops).(SrvReqProcessOps); ok {
@@ -265,9 +278,7 @@ func (req *SrvReq) Process() {
 	tc := req.Tc
 
 	if tc.Fid != NOFID && tc.Type != Tattach {
-		srv.Lock()
 		req.Fid = conn.FidGet(tc.Fid)
-		srv.Unlock()
 		if req.Fid == nil {
 			req.RespondError(Eunknownfid)
 			return
@@ -276,6 +287,11 @@ func (req *SrvReq) Process() {
 
 	switch req.Tc.Type {
 	default:
+		if tc.Type < Tversion {
+			srv.dotl(req)
+			return
+		}
+
 		req.RespondError(&Error{"unknown message type", EINVAL})
 
 	case Tversion:
@@ -354,6 +370,12 @@ func (req *SrvReq) PostProcess() {
 
 	case Tremove:
 		srv.removePost(req)
+
+	case Tlopen:
+		srv.lopenPost(req)
+
+	case Tlcreate:
+		srv.lcreatePost(req)
 	}
 
 	if req.Fid != nil {
@@ -5,6 +5,7 @@
 package go9p
 
 import (
+	"errors"
 	"io"
 	"log"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"path"
 	"sort"
 	"strconv"
+	"sync"
 	"syscall"
 )
 
@@ -23,19 +25,27 @@ type ufsFid struct {
 	dirents    []byte
 	diroffset  uint64
 	st         os.FileInfo
+	cache      *statCache // nil if the attributes are not cached
 }
 
 type Ufs struct {
 	Srv
-	Root string
+	Root      string
+	CacheSize int // Number of cached file attributes and directory entries, DefaultCacheSize if 0, none if negative
+
+	cacheOnce sync.Once
+	cache     *statCache
 }
 
+// toError converts the error of an os function, with the number the
+// Linux clients know the error by.
 func toError(err error) *Error {
 	var ecode uint32
+	var e syscall.Errno
 
 	ename := err.Error()
-	if e, ok := err.(syscall.Errno); ok {
-		ecode = uint32(e)
+	if errors.As(err, &e) {
+		ecode = linuxErrno(e)
 	} else {
 		ecode = EIO
 	}
@@ -43,17 +53,89 @@ func toError(err error) *Error {
 	return &Error{ename, ecode}
 }
 
-func (fid *ufsFid) stat() *Error {
+// statCache returns the cache of the file attributes, nil if it is disabled
+func (ufs *Ufs) statCache() *statCache {
+	ufs.cacheOnce.Do(func() {
+		size := ufs.CacheSize
+		if size < 0 {
+			return
+		}
+
+		if size == 0 {
+			size = DefaultCacheSize
+		}
+
+		c, err := newStatCache(size)
+		if err != nil {
+			log.Printf("ufs: stat cache disabled: %v", err)
+			return
+		}
+
+		ufs.cache = c
+	})
+
+	return ufs.cache
+}
+
+// lstat returns the attributes of the file of the fid, cached if possible
+func (fid *ufsFid) lstat(p string) (os.FileInfo, *Error) {
+	var st os.FileInfo
 	var err error
 
-	fid.st, err = os.Lstat(fid.path)
+	if fid.cache != nil {
+		st, err = fid.cache.lstat(p)
+	} else {
+		st, err = os.Lstat(p)
+	}
+
 	if err != nil {
-		return toError(err)
+		return nil, toError(err)
 	}
 
+	return st, nil
+}
+
+func (fid *ufsFid) stat() *Error {
+	st, err := fid.lstat(fid.path)
+	if err != nil {
+		return err
+	}
+
+	fid.st = st
 	return nil
 }
 
+// readdir returns the attributes of the files of the directory of the fid
+func (fid *ufsFid) readdir() ([]os.FileInfo, error) {
+	if fid.cache != nil {
+		return fid.cache.readdir(fid.path)
+	}
+
+	f, err := os.Open(fid.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+// forget drops the cached attributes of the files changed by the server,
+// before it responds
+func (fid *ufsFid) forget(paths ...string) {
+	if fid.cache != nil {
+		fid.cache.invalidate(paths...)
+	}
+}
+
+// forgetTree drops the cached attributes of the files removed or renamed by
+// the server, and of the files below them
+func (fid *ufsFid) forgetTree(paths ...string) {
+	if fid.cache != nil {
+		fid.cache.invalidateTree(paths...)
+	}
+}
+
 func omode2uflags(mode uint8) int {
 	ret := int(0)
 	switch mode & 3 {
@@ -169,6 +251,7 @@ func (ufs *Ufs) Attach(req *SrvReq) {
 
 	tc := req.Tc
 	fid := new(ufsFid)
+	fid.cache = ufs.statCache()
 	// You can think of the ufs.Root as a 'chroot' of a sort.
 	// clients attach are not allowed to go outside the
 	// directory represented by ufs.Root
@@ -198,7 +281,7 @@ func (*Ufs) Walk(req *SrvReq) {
 	}
 
 	if req.Newfid.Aux == nil {
-		req.Newfid.Aux = new(ufsFid)
+		req.Newfid.Aux = &ufsFid{cache: fid.cache}
 	}
 
 	nfid := req.Newfid.Aux.(*ufsFid)
@@ -207,7 +290,7 @@ func (*Ufs) Walk(req *SrvReq) {
 	i := 0
 	for ; i < len(tc.Wname); i++ {
 		p := path + "/" + tc.Wname[i]
-		st, err := os.Lstat(p)
+		st, err := fid.lstat(p)
 		if err != nil {
 			if i == 0 {
 				req.RespondError(Enoent)
@@ -305,6 +388,7 @@ func (*Ufs) Create(req *SrvReq) {
 		return
 	}
 
+	fid.forget(path)
 	fid.path = path
 	fid.file = file
 	err = fid.stat()
@@ -320,27 +404,15 @@ func (*Ufs) Read(req *SrvReq) {
 	fid := req.Fid.Aux.(*ufsFid)
 	tc := req.Tc
 	rc := req.Rc
-	err := fid.stat()
-	if err != nil {
-		req.RespondError(err)
-		return
-	}
 
+	/* the data is read directly in the response */
 	InitRread(rc, tc.Count)
 	var count int
 	var e error
-	if fid.st.IsDir() {
+	if (req.Fid.Type & QTDIR) != 0 {
 		if tc.Offset == 0 {
 			var e error
-			// If we got here, it was open. Can't really seek
-			// in most cases, just close and reopen it.
-			fid.file.Close()
-			if fid.file, e = os.OpenFile(fid.path, omode2uflags(req.Fid.Omode), 0); e != nil {
-				req.RespondError(toError(e))
-				return
-			}
-
-			if fid.dirs, e = fid.file.Readdir(-1); e != nil {
+			if fid.dirs, e = fid.readdir(); e != nil {
 				req.RespondError(toError(e))
 				return
 			}
@@ -404,13 +476,8 @@ func (*Ufs) Read(req *SrvReq) {
 func (*Ufs) Write(req *SrvReq) {
 	fid := req.Fid.Aux.(*ufsFid)
 	tc := req.Tc
-	err := fid.stat()
-	if err != nil {
-		req.RespondError(err)
-		return
-	}
-
 	n, e := fid.file.WriteAt(tc.Data, int64(tc.Offset))
+	fid.forget(fid.path)
 	if e != nil {
 		req.RespondError(toError(e))
 		return
@@ -430,6 +497,7 @@ func (*Ufs) Remove(req *SrvReq) {
 	}
 
 	e := os.Remove(fid.path)
+	fid.forgetTree(fid.path)
 	if e != nil {
 		req.RespondError(toError(e))
 		return
@@ -14,6 +14,7 @@ import (
 func StartServer(addrVal string, debugVal int, rootVal string) {
 	ufs := new(go9p.Ufs)
 	ufs.Dotu = true
+	ufs.Dotl = true
 	ufs.Id = "ufs"
 	ufs.Root = rootVal
 	ufs.Debuglevel = debugVal
@@ -0,0 +1,60 @@
+// Copyright 2009 The go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd
+
+package go9p
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxCacheWatches bounds the directories watched by the stat cache,
+// kqueue holding a descriptor for every file of a watched directory
+const maxCacheWatches = 256
+
+// bsdErrnos are the errors numbered differently by Linux, the lower
+// numbers are the same
+var bsdErrnos = map[syscall.Errno]uint32{
+	syscall.EDEADLK:      35,
+	syscall.EAGAIN:       11,
+	syscall.ENAMETOOLONG: 36,
+	syscall.ENOLCK:       37,
+	syscall.ENOSYS:       38,
+	syscall.ENOTEMPTY:    39,
+	syscall.ELOOP:        40,
+	syscall.EOVERFLOW:    75,
+	syscall.EILSEQ:       84,
+	syscall.EOPNOTSUPP:   ENOTSUP,
+	syscall.ETIMEDOUT:    110,
+	syscall.ECONNREFUSED: 111,
+	syscall.EDQUOT:       122,
+}
+
+// linuxErrno returns the number of the error for a Linux client
+func linuxErrno(e syscall.Errno) uint32 {
+	if n, ok := bsdErrnos[e]; ok {
+		return n
+	}
+
+	if e <= syscall.ERANGE {
+		return uint32(e)
+	}
+
+	return EIO
+}
+
+// sysLattr sets the attributes of Rgetattr which are not portable
+func sysLattr(d os.FileInfo, a *Lattr) {
+	stat := d.Sys().(*syscall.Stat_t)
+	a.Uid = stat.Uid
+	a.Gid = stat.Gid
+	a.Nlink = uint64(stat.Nlink)
+	a.Rdev = uint64(stat.Rdev)
+	a.Blksize = uint64(stat.Blksize)
+	a.Blocks = uint64(stat.Blocks)
+	a.AtimeSec, a.AtimeNsec = uint64(stat.Atimespec.Sec), uint64(stat.Atimespec.Nsec)
+	a.CtimeSec, a.CtimeNsec = uint64(stat.Ctimespec.Sec), uint64(stat.Ctimespec.Nsec)
+}
@@ -0,0 +1,322 @@
+// Copyright 2009 The go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package go9p
+
+import (
+	"container/list"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultCacheSize is the default number of file attributes and directory
+// entries kept by the stat cache of ufs.
+const DefaultCacheSize = 65536
+
+// statCache keeps the attributes and the listings of the recently used files
+// and directories, so that walking to a file or listing a directory does not
+// stat it again. An entry only stays cached while the directory it depends on
+// is watched: it is dropped once fsnotify reports a change of the file, or when
+// the server changes the file itself.
+type statCache struct {
+	sync.Mutex
+	max     int
+	size    int // weight of the entries, a listing weighs the number of its files
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+	watcher *fsnotify.Watcher
+	watches map[string]*dirWatch
+}
+
+type cacheKey struct {
+	path    string
+	listing bool
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	st    os.FileInfo
+	files []os.FileInfo
+}
+
+// dirWatch is a watched directory. Its generation changes with every change
+// reported in it, so that a stat racing with a change is not cached.
+type dirWatch struct {
+	gen uint64
+}
+
+func newStatCache(max int) (*statCache, error) {
+	c := &statCache{
+		max:     max,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element),
+		watches: make(map[string]*dirWatch),
+	}
+	if err := c.reset(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// reset drops all the entries and watches, with a new watcher. Should be
+// called with the cache locked, or before it is used.
+func (c *statCache) reset() error {
+	if c.watcher != nil {
+		/* its events are discarded, it may be blocked sending one of them */
+		go c.watcher.Close()
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.watcher = nil
+		return err
+	}
+
+	c.watcher = w
+	c.lru.Init()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.watches = make(map[string]*dirWatch)
+	c.size = 0
+	go c.watch(w)
+	return nil
+}
+
+// watch invalidates the entries changed according to the watcher, until it is closed
+func (c *statCache) watch(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+
+			c.Lock()
+			if c.watcher == w {
+				if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+					c.forgetTree(filepath.Clean(ev.Name))
+				} else {
+					c.forget(filepath.Clean(ev.Name))
+				}
+			}
+			c.Unlock()
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+
+			/* the changes may have been lost, nothing can be trusted */
+			log.Printf("ufs: stat cache watcher: %v", err)
+			c.Lock()
+			if c.watcher == w {
+				c.restart()
+			}
+			c.Unlock()
+		}
+	}
+}
+
+// restart resets the cache, disabling it if no watcher can be created.
+// Should be called with the cache locked.
+func (c *statCache) restart() {
+	if err := c.reset(); err != nil {
+		log.Printf("ufs: stat cache disabled: %v", err)
+	}
+}
+
+// lstat returns the attributes of the file, as os.Lstat does
+func (c *statCache) lstat(p string) (os.FileInfo, error) {
+	p = filepath.Clean(p)
+	k := cacheKey{p, false}
+	c.Lock()
+	if e, ok := c.entries[k]; ok {
+		c.lru.MoveToFront(e)
+		st := e.Value.(*cacheEntry).st
+		c.Unlock()
+		return st, nil
+	}
+
+	dir := filepath.Dir(p)
+	w, gen := c.acquire(dir)
+	c.Unlock()
+
+	st, err := os.Lstat(p)
+	if err == nil && w != nil {
+		c.Lock()
+		c.store(&cacheEntry{key: k, st: st}, dir, w, gen)
+		c.Unlock()
+	}
+
+	return st, err
+}
+
+// readdir returns the attributes of the files of the directory, as
+// os.File.Readdir does. The attributes of the files are cached too.
+func (c *statCache) readdir(p string) ([]os.FileInfo, error) {
+	p = filepath.Clean(p)
+	k := cacheKey{p, true}
+	c.Lock()
+	if e, ok := c.entries[k]; ok {
+		c.lru.MoveToFront(e)
+		files := e.Value.(*cacheEntry).files
+		c.Unlock()
+		return files, nil
+	}
+
+	w, gen := c.acquire(p)
+	c.Unlock()
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if w != nil {
+		c.Lock()
+		if c.store(&cacheEntry{key: k, files: files}, p, w, gen) {
+			for _, st := range files {
+				c.store(&cacheEntry{key: cacheKey{filepath.Join(p, st.Name()), false}, st: st}, p, w, gen)
+			}
+		}
+		c.Unlock()
+	}
+
+	return files, nil
+}
+
+// acquire watches the directory, returning its watch and generation, or a nil
+// watch if it can not be watched. Should be called with the cache locked.
+func (c *statCache) acquire(dir string) (*dirWatch, uint64) {
+	if c.watcher == nil {
+		return nil, 0
+	}
+
+	if w, ok := c.watches[dir]; ok {
+		return w, w.gen
+	}
+
+	/* bound the watches too, the oldest ones are not tracked */
+	if len(c.watches) >= maxCacheWatches {
+		c.restart()
+		if c.watcher == nil {
+			return nil, 0
+		}
+	}
+
+	if err := c.watcher.Add(dir); err != nil {
+		return nil, 0
+	}
+
+	w := new(dirWatch)
+	c.watches[dir] = w
+	return w, 0
+}
+
+// store caches the entry, unless its directory changed since the generation
+// was read. Should be called with the cache locked.
+func (c *statCache) store(ce *cacheEntry, dir string, w *dirWatch, gen uint64) bool {
+	if c.watches[dir] != w || w.gen != gen {
+		return false
+	}
+
+	if e, ok := c.entries[ce.key]; ok {
+		c.remove(e)
+	}
+
+	c.entries[ce.key] = c.lru.PushFront(ce)
+	c.size += ce.weight()
+	for c.size > c.max && c.lru.Len() > 1 {
+		c.remove(c.lru.Back())
+	}
+
+	return true
+}
+
+func (ce *cacheEntry) weight() int {
+	return 1 + len(ce.files)
+}
+
+func (c *statCache) remove(e *list.Element) {
+	ce := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, ce.key)
+	c.size -= ce.weight()
+}
+
+func (c *statCache) drop(k cacheKey) {
+	if e, ok := c.entries[k]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *statCache) bump(dir string) {
+	if w, ok := c.watches[dir]; ok {
+		w.gen++
+	}
+}
+
+// forget drops the attributes and the listing of the file and the listing of
+// its directory. Should be called with the cache locked.
+func (c *statCache) forget(p string) {
+	dir := filepath.Dir(p)
+	c.bump(p)
+	c.bump(dir)
+	c.drop(cacheKey{p, false})
+	c.drop(cacheKey{p, true})
+	c.drop(cacheKey{dir, true})
+}
+
+// forgetTree forgets the removed or renamed file and everything below it.
+// Should be called with the cache locked.
+func (c *statCache) forgetTree(p string) {
+	prefix := p + string(filepath.Separator)
+	for dir := range c.watches {
+		if dir == p || strings.HasPrefix(dir, prefix) {
+			/* the watcher can not follow a watched directory which moved */
+			c.restart()
+			return
+		}
+	}
+
+	c.forget(p)
+}
+
+// invalidate drops the cached state of the files changed by the server
+func (c *statCache) invalidate(paths ...string) {
+	c.Lock()
+	for _, p := range paths {
+		c.forget(filepath.Clean(p))
+	}
+	c.Unlock()
+}
+
+// invalidateTree drops the cached state of the files removed or renamed by the server
+func (c *statCache) invalidateTree(paths ...string) {
+	c.Lock()
+	for _, p := range paths {
+		c.forgetTree(filepath.Clean(p))
+	}
+	c.Unlock()
+}
+
+func (c *statCache) close() {
+	c.Lock()
+	w := c.watcher
+	c.watcher = nil
+	c.Unlock()
+	if w != nil {
+		w.Close()
+	}
+}
@@ -0,0 +1,471 @@
+// Copyright 2009 The go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package go9p
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lflags2uflags converts the Linux open flags of Tlopen and Tlcreate.
+// O_APPEND is dropped: the client sends the offsets of the writes, and
+// WriteAt does not write to files opened with it.
+func lflags2uflags(flags uint32) int {
+	ret := os.O_RDONLY
+	switch flags & 3 {
+	case LOWRONLY:
+		ret = os.O_WRONLY
+
+	case LORDWR:
+		ret = os.O_RDWR
+	}
+
+	if flags&LOTRUNC != 0 {
+		ret |= os.O_TRUNC
+	}
+
+	if flags&LOEXCL != 0 {
+		ret |= os.O_EXCL
+	}
+
+	return ret
+}
+
+// lmode2perm converts the permissions of a Linux mode
+func lmode2perm(mode uint32) os.FileMode {
+	perm := os.FileMode(mode & 0777)
+	if mode&LSISUID != 0 {
+		perm |= os.ModeSetuid
+	}
+
+	if mode&LSISGID != 0 {
+		perm |= os.ModeSetgid
+	}
+
+	if mode&LSISVTX != 0 {
+		perm |= os.ModeSticky
+	}
+
+	return perm
+}
+
+// dir2Lmode converts the mode of a file to a Linux mode
+func dir2Lmode(d os.FileInfo) uint32 {
+	mode := d.Mode()
+	ret := uint32(mode.Perm())
+	switch {
+	case mode.IsDir():
+		ret |= LSIFDIR
+	case mode&os.ModeSymlink != 0:
+		ret |= LSIFLNK
+	case mode&os.ModeSocket != 0:
+		ret |= LSIFSOCK
+	case mode&os.ModeNamedPipe != 0:
+		ret |= LSIFIFO
+	case mode&os.ModeCharDevice != 0:
+		ret |= LSIFCHR
+	case mode&os.ModeDevice != 0:
+		ret |= LSIFBLK
+	default:
+		ret |= LSIFREG
+	}
+
+	if mode&os.ModeSetuid != 0 {
+		ret |= LSISUID
+	}
+
+	if mode&os.ModeSetgid != 0 {
+		ret |= LSISGID
+	}
+
+	if mode&os.ModeSticky != 0 {
+		ret |= LSISVTX
+	}
+
+	return ret
+}
+
+// dir2Lattr converts the attributes of a file to the ones of Rgetattr
+func dir2Lattr(d os.FileInfo) *Lattr {
+	mt := d.ModTime()
+	a := &Lattr{
+		Valid:     GABASIC,
+		Qid:       *dir2Qid(d),
+		Mode:      dir2Lmode(d),
+		Size:      uint64(d.Size()),
+		MtimeSec:  uint64(mt.Unix()),
+		MtimeNsec: uint64(mt.Nanosecond()),
+	}
+	sysLattr(d, a)
+	return a
+}
+
+func (*Ufs) Lopen(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+	st, err := fid.lstat(fid.path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	/* directories are listed by path, they need no file */
+	if !st.IsDir() {
+		var e error
+		fid.file, e = os.OpenFile(fid.path, lflags2uflags(tc.Flags), 0)
+		if e != nil {
+			req.RespondError(toError(e))
+			return
+		}
+
+		if tc.Flags&LOTRUNC != 0 {
+			fid.forget(fid.path)
+		}
+	}
+
+	req.RespondRlopen(dir2Qid(st), 0)
+}
+
+func (*Ufs) Lcreate(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+	path := fid.path + "/" + tc.Name
+	file, e := os.OpenFile(path, lflags2uflags(tc.Flags)|os.O_CREATE, lmode2perm(tc.Perm))
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	fid.forget(path)
+	st, err := fid.lstat(path)
+	if err != nil {
+		file.Close()
+		req.RespondError(err)
+		return
+	}
+
+	fid.path = path
+	fid.file = file
+	req.RespondRlcreate(dir2Qid(st), 0)
+}
+
+func (*Ufs) Getattr(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	st, err := fid.lstat(fid.path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	req.RespondRgetattr(dir2Lattr(st))
+}
+
+func (*Ufs) Setattr(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	a := &req.Tc.Attr
+	var e error
+	if a.Valid&SAMODE != 0 {
+		e = os.Chmod(fid.path, lmode2perm(a.Mode))
+	}
+
+	if e == nil && a.Valid&(SAUID|SAGID) != 0 {
+		uid, gid := -1, -1
+		if a.Valid&SAUID != 0 {
+			uid = int(a.Uid)
+		}
+
+		if a.Valid&SAGID != 0 {
+			gid = int(a.Gid)
+		}
+
+		e = os.Lchown(fid.path, uid, gid)
+	}
+
+	if e == nil && a.Valid&SASIZE != 0 {
+		if fid.file != nil {
+			e = fid.file.Truncate(int64(a.Size))
+		} else {
+			e = os.Truncate(fid.path, int64(a.Size))
+		}
+	}
+
+	if e == nil && a.Valid&(SAATIME|SAMTIME) != 0 {
+		/* the zero times are left unchanged */
+		var at, mt time.Time
+		now := time.Now()
+		if a.Valid&SAATIME != 0 {
+			at = now
+			if a.Valid&SAATIMESET != 0 {
+				at = time.Unix(int64(a.AtimeSec), int64(a.AtimeNsec))
+			}
+		}
+
+		if a.Valid&SAMTIME != 0 {
+			mt = now
+			if a.Valid&SAMTIMESET != 0 {
+				mt = time.Unix(int64(a.MtimeSec), int64(a.MtimeNsec))
+			}
+		}
+
+		e = os.Chtimes(fid.path, at, mt)
+	}
+
+	fid.forget(fid.path)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRempty()
+}
+
+func (ufs *Ufs) Readdir(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+	rc := req.Rc
+
+	/* the offsets of the entries are their index, after . and .. */
+	if tc.Offset == 0 || fid.dirs == nil {
+		dirs, e := fid.readdir()
+		if e != nil {
+			req.RespondError(toError(e))
+			return
+		}
+
+		fid.dirs = dirs
+	}
+
+	st, err := fid.lstat(fid.path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	/* the entries are written directly in the response */
+	InitRreaddir(rc, tc.Count)
+	count := 0
+	n := len(fid.dirs) + 2
+	for i := int(tc.Offset); i < n; i++ {
+		d := Dirent{Offset: uint64(i + 1)}
+		switch i {
+		case 0:
+			d.Qid, d.Type, d.Name = *dir2Qid(st), uint8(LSIFDIR>>12), "."
+
+		case 1:
+			d.Qid, d.Type, d.Name = *dir2Qid(st), uint8(LSIFDIR>>12), ".."
+			if filepath.Clean(fid.path) != filepath.Clean(ufs.Root) {
+				if pst, err := fid.lstat(filepath.Dir(fid.path)); err == nil {
+					d.Qid = *dir2Qid(pst)
+				}
+			}
+
+		default:
+			ds := fid.dirs[i-2]
+			d.Qid, d.Type, d.Name = *dir2Qid(ds), uint8(dir2Lmode(ds)>>12), ds.Name()
+		}
+
+		sz := PackDirent(&d, rc.Data[count:])
+		if sz == 0 {
+			break
+		}
+
+		count += sz
+	}
+
+	if count == 0 && int(tc.Offset) < n {
+		req.RespondError(&Error{"too small read size for dir entry", EINVAL})
+		return
+	}
+
+	SetRreaddirCount(rc, uint32(count))
+	req.Respond()
+}
+
+func (*Ufs) Mkdir(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+	path := fid.path + "/" + tc.Name
+	if e := os.Mkdir(path, lmode2perm(tc.Perm)); e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	fid.forget(path)
+	st, err := fid.lstat(path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	req.RespondRmkdir(dir2Qid(st))
+}
+
+func (*Ufs) Symlink(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+	path := fid.path + "/" + tc.Name
+	if e := os.Symlink(tc.Ext, path); e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	fid.forget(path)
+	st, err := fid.lstat(path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	req.RespondRsymlink(dir2Qid(st))
+}
+
+func (*Ufs) Mknod(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	tc := req.Tc
+
+	/* only regular files, special files are not created on the host */
+	if t := tc.Perm & LSIFMT; t != 0 && t != LSIFREG {
+		req.RespondError(Enotsup)
+		return
+	}
+
+	path := fid.path + "/" + tc.Name
+	file, e := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, lmode2perm(tc.Perm))
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	file.Close()
+	fid.forget(path)
+	st, err := fid.lstat(path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	req.RespondRmknod(dir2Qid(st))
+}
+
+func (*Ufs) Readlink(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	target, e := os.Readlink(fid.path)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRreadlink(target)
+}
+
+func (*Ufs) Link(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	src := req.Newfid.Aux.(*ufsFid).path
+	path := fid.path + "/" + req.Tc.Name
+	e := os.Link(src, path)
+	fid.forget(src, path)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRempty()
+}
+
+func (*Ufs) Rename(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	dest := req.Newfid.Aux.(*ufsFid).path + "/" + req.Tc.Name
+	e := os.Rename(fid.path, dest)
+	fid.forgetTree(fid.path, dest)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	fid.path = dest
+	req.RespondRempty()
+}
+
+func (*Ufs) Renameat(req *SrvReq) {
+	tc := req.Tc
+	src := req.Fid.Aux.(*ufsFid).path + "/" + tc.Name
+	dest := req.Newfid.Aux.(*ufsFid).path + "/" + tc.Newname
+	e := os.Rename(src, dest)
+	req.Fid.Aux.(*ufsFid).forgetTree(src, dest)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRempty()
+}
+
+func (*Ufs) Unlinkat(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	path := fid.path + "/" + req.Tc.Name
+	st, err := fid.lstat(path)
+	if err != nil {
+		req.RespondError(err)
+		return
+	}
+
+	/* only remove what unlink(2) or rmdir(2) would */
+	rmdir := (req.Tc.Flags & LATREMOVEDIR) != 0
+	if st.IsDir() != rmdir {
+		if rmdir {
+			req.RespondError(Enotdir)
+		} else {
+			req.RespondError(&Error{"is a directory", EISDIR})
+		}
+		return
+	}
+
+	e := os.Remove(path)
+	fid.forgetTree(path)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRempty()
+}
+
+func (*Ufs) Statfs(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	s, e := statfs(fid.path)
+	if e != nil {
+		req.RespondError(toError(e))
+		return
+	}
+
+	req.RespondRstatfs(s)
+}
+
+func (*Ufs) Fsync(req *SrvReq) {
+	fid := req.Fid.Aux.(*ufsFid)
+	if fid.file != nil {
+		if e := fid.file.Sync(); e != nil {
+			req.RespondError(toError(e))
+			return
+		}
+	}
+
+	req.RespondRempty()
+}
+
+// Flock grants the POSIX locks: the Linux client takes them on its side
+// before asking the server, so they hold between the processes of the guest,
+// but not against the ones of the host.
+func (*Ufs) Flock(req *SrvReq) {
+	req.RespondRlock(LOCKSUCCESS)
+}
+
+// Getlock reports no conflicting lock, the client checked its own locks.
+func (*Ufs) Getlock(req *SrvReq) {
+	l := req.Tc.Flock
+	l.Type = LOCKUNLCK
+	req.RespondRgetlock(&l)
+}
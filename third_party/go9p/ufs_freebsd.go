@@ -198,6 +198,7 @@ func (u *Ufs) Wstat(req *SrvReq) {
 			req.RespondError(toError(err))
 			return
 		}
+		fid.forgetTree(fid.path, destpath)
 		fid.path = destpath
 	}
 
@@ -235,5 +236,25 @@ func (u *Ufs) Wstat(req *SrvReq) {
 		}
 	}
 
+	fid.forget(fid.path)
 	req.RespondRwstat()
 }
+
+func statfs(path string) (*Statfs, error) {
+	var s syscall.Statfs_t
+	if err := syscall.Statfs(path, &s); err != nil {
+		return nil, err
+	}
+
+	return &Statfs{
+		Type:    s.Type,
+		Bsize:   uint32(s.Bsize),
+		Blocks:  s.Blocks,
+		Bfree:   s.Bfree,
+		Bavail:  uint64(s.Bavail),
+		Files:   s.Files,
+		Ffree:   uint64(s.Ffree),
+		Fsid:    uint64(uint32(s.Fsid.Val[0])) | uint64(uint32(s.Fsid.Val[1]))<<32,
+		Namelen: s.Namemax,
+	}, nil
+}
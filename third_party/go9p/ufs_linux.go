@@ -194,6 +194,7 @@ func (u *Ufs) Wstat(req *SrvReq) {
 			req.RespondError(toError(err))
 			return
 		}
+		fid.forgetTree(fid.path, destpath)
 		fid.path = destpath
 	}
 
@@ -229,5 +230,46 @@ func (u *Ufs) Wstat(req *SrvReq) {
 		}
 	}
 
+	fid.forget(fid.path)
 	req.RespondRwstat()
 }
+
+// maxCacheWatches bounds the directories watched by the stat cache
+const maxCacheWatches = 4096
+
+// linuxErrno returns the number of the error for a Linux client
+func linuxErrno(e syscall.Errno) uint32 {
+	return uint32(e)
+}
+
+// sysLattr sets the attributes of Rgetattr which are not portable
+func sysLattr(d os.FileInfo, a *Lattr) {
+	stat := d.Sys().(*syscall.Stat_t)
+	a.Uid = stat.Uid
+	a.Gid = stat.Gid
+	a.Nlink = uint64(stat.Nlink)
+	a.Rdev = uint64(stat.Rdev)
+	a.Blksize = uint64(stat.Blksize)
+	a.Blocks = uint64(stat.Blocks)
+	a.AtimeSec, a.AtimeNsec = uint64(stat.Atim.Sec), uint64(stat.Atim.Nsec)
+	a.CtimeSec, a.CtimeNsec = uint64(stat.Ctim.Sec), uint64(stat.Ctim.Nsec)
+}
+
+func statfs(path string) (*Statfs, error) {
+	var s syscall.Statfs_t
+	if err := syscall.Statfs(path, &s); err != nil {
+		return nil, err
+	}
+
+	return &Statfs{
+		Type:    uint32(s.Type),
+		Bsize:   uint32(s.Bsize),
+		Blocks:  s.Blocks,
+		Bfree:   s.Bfree,
+		Bavail:  s.Bavail,
+		Files:   s.Files,
+		Ffree:   s.Ffree,
+		Fsid:    uint64(uint32(s.Fsid.X__val[0])) | uint64(uint32(s.Fsid.X__val[1]))<<32,
+		Namelen: uint32(s.Namelen),
+	}, nil
+}
@@ -0,0 +1,564 @@
+// Copyright 2009 The go9p Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+
+package go9p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lclient is a minimal 9P2000.L client, sending concurrent requests over a
+// single connection as the Linux kernel does.
+type lclient struct {
+	conn  net.Conn
+	msize uint32
+	fids  uint32 // last allocated fid, the root is fid 0
+
+	wlk  sync.Mutex // serializes the writes
+	mu   sync.Mutex
+	tags map[uint16]chan *Fcall
+	next uint16
+}
+
+// startUfs serves the directory on a loopback socket, returning its address
+func startUfs(tb testing.TB, root string) string {
+	ufs := new(Ufs)
+	ufs.Dotu = true
+	ufs.Dotl = true
+	ufs.Id = "ufs"
+	ufs.Root = root
+	ufs.Start(ufs)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	go ufs.StartListener(l)
+	tb.Cleanup(func() {
+		l.Close()
+		if c := ufs.statCache(); c != nil {
+			c.close()
+		}
+	})
+
+	return l.Addr().String()
+}
+
+// dialUfs negotiates 9P2000.L and attaches the root as fid 0
+func dialUfs(tb testing.TB, addr string, msize uint32) *lclient {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	c := &lclient{conn: conn, msize: msize, tags: make(map[uint16]chan *Fcall)}
+	tb.Cleanup(func() { conn.Close() })
+
+	fc := NewFcall(msize)
+	PackTversion(fc, msize, "9P2000.L")
+	if _, err := conn.Write(fc.Pkt); err != nil {
+		tb.Fatal(err)
+	}
+
+	rc, err := c.recv()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if rc.Type != Rversion || rc.Version != "9P2000.L" {
+		tb.Fatalf("version: got %v", rc)
+	}
+
+	c.msize = rc.Msize
+	go c.reader()
+
+	fc = c.fcall()
+	PackTattach(fc, 0, NOFID, "", "", uint32(os.Getuid()), true)
+	if _, err := c.rpc(fc); err != nil {
+		tb.Fatalf("attach: %v", err)
+	}
+
+	return c
+}
+
+// fcall returns a buffer for a request, the requests sent carry little data
+func (c *lclient) fcall() *Fcall { return NewFcall(8192) }
+
+func (c *lclient) fid() uint32 { return atomic.AddUint32(&c.fids, 1) }
+
+func (c *lclient) recv() (*Fcall, error) {
+	var sz [4]byte
+	if _, err := io.ReadFull(c.conn, sz[:]); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.LittleEndian.Uint32(sz[:]))
+	copy(buf, sz[:])
+	if _, err := io.ReadFull(c.conn, buf[4:]); err != nil {
+		return nil, err
+	}
+
+	fc, err, _ := Unpack(buf, true)
+	return fc, err
+}
+
+// reader dispatches the responses to the waiting requests
+func (c *lclient) reader() {
+	for {
+		rc, err := c.recv()
+		c.mu.Lock()
+		if err != nil {
+			for tag, ch := range c.tags {
+				close(ch)
+				delete(c.tags, tag)
+			}
+			c.mu.Unlock()
+			return
+		}
+
+		ch := c.tags[rc.Tag]
+		delete(c.tags, rc.Tag)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- rc
+		}
+	}
+}
+
+// rpc sends the request and waits for its response, returning the error of an Rlerror
+func (c *lclient) rpc(fc *Fcall) (*Fcall, error) {
+	ch := make(chan *Fcall, 1)
+	c.mu.Lock()
+	for {
+		c.next++
+		if _, ok := c.tags[c.next]; !ok && c.next != NOTAG {
+			break
+		}
+	}
+	tag := c.next
+	c.tags[tag] = ch
+	c.mu.Unlock()
+
+	SetTag(fc, tag)
+	c.wlk.Lock()
+	_, err := c.conn.Write(fc.Pkt)
+	c.wlk.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	rc, ok := <-ch
+	if !ok {
+		return nil, errors.New("connection closed")
+	}
+
+	if rc.Type == Rlerror {
+		return rc, &Error{fmt.Sprintf("error %d", rc.Ecode), rc.Ecode}
+	}
+
+	if rc.Type != fc.Type+1 {
+		return rc, fmt.Errorf("unexpected response %v to %v", rc, fc)
+	}
+
+	return rc, nil
+}
+
+func (c *lclient) walk(fid uint32, names ...string) (uint32, error) {
+	newfid := c.fid()
+	fc := c.fcall()
+	PackTwalk(fc, fid, newfid, names)
+	rc, err := c.rpc(fc)
+	if err != nil {
+		return NOFID, err
+	}
+
+	if len(rc.Wqid) != len(names) {
+		return NOFID, &Error{"file not found", ENOENT}
+	}
+
+	return newfid, nil
+}
+
+func (c *lclient) clunk(fid uint32) error {
+	fc := c.fcall()
+	PackTclunk(fc, fid)
+	_, err := c.rpc(fc)
+	return err
+}
+
+func (c *lclient) getattr(fid uint32) (*Lattr, error) {
+	fc := c.fcall()
+	PackTgetattr(fc, fid, GABASIC)
+	rc, err := c.rpc(fc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rc.Attr, nil
+}
+
+// stat walks to the file and returns its attributes
+func (c *lclient) stat(names ...string) (*Lattr, error) {
+	fid, err := c.walk(0, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.clunk(fid)
+	return c.getattr(fid)
+}
+
+func (c *lclient) lopen(fid, flags uint32) error {
+	fc := c.fcall()
+	PackTlopen(fc, fid, flags)
+	_, err := c.rpc(fc)
+	return err
+}
+
+// readdir returns the names of the files of the directory
+func (c *lclient) readdir(names ...string) ([]string, error) {
+	fid, err := c.walk(0, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.clunk(fid)
+	if err := c.lopen(fid, LORDONLY); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var offset uint64
+	for {
+		fc := c.fcall()
+		PackTreaddir(fc, fid, offset, c.msize-IOHDRSZ)
+		rc, err := c.rpc(fc)
+		if err != nil {
+			return nil, err
+		}
+
+		ds, err := UnpackDirents(rc.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ds) == 0 {
+			sort.Strings(files)
+			return files, nil
+		}
+
+		for _, d := range ds {
+			files = append(files, d.Name)
+			offset = d.Offset
+		}
+	}
+}
+
+// read returns the count of the bytes of the file, read in messages of the msize
+func (c *lclient) read(names ...string) (int, error) {
+	fid, err := c.walk(0, names...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer c.clunk(fid)
+	if err := c.lopen(fid, LORDONLY); err != nil {
+		return 0, err
+	}
+
+	var offset uint64
+	for {
+		fc := c.fcall()
+		PackTread(fc, fid, offset, c.msize-IOHDRSZ)
+		rc, err := c.rpc(fc)
+		if err != nil {
+			return 0, err
+		}
+
+		if rc.Count == 0 {
+			return int(offset), nil
+		}
+
+		offset += uint64(rc.Count)
+	}
+}
+
+func errnum(err error) uint32 {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Errornum
+	}
+
+	return 0
+}
+
+func writeFiles(tb testing.TB, dir string, n, size int) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		tb.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte{'x'}, size)
+	for i := 0; i < n; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", i)), data, 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestDotlVersion(t *testing.T) {
+	addr := startUfs(t, t.TempDir())
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	c := &lclient{conn: conn}
+	for _, tc := range []struct{ version, want string }{
+		{"9P2000.L", "9P2000.L"},
+		{"9P2000.u", "9P2000.u"},
+		{"9P2000", "9P2000"},
+	} {
+		fc := NewFcall(MSIZE)
+		PackTversion(fc, MSIZE, tc.version)
+		if _, err := conn.Write(fc.Pkt); err != nil {
+			t.Fatal(err)
+		}
+
+		rc, err := c.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rc.Version != tc.want {
+			t.Errorf("version %s: got %s, want %s", tc.version, rc.Version, tc.want)
+		}
+	}
+}
+
+func TestDotl(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := dialUfs(t, startUfs(t, root), MSIZE)
+
+	a, err := c.stat("a")
+	if err != nil {
+		t.Fatalf("getattr a: %v", err)
+	}
+
+	if a.Size != 5 || a.Mode&LSIFMT != LSIFREG || a.Mode&0777 != 0644 {
+		t.Errorf("getattr a: got size %d mode %o", a.Size, a.Mode)
+	}
+
+	files, err := c.readdir()
+	if err != nil {
+		t.Fatalf("readdir: %v", err)
+	}
+
+	if fmt.Sprint(files) != "[. .. a sub]" {
+		t.Errorf("readdir: got %v", files)
+	}
+
+	if _, err := c.stat("missing"); errnum(err) != ENOENT {
+		t.Errorf("walk missing: got %v, want ENOENT", err)
+	}
+
+	/* the changes of the server must be seen at once */
+	dfid, err := c.walk(0, "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc := c.fcall()
+	PackTlcreate(fc, dfid, "c", LOCREAT|LORDWR, 0600, 0)
+	if _, err := c.rpc(fc); err != nil {
+		t.Fatalf("lcreate: %v", err)
+	}
+
+	if st, err := c.stat("sub", "c"); err != nil || st.Size != 0 {
+		t.Fatalf("getattr sub/c: got %v %v", st, err)
+	}
+
+	fc = c.fcall()
+	PackTwrite(fc, dfid, 0, 3, []byte("abc"))
+	if _, err := c.rpc(fc); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if st, err := c.stat("sub", "c"); err != nil || st.Size != 3 {
+		t.Errorf("getattr sub/c after write: got %v %v", st, err)
+	}
+
+	c.clunk(dfid)
+	if dfid, err = c.walk(0, "sub"); err != nil {
+		t.Fatal(err)
+	}
+
+	fc = c.fcall()
+	PackTrenameat(fc, dfid, "c", dfid, "d")
+	if _, err := c.rpc(fc); err != nil {
+		t.Fatalf("renameat: %v", err)
+	}
+
+	if _, err := c.stat("sub", "c"); errnum(err) != ENOENT {
+		t.Errorf("walk sub/c after renameat: got %v, want ENOENT", err)
+	}
+
+	if files, err := c.readdir("sub"); err != nil || fmt.Sprint(files) != "[. .. d]" {
+		t.Errorf("readdir sub after renameat: got %v %v", files, err)
+	}
+
+	fc = c.fcall()
+	PackTunlinkat(fc, dfid, "d", 0)
+	if _, err := c.rpc(fc); err != nil {
+		t.Fatalf("unlinkat: %v", err)
+	}
+
+	if files, err := c.readdir("sub"); err != nil || fmt.Sprint(files) != "[. ..]" {
+		t.Errorf("readdir sub after unlinkat: got %v %v", files, err)
+	}
+
+	fc = c.fcall()
+	PackTunlinkat(fc, 0, "sub", 0)
+	if _, err := c.rpc(fc); errnum(err) != EISDIR {
+		t.Errorf("unlinkat sub: got %v, want EISDIR", err)
+	}
+}
+
+// TestCacheHostChange checks that the changes made on the host are seen
+func TestCacheHostChange(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := dialUfs(t, startUfs(t, root), MSIZE)
+	if st, err := c.stat("a"); err != nil || st.Size != 5 {
+		t.Fatalf("getattr a: got %v %v", st, err)
+	}
+
+	if _, err := c.readdir(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a"), []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "b"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		st, err := c.stat("a")
+		files, _ := c.readdir()
+		if err == nil && st.Size == 11 && fmt.Sprint(files) == "[. .. a b]" {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("host changes not seen: got %v %v, files %v", st, err, files)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func BenchmarkWalkGetattr(b *testing.B) {
+	root := b.TempDir()
+	writeFiles(b, filepath.Join(root, "node_modules", "pkg"), 100, 0)
+	c := dialUfs(b, startUfs(b, root), MSIZE)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.stat("node_modules", "pkg", fmt.Sprintf("f%d", i%100)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWalkGetattrParallel sends concurrent requests over a single connection
+func BenchmarkWalkGetattrParallel(b *testing.B) {
+	root := b.TempDir()
+	writeFiles(b, filepath.Join(root, "node_modules", "pkg"), 100, 0)
+	c := dialUfs(b, startUfs(b, root), MSIZE)
+
+	var n uint32
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := atomic.AddUint32(&n, 1)
+			if _, err := c.stat("node_modules", "pkg", fmt.Sprintf("f%d", i%100)); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkReaddir(b *testing.B) {
+	root := b.TempDir()
+	writeFiles(b, root, 1000, 0)
+	c := dialUfs(b, startUfs(b, root), MSIZE)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		files, err := c.readdir()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if len(files) != 1002 {
+			b.Fatalf("readdir: got %d files", len(files))
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	const size = 16 << 20
+	root := b.TempDir()
+	writeFiles(b, root, 1, size)
+	addr := startUfs(b, root)
+
+	for _, msize := range []uint32{8192 + IOHDRSZ, 65536 + IOHDRSZ, 262144 + IOHDRSZ, MSIZE} {
+		b.Run(fmt.Sprintf("msize=%d", msize-IOHDRSZ), func(b *testing.B) {
+			c := dialUfs(b, addr, msize)
+			b.SetBytes(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				n, err := c.read("f0")
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if n != size {
+					b.Fatalf("read: got %d bytes", n)
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"golang.org/x/sys/windows"
 )
 
 func atime(fi os.FileInfo) time.Time {
@@ -210,6 +212,7 @@ func (u *Ufs) Wstat(req *SrvReq) {
 			req.RespondError(toError(err))
 			return
 		}
+		fid.forgetTree(fid.path, destpath)
 		fid.path = destpath
 	}
 
@@ -245,5 +248,66 @@ func (u *Ufs) Wstat(req *SrvReq) {
 	// 	}
 	// }
 
+	fid.forget(fid.path)
 	req.RespondRwstat()
 }
+
+// maxCacheWatches bounds the directories watched by the stat cache
+const maxCacheWatches = 4096
+
+// windowsErrnos are the Linux numbers of the errors of the Windows file functions
+var windowsErrnos = map[syscall.Errno]uint32{
+	2:   ENOENT,  // ERROR_FILE_NOT_FOUND
+	3:   ENOENT,  // ERROR_PATH_NOT_FOUND
+	5:   13,      // ERROR_ACCESS_DENIED: EACCES
+	17:  18,      // ERROR_NOT_SAME_DEVICE: EXDEV
+	32:  16,      // ERROR_SHARING_VIOLATION: EBUSY
+	39:  28,      // ERROR_HANDLE_DISK_FULL: ENOSPC
+	80:  EEXIST,  // ERROR_FILE_EXISTS
+	87:  EINVAL,  // ERROR_INVALID_PARAMETER
+	112: 28,      // ERROR_DISK_FULL: ENOSPC
+	123: EINVAL,  // ERROR_INVALID_NAME
+	145: 39,      // ERROR_DIR_NOT_EMPTY: ENOTEMPTY
+	183: EEXIST,  // ERROR_ALREADY_EXISTS
+	267: ENOTDIR, // ERROR_DIRECTORY
+}
+
+// linuxErrno returns the number of the error for a Linux client
+func linuxErrno(e syscall.Errno) uint32 {
+	if n, ok := windowsErrnos[e]; ok {
+		return n
+	}
+
+	return EIO
+}
+
+// sysLattr sets the attributes of Rgetattr which are not portable
+func sysLattr(d os.FileInfo, a *Lattr) {
+	at := atime(d)
+	ct := time.Unix(0, d.Sys().(*syscall.Win32FileAttributeData).CreationTime.Nanoseconds())
+	a.Nlink = 1
+	a.Blksize = 4096
+	a.Blocks = (uint64(d.Size()) + 511) / 512
+	a.AtimeSec, a.AtimeNsec = uint64(at.Unix()), uint64(at.Nanosecond())
+	a.CtimeSec, a.CtimeNsec = uint64(ct.Unix()), uint64(ct.Nanosecond())
+}
+
+func statfs(path string) (*Statfs, error) {
+	var avail, total, free uint64
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := windows.GetDiskFreeSpaceEx(p, &avail, &total, &free); err != nil {
+		return nil, err
+	}
+
+	return &Statfs{
+		Bsize:   4096,
+		Blocks:  total / 4096,
+		Bfree:   free / 4096,
+		Bavail:  avail / 4096,
+		Namelen: 255,
+	}, nil
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // Creates a Fcall value from the on-the-wire representation. If
-// dotu is true, reads 9P2000.u messages. Returns the unpacked message,
+// dotu is true, reads 9P2000.u messages. The 9P2000.L messages, which
+// have their own types, are always read. Returns the unpacked message,
 // error and how many bytes from the buffer were used by the message.
 func Unpack(buf []byte, dotu bool) (fc *Fcall, err error, fcsz int) {
 	var m uint16
@@ -38,7 +39,16 @@ func Unpack(buf []byte, dotu bool) (fc *Fcall, err error, fcsz int) {
 	p = p[0 : fc.Size-7]
 	fc.Pkt = buf[0:fc.Size]
 	fcsz = int(fc.Size)
-	if fc.Type < Tversion || fc.Type >= Tlast {
+	if fc.Type < Tversion {
+		/* 9P2000.L messages */
+		if err = unpackDotl(fc, p); err != nil {
+			return nil, err, 0
+		}
+
+		return
+	}
+
+	if fc.Type >= Tlast {
 		return nil, &Error{"invalid id", EINVAL}, 0
 	}
 